@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"runtime"
 	"runtime/debug"
 	"sync"
 
@@ -16,6 +17,8 @@ import (
 	"github.com/filecoin-project/go-f3/internal/measurements"
 )
 
+var _ gpbft.BatchVerifier = (*Verifier)(nil)
+
 type Verifier struct {
 	scheme   *bdn.Scheme
 	keyGroup kyber.Group
@@ -102,3 +105,27 @@ func (v *Verifier) Verify(pubKey gpbft.PubKey, msg, sig []byte) (_err error) {
 
 	return v.scheme.Verify(point, msg, sig)
 }
+
+// BatchVerify verifies the given signatures concurrently, using at most
+// GOMAXPROCS goroutines at a time.
+func (v *Verifier) BatchVerify(pubKeys []gpbft.PubKey, msgs, sigs [][]byte) []error {
+	errs := make([]error, len(pubKeys))
+	workers := min(runtime.GOMAXPROCS(0), len(pubKeys))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = v.Verify(pubKeys[i], msgs[i], sigs[i])
+			}
+		}()
+	}
+	for i := range pubKeys {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return errs
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/kyber/v4/sign/bdn"
 
+	"github.com/filecoin-project/go-f3/gpbft"
 	bls12381 "github.com/filecoin-project/go-f3/internal/gnark"
 )
 
@@ -31,3 +32,39 @@ func BenchmarkBLSSigning(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestBatchVerify(t *testing.T) {
+	var (
+		blsSuit   = bls12381.NewSuiteBLS12381()
+		blsSchema = bdn.NewSchemeOnG2(blsSuit)
+	)
+	privKey, pubKey := blsSchema.NewKeyPair(blsSuit.RandomStream())
+	pubKeyB, err := pubKey.MarshalBinary()
+	require.NoError(t, err)
+	signer := SignerWithKeyOnG1(pubKeyB, privKey)
+	verifier := VerifierWithKeyOnG1()
+
+	var pubKeys []gpbft.PubKey
+	var msgs, sigs [][]byte
+	for i := 0; i < 10; i++ {
+		msg := []byte{byte(i)}
+		sig, err := signer.Sign(context.Background(), pubKeyB, msg)
+		require.NoError(t, err)
+		pubKeys = append(pubKeys, pubKeyB)
+		msgs = append(msgs, msg)
+		sigs = append(sigs, sig)
+	}
+	// A signature over another message fails only itself.
+	sigs[3] = sigs[4]
+
+	errs := verifier.BatchVerify(pubKeys, msgs, sigs)
+	require.Len(t, errs, len(sigs))
+	for i, err := range errs {
+		if i == 3 {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err, "signature %d", i)
+		}
+	}
+	require.Empty(t, verifier.BatchVerify(nil, nil, nil))
+}
@@ -8,6 +8,7 @@ import (
 	"math"
 	"sort"
 
+	certs "github.com/filecoin-project/go-f3/certs"
 	gpbft "github.com/filecoin-project/go-f3/gpbft"
 	cid "github.com/ipfs/go-cid"
 	cbg "github.com/whyrusleeping/cbor-gen"
@@ -19,7 +20,7 @@ var _ = cid.Undef
 var _ = math.E
 var _ = sort.Sort
 
-var lengthBufRequest = []byte{131}
+var lengthBufRequest = []byte{134}
 
 func (t *Request) MarshalCBOR(w io.Writer) error {
 	if t == nil {
@@ -49,6 +50,22 @@ func (t *Request) MarshalCBOR(w io.Writer) error {
 	if err := cbg.WriteBool(w, t.IncludePowerTable); err != nil {
 		return err
 	}
+
+	// t.PowerTablesOnly (bool) (bool)
+	if err := cbg.WriteBool(w, t.PowerTablesOnly); err != nil {
+		return err
+	}
+
+	// t.BasePowerTableInstance (uint64) (uint64)
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.BasePowerTableInstance)); err != nil {
+		return err
+	}
+
+	// t.AcceptCompression (bool) (bool)
+	if err := cbg.WriteBool(w, t.AcceptCompression); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -71,7 +88,7 @@ func (t *Request) UnmarshalCBOR(r io.Reader) (err error) {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 3 {
+	if extra != 6 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
@@ -120,10 +137,58 @@ func (t *Request) UnmarshalCBOR(r io.Reader) (err error) {
 	default:
 		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
 	}
+	// t.PowerTablesOnly (bool) (bool)
+
+	maj, extra, err = cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.PowerTablesOnly = false
+	case 21:
+		t.PowerTablesOnly = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
+	// t.BasePowerTableInstance (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cr.ReadHeader()
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.BasePowerTableInstance = uint64(extra)
+
+	}
+	// t.AcceptCompression (bool) (bool)
+
+	maj, extra, err = cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.AcceptCompression = false
+	case 21:
+		t.AcceptCompression = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
 	return nil
 }
 
-var lengthBufResponseHeader = []byte{130}
+var lengthBufResponseHeader = []byte{133}
 
 func (t *ResponseHeader) MarshalCBOR(w io.Writer) error {
 	if t == nil {
@@ -157,6 +222,31 @@ func (t *ResponseHeader) MarshalCBOR(w io.Writer) error {
 		}
 
 	}
+
+	// t.PowerTableIsDelta (bool) (bool)
+	if err := cbg.WriteBool(w, t.PowerTableIsDelta); err != nil {
+		return err
+	}
+
+	// t.PowerTableDelta (certs.PowerTableDiff) (slice)
+	if len(t.PowerTableDelta) > 8192 {
+		return xerrors.Errorf("Slice value in field t.PowerTableDelta was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.PowerTableDelta))); err != nil {
+		return err
+	}
+	for _, v := range t.PowerTableDelta {
+		if err := v.MarshalCBOR(cw); err != nil {
+			return err
+		}
+
+	}
+
+	// t.Compressed (bool) (bool)
+	if err := cbg.WriteBool(w, t.Compressed); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -179,6 +269,297 @@ func (t *ResponseHeader) UnmarshalCBOR(r io.Reader) (err error) {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
+	if extra != 5 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.PendingInstance (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cr.ReadHeader()
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.PendingInstance = uint64(extra)
+
+	}
+	// t.PowerTable (gpbft.PowerEntries) (slice)
+
+	maj, extra, err = cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+
+	if extra > 8192 {
+		return fmt.Errorf("t.PowerTable: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.PowerTable = make([]gpbft.PowerEntry, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+		{
+			var maj byte
+			var extra uint64
+			var err error
+			_ = maj
+			_ = extra
+			_ = err
+
+			{
+
+				if err := t.PowerTable[i].UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.PowerTable[i]: %w", err)
+				}
+
+			}
+
+		}
+	}
+	// t.PowerTableIsDelta (bool) (bool)
+
+	maj, extra, err = cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.PowerTableIsDelta = false
+	case 21:
+		t.PowerTableIsDelta = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
+	// t.PowerTableDelta (certs.PowerTableDiff) (slice)
+
+	maj, extra, err = cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+
+	if extra > 8192 {
+		return fmt.Errorf("t.PowerTableDelta: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.PowerTableDelta = make([]certs.PowerTableDelta, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+		{
+			var maj byte
+			var extra uint64
+			var err error
+			_ = maj
+			_ = extra
+			_ = err
+
+			{
+
+				if err := t.PowerTableDelta[i].UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.PowerTableDelta[i]: %w", err)
+				}
+
+			}
+
+		}
+	}
+	// t.Compressed (bool) (bool)
+
+	maj, extra, err = cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.Compressed = false
+	case 21:
+		t.Compressed = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
+	return nil
+}
+
+var lengthBufRequestV1 = []byte{131}
+
+func (t *RequestV1) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write(lengthBufRequestV1); err != nil {
+		return err
+	}
+
+	// t.FirstInstance (uint64) (uint64)
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.FirstInstance)); err != nil {
+		return err
+	}
+
+	// t.Limit (uint64) (uint64)
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Limit)); err != nil {
+		return err
+	}
+
+	// t.IncludePowerTable (bool) (bool)
+	if err := cbg.WriteBool(w, t.IncludePowerTable); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *RequestV1) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = RequestV1{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 3 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.FirstInstance (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cr.ReadHeader()
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.FirstInstance = uint64(extra)
+
+	}
+	// t.Limit (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cr.ReadHeader()
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Limit = uint64(extra)
+
+	}
+	// t.IncludePowerTable (bool) (bool)
+
+	maj, extra, err = cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.IncludePowerTable = false
+	case 21:
+		t.IncludePowerTable = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
+	return nil
+}
+
+var lengthBufResponseHeaderV1 = []byte{130}
+
+func (t *ResponseHeaderV1) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write(lengthBufResponseHeaderV1); err != nil {
+		return err
+	}
+
+	// t.PendingInstance (uint64) (uint64)
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.PendingInstance)); err != nil {
+		return err
+	}
+
+	// t.PowerTable (gpbft.PowerEntries) (slice)
+	if len(t.PowerTable) > 8192 {
+		return xerrors.Errorf("Slice value in field t.PowerTable was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.PowerTable))); err != nil {
+		return err
+	}
+	for _, v := range t.PowerTable {
+		if err := v.MarshalCBOR(cw); err != nil {
+			return err
+		}
+
+	}
+	return nil
+}
+
+func (t *ResponseHeaderV1) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ResponseHeaderV1{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
 	if extra != 2 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
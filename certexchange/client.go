@@ -14,6 +14,7 @@ import (
 	"github.com/filecoin-project/go-f3/internal/clock"
 	"github.com/filecoin-project/go-f3/internal/measurements"
 	cid "github.com/ipfs/go-cid"
+	"github.com/klauspost/compress/zstd"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"go.opentelemetry.io/otel/metric"
@@ -62,15 +63,22 @@ func (c *Client) Request(ctx context.Context, p peer.ID, req *Request) (_rh *Res
 		metrics.requestLatency.Record(ctx, time.Since(requestStart).Seconds(), metric.WithAttributes(
 			measurements.Status(ctx, _err),
 			measurements.AttrDialSucceeded.Bool(dialSucceeded),
+			measurements.AttrNetwork.String(string(c.NetworkName)),
 		))
 	}(time.Now())
 
-	proto := FetchProtocolName(c.NetworkName)
-	stream, err := c.Host.NewStream(ctx, p, proto)
+	// Fall back to the first version of the protocol for peers that predate the
+	// current one, which ignore the request fields it does not carry.
+	stream, err := c.Host.NewStream(ctx, p, FetchProtocolName(c.NetworkName), FetchProtocolNameV1(c.NetworkName))
 	if err != nil {
 		return nil, nil, err
 	}
 	dialSucceeded = true
+	v1 := stream.Protocol() == FetchProtocolNameV1(c.NetworkName)
+	if v1 && req.PowerTablesOnly {
+		_ = stream.Reset()
+		return nil, nil, fmt.Errorf("peer %s does not serve power tables only", p)
+	}
 
 	// Reset the stream if the parent context is canceled. We never call the returned stop
 	// function because we call the cancel function returned by `withDeadline` (which cancels
@@ -82,10 +90,11 @@ func (c *Client) Request(ctx context.Context, p peer.ID, req *Request) (_rh *Res
 		_ = stream.SetDeadline(deadline)
 	}
 
-	br := &io.LimitedReader{R: bufio.NewReader(stream), N: 100}
+	sr := bufio.NewReader(stream)
+	br := &io.LimitedReader{R: sr, N: 100}
 	bw := bufio.NewWriter(stream)
 
-	if err := req.MarshalCBOR(bw); err != nil {
+	if err := writeRequest(bw, v1, req); err != nil {
 		log.Debugw("failed to marshal certificate exchange request to peer", "peer", p, "error", err)
 		return nil, nil, err
 	}
@@ -101,6 +110,7 @@ func (c *Client) Request(ctx context.Context, p peer.ID, req *Request) (_rh *Res
 		if cancel != nil {
 			metrics.totalResponseTime.Record(ctx, time.Since(responseStart).Seconds(), metric.WithAttributes(
 				measurements.Status(ctx, _err),
+				measurements.AttrNetwork.String(string(c.NetworkName)),
 			))
 		}
 	}()
@@ -109,7 +119,7 @@ func (c *Client) Request(ctx context.Context, p peer.ID, req *Request) (_rh *Res
 	if req.IncludePowerTable {
 		br.N = maxPowerTableSize
 	}
-	err = resp.UnmarshalCBOR(br)
+	err = readResponseHeader(br, v1, &resp)
 	if err != nil {
 		log.Debugw("failed to unmarshal certificate exchange response header from peer", "peer", p, "error", err)
 		return nil, nil, err
@@ -129,6 +139,15 @@ func (c *Client) Request(ctx context.Context, p peer.ID, req *Request) (_rh *Res
 		return &resp, ch, nil
 	}
 
+	zr, err := decompressor(sr, &resp)
+	if err != nil {
+		log.Debugw("failed to decompress certificate exchange response from peer", "peer", p, "error", err)
+		return nil, nil, err
+	}
+	if zr != nil {
+		br.R = zr
+	}
+
 	ch := make(chan *certs.FinalityCertificate, 1)
 	// copy this in case the caller decides to re-use the request object...
 	request := *req
@@ -145,6 +164,7 @@ func (c *Client) Request(ctx context.Context, p peer.ID, req *Request) (_rh *Res
 
 			metrics.totalResponseTime.Record(ctx, time.Since(responseStart).Seconds(), metric.WithAttributes(
 				measurements.Status(ctx, _err),
+				measurements.AttrNetwork.String(string(c.NetworkName)),
 			))
 
 			// Reset immediately instead of waiting for it to get run async (better cleanup
@@ -153,6 +173,9 @@ func (c *Client) Request(ctx context.Context, p peer.ID, req *Request) (_rh *Res
 				_ = stream.Reset()
 			}
 
+			if zr != nil {
+				zr.Close()
+			}
 			cancelReq()
 			close(ch)
 		}()
@@ -188,6 +211,103 @@ func (c *Client) Request(ctx context.Context, p peer.ID, req *Request) (_rh *Res
 	return &resp, ch, nil
 }
 
+// RequestPowerTables requests the power tables of up to limit sequential
+// instances starting at the given first instance from the specified peer,
+// without the finality certificates. The returned power tables are unvalidated.
+func (c *Client) RequestPowerTables(ctx context.Context, p peer.ID, first, limit uint64) (_rh *ResponseHeader, _pts []gpbft.PowerEntries, _err error) {
+	defer func() {
+		if perr := recover(); perr != nil {
+			_err = fmt.Errorf("panicked requesting power tables from peer %s: %v\n%s", p, perr, string(debug.Stack()))
+			log.Error(_err)
+		}
+	}()
+
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	var dialSucceeded bool
+	defer func(requestStart time.Time) {
+		metrics.requestLatency.Record(ctx, time.Since(requestStart).Seconds(), metric.WithAttributes(
+			measurements.Status(ctx, _err),
+			measurements.AttrDialSucceeded.Bool(dialSucceeded),
+			measurements.AttrNetwork.String(string(c.NetworkName)),
+		))
+	}(time.Now())
+
+	stream, err := c.Host.NewStream(ctx, p, FetchProtocolName(c.NetworkName))
+	if err != nil {
+		return nil, nil, err
+	}
+	dialSucceeded = true
+	defer func() { _ = stream.Reset() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		// Not all transports support deadlines.
+		_ = stream.SetDeadline(deadline)
+	}
+
+	sr := bufio.NewReader(stream)
+	br := &io.LimitedReader{R: sr, N: 100}
+	bw := bufio.NewWriter(stream)
+
+	req := Request{
+		FirstInstance:     first,
+		Limit:             limit,
+		PowerTablesOnly:   true,
+		AcceptCompression: true,
+	}
+	if err := req.MarshalCBOR(bw); err != nil {
+		log.Debugw("failed to marshal power table exchange request to peer", "peer", p, "error", err)
+		return nil, nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, nil, err
+	}
+	if err := stream.CloseWrite(); err != nil {
+		return nil, nil, err
+	}
+
+	var resp ResponseHeader
+	if err := resp.UnmarshalCBOR(br); err != nil {
+		log.Debugw("failed to unmarshal power table exchange response header from peer", "peer", p, "error", err)
+		return nil, nil, err
+	}
+	if zr, err := decompressor(sr, &resp); err != nil {
+		log.Debugw("failed to decompress power table exchange response from peer", "peer", p, "error", err)
+		return nil, nil, err
+	} else if zr != nil {
+		defer zr.Close()
+		br.R = zr
+	}
+
+	var powerTables []gpbft.PowerEntries
+	for i := uint64(0); i < limit; i++ {
+		var pt gpbft.PowerEntries
+		br.N = maxPowerTableSize
+		switch err := pt.UnmarshalCBOR(br); err {
+		case nil:
+			powerTables = append(powerTables, pt)
+		case io.EOF:
+			return &resp, powerTables, nil
+		default:
+			log.Debugw("failed to unmarshal power table from peer", "peer", p, "error", err)
+			return nil, nil, err
+		}
+	}
+	return &resp, powerTables, nil
+}
+
+// decompressor returns a reader of the response body following the given
+// header if it is compressed, or nil otherwise.
+func decompressor(r io.Reader, rh *ResponseHeader) (*zstd.Decoder, error) {
+	if !rh.Compressed {
+		return nil, nil
+	}
+	return zstd.NewReader(r,
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderMaxWindow(compressionWindowSize))
+}
+
 func FindInitialPowerTable(ctx context.Context, c Client, powerTableCID cid.Cid, ecPeriod time.Duration) (gpbft.PowerEntries, error) {
 	request := Request{
 		FirstInstance:     0,
@@ -200,9 +320,8 @@ func FindInitialPowerTable(ctx context.Context, c Client, powerTableCID cid.Cid,
 	defer ticker.Stop()
 
 	pollOne := func(ctx context.Context, p peer.ID) (gpbft.PowerEntries, bool) {
-		targetProtocol := FetchProtocolName(c.NetworkName)
-		if proto, err := c.Host.Peerstore().FirstSupportedProtocol(p, targetProtocol); err != nil ||
-			proto != targetProtocol {
+		if proto, err := c.Host.Peerstore().FirstSupportedProtocol(p,
+			FetchProtocolName(c.NetworkName), FetchProtocolNameV1(c.NetworkName)); err != nil || proto == "" {
 
 			return nil, false
 		}
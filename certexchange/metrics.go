@@ -10,11 +10,21 @@ import (
 var meter = otel.Meter("f3/certexchange")
 var attrWithPowerTable = attribute.Key("with-power-table")
 
+// attrThrottledBy is the limit by which a request was throttled, one of
+// throttledByBandwidth or throttledByStreams.
+var attrThrottledBy = attribute.Key("throttled-by")
+
+const (
+	throttledByBandwidth = "bandwidth"
+	throttledByStreams   = "streams"
+)
+
 var metrics = struct {
 	requestLatency     metric.Float64Histogram
 	totalResponseTime  metric.Float64Histogram
 	serveTime          metric.Float64Histogram
 	certificatesServed metric.Int64Histogram
+	throttledRequests  metric.Int64Counter
 }{
 	requestLatency: measurements.Must(meter.Float64Histogram(
 		"f3_certexchange_request_latency",
@@ -36,4 +46,8 @@ var metrics = struct {
 		metric.WithDescription("The number of certificates served (per request)."),
 		metric.WithUnit("{certificate}"),
 	)),
+	throttledRequests: measurements.Must(meter.Int64Counter(
+		"f3_certexchange_throttled_requests",
+		metric.WithDescription("The number of requests served fewer certificates than requested due to server limits."),
+	)),
 }
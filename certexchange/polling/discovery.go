@@ -23,12 +23,13 @@ func discoverPeers(ctx context.Context, h host.Host, nn gpbft.NetworkName) (<-ch
 		return nil, err
 	}
 
-	targetProtocol := certexchange.FetchProtocolName(nn)
+	// Peers serving either version of the protocol can be polled.
+	targetProtocols := []protocol.ID{certexchange.FetchProtocolName(nn), certexchange.FetchProtocolNameV1(nn)}
 
 	// record existing peers.
 fillInitialPeers:
 	for _, p := range h.Network().Peers() {
-		if proto, err := h.Peerstore().FirstSupportedProtocol(p, targetProtocol); err == nil && proto == targetProtocol {
+		if proto, err := h.Peerstore().FirstSupportedProtocol(p, targetProtocols...); err == nil && proto != "" {
 			select {
 			case out <- p:
 			default:
@@ -68,7 +69,9 @@ fillInitialPeers:
 			default:
 				continue
 			}
-			if slices.Contains(protos, targetProtocol) {
+			if slices.ContainsFunc(protos, func(proto protocol.ID) bool {
+				return slices.Contains(targetProtocols, proto)
+			}) {
 				// If the channel is full, ignore newly discovered peers. We
 				// likely have enough anyways and we'll drain the channel
 				// eventually.
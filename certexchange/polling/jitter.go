@@ -0,0 +1,38 @@
+package polling
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// A poll jitter randomly brings forward or delays each poll by up to a fraction of the predicted
+// interval, such that nodes started together don't poll their peers in lockstep. The jitter only
+// affects when polls are scheduled, and not the interval learned by the predictor.
+type pollJitter struct {
+	fraction float64
+	rng      *rand.Rand
+}
+
+// newPollJitter returns a jitter of up to the given fraction of the interval, seeded by the given
+// peer ID such that each node draws a different sequence of jitters.
+func newPollJitter(fraction float64, self peer.ID) *pollJitter {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(self))
+	return &pollJitter{
+		fraction: fraction,
+		rng:      rand.New(rand.NewSource(int64(hasher.Sum64()))),
+	}
+}
+
+// apply returns the given delay jittered by up to the jitter fraction of the given interval in
+// either direction, but never brought forward to less than the given floor.
+func (j *pollJitter) apply(delay, interval, floor time.Duration) time.Duration {
+	if j.fraction == 0 {
+		return delay
+	}
+	jitter := time.Duration((2*j.rng.Float64() - 1) * j.fraction * float64(interval))
+	return max(delay+jitter, min(delay, floor), 0)
+}
@@ -0,0 +1,58 @@
+package polling
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollJitter(t *testing.T) {
+	const (
+		nodes    = 100
+		interval = 10 * time.Second
+		fraction = 0.2
+	)
+
+	// Without jitter, nodes that predict the same interval poll in lockstep.
+	for range nodes {
+		require.Equal(t, interval, newPollJitter(0, test.RandPeerIDFatal(t)).apply(interval, interval, 0))
+	}
+
+	// With jitter, the poll times of nodes spread out across the jitter window.
+	delays := make([]time.Duration, nodes)
+	for i := range delays {
+		delays[i] = newPollJitter(fraction, test.RandPeerIDFatal(t)).apply(interval, interval, 0)
+		require.InDelta(t, interval, delays[i], fraction*float64(interval))
+	}
+	slices.Sort(delays)
+	require.Greater(t, len(slices.Compact(slices.Clone(delays))), nodes*9/10)
+	require.Greater(t, delays[len(delays)-1]-delays[0], time.Duration(fraction*float64(interval)))
+	early, late := 0, 0
+	for _, delay := range delays {
+		if delay < interval {
+			early++
+		} else if delay > interval {
+			late++
+		}
+	}
+	require.Positive(t, early)
+	require.Positive(t, late)
+
+	// The jitter is deterministic for a given node, and never yields a negative delay.
+	self := test.RandPeerIDFatal(t)
+	one, other := newPollJitter(fraction, self), newPollJitter(fraction, self)
+	for range 10 {
+		require.Equal(t, one.apply(interval, interval, 0), other.apply(interval, interval, 0))
+		require.GreaterOrEqual(t, one.apply(0, interval, 0), time.Duration(0))
+		require.GreaterOrEqual(t, other.apply(0, interval, 0), time.Duration(0))
+	}
+
+	// Nor brings a delay forward to less than the given floor.
+	const floor = interval - interval/20
+	for range 10 {
+		require.GreaterOrEqual(t, one.apply(interval, interval, floor), floor)
+	}
+}
@@ -16,6 +16,7 @@ var metrics = struct {
 	peersPolled              metric.Int64Histogram
 	peersRequiredPerPoll     metric.Int64Histogram
 	pollEfficiency           metric.Float64Histogram
+	concurrentPolls          metric.Int64Histogram
 }{
 	activePeers: measurements.Must(meter.Int64Gauge(
 		"f3_certexchange_polling_active_peers",
@@ -51,6 +52,11 @@ var metrics = struct {
 		"f3_certexchange_polling_poll_efficiency",
 		metric.WithDescription("The fraction of requests necessary to make progress."),
 	)),
+	concurrentPolls: measurements.Must(meter.Int64Histogram(
+		"f3_certexchange_polling_concurrent_polls",
+		metric.WithDescription("The maximum number of peers polled at once per certificate exchange poll."),
+		metric.WithUnit("{peer}"),
+	)),
 }
 
 var attrMadeProgress = attribute.Key("made-progress")
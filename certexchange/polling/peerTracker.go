@@ -4,12 +4,19 @@ import (
 	"cmp"
 	"container/heap"
 	"context"
+	"encoding/json"
+	"fmt"
 	"math/rand"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/filecoin-project/go-f3/internal/clock"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 const (
@@ -31,6 +38,15 @@ const (
 
 	// EWMA alpha for latency tracking (0-1, smaller numbers favor newer readings)
 	latencyAlpha = 0.7
+
+	// The default number of illegal responses within the invalid window after which a peer
+	// is considered evil.
+	defaultInvalidThreshold = 3
+	// The default window over which illegal responses are counted.
+	defaultInvalidWindow = time.Hour
+	// The default time for which the persisted score of a peer that is no longer seen is
+	// retained.
+	defaultPeerScoreTTL = 24 * time.Hour
 )
 
 type peerState int
@@ -57,6 +73,8 @@ type peerRecord struct {
 	state        peerState
 	lastSeen     time.Time
 	latency      time.Duration
+	// Times at which illegal responses were received, within the invalid window.
+	invalidAt []time.Time
 }
 
 type backoffHeap []*backoffRecord
@@ -67,10 +85,21 @@ type backoffRecord struct {
 	delayUntil int
 }
 
-func newPeerTracker(clk clock.Clock) *peerTracker {
+// newPeerTracker creates a peer tracker that considers a peer evil once it has returned
+// invalidThreshold illegal responses within invalidWindow. Non-positive values fall back to the
+// defaults.
+func newPeerTracker(clk clock.Clock, invalidThreshold int, invalidWindow time.Duration) *peerTracker {
+	if invalidThreshold <= 0 {
+		invalidThreshold = defaultInvalidThreshold
+	}
+	if invalidWindow <= 0 {
+		invalidWindow = defaultInvalidWindow
+	}
 	return &peerTracker{
-		peers: make(map[peer.ID]*peerRecord),
-		clock: clk,
+		peers:            make(map[peer.ID]*peerRecord),
+		clock:            clk,
+		invalidThreshold: invalidThreshold,
+		invalidWindow:    invalidWindow,
 	}
 }
 
@@ -80,7 +109,12 @@ type peerTracker struct {
 	backoff                    backoffHeap
 	lastHitRound, currentRound int
 
+	invalidThreshold int
+	invalidWindow    time.Duration
+
 	clock clock.Clock
+	// attrNetwork attributes metrics to the network of the tracked peers.
+	attrNetwork attribute.KeyValue
 }
 
 func (r *peerRecord) Cmp(other *peerRecord) int {
@@ -182,10 +216,20 @@ func (r *peerRecord) recordMiss() {
 	}
 }
 
-func (r *peerRecord) recordInvalid() {
+// Records an illegal response at the given time and returns whether the peer has now been marked
+// as evil, i.e. it has returned at least threshold illegal responses within the window.
+func (r *peerRecord) recordInvalid(now time.Time, window time.Duration, threshold int) bool {
+	r.invalidAt = slices.DeleteFunc(r.invalidAt, func(at time.Time) bool {
+		return !at.After(now.Add(-window))
+	})
+	r.invalidAt = append(r.invalidAt, now)
+	if len(r.invalidAt) < threshold {
+		return false
+	}
 	r.state = peerEvil
 	r.sequentialFailures = 0
-	r.lastSeen = time.Now()
+	r.lastSeen = now
+	return true
 }
 
 // Return the hit rate a number between 0-10 indicating how "full" our window is.
@@ -215,7 +259,11 @@ func (t *peerTracker) updateLatency(p peer.ID, d time.Duration) {
 }
 
 func (t *peerTracker) recordInvalid(p peer.ID) {
-	t.getOrCreate(p).recordInvalid()
+	if !t.getOrCreate(p).recordInvalid(t.clock.Now(), t.invalidWindow, t.invalidThreshold) {
+		// Tolerate the illegal response, e.g. due to a transient bug or version skew, but
+		// backoff the peer as we would on failure.
+		t.recordFailure(p)
+	}
 }
 
 func (t *peerTracker) recordMiss(p peer.ID) {
@@ -264,6 +312,158 @@ func (t *peerTracker) peerSeen(p peer.ID) {
 	}
 }
 
+// PeerScore is a serializable snapshot of how reliable a peer has been, used to seed the peer
+// tracker of another node.
+type PeerScore struct {
+	ID peer.ID
+	// Hits and Misses are the sliding windows of hits and misses, each between 0 and 3.
+	Hits, Misses int
+	// Latency is the exponentially weighted moving average of the peer's response latency,
+	// or zero if unknown.
+	Latency time.Duration
+}
+
+// Export returns the scores of all known peers that are not considered evil, ordered from best to
+// worst.
+func (t *peerTracker) Export() []PeerScore {
+	records := make([]*peerRecord, 0, len(t.peers))
+	for _, r := range t.peers {
+		if r.state != peerEvil {
+			records = append(records, r)
+		}
+	}
+	slices.SortFunc(records, func(a, b *peerRecord) int {
+		return b.Cmp(a)
+	})
+	scores := make([]PeerScore, 0, len(records))
+	for _, r := range records {
+		scores = append(scores, PeerScore{ID: r.id, Hits: r.hits, Misses: r.misses, Latency: r.latency})
+	}
+	return scores
+}
+
+// Import seeds the tracker with the given peer scores, overriding the scores of peers that are
+// already known. Unknown peers are tracked as active, while peers considered evil remain so.
+func (t *peerTracker) Import(scores []PeerScore) {
+	now := t.clock.Now()
+	for _, score := range scores {
+		r, ok := t.peers[score.ID]
+		switch {
+		case !ok:
+			r = &peerRecord{id: score.ID, state: peerActive, lastSeen: now}
+			t.peers[score.ID] = r
+			t.active = append(t.active, score.ID)
+		case r.state == peerEvil:
+			continue
+		}
+		r.hits = min(max(score.Hits, 0), hitMissSlidingWindow)
+		r.misses = min(max(score.Misses, 0), hitMissSlidingWindow)
+		r.latency = max(score.Latency, 0)
+	}
+	t.maybeGc()
+}
+
+// storedPeerScore is the form in which the score of a peer is persisted, keyed by peer ID.
+type storedPeerScore struct {
+	Hits, Misses int
+	Latency      time.Duration
+	LastSeen     time.Time
+}
+
+// save persists the scores of all known peers that are not considered evil to the given
+// datastore, replacing those persisted previously.
+func (t *peerTracker) save(ctx context.Context, ds datastore.Datastore) error {
+	scores := t.Export()
+	keep := make(map[datastore.Key]struct{}, len(scores))
+	for _, score := range scores {
+		keep[peerScoreKey(score.ID)] = struct{}{}
+	}
+
+	results, err := ds.Query(ctx, query.Query{KeysOnly: true})
+	if err != nil {
+		return fmt.Errorf("querying persisted peer scores: %w", err)
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return fmt.Errorf("querying persisted peer scores: %w", err)
+	}
+	for _, entry := range entries {
+		key := datastore.NewKey(entry.Key)
+		if _, ok := keep[key]; !ok {
+			if err := ds.Delete(ctx, key); err != nil {
+				return fmt.Errorf("deleting persisted score of peer: %w", err)
+			}
+		}
+	}
+
+	for _, score := range scores {
+		value, err := json.Marshal(storedPeerScore{
+			Hits:     score.Hits,
+			Misses:   score.Misses,
+			Latency:  score.Latency,
+			LastSeen: t.peers[score.ID].lastSeen,
+		})
+		if err != nil {
+			return fmt.Errorf("encoding score of peer %s: %w", score.ID, err)
+		}
+		if err := ds.Put(ctx, peerScoreKey(score.ID), value); err != nil {
+			return fmt.Errorf("persisting score of peer %s: %w", score.ID, err)
+		}
+	}
+	return nil
+}
+
+// load seeds the tracker with the peer scores persisted to the given datastore by save, as
+// Import would. The scores of peers last seen longer than ttl ago are discarded.
+func (t *peerTracker) load(ctx context.Context, ds datastore.Datastore, ttl time.Duration) error {
+	results, err := ds.Query(ctx, query.Query{})
+	if err != nil {
+		return fmt.Errorf("querying persisted peer scores: %w", err)
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return fmt.Errorf("querying persisted peer scores: %w", err)
+	}
+
+	now := t.clock.Now()
+	scores := make([]PeerScore, 0, len(entries))
+	lastSeen := make(map[peer.ID]time.Time, len(entries))
+	for _, entry := range entries {
+		key := datastore.NewKey(entry.Key)
+		var stored storedPeerScore
+		id, err := peer.Decode(strings.TrimPrefix(key.String(), "/"))
+		if err == nil {
+			err = json.Unmarshal(entry.Value, &stored)
+		}
+		if err != nil || now.Sub(stored.LastSeen) > ttl {
+			if err != nil {
+				log.Warnw("discarding malformed persisted peer score", "key", key, "error", err)
+			}
+			if err := ds.Delete(ctx, key); err != nil {
+				return fmt.Errorf("deleting persisted score of peer: %w", err)
+			}
+			continue
+		}
+		scores = append(scores, PeerScore{ID: id, Hits: stored.Hits, Misses: stored.Misses, Latency: stored.Latency})
+		if _, known := t.peers[id]; !known {
+			lastSeen[id] = stored.LastSeen
+		}
+	}
+
+	t.Import(scores)
+	// Peers not seen since the restart are as stale as they were when persisted.
+	for id, seen := range lastSeen {
+		if r, ok := t.peers[id]; ok {
+			r.lastSeen = seen
+		}
+	}
+	return nil
+}
+
+func peerScoreKey(p peer.ID) datastore.Key {
+	return datastore.NewKey(p.String())
+}
+
 // Garbage collect peers down to our "low" water mark (1000)
 func (t *peerTracker) maybeGc() {
 	if len(t.peers) < gcHighWater {
@@ -381,8 +581,8 @@ func (t *peerTracker) processPeerChanges(ctx context.Context) {
 	t.maybeGc()
 	t.rank()
 
-	metrics.activePeers.Record(ctx, int64(len(t.active)))
-	metrics.backoffPeers.Record(ctx, int64(len(t.backoff)))
+	metrics.activePeers.Record(ctx, int64(len(t.active)), metric.WithAttributes(t.attrNetwork))
+	metrics.backoffPeers.Record(ctx, int64(len(t.backoff)), metric.WithAttributes(t.attrNetwork))
 }
 
 // Suggest a number of peers from which to request new certificates based on their historical
@@ -2,9 +2,12 @@ package polling
 
 import (
 	"context"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/filecoin-project/go-f3/internal/clock"
+	"github.com/ipfs/go-datastore"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/test"
 	"github.com/stretchr/testify/require"
@@ -107,7 +110,7 @@ func TestPeerRecordExponentialBackoff(t *testing.T) {
 }
 
 func TestPeerTracker(t *testing.T) {
-	pt := newPeerTracker(clock.NewMock())
+	pt := newPeerTracker(clock.NewMock(), 1, 0)
 	ctx := context.Background()
 
 	var peers []peer.ID
@@ -241,3 +244,132 @@ func TestPeerTracker(t *testing.T) {
 	}
 
 }
+
+func TestPeerTrackerInvalidTolerance(t *testing.T) {
+	clk := clock.NewMock()
+	pt := newPeerTracker(clk, 2, time.Minute)
+	ctx := context.Background()
+
+	p := test.RandPeerIDFatal(t)
+	pt.peerSeen(p)
+
+	// A single illegal response backs off the peer but doesn't mark it as evil.
+	pt.recordInvalid(p)
+	require.NotContains(t, pt.suggestPeers(ctx), p)
+	require.Contains(t, pt.suggestPeers(ctx), p)
+
+	// Illegal responses outside the window are forgotten.
+	clk.Add(2 * time.Minute)
+	pt.recordInvalid(p)
+	require.NotEqual(t, peerEvil, pt.peers[p].state)
+	pt.suggestPeers(ctx)
+	pt.suggestPeers(ctx)
+	require.Contains(t, pt.suggestPeers(ctx), p)
+
+	// Repeated illegal responses within the window mark the peer as evil for good.
+	clk.Add(time.Second)
+	pt.recordInvalid(p)
+	require.Equal(t, peerEvil, pt.peers[p].state)
+	for i := 0; i < 5; i++ {
+		require.NotContains(t, pt.suggestPeers(ctx), p)
+		pt.recordHit(p)
+		pt.peerSeen(p)
+	}
+}
+
+func TestPeerTrackerExportImport(t *testing.T) {
+	clk := clock.NewMock()
+	source := newPeerTracker(clk, 1, time.Minute)
+
+	reliable, unreliable, unmeasured, evil := test.RandPeerIDFatal(t), test.RandPeerIDFatal(t),
+		test.RandPeerIDFatal(t), test.RandPeerIDFatal(t)
+	for _, p := range []peer.ID{reliable, unreliable, unmeasured, evil} {
+		source.peerSeen(p)
+	}
+	for i := 0; i < 3; i++ {
+		source.recordHit(reliable)
+		source.updateLatency(reliable, 10*time.Millisecond)
+		source.recordMiss(unreliable)
+		source.updateLatency(unreliable, time.Second)
+	}
+	source.recordInvalid(evil)
+
+	exported := source.Export()
+	require.Equal(t, []PeerScore{
+		{ID: reliable, Hits: 3, Latency: 10 * time.Millisecond},
+		{ID: unmeasured},
+		{ID: unreliable, Misses: 3, Latency: time.Second},
+	}, exported)
+
+	// The scores carry over to a tracker that has never seen the peers.
+	target := newPeerTracker(clk, 0, 0)
+	target.Import(exported)
+	require.Equal(t, exported, target.Export())
+	require.Equal(t, reliable, target.suggestPeers(context.Background())[0])
+
+	// Evil peers are not redeemed by an import.
+	target.recordInvalid(reliable)
+	target.recordInvalid(reliable)
+	target.recordInvalid(reliable)
+	target.Import(exported)
+	require.Equal(t, exported[1:], target.Export())
+}
+
+func TestPeerTrackerPersistence(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	ds := datastore.NewMapDatastore()
+	source := newPeerTracker(clk, 1, time.Minute)
+
+	fast, slow, flaky, unreliable, unmeasured, stale, evil := test.RandPeerIDFatal(t), test.RandPeerIDFatal(t),
+		test.RandPeerIDFatal(t), test.RandPeerIDFatal(t), test.RandPeerIDFatal(t), test.RandPeerIDFatal(t),
+		test.RandPeerIDFatal(t)
+	for _, p := range []peer.ID{fast, slow, flaky, unreliable, unmeasured, stale, evil} {
+		source.peerSeen(p)
+	}
+	for i := 0; i < 3; i++ {
+		source.recordHit(fast)
+		source.recordHit(slow)
+		source.recordMiss(unreliable)
+	}
+	source.updateLatency(fast, 10*time.Millisecond)
+	source.updateLatency(slow, time.Second)
+	source.recordHit(flaky)
+	source.recordMiss(flaky)
+	source.recordInvalid(evil)
+
+	// All but the stale peer are seen again well after it.
+	const ttl = time.Hour
+	clk.Add(2 * ttl)
+	for _, p := range []peer.ID{fast, slow, flaky, unreliable, unmeasured} {
+		source.peerSeen(p)
+	}
+	require.NoError(t, source.save(ctx, ds))
+
+	// A restarted tracker ranks the peers as before, less the evil and stale peers.
+	target := newPeerTracker(clk, 0, 0)
+	require.NoError(t, target.load(ctx, ds, ttl))
+	source.suggestPeers(ctx)
+	target.suggestPeers(ctx)
+	want := slices.DeleteFunc(slices.Clone(source.active), func(p peer.ID) bool {
+		return p == stale || p == evil
+	})
+	require.Equal(t, []peer.ID{fast, slow, flaky, unmeasured, unreliable}, want)
+	require.Equal(t, want, target.active)
+	require.Equal(t, slices.DeleteFunc(source.Export(), func(score PeerScore) bool {
+		return score.ID == stale
+	}), target.Export())
+
+	// The stale peer is gone from the datastore, and peers gone from the tracker are
+	// removed on the next save.
+	has, err := ds.Has(ctx, peerScoreKey(stale))
+	require.NoError(t, err)
+	require.False(t, has)
+	target.recordInvalid(slow)
+	target.recordInvalid(slow)
+	target.recordInvalid(slow)
+	require.NoError(t, target.save(ctx, ds))
+	has, err = ds.Has(ctx, peerScoreKey(slow))
+	require.NoError(t, err)
+	require.False(t, has)
+}
@@ -3,6 +3,7 @@ package polling
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-f3/certexchange"
@@ -14,14 +15,20 @@ import (
 )
 
 // A Poller will poll specific peers on-demand to try to advance the current GPBFT instance.
+//
+// Multiple peers may be polled concurrently: certificates are fetched from each in parallel,
+// while validating and storing them, and so advancing the poller, is serialized.
 type Poller struct {
 	*certexchange.Client
 
 	Store             *certstore.Store
 	SignatureVerifier gpbft.Verifier
-	PowerTable        gpbft.PowerEntries
-	NextInstance      uint64
-	clock             clock.Clock
+	// PowerTable and NextInstance are guarded by mu while polls are in progress.
+	PowerTable   gpbft.PowerEntries
+	NextInstance uint64
+	clock        clock.Clock
+
+	mu sync.Mutex
 }
 
 // NewPoller constructs a new certificate poller and initializes it from the passed certificate store.
@@ -54,6 +61,8 @@ type PollResult struct {
 	NewCertificates uint64
 	// Total certificates received.
 	ReceivedCertificates uint64
+	// PendingInstance is the highest pending instance reported by the peer.
+	PendingInstance uint64
 }
 
 type PollStatus int
@@ -72,6 +81,12 @@ func (p PollStatus) GoString() string {
 // CatchUp attempts to advance to the latest instance from the certificate store without making any
 // network requests. It returns the number of instances we advanced.
 func (p *Poller) CatchUp(ctx context.Context) (uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.catchUp(ctx)
+}
+
+func (p *Poller) catchUp(ctx context.Context) (uint64, error) {
 	latest := p.Store.Latest()
 	if latest == nil {
 		return 0, nil
@@ -93,11 +108,20 @@ func (p *Poller) CatchUp(ctx context.Context) (uint64, error) {
 	return progress, nil
 }
 
+// nextInstance returns the next instance, safe to call while polls are in progress.
+func (p *Poller) nextInstance() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.NextInstance
+}
+
 // Poll polls a specific peer, possibly multiple times, in order to advance the instance as much as
 // possible. It returns:
 //
 // 1. A PollResult indicating the outcome: miss, hit, failed, illegal.
 // 2. An error if something went wrong internally (e.g., the certificate store returned an error).
+//
+// Poll may be called concurrently to poll multiple peers at once.
 func (p *Poller) Poll(ctx context.Context, peer peer.ID) (*PollResult, error) {
 	res := new(PollResult)
 
@@ -108,15 +132,20 @@ func (p *Poller) Poll(ctx context.Context, peer peer.ID) (*PollResult, error) {
 	for {
 		// Requests take time, so always try to catch-up between requests in case there has
 		// been some "local" action from the GPBFT instance.
-		if _, err := p.CatchUp(ctx); err != nil {
+		p.mu.Lock()
+		_, err := p.catchUp(ctx)
+		first := p.NextInstance
+		p.mu.Unlock()
+		if err != nil {
 			return nil, err
 		}
 
 		start := p.clock.Now()
 		resp, ch, err := p.Request(ctx, peer, &certexchange.Request{
-			FirstInstance:     p.NextInstance,
+			FirstInstance:     first,
 			Limit:             maxRequestLength,
 			IncludePowerTable: false,
+			AcceptCompression: true,
 		})
 		res.Latency = p.clock.Since(start)
 		if err != nil {
@@ -125,49 +154,98 @@ func (p *Poller) Poll(ctx context.Context, peer peer.ID) (*PollResult, error) {
 			return res, nil
 		}
 
+		res.PendingInstance = max(res.PendingInstance, resp.PendingInstance)
+
 		// If they're caught up, record it as a hit. Otherwise, if they have nothing
 		// to give us, move on.
-		if resp.PendingInstance >= p.NextInstance {
+		if resp.PendingInstance >= first {
 			res.Status = PollHit
 		}
 
+		// Receive the whole response before advancing, such that concurrent polls only
+		// wait on each other to validate and store certificates, not to fetch them.
+		var received []*certs.FinalityCertificate
 		for cert := range ch {
-			// TODO: consider batching verification, it's slightly faster.
-			next, _, pt, err := certs.ValidateFinalityCertificates(
-				p.SignatureVerifier, p.NetworkName, p.PowerTable, p.NextInstance, nil,
-				cert,
-			)
-			if err != nil {
-				res.Status = PollIllegal
-				res.Error = err
-				return res, nil
-			}
-			res.ReceivedCertificates++
-
-			// We check if we've already received this certificate not as an
-			// optimization but to determine whether or not this request was actually
-			// useful. This check is inherently racy; even if we made the check/put
-			// atomic, we'd still race with GPBFT finishing the current instance.
-			if l := p.Store.Latest(); l == nil || cert.GPBFTInstance > l.GPBFTInstance {
-				if err := p.Store.Put(ctx, cert); err != nil {
-					return nil, err
-				}
-				res.NewCertificates++
-			}
-			p.NextInstance = next
-			p.PowerTable = pt
+			received = append(received, cert)
+		}
+
+		p.mu.Lock()
+		illegal, err := p.advance(ctx, res, received)
+		next := p.NextInstance
+		p.mu.Unlock()
+		if err != nil {
+			return nil, err
+		} else if illegal {
+			return res, nil
 		}
 
 		// Try again if they're claiming to have more instances (and gave me at
 		// least one).
-		if resp.PendingInstance <= p.NextInstance {
+		if resp.PendingInstance <= next {
 			return res, nil
-		} else if res.ReceivedCertificates == 0 {
+		} else if len(received) == 0 {
 			res.Status = PollFailed
 			// If they give me no certificates but claim to have more, treat this as a
 			// failure (could be a connection failure, etc).
 			return res, nil
 		}
+	}
+}
 
+// advance validates and stores the certificates received, skipping any that a
+// concurrent poll has already advanced past, and accounts for them in the given
+// result. It returns whether an invalid certificate was received, in which case
+// the certificates validated before it are still stored. The poller only
+// advances past the certificates once they are stored. It must be called with
+// mu held.
+func (p *Poller) advance(ctx context.Context, res *PollResult, received []*certs.FinalityCertificate) (bool, error) {
+	// Certificates must extend the chain finalized by the latest certificate we
+	// have, if any.
+	var base *gpbft.TipSet
+	if latest := p.Store.Latest(); latest != nil && latest.GPBFTInstance+1 == p.NextInstance {
+		base = latest.ECChain.Head()
+	}
+
+	// Validated certificates are stored together once all are validated, or an
+	// invalid certificate is received, and only then does the poller advance.
+	var validated []*certs.FinalityCertificate
+	nextInstance, powerTable := p.NextInstance, p.PowerTable
+	store := func() error {
+		if err := p.Store.PutRange(ctx, validated); err != nil {
+			return err
+		}
+		p.NextInstance, p.PowerTable = nextInstance, powerTable
+		return nil
+	}
+	for _, cert := range received {
+		if cert.GPBFTInstance < nextInstance {
+			continue
+		}
+		// TODO: consider batching verification, it's slightly faster.
+		next, _, pt, err := certs.ValidateFinalityCertificates(
+			p.SignatureVerifier, p.NetworkName, powerTable, nextInstance, base,
+			cert,
+		)
+		if err != nil {
+			if err := store(); err != nil {
+				return false, err
+			}
+			res.Status = PollIllegal
+			res.Error = err
+			return true, nil
+		}
+		res.ReceivedCertificates++
+
+		// We check if we've already received this certificate not as an
+		// optimization but to determine whether or not this request was actually
+		// useful. This check is inherently racy; even if we made the check/put
+		// atomic, we'd still race with GPBFT finishing the current instance.
+		if l := p.Store.Latest(); l == nil || cert.GPBFTInstance > l.GPBFTInstance {
+			validated = append(validated, cert)
+			res.NewCertificates++
+		}
+		nextInstance, powerTable = next, pt
+		base = cert.ECChain.Head()
 	}
+	return false, store()
 }
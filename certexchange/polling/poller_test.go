@@ -2,7 +2,10 @@ package polling_test
 
 import (
 	"context"
+	"errors"
 	"math/rand"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/filecoin-project/go-f3/certexchange"
@@ -12,6 +15,7 @@ import (
 
 	"github.com/ipfs/go-datastore"
 	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p/core/peer"
 	mocknetwork "github.com/libp2p/go-libp2p/p2p/net/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -142,3 +146,225 @@ func TestPoller(t *testing.T) {
 		require.Equal(t, polling.PollFailed, res.Status)
 	}
 }
+
+func TestPollerRejectsNonContiguous(t *testing.T) {
+	backend := signing.NewFakeBackend()
+	rng := rand.New(rand.NewSource(1234))
+
+	cg := polling.MakeCertificates(t, rng, backend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mocknet := mocknetwork.New()
+
+	clientHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	serverHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	require.NoError(t, mocknet.LinkAll())
+
+	serverCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+	clientCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+
+	for cg.NextInstance < 4 {
+		cert := cg.MakeCertificate()
+		require.NoError(t, serverCs.Put(ctx, cert))
+		require.NoError(t, clientCs.Put(ctx, cert))
+	}
+
+	// The server follows a fork from instance 4 onwards, with validly signed
+	// certificates that do not extend the head finalized by the client.
+	fork := *cg
+	require.NoError(t, clientCs.Put(ctx, cg.MakeCertificate()))
+	for fork.NextInstance < 10 {
+		require.NoError(t, serverCs.Put(ctx, fork.MakeCertificate()))
+	}
+
+	server := certexchange.Server{
+		NetworkName: polling.TestNetworkName,
+		Host:        serverHost,
+		Store:       serverCs,
+	}
+	require.NoError(t, server.Start(ctx))
+	t.Cleanup(func() { require.NoError(t, server.Stop(context.Background())) })
+
+	client := certexchange.Client{
+		Host:        clientHost,
+		NetworkName: polling.TestNetworkName,
+	}
+	poller, err := polling.NewPoller(ctx, &client, clientCs, backend)
+	require.NoError(t, err)
+
+	require.NoError(t, mocknet.ConnectAllButSelf())
+
+	res, err := poller.Poll(ctx, serverHost.ID())
+	require.NoError(t, err)
+	require.Equal(t, polling.PollIllegal, res.Status)
+	require.ErrorContains(t, res.Error, "base tipset does not match")
+
+	// Nothing past the fork is stored.
+	require.Equal(t, uint64(5), poller.NextInstance)
+	require.Equal(t, uint64(4), clientCs.Latest().GPBFTInstance)
+}
+
+// failingDatastore fails every write while failing is set.
+type failingDatastore struct {
+	datastore.Datastore
+	failing atomic.Bool
+}
+
+func (f *failingDatastore) Put(ctx context.Context, key datastore.Key, value []byte) error {
+	if f.failing.Load() {
+		return errors.New("disk on fire")
+	}
+	return f.Datastore.Put(ctx, key, value)
+}
+
+func TestPollerRecoversFromFailedStore(t *testing.T) {
+	backend := signing.NewFakeBackend()
+	rng := rand.New(rand.NewSource(1234))
+
+	cg := polling.MakeCertificates(t, rng, backend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mocknet := mocknetwork.New()
+
+	clientHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	serverHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	require.NoError(t, mocknet.LinkAll())
+
+	serverCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+	for cg.NextInstance < 10 {
+		require.NoError(t, serverCs.Put(ctx, cg.MakeCertificate()))
+	}
+	clientDs := &failingDatastore{Datastore: ds_sync.MutexWrap(datastore.NewMapDatastore())}
+	clientCs, err := certstore.CreateStore(ctx, clientDs, 0, cg.PowerTable)
+	require.NoError(t, err)
+
+	server := certexchange.Server{
+		NetworkName: polling.TestNetworkName,
+		Host:        serverHost,
+		Store:       serverCs,
+	}
+	require.NoError(t, server.Start(ctx))
+	t.Cleanup(func() { require.NoError(t, server.Stop(context.Background())) })
+
+	client := certexchange.Client{
+		Host:        clientHost,
+		NetworkName: polling.TestNetworkName,
+	}
+	poller, err := polling.NewPoller(ctx, &client, clientCs, backend)
+	require.NoError(t, err)
+
+	require.NoError(t, mocknet.ConnectAllButSelf())
+
+	// The poller does not advance past certificates it failed to store.
+	clientDs.failing.Store(true)
+	_, err = poller.Poll(ctx, serverHost.ID())
+	require.ErrorContains(t, err, "disk on fire")
+	require.Zero(t, poller.NextInstance)
+
+	// So it catches up once the store recovers.
+	clientDs.failing.Store(false)
+	res, err := poller.Poll(ctx, serverHost.ID())
+	require.NoError(t, err)
+	require.Equal(t, polling.PollHit, res.Status)
+	require.Equal(t, cg.NextInstance, poller.NextInstance)
+	require.Equal(t, cg.NextInstance-1, clientCs.Latest().GPBFTInstance)
+}
+
+func TestPollerConcurrent(t *testing.T) {
+	backend := signing.NewFakeBackend()
+	rng := rand.New(rand.NewSource(1234))
+
+	cg := polling.MakeCertificates(t, rng, backend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mocknet := mocknetwork.New()
+	clientHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	// More certificates than fit in a single request, served by every server.
+	const certCount = 600
+	servers := make([]*certexchange.Server, 4)
+	for i := range servers {
+		h, err := mocknet.GenPeer()
+		require.NoError(t, err)
+		cs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+		require.NoError(t, err)
+		servers[i] = &certexchange.Server{
+			NetworkName: polling.TestNetworkName,
+			Host:        h,
+			Store:       cs,
+		}
+	}
+	for cg.NextInstance < certCount {
+		cert := cg.MakeCertificate()
+		for _, server := range servers {
+			require.NoError(t, server.Store.Put(ctx, cert))
+		}
+	}
+	unreachable, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	require.NoError(t, mocknet.LinkAll())
+	for _, server := range servers {
+		require.NoError(t, server.Start(ctx))
+		t.Cleanup(func() { require.NoError(t, server.Stop(context.Background())) })
+	}
+
+	clientCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+	client := certexchange.Client{
+		Host:        clientHost,
+		NetworkName: polling.TestNetworkName,
+	}
+	poller, err := polling.NewPoller(ctx, &client, clientCs, backend)
+	require.NoError(t, err)
+	require.NoError(t, mocknet.ConnectAllButSelf())
+
+	peers := []peer.ID{unreachable.ID()}
+	for _, server := range servers {
+		peers = append(peers, server.Host.ID())
+	}
+	results := make([]*polling.PollResult, len(peers))
+	errs := make([]error, len(peers))
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = poller.Poll(ctx, p)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	// The unreachable peer failed, while the servers between them caught us up,
+	// each certificate being validated and stored exactly once.
+	require.Equal(t, polling.PollFailed, results[0].Status)
+	var newCertificates uint64
+	for _, res := range results[1:] {
+		require.Equal(t, polling.PollHit, res.Status)
+		newCertificates += res.NewCertificates
+	}
+	require.EqualValues(t, certCount, newCertificates)
+	require.EqualValues(t, certCount, poller.NextInstance)
+	require.EqualValues(t, certCount-1, clientCs.Latest().GPBFTInstance)
+}
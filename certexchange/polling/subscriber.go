@@ -3,11 +3,14 @@ package polling
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-f3/internal/measurements"
+	"github.com/ipfs/go-datastore"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 
 	"github.com/filecoin-project/go-f3/certexchange"
@@ -18,6 +21,13 @@ import (
 
 const maxRequestLength = 256
 
+// peerScoreSaveInterval is the minimum interval at which peer scores are persisted while
+// polling.
+const peerScoreSaveInterval = 10 * time.Minute
+
+// DefaultPollJitter is the PollJitter with which F3 polls for finality certificates.
+const DefaultPollJitter = 0.1
+
 // A polling Subscriber will continuously poll the network for new finality certificates.
 type Subscriber struct {
 	certexchange.Client
@@ -27,24 +37,82 @@ type Subscriber struct {
 	InitialPollInterval time.Duration
 	MaximumPollInterval time.Duration
 	MinimumPollInterval time.Duration
-
-	peerTracker *peerTracker
-	poller      *Poller
-	discoverCh  <-chan peer.ID
-	clock       clock.Clock
+	// PollJitter is the fraction of the predicted poll interval by which each poll, including the
+	// first, is randomly brought forward or delayed, such that nodes restarted together don't poll
+	// in lockstep. Polls are never brought forward to within MinimumPollInterval of the previous
+	// poll. The jitter is seeded by the ID of the local host. Must be between 0 and 1. Defaults to
+	// 0, i.e. no jitter, if unset; see DefaultPollJitter.
+	PollJitter float64
+	// InvalidPeerThreshold is the number of illegal responses a peer may return within
+	// InvalidPeerWindow before it is no longer polled. Defaults to 3 if unset.
+	InvalidPeerThreshold int
+	// InvalidPeerWindow is the window over which illegal responses are counted. Defaults to one
+	// hour if unset.
+	InvalidPeerWindow time.Duration
+	// MaxConcurrentPolls is the maximum number of peers polled at once. Peers are polled one
+	// after another, and a further peer is only polled alongside those in flight once the last
+	// poll has taken longer than the latency observed of its peer, such that concurrency ramps
+	// up only when peers are slow to respond. Defaults to 1, i.e. sequential polling, if unset.
+	MaxConcurrentPolls int
+	// Datastore, if set, is where the peer scores are persisted, such that they survive
+	// restarts. The scores are loaded on Start, and saved periodically and on Stop.
+	Datastore datastore.Datastore
+	// PeerScoreTTL is the time for which the persisted score of a peer that is no longer seen
+	// is retained. Defaults to a day if unset.
+	PeerScoreTTL time.Duration
+
+	// peerTrackerMu guards the peer tracker, and the peer scores to seed it with at Start.
+	peerTrackerMu      sync.Mutex
+	peerTracker        *peerTracker
+	importedPeerScores []PeerScore
+	poller             *Poller
+	// maxPendingInstance is the highest pending instance reported by any peer
+	// polled so far.
+	maxPendingInstance uint64
+	// caughtUp is closed once the subscriber first catches up with the pending
+	// instance reported by the network.
+	caughtUpInit   sync.Once
+	caughtUp       chan struct{}
+	caughtUpClosed bool
+	discoverCh     <-chan peer.ID
+	clock          clock.Clock
+
+	// announceMu guards announced, the highest pending instance announced since
+	// it was last consumed by the run loop.
+	announceMu     sync.Mutex
+	announced      uint64
+	announceInit   sync.Once
+	announceSignal chan struct{}
 
 	wg   sync.WaitGroup
 	stop context.CancelFunc
 }
 
 func (s *Subscriber) Start(startCtx context.Context) error {
+	if !(s.PollJitter >= 0 && s.PollJitter <= 1) {
+		return fmt.Errorf("poll jitter must be between 0 and 1, was %f", s.PollJitter)
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	s.stop = cancel
 	s.clock = clock.GetClock(startCtx)
 
 	var err error
 
-	s.peerTracker = newPeerTracker(s.clock)
+	s.peerTrackerMu.Lock()
+	s.peerTracker = newPeerTracker(s.clock, s.InvalidPeerThreshold, s.InvalidPeerWindow)
+	s.peerTracker.attrNetwork = s.attrNetwork()
+	if s.Datastore != nil {
+		ttl := s.PeerScoreTTL
+		if ttl <= 0 {
+			ttl = defaultPeerScoreTTL
+		}
+		if err := s.peerTracker.load(startCtx, s.Datastore, ttl); err != nil {
+			log.Warnw("failed to load persisted peer scores", "error", err)
+		}
+	}
+	s.peerTracker.Import(s.importedPeerScores)
+	s.importedPeerScores = nil
+	s.peerTrackerMu.Unlock()
 	s.poller, err = NewPoller(startCtx, &s.Client, s.Store, s.SignatureVerifier)
 	if err != nil {
 		return err
@@ -76,18 +144,87 @@ func (s *Subscriber) Start(startCtx context.Context) error {
 	return nil
 }
 
+// CaughtUp returns a channel that is closed once the subscriber has first
+// caught up with the network, i.e. has received the certificates of every
+// instance up to the latest pending instance reported by any of the peers that
+// answered a poll.
+func (s *Subscriber) CaughtUp() <-chan struct{} {
+	s.caughtUpInit.Do(func() { s.caughtUp = make(chan struct{}) })
+	return s.caughtUp
+}
+
+// Announce notifies the subscriber that a peer has announced the given pending
+// instance, i.e. that certificates up to but excluding it are available. If the
+// announced instance is ahead of the local store, the next poll is brought
+// forward, though never to within MinimumPollInterval of the previous poll.
+//
+// Announcements are merely hints: they do not count towards the pending instance
+// reported by the network, so that a bogus announcement can trigger at most one
+// early poll. Announce never blocks.
+func (s *Subscriber) Announce(pendingInstance uint64) {
+	s.announceMu.Lock()
+	s.announced = max(s.announced, pendingInstance)
+	s.announceMu.Unlock()
+	select {
+	case s.announcements() <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Subscriber) announcements() chan struct{} {
+	s.announceInit.Do(func() { s.announceSignal = make(chan struct{}, 1) })
+	return s.announceSignal
+}
+
+// consumeAnnounced returns the highest pending instance announced since the last
+// call, resetting it.
+func (s *Subscriber) consumeAnnounced() uint64 {
+	s.announceMu.Lock()
+	defer s.announceMu.Unlock()
+	announced := s.announced
+	s.announced = 0
+	return announced
+}
+
+// attrNetwork attributes metrics to the network the subscriber polls.
+func (s *Subscriber) attrNetwork() attribute.KeyValue {
+	return measurements.AttrNetwork.String(string(s.NetworkName))
+}
+
 func (s *Subscriber) Stop(stopCtx context.Context) error {
 	if s.stop != nil {
 		s.stop()
 		s.wg.Wait()
+		s.savePeerScores(stopCtx)
 	}
 
 	return nil
 }
 
+// savePeerScores persists the peer scores, if a datastore is set.
+func (s *Subscriber) savePeerScores(ctx context.Context) {
+	if s.Datastore == nil {
+		return
+	}
+	s.peerTrackerMu.Lock()
+	defer s.peerTrackerMu.Unlock()
+	if err := s.peerTracker.save(ctx, s.Datastore); err != nil {
+		log.Warnw("failed to persist peer scores", "error", err)
+	}
+}
+
 func (s *Subscriber) run(ctx context.Context) error {
-	timer := s.clock.Timer(s.InitialPollInterval)
+	jitter := newPollJitter(s.PollJitter, s.Host.ID())
+	initialDelay := jitter.apply(s.InitialPollInterval, s.InitialPollInterval, s.MinimumPollInterval)
+	timer := s.clock.Timer(initialDelay)
 	defer timer.Stop()
+	// lastPollTime and nextPollDue track when the timer last fired and when it is
+	// next due to, such that announcements only ever bring polls forward, and no
+	// closer together than the minimum poll interval.
+	var lastPollTime time.Time
+	nextPollDue := s.clock.Now().Add(initialDelay)
+	lastSaveTime := s.clock.Now()
+	announcements := s.announcements()
 
 	predictor := newPredictor(
 		s.MinimumPollInterval,
@@ -98,8 +235,22 @@ func (s *Subscriber) run(ctx context.Context) error {
 	for ctx.Err() == nil {
 		select {
 		case p := <-s.discoverCh:
+			s.peerTrackerMu.Lock()
 			s.peerTracker.peerSeen(p)
+			s.peerTrackerMu.Unlock()
+		case <-announcements:
+			if s.consumeAnnounced() <= s.poller.NextInstance {
+				// Nothing we don't already have.
+				continue
+			}
+			earliest := lastPollTime.Add(s.MinimumPollInterval)
+			if earliest.Before(nextPollDue) {
+				log.Debugf("polling early upon announcement of a new certificate")
+				timer.Reset(max(s.clock.Until(earliest), 0))
+				nextPollDue = earliest
+			}
 		case pollTime := <-timer.C:
+			lastPollTime = pollTime
 			// First, see if we made progress locally. If we have, update
 			// interval prediction based on that local progress. If our interval
 			// was accurate, we'll keep predicting the same interval and we'll
@@ -124,16 +275,22 @@ func (s *Subscriber) run(ctx context.Context) error {
 				if progress > 0 && !newCert {
 					offset = requestTime
 				}
+				if s.clock.Since(lastSaveTime) >= peerScoreSaveInterval {
+					s.savePeerScores(ctx)
+					lastSaveTime = s.clock.Now()
+				}
 			}
 
 			nextInterval := predictor.update(progress)
 			nextPollTime := pollTime.Add(nextInterval)
 			delay := max(s.clock.Until(nextPollTime), 0)
 			delay += max(offset, delay/2) // Offset the delay by at most half the predicted interval.
+			delay = jitter.apply(delay, nextInterval, s.clock.Until(pollTime.Add(s.MinimumPollInterval)))
 			log.Debugf("predicted interval is %s (waiting %s)", nextInterval, delay)
 			timer.Reset(delay)
+			nextPollDue = s.clock.Now().Add(delay)
 
-			metrics.predictedPollingInterval.Record(ctx, delay.Seconds())
+			metrics.predictedPollingInterval.Record(ctx, delay.Seconds(), metric.WithAttributes(s.attrNetwork()))
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -141,6 +298,56 @@ func (s *Subscriber) run(ctx context.Context) error {
 	return ctx.Err()
 }
 
+// ExportPeerScores returns the scores of the peers known to be reliable, ordered from best to
+// worst, such that they can be used to seed the subscriber of another node via ImportPeerScores.
+func (s *Subscriber) ExportPeerScores() []PeerScore {
+	s.peerTrackerMu.Lock()
+	defer s.peerTrackerMu.Unlock()
+	if s.peerTracker == nil {
+		return slices.Clone(s.importedPeerScores)
+	}
+	return s.peerTracker.Export()
+}
+
+// ImportPeerScores seeds the subscriber with the given peer scores, e.g. exported from another
+// node, such that it starts polling a curated set of peers rather than discovering them from
+// scratch. Scores imported before Start are applied once started.
+func (s *Subscriber) ImportPeerScores(scores []PeerScore) {
+	s.peerTrackerMu.Lock()
+	defer s.peerTrackerMu.Unlock()
+	if s.peerTracker == nil {
+		s.importedPeerScores = append(s.importedPeerScores, scores...)
+		return
+	}
+	s.peerTracker.Import(scores)
+}
+
+// peerLatency returns the latency observed of the given peer, or zero if unknown.
+func (s *Subscriber) peerLatency(p peer.ID) time.Duration {
+	s.peerTrackerMu.Lock()
+	defer s.peerTrackerMu.Unlock()
+	if record, ok := s.peerTracker.peers[p]; ok {
+		return record.latency
+	}
+	return 0
+}
+
+// recordPollResult records the outcome of polling the given peer with the peer tracker.
+func (s *Subscriber) recordPollResult(p peer.ID, res *PollResult) {
+	s.peerTrackerMu.Lock()
+	defer s.peerTrackerMu.Unlock()
+	switch res.Status {
+	case PollMiss, PollHit:
+		s.peerTracker.updateLatency(p, res.Latency)
+	case PollFailed:
+		s.peerTracker.recordFailure(p)
+	case PollIllegal:
+		s.peerTracker.recordInvalid(p)
+	default:
+		panic(fmt.Sprintf("unexpected polling.PollResult: %#v", res))
+	}
+}
+
 // Polls peers for new certificates, returning:
 //
 //  1. The total progress made (including certificates not received from polled peers).
@@ -161,25 +368,103 @@ func (s *Subscriber) poll(ctx context.Context) (_progress uint64, _new bool, _er
 		metrics.pollDuration.Record(ctx, time.Since(startTime).Seconds(), metric.WithAttributes(
 			status,
 			attrMadeProgress.Bool(_progress > 0),
+			s.attrNetwork(),
 		))
 	}(time.Now())
 
+	s.peerTrackerMu.Lock()
 	peers := s.peerTracker.suggestPeers(ctx)
+	s.peerTrackerMu.Unlock()
 
-	log.Debugf("polling %d peers for instance %d", len(peers), s.poller.NextInstance)
+	log.Debugf("polling %d peers for instance %d", len(peers), s.poller.nextInstance())
 	pollsSinceLastProgress := 0
-	start := s.poller.NextInstance
+	start := s.poller.nextInstance()
+	previousMaxPendingInstance := s.maxPendingInstance
 	var (
 		certificatesReceived    uint64
 		newCertificatesReceived uint64
+		started                 int
+		polled                  int
+		answered                bool
 	)
-	for _, peer := range peers {
-		res, err := s.poller.Poll(ctx, peer)
-		if err != nil {
-			return start - s.poller.NextInstance, newCertificatesReceived > 0, err
+
+	type outcome struct {
+		peer peer.ID
+		res  *PollResult
+		err  error
+	}
+	// Polls still in flight once we stop are cancelled, and waited on so that they
+	// never outlive this call. The outcomes channel is large enough for every poll
+	// to complete without blocking.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	pollCtx, cancelPolls := context.WithCancel(ctx)
+	defer cancelPolls()
+	outcomes := make(chan outcome, len(peers))
+
+	maxConcurrent := max(s.MaxConcurrentPolls, 1)
+	var (
+		inFlight, maxInFlight int
+		// slow fires once the last poll started takes longer than the latency observed
+		// of its peer, or is nil if there is nothing to wait for.
+		slow      <-chan time.Time
+		slowTimer *clock.Timer
+		pollNext  = true
+	)
+	defer func() {
+		if slowTimer != nil {
+			slowTimer.Stop()
 		}
+		metrics.concurrentPolls.Record(ctx, int64(maxInFlight), metric.WithAttributes(s.attrNetwork()))
+	}()
 
-		log.Debugf("polled %s for instance %d, got %+v", peer, s.poller.NextInstance, res)
+poll:
+	for started < len(peers) || inFlight > 0 {
+		if pollNext && started < len(peers) && inFlight < maxConcurrent {
+			peer := peers[started]
+			started++
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := s.poller.Poll(pollCtx, peer)
+				outcomes <- outcome{peer: peer, res: res, err: err}
+			}()
+
+			if slowTimer != nil {
+				slowTimer.Stop()
+				slow = nil
+			}
+			// Poll the next peer straight away if we know nothing of how long this one
+			// takes to respond.
+			pollNext = true
+			if latency := s.peerLatency(peer); latency > 0 && maxConcurrent > 1 {
+				pollNext = false
+				slowTimer = s.clock.Timer(latency)
+				slow = slowTimer.C
+			}
+			continue
+		}
+
+		var o outcome
+		select {
+		case <-slow:
+			slow = nil
+			pollNext = true
+			continue
+		case o = <-outcomes:
+			inFlight--
+			polled++
+			// Keep the number of polls in flight steady.
+			pollNext = true
+		}
+		if o.err != nil {
+			return start - s.poller.nextInstance(), newCertificatesReceived > 0, o.err
+		}
+		peer, res := o.peer, o.res
+
+		log.Debugf("polled %s for instance %d, got %+v", peer, s.poller.nextInstance(), res)
 		// If we manage to advance (because of this peer), consider old "hits" to be misses.
 		if res.ReceivedCertificates > 0 {
 			misses = append(misses, hits...)
@@ -189,17 +474,12 @@ func (s *Subscriber) poll(ctx context.Context) (_progress uint64, _new bool, _er
 		switch res.Status {
 		case PollMiss:
 			misses = append(misses, peer)
-			s.peerTracker.updateLatency(peer, res.Latency)
+			answered = true
 		case PollHit:
 			hits = append(hits, peer)
-			s.peerTracker.updateLatency(peer, res.Latency)
-		case PollFailed:
-			s.peerTracker.recordFailure(peer)
-		case PollIllegal:
-			s.peerTracker.recordInvalid(peer)
-		default:
-			panic(fmt.Sprintf("unexpected polling.PollResult: %#v", res))
+			answered = true
 		}
+		s.recordPollResult(peer, res)
 
 		if res.ReceivedCertificates == 0 {
 			pollsSinceLastProgress++
@@ -209,6 +489,32 @@ func (s *Subscriber) poll(ctx context.Context) (_progress uint64, _new bool, _er
 
 		newCertificatesReceived += res.NewCertificates
 		certificatesReceived += res.ReceivedCertificates
+		s.maxPendingInstance = max(s.maxPendingInstance, res.PendingInstance)
+
+		// Stop polling once caught up with the latest pending instance reported so far,
+		// as long as it was first reported during this poll, i.e. by a peer ahead of
+		// any seen before. Otherwise, the peer may well be lagging behind the network,
+		// e.g. a peer that has stopped updating. Either way, peers that have given us
+		// no certificates say nothing about whether others are further ahead.
+		//
+		// Always poll the minimum number of peers regardless, so that the peer tracker
+		// keeps learning which peers are lagging behind. Polls still in flight are
+		// abandoned, and say nothing about their peers either.
+		if polled >= minRequests && certificatesReceived > 0 &&
+			s.maxPendingInstance > previousMaxPendingInstance &&
+			s.poller.nextInstance() >= s.maxPendingInstance {
+			log.Debugf("caught up to instance %d after polling %d of %d peers", s.poller.nextInstance(), polled, len(peers))
+			break poll
+		}
+	}
+
+	// Only a peer that answered can tell whether there is anything left to catch up
+	// with.
+	if answered && !s.caughtUpClosed && s.poller.nextInstance() >= s.maxPendingInstance {
+		log.Infof("caught up with the network at instance %d", s.poller.nextInstance())
+		s.CaughtUp()
+		close(s.caughtUp)
+		s.caughtUpClosed = true
 	}
 
 	// If we received any certificates, record which peers had them and which peers didn't. This
@@ -216,24 +522,28 @@ func (s *Subscriber) poll(ctx context.Context) (_progress uint64, _new bool, _er
 	// not much we can do about that (other than to try to poll peers a bit after we expect the
 	// instance to finish.
 	if certificatesReceived > 0 {
+		s.peerTrackerMu.Lock()
 		for _, p := range misses {
 			s.peerTracker.recordMiss(p)
 		}
 		for _, p := range hits {
 			s.peerTracker.recordHit(p)
 		}
+		s.peerTrackerMu.Unlock()
 	}
 
 	// Record our metrics.
-	metrics.peersPolled.Record(ctx, int64(len(peers)),
-		metric.WithAttributes(attrMadeProgress.Bool(certificatesReceived > 0)),
+	metrics.peersPolled.Record(ctx, int64(polled),
+		metric.WithAttributes(attrMadeProgress.Bool(certificatesReceived > 0), s.attrNetwork()),
 	)
-	if len(peers) > 0 && pollsSinceLastProgress < len(peers) {
-		required := len(peers) - pollsSinceLastProgress
-		metrics.peersRequiredPerPoll.Record(ctx, int64(required))
-		efficiency := float64(required) / float64(len(peers))
-		metrics.pollEfficiency.Record(ctx, efficiency)
+	if polled > 0 && pollsSinceLastProgress < polled {
+		// Efficiency is relative to the peers actually polled, such that the requests
+		// saved by stopping early once caught up are reflected in it.
+		required := polled - pollsSinceLastProgress
+		metrics.peersRequiredPerPoll.Record(ctx, int64(required), metric.WithAttributes(s.attrNetwork()))
+		efficiency := float64(required) / float64(polled)
+		metrics.pollEfficiency.Record(ctx, efficiency, metric.WithAttributes(s.attrNetwork()))
 	}
 
-	return start - s.poller.NextInstance, newCertificatesReceived > 0, nil
+	return start - s.poller.nextInstance(), newCertificatesReceived > 0, nil
 }
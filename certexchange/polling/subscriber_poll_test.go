@@ -0,0 +1,315 @@
+package polling
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/certexchange"
+	"github.com/filecoin-project/go-f3/certstore"
+	"github.com/filecoin-project/go-f3/internal/clock"
+	"github.com/filecoin-project/go-f3/sim/signing"
+	"github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mocknetwork "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriber_PollStopsOnceCaughtUp(t *testing.T) {
+	backend := signing.NewFakeBackend()
+	rng := rand.New(rand.NewSource(1413))
+	cg := MakeCertificates(t, rng, backend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, clk := clock.WithMockClock(ctx)
+	defer cancel()
+
+	mocknet := mocknetwork.New()
+	clientHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	// Every server is fully caught up with the network, such that whichever is
+	// polled first catches the client up.
+	const certCount = 10
+	servers := make([]*certexchange.Server, 2*minRequests)
+	for i := range servers {
+		h, err := mocknet.GenPeer()
+		require.NoError(t, err)
+		cs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+		require.NoError(t, err)
+		servers[i] = &certexchange.Server{
+			NetworkName: TestNetworkName,
+			Host:        h,
+			Store:       cs,
+		}
+	}
+	for range certCount {
+		cert := cg.MakeCertificate()
+		for _, server := range servers {
+			require.NoError(t, server.Store.Put(ctx, cert))
+		}
+	}
+	require.NoError(t, mocknet.LinkAll())
+	require.NoError(t, mocknet.ConnectAllButSelf())
+	for _, server := range servers {
+		require.NoError(t, server.Start(ctx))
+		t.Cleanup(func() { require.NoError(t, server.Stop(context.Background())) })
+	}
+
+	clientCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+	subject := &Subscriber{
+		Client: certexchange.Client{
+			Host:        clientHost,
+			NetworkName: TestNetworkName,
+		},
+		Store:             clientCs,
+		SignatureVerifier: backend,
+		clock:             clk,
+		peerTracker:       newPeerTracker(clk, 0, 0),
+	}
+	subject.poller, err = NewPoller(ctx, &subject.Client, subject.Store, subject.SignatureVerifier)
+	require.NoError(t, err)
+	for _, server := range servers {
+		subject.peerTracker.peerSeen(server.Host.ID())
+	}
+
+	_, newCerts, err := subject.poll(ctx)
+	require.NoError(t, err)
+	require.True(t, newCerts)
+	require.Equal(t, uint64(certCount), subject.poller.NextInstance)
+	require.Equal(t, uint64(certCount-1), clientCs.Latest().GPBFTInstance)
+
+	// The client was caught up by the first peer polled, after which only the
+	// minimum number of peers were polled and the rest were skipped.
+	var polled int
+	for _, record := range subject.peerTracker.peers {
+		if record.hits > 0 || record.misses > 0 {
+			polled++
+		}
+	}
+	require.Equal(t, minRequests, polled)
+}
+
+func TestSubscriber_PollConcurrently(t *testing.T) {
+	backend := signing.NewFakeBackend()
+	rng := rand.New(rand.NewSource(1414))
+	cg := MakeCertificates(t, rng, backend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, clk := clock.WithMockClock(ctx)
+	defer cancel()
+
+	mocknet := mocknetwork.New()
+	clientHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	// Of the minimum number of peers polled, one is unreachable and the rest serve
+	// every certificate.
+	const certCount = 10
+	servers := make([]*certexchange.Server, minRequests-1)
+	for i := range servers {
+		h, err := mocknet.GenPeer()
+		require.NoError(t, err)
+		cs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+		require.NoError(t, err)
+		servers[i] = &certexchange.Server{
+			NetworkName: TestNetworkName,
+			Host:        h,
+			Store:       cs,
+		}
+	}
+	for range certCount {
+		cert := cg.MakeCertificate()
+		for _, server := range servers {
+			require.NoError(t, server.Store.Put(ctx, cert))
+		}
+	}
+	unreachable, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	// The others never respond, such that polling them one after another would
+	// never complete.
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	hanging := make([]peer.ID, 2)
+	for i := range hanging {
+		h, err := mocknet.GenPeer()
+		require.NoError(t, err)
+		h.SetStreamHandler(certexchange.FetchProtocolName(TestNetworkName), func(s network.Stream) {
+			<-release
+			_ = s.Reset()
+		})
+		hanging[i] = h.ID()
+	}
+
+	require.NoError(t, mocknet.LinkAll())
+	require.NoError(t, mocknet.ConnectAllButSelf())
+	for _, server := range servers {
+		require.NoError(t, server.Start(ctx))
+		t.Cleanup(func() { require.NoError(t, server.Stop(context.Background())) })
+	}
+
+	clientCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+	subject := &Subscriber{
+		Client: certexchange.Client{
+			Host:        clientHost,
+			NetworkName: TestNetworkName,
+		},
+		Store:              clientCs,
+		SignatureVerifier:  backend,
+		MaxConcurrentPolls: len(servers) + 1 + len(hanging),
+		clock:              clk,
+		peerTracker:        newPeerTracker(clk, 0, 0),
+	}
+	subject.poller, err = NewPoller(ctx, &subject.Client, subject.Store, subject.SignatureVerifier)
+	require.NoError(t, err)
+	for _, server := range servers {
+		subject.peerTracker.peerSeen(server.Host.ID())
+	}
+	subject.peerTracker.peerSeen(unreachable.ID())
+	for _, p := range hanging {
+		subject.peerTracker.peerSeen(p)
+	}
+
+	type pollResult struct {
+		newCerts bool
+		err      error
+	}
+	done := make(chan pollResult, 1)
+	go func() {
+		_, newCerts, err := subject.poll(ctx)
+		done <- pollResult{newCerts, err}
+	}()
+	var res pollResult
+	select {
+	case res = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("polling did not complete")
+	}
+	require.NoError(t, res.err)
+	require.True(t, res.newCerts)
+	require.Equal(t, uint64(certCount), subject.poller.NextInstance)
+	require.Equal(t, uint64(certCount-1), clientCs.Latest().GPBFTInstance)
+
+	// Every server answered, the unreachable peer failed, and the polls of the
+	// hanging peers were abandoned without counting against them.
+	for _, server := range servers {
+		record := subject.peerTracker.peers[server.Host.ID()]
+		require.Equal(t, 1, record.hits+record.misses, "server %s", server.Host.ID())
+		require.Zero(t, record.sequentialFailures)
+	}
+	require.Equal(t, 1, subject.peerTracker.peers[unreachable.ID()].sequentialFailures)
+	for _, p := range hanging {
+		record := subject.peerTracker.peers[p]
+		require.Zero(t, record.hits+record.misses+record.sequentialFailures, "peer %s", p)
+	}
+}
+
+func TestSubscriber_PollRampsUpWithLatency(t *testing.T) {
+	backend := signing.NewFakeBackend()
+	rng := rand.New(rand.NewSource(1415))
+	cg := MakeCertificates(t, rng, backend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, clk := clock.WithMockClock(ctx)
+	defer cancel()
+
+	mocknet := mocknetwork.New()
+	clientHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+
+	// Every peer reports being polled, then hangs until released.
+	polled := make(chan peer.ID, 2)
+	release := make(chan struct{})
+	var released bool
+	releaseAll := func() {
+		if !released {
+			released = true
+			close(release)
+		}
+	}
+	t.Cleanup(releaseAll)
+	peers := make([]peer.ID, 2)
+	for i := range peers {
+		h, err := mocknet.GenPeer()
+		require.NoError(t, err)
+		id := h.ID()
+		h.SetStreamHandler(certexchange.FetchProtocolName(TestNetworkName), func(s network.Stream) {
+			polled <- id
+			<-release
+			_ = s.Reset()
+		})
+		peers[i] = id
+	}
+	require.NoError(t, mocknet.LinkAll())
+	require.NoError(t, mocknet.ConnectAllButSelf())
+
+	clientCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+	subject := &Subscriber{
+		Client: certexchange.Client{
+			Host:        clientHost,
+			NetworkName: TestNetworkName,
+		},
+		Store:              clientCs,
+		SignatureVerifier:  backend,
+		MaxConcurrentPolls: len(peers),
+		clock:              clk,
+		peerTracker:        newPeerTracker(clk, 0, 0),
+	}
+	subject.poller, err = NewPoller(ctx, &subject.Client, subject.Store, subject.SignatureVerifier)
+	require.NoError(t, err)
+	const latency = time.Second
+	for _, p := range peers {
+		subject.peerTracker.peerSeen(p)
+		subject.peerTracker.updateLatency(p, latency)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := subject.poll(ctx)
+		done <- err
+	}()
+
+	// The first peer is polled straight away, but the next only once the first has
+	// taken longer to respond than its observed latency.
+	var first peer.ID
+	select {
+	case first = <-polled:
+	case <-time.After(10 * time.Second):
+		t.Fatal("first peer was not polled")
+	}
+	select {
+	case p := <-polled:
+		t.Fatalf("peer %s polled before the first was slow to respond", p)
+	case <-time.After(100 * time.Millisecond):
+	}
+	var second peer.ID
+	require.Eventually(t, func() bool {
+		clk.Add(latency)
+		select {
+		case second = <-polled:
+			return true
+		default:
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+	require.NotEqual(t, first, second)
+
+	// Both polls were in flight at once, and fail once the peers are released.
+	releaseAll()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("polling did not complete")
+	}
+	for _, p := range peers {
+		require.Equal(t, 1, subject.peerTracker.peers[p].sequentialFailures, "peer %s", p)
+	}
+}
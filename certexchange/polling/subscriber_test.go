@@ -15,6 +15,7 @@ import (
 
 	"github.com/ipfs/go-datastore"
 	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p/core/test"
 	mocknetwork "github.com/libp2p/go-libp2p/p2p/net/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -117,3 +118,143 @@ func TestSubscriber(t *testing.T) {
 		}
 	}
 }
+
+func TestSubscriber_Announce(t *testing.T) {
+	backend := signing.NewFakeBackend()
+	rng := rand.New(rand.NewSource(1234))
+
+	cg := polling.MakeCertificates(t, rng, backend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, clk := clock.WithMockClock(ctx)
+	defer cancel()
+
+	mocknet := mocknetwork.New()
+
+	clientHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	serverHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	require.NoError(t, mocknet.LinkAll())
+
+	serverCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+	server := certexchange.Server{
+		NetworkName: polling.TestNetworkName,
+		Host:        serverHost,
+		Store:       serverCs,
+	}
+	require.NoError(t, server.Start(ctx))
+	t.Cleanup(func() { require.NoError(t, server.Stop(context.Background())) })
+
+	clientCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+
+	// Poll so rarely that any progress within the test is due to announcements.
+	subscriber := polling.Subscriber{
+		Client: certexchange.Client{
+			Host:        clientHost,
+			NetworkName: polling.TestNetworkName,
+		},
+		Store:               clientCs,
+		SignatureVerifier:   backend,
+		MinimumPollInterval: time.Minute,
+		MaximumPollInterval: time.Hour,
+		InitialPollInterval: time.Hour,
+	}
+	require.NoError(t, subscriber.Start(ctx))
+	t.Cleanup(func() { require.NoError(t, subscriber.Stop(context.Background())) })
+
+	require.NoError(t, mocknet.ConnectAllButSelf())
+	require.Eventually(t, func() bool {
+		return len(subscriber.ExportPeerScores()) == 1
+	}, 10*time.Second, time.Millisecond)
+
+	storeCertificates := func(count int) uint64 {
+		var latest uint64
+		for range count {
+			cert := cg.MakeCertificate()
+			require.NoError(t, serverCs.Put(ctx, cert))
+			latest = cert.GPBFTInstance
+		}
+		return latest
+	}
+	caughtUpTo := func(instance uint64) func() bool {
+		return func() bool {
+			// Fire any timer that is due without moving the clock forward.
+			clk.Add(0)
+			latest := clientCs.Latest()
+			return latest != nil && latest.GPBFTInstance == instance
+		}
+	}
+
+	start := clk.Now()
+
+	// An announcement ahead of the local store triggers an immediate poll.
+	latest := storeCertificates(10)
+	subscriber.Announce(latest + 1)
+	require.Eventually(t, caughtUpTo(latest), 10*time.Second, time.Millisecond)
+	require.Equal(t, start, clk.Now())
+
+	// An announcement of what is already stored locally is ignored, and so is one
+	// made too soon after the previous poll; the latter only brings the next poll
+	// forward to the minimum poll interval.
+	subscriber.Announce(latest + 1)
+	previous := latest
+	latest = storeCertificates(10)
+	subscriber.Announce(latest + 1)
+	clk.Add(subscriber.MinimumPollInterval - time.Second)
+	require.Never(t, caughtUpTo(latest), 100*time.Millisecond, time.Millisecond)
+	require.Equal(t, previous, clientCs.Latest().GPBFTInstance)
+
+	clk.Add(time.Second)
+	require.Eventually(t, caughtUpTo(latest), 10*time.Second, time.Millisecond)
+}
+
+func TestSubscriber_PersistsPeerScores(t *testing.T) {
+	backend := signing.NewFakeBackend()
+	rng := rand.New(rand.NewSource(1234))
+	cg := polling.MakeCertificates(t, rng, backend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, _ = clock.WithMockClock(ctx)
+	defer cancel()
+
+	mocknet := mocknetwork.New()
+	clientHost, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	clientCs, err := certstore.CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 0, cg.PowerTable)
+	require.NoError(t, err)
+	peerScores := ds_sync.MutexWrap(datastore.NewMapDatastore())
+
+	newSubscriber := func() *polling.Subscriber {
+		return &polling.Subscriber{
+			Client: certexchange.Client{
+				Host:        clientHost,
+				NetworkName: polling.TestNetworkName,
+			},
+			Store:               clientCs,
+			SignatureVerifier:   backend,
+			MinimumPollInterval: time.Minute,
+			MaximumPollInterval: time.Hour,
+			InitialPollInterval: time.Hour,
+			Datastore:           peerScores,
+		}
+	}
+
+	scores := []polling.PeerScore{
+		{ID: test.RandPeerIDFatal(t), Hits: 3, Latency: time.Millisecond},
+		{ID: test.RandPeerIDFatal(t), Hits: 2, Misses: 1},
+		{ID: test.RandPeerIDFatal(t), Misses: 3},
+	}
+	subscriber := newSubscriber()
+	require.NoError(t, subscriber.Start(ctx))
+	subscriber.ImportPeerScores(scores)
+	require.NoError(t, subscriber.Stop(ctx))
+
+	// The scores survive a restart.
+	restarted := newSubscriber()
+	require.NoError(t, restarted.Start(ctx))
+	t.Cleanup(func() { require.NoError(t, restarted.Stop(context.Background())) })
+	require.Equal(t, scores, restarted.ExportPeerScores())
+}
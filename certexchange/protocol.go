@@ -1,16 +1,33 @@
 package certexchange
 
 import (
+	"fmt"
+	"io"
 	"math"
 
+	"github.com/filecoin-project/go-f3/certs"
 	"github.com/filecoin-project/go-f3/gpbft"
 	"github.com/libp2p/go-libp2p/core/protocol"
 )
 
+// FetchProtocolName returns the ID of the certificate exchange protocol, whose
+// requests and response headers carry every field of Request and ResponseHeader.
 func FetchProtocolName(nn gpbft.NetworkName) protocol.ID {
+	return protocol.ID("/f3/certexch/get/2/" + string(nn))
+}
+
+// FetchProtocolNameV1 returns the ID of the first version of the certificate
+// exchange protocol, which is still served to and requested from peers that
+// predate FetchProtocolName. Its requests and response headers are encoded as
+// RequestV1 and ResponseHeaderV1.
+func FetchProtocolNameV1(nn gpbft.NetworkName) protocol.ID {
 	return protocol.ID("/f3/certexch/get/1/" + string(nn))
 }
 
+// compressionWindowSize is the zstd window size used to compress response
+// bodies, which bounds the memory needed to decompress them.
+const compressionWindowSize = 1 << 20
+
 // Request unlimited certificates.
 const NoLimit uint64 = math.MaxUint64
 
@@ -23,11 +40,122 @@ type Request struct {
 	// Include the full power table needed to validate the first finality certificate.
 	// Checked by the user against their last finality certificate.
 	IncludePowerTable bool
+	// Respond with the power tables of up to Limit sequential instances starting at
+	// FirstInstance in place of finality certificates. Intended for clients that
+	// only track committees.
+	PowerTablesOnly bool
+	// Instance of a power table held by the user, relative to which the power table
+	// requested via IncludePowerTable may be served as a diff. Zero requests the
+	// full power table, as do peers of FetchProtocolNameV1, to which it is not sent.
+	BasePowerTableInstance uint64
+	// Whether the user accepts a zstd compressed response body. The response
+	// header itself is never compressed. Not sent to peers of FetchProtocolNameV1,
+	// which never compress.
+	AcceptCompression bool
 }
 
 type ResponseHeader struct {
 	// The next instance to be finalized. This is 0 when no instances have been finalized.
 	PendingInstance uint64
-	// Power table, if requested, or empty.
+	// Power table, if requested in full, or empty.
 	PowerTable gpbft.PowerEntries
+	// Whether the power table requested is served as PowerTableDelta, relative to
+	// the power table at BasePowerTableInstance, in place of PowerTable. Never set
+	// by peers of FetchProtocolNameV1.
+	PowerTableIsDelta bool
+	// Diff from the power table at BasePowerTableInstance to the power table
+	// requested, if PowerTableIsDelta, or empty.
+	PowerTableDelta certs.PowerTableDiff
+	// Whether the certificates or power tables following the header are zstd
+	// compressed as a single stream. Only set if the request AcceptCompression,
+	// and never by peers of FetchProtocolNameV1.
+	Compressed bool
+}
+
+// RequestV1 is the encoding of a Request in the first version of the protocol,
+// which carries only its first fields. See FetchProtocolNameV1.
+type RequestV1 struct {
+	FirstInstance     uint64
+	Limit             uint64
+	IncludePowerTable bool
+}
+
+// ResponseHeaderV1 is the encoding of a ResponseHeader in the first version of
+// the protocol, which carries only its first fields. See FetchProtocolNameV1.
+type ResponseHeaderV1 struct {
+	PendingInstance uint64
+	PowerTable      gpbft.PowerEntries
+}
+
+// readRequest reads a request encoded as of the given protocol version, leaving
+// the fields it does not carry unset.
+func readRequest(r io.Reader, v1 bool, req *Request) error {
+	if !v1 {
+		return req.UnmarshalCBOR(r)
+	}
+	var legacy RequestV1
+	if err := legacy.UnmarshalCBOR(r); err != nil {
+		return err
+	}
+	*req = Request{
+		FirstInstance:     legacy.FirstInstance,
+		Limit:             legacy.Limit,
+		IncludePowerTable: legacy.IncludePowerTable,
+	}
+	return nil
+}
+
+// writeRequest writes a request encoded as of the given protocol version,
+// dropping the fields it does not carry.
+func writeRequest(w io.Writer, v1 bool, req *Request) error {
+	if !v1 {
+		return req.MarshalCBOR(w)
+	}
+	return (&RequestV1{
+		FirstInstance:     req.FirstInstance,
+		Limit:             req.Limit,
+		IncludePowerTable: req.IncludePowerTable,
+	}).MarshalCBOR(w)
+}
+
+// readResponseHeader reads a response header encoded as of the given protocol
+// version, leaving the fields it does not carry unset.
+func readResponseHeader(r io.Reader, v1 bool, rh *ResponseHeader) error {
+	if !v1 {
+		return rh.UnmarshalCBOR(r)
+	}
+	var legacy ResponseHeaderV1
+	if err := legacy.UnmarshalCBOR(r); err != nil {
+		return err
+	}
+	*rh = ResponseHeader{
+		PendingInstance: legacy.PendingInstance,
+		PowerTable:      legacy.PowerTable,
+	}
+	return nil
+}
+
+// writeResponseHeader writes a response header encoded as of the given protocol
+// version, dropping the fields it does not carry.
+func writeResponseHeader(w io.Writer, v1 bool, rh *ResponseHeader) error {
+	if !v1 {
+		return rh.MarshalCBOR(w)
+	}
+	return (&ResponseHeaderV1{
+		PendingInstance: rh.PendingInstance,
+		PowerTable:      rh.PowerTable,
+	}).MarshalCBOR(w)
+}
+
+// ResolvePowerTable returns the power table served in the response, given the
+// power table at the BasePowerTableInstance of the request.
+func (rh *ResponseHeader) ResolvePowerTable(base gpbft.PowerEntries) (gpbft.PowerEntries, error) {
+	if !rh.PowerTableIsDelta {
+		return rh.PowerTable, nil
+	}
+	pt, err := certs.ApplyPowerTableDiffs(base, rh.PowerTableDelta)
+	if err != nil {
+		return nil, fmt.Errorf("applying power table delta: %w", err)
+	}
+	return pt, nil
 }
@@ -1,7 +1,10 @@
 package certexchange_test
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -9,10 +12,13 @@ import (
 	"github.com/filecoin-project/go-f3/certs"
 	"github.com/filecoin-project/go-f3/certstore"
 	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/internal/clock"
 
 	cid "github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
 	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
 	mocknetwork "github.com/libp2p/go-libp2p/p2p/net/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -200,3 +206,544 @@ func TestClientServer(t *testing.T) {
 		require.EqualValues(t, pt, pt2)
 	}
 }
+
+func TestClientServer_PowerTablesOnly(t *testing.T) {
+	mocknet := mocknetwork.New()
+	h1, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	h2, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	require.NoError(t, mocknet.LinkAll())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	pt0, pcid0 := testPowerTable(10)
+	cs, err := certstore.CreateStore(ctx, ds, 0, pt0)
+	require.NoError(t, err)
+
+	// Change the power table at instance 1.
+	delta := certs.PowerTableDiff{{ParticipantID: 1, PowerDelta: gpbft.NewStoragePower(5)}}
+	pt1, err := certs.ApplyPowerTableDiffs(pt0, delta)
+	require.NoError(t, err)
+	pcid1, err := certs.MakePowerTableCID(pt1)
+	require.NoError(t, err)
+	require.NotEqual(t, pcid0, pcid1)
+
+	chain := &gpbft.ECChain{
+		TipSets: []*gpbft.TipSet{
+			{Epoch: 0, Key: gpbft.TipSetKey("tsk0"), PowerTable: pcid0},
+		},
+	}
+	require.NoError(t, cs.Put(ctx, &certs.FinalityCertificate{
+		GPBFTInstance:    0,
+		SupplementalData: gpbft.SupplementalData{PowerTable: pcid1},
+		ECChain:          chain,
+		PowerTableDelta:  delta,
+	}))
+	require.NoError(t, cs.Put(ctx, &certs.FinalityCertificate{
+		GPBFTInstance:    1,
+		SupplementalData: gpbft.SupplementalData{PowerTable: pcid1},
+		ECChain:          chain,
+	}))
+
+	server := certexchange.Server{
+		NetworkName: testNetworkName,
+		Host:        h1,
+		Store:       cs,
+	}
+	client := certexchange.Client{
+		Host:        h2,
+		NetworkName: testNetworkName,
+	}
+	require.NoError(t, server.Start(ctx))
+	t.Cleanup(func() { require.NoError(t, server.Stop(context.Background())) })
+	require.NoError(t, mocknet.ConnectAllButSelf())
+
+	for _, test := range []struct {
+		name  string
+		first uint64
+		limit uint64
+		want  []gpbft.PowerEntries
+	}{
+		{name: "all up to pending instance", first: 0, limit: certexchange.NoLimit, want: []gpbft.PowerEntries{pt0, pt1, pt1}},
+		{name: "limited", first: 0, limit: 2, want: []gpbft.PowerEntries{pt0, pt1}},
+		{name: "from later instance", first: 1, limit: 1, want: []gpbft.PowerEntries{pt1}},
+		{name: "beyond pending instance", first: 3, limit: certexchange.NoLimit},
+		{name: "zero limit", first: 0, limit: 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			head, powerTables, err := client.RequestPowerTables(ctx, h1.ID(), test.first, test.limit)
+			require.NoError(t, err)
+			require.EqualValues(t, 2, head.PendingInstance)
+			require.Nil(t, head.PowerTable)
+			require.Equal(t, test.want, powerTables)
+		})
+	}
+}
+
+func TestClientServer_PowerTableDelta(t *testing.T) {
+	mocknet := mocknetwork.New()
+	h1, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	h2, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	require.NoError(t, mocknet.LinkAll())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	pt0, pcid0 := testPowerTable(10)
+	cs, err := certstore.CreateStore(ctx, ds, 0, pt0)
+	require.NoError(t, err)
+
+	// Change the power table at instances 1 and 2.
+	powerTables := []gpbft.PowerEntries{pt0}
+	deltas := []certs.PowerTableDiff{
+		{{ParticipantID: 1, PowerDelta: gpbft.NewStoragePower(5)}},
+		{{ParticipantID: 2, PowerDelta: gpbft.NewStoragePower(-3)}, {ParticipantID: 3, PowerDelta: gpbft.NewStoragePower(7)}},
+	}
+	chain := &gpbft.ECChain{
+		TipSets: []*gpbft.TipSet{
+			{Epoch: 0, Key: gpbft.TipSetKey("tsk0"), PowerTable: pcid0},
+		},
+	}
+	for instance, delta := range deltas {
+		next, err := certs.ApplyPowerTableDiffs(powerTables[instance], delta)
+		require.NoError(t, err)
+		nextCid, err := certs.MakePowerTableCID(next)
+		require.NoError(t, err)
+		powerTables = append(powerTables, next)
+		require.NoError(t, cs.Put(ctx, &certs.FinalityCertificate{
+			GPBFTInstance:    uint64(instance),
+			SupplementalData: gpbft.SupplementalData{PowerTable: nextCid},
+			ECChain:          chain,
+			PowerTableDelta:  delta,
+		}))
+	}
+
+	server := certexchange.Server{
+		NetworkName: testNetworkName,
+		Host:        h1,
+		Store:       cs,
+	}
+	client := certexchange.Client{
+		Host:        h2,
+		NetworkName: testNetworkName,
+	}
+	require.NoError(t, server.Start(ctx))
+	t.Cleanup(func() { require.NoError(t, server.Stop(context.Background())) })
+	require.NoError(t, mocknet.ConnectAllButSelf())
+
+	for _, test := range []struct {
+		name      string
+		base      uint64
+		wantDelta bool
+	}{
+		{name: "full without base", base: 0},
+		{name: "delta from previous instance", base: 1, wantDelta: true},
+		{name: "delta from same instance", base: 2, wantDelta: true},
+		{name: "full with base beyond pending instance", base: 3},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			head, _, err := client.Request(ctx, h1.ID(), &certexchange.Request{
+				FirstInstance:          2,
+				IncludePowerTable:      true,
+				BasePowerTableInstance: test.base,
+			})
+			require.NoError(t, err)
+			require.EqualValues(t, 2, head.PendingInstance)
+			require.Equal(t, test.wantDelta, head.PowerTableIsDelta)
+			if test.wantDelta {
+				require.Nil(t, head.PowerTable)
+			} else {
+				require.Empty(t, head.PowerTableDelta)
+			}
+			var base gpbft.PowerEntries
+			if test.base < uint64(len(powerTables)) {
+				base = powerTables[test.base]
+			}
+			pt, err := head.ResolvePowerTable(base)
+			require.NoError(t, err)
+			require.EqualValues(t, powerTables[2], pt)
+		})
+	}
+}
+
+// newThrottledTestServer starts a server with the given limits that holds
+// certCount certificates of identical size, and returns a client to it along
+// with the size of each certificate.
+func newThrottledTestServer(t *testing.T, ctx context.Context, certCount uint64, limits func(certSize int) certexchange.Server) (*certexchange.Client, peer.ID, int) {
+	mocknet := mocknetwork.New()
+	h1, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	h2, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	require.NoError(t, mocknet.LinkAll())
+
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	pt, pcid := testPowerTable(10)
+	cs, err := certstore.CreateStore(ctx, ds, 0, pt)
+	require.NoError(t, err)
+	for instance := range certCount {
+		require.NoError(t, cs.Put(ctx, &certs.FinalityCertificate{
+			GPBFTInstance:    instance,
+			SupplementalData: gpbft.SupplementalData{PowerTable: pcid},
+			ECChain: &gpbft.ECChain{
+				TipSets: []*gpbft.TipSet{
+					{Epoch: 0, Key: gpbft.TipSetKey("tsk0"), PowerTable: pcid},
+				},
+			},
+		}))
+	}
+	// The certificates differ only by instance, encoded in a single byte.
+	var buf bytes.Buffer
+	require.NoError(t, cs.Latest().MarshalCBOR(&buf))
+	certSize := buf.Len()
+
+	server := limits(certSize)
+	server.NetworkName = testNetworkName
+	server.Host = h1
+	server.Store = cs
+	require.NoError(t, server.Start(ctx))
+	t.Cleanup(func() { require.NoError(t, server.Stop(context.Background())) })
+	require.NoError(t, mocknet.ConnectAllButSelf())
+
+	return &certexchange.Client{Host: h2, NetworkName: testNetworkName}, h1.ID(), certSize
+}
+
+func requestAllInstances(t *testing.T, ctx context.Context, client *certexchange.Client, server peer.ID, first uint64) (*certexchange.ResponseHeader, []uint64) {
+	head, received, err := client.Request(ctx, server, &certexchange.Request{
+		FirstInstance: first,
+		Limit:         certexchange.NoLimit,
+	})
+	require.NoError(t, err)
+	var instances []uint64
+	for c := range received {
+		instances = append(instances, c.GPBFTInstance)
+	}
+	return head, instances
+}
+
+func TestClientServer_BandwidthThrottled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx, clk := clock.WithMockClock(ctx)
+
+	const certCount = 10
+	client, server, _ := newThrottledTestServer(t, ctx, certCount, func(certSize int) certexchange.Server {
+		// Allow three and a half certificates worth of bytes per second.
+		return certexchange.Server{MaxBytesPerSecond: certSize*3 + certSize/2}
+	})
+
+	// Bandwidth is exhausted after three certificates, but the header is still
+	// served in full.
+	head, instances := requestAllInstances(t, ctx, client, server, 0)
+	require.EqualValues(t, certCount, head.PendingInstance)
+	require.Equal(t, []uint64{0, 1, 2}, instances)
+	head, instances = requestAllInstances(t, ctx, client, server, 3)
+	require.EqualValues(t, certCount, head.PendingInstance)
+	require.Empty(t, instances)
+
+	// Until a second later, once bandwidth is replenished.
+	clk.Add(time.Second)
+	_, instances = requestAllInstances(t, ctx, client, server, 3)
+	require.Equal(t, []uint64{3, 4, 5}, instances)
+}
+
+func TestClientServer_BandwidthThrottledLargerThanLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx, clk := clock.WithMockClock(ctx)
+
+	const certCount = 10
+	client, server, _ := newThrottledTestServer(t, ctx, certCount, func(int) certexchange.Server {
+		// Every certificate and power table is larger than the limit.
+		return certexchange.Server{MaxBytesPerSecond: 1}
+	})
+	pt, _ := testPowerTable(10)
+
+	// The power table in the header is served since bandwidth has accrued, leaving
+	// none for certificates.
+	head, received, err := client.Request(ctx, server, &certexchange.Request{
+		Limit:             certexchange.NoLimit,
+		IncludePowerTable: true,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, pt, head.PowerTable)
+	for range received {
+		require.Fail(t, "certificate served beyond the bandwidth limit")
+	}
+
+	// Nor for another power table, which is omitted from the header.
+	head, received, err = client.Request(ctx, server, &certexchange.Request{
+		Limit:             certexchange.NoLimit,
+		IncludePowerTable: true,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, certCount, head.PendingInstance)
+	require.Empty(t, head.PowerTable)
+	for range received {
+		require.Fail(t, "certificate served beyond the bandwidth limit")
+	}
+
+	// Certificates larger than the limit are served one at a time, once enough
+	// bandwidth has accrued.
+	for instance := range uint64(2) {
+		_, instances := requestAllInstances(t, ctx, client, server, instance)
+		require.Empty(t, instances)
+		clk.Add(time.Hour)
+		_, instances = requestAllInstances(t, ctx, client, server, instance)
+		require.Equal(t, []uint64{instance}, instances)
+	}
+}
+
+func TestClientServer_StreamsThrottled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const certCount = 10
+	client, server, _ := newThrottledTestServer(t, ctx, certCount, func(int) certexchange.Server {
+		return certexchange.Server{MaxConcurrentStreams: 1}
+	})
+
+	// Occupy the only stream available by opening one and never completing the
+	// request.
+	stream, err := client.Host.NewStream(ctx, server, certexchange.FetchProtocolName(testNetworkName))
+	require.NoError(t, err)
+	var req bytes.Buffer
+	require.NoError(t, (&certexchange.Request{Limit: certexchange.NoLimit}).MarshalCBOR(&req))
+	_, err = stream.Write(req.Bytes()[:req.Len()-1])
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		head, instances := requestAllInstances(t, ctx, client, server, 0)
+		return head.PendingInstance == certCount && len(instances) == 0
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// Once the stream is gone, certificates are served again.
+	require.NoError(t, stream.Reset())
+	require.Eventually(t, func() bool {
+		_, instances := requestAllInstances(t, ctx, client, server, 0)
+		return len(instances) == certCount
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestClientServer_Compression(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const certCount = 10
+	client, server, _ := newThrottledTestServer(t, ctx, certCount, func(int) certexchange.Server {
+		return certexchange.Server{}
+	})
+	pt, _ := testPowerTable(10)
+
+	for _, compressed := range []bool{false, true} {
+		name := "uncompressed"
+		if compressed {
+			name = "compressed"
+		}
+		t.Run(name, func(t *testing.T) {
+			head, received, err := client.Request(ctx, server, &certexchange.Request{
+				FirstInstance:     0,
+				Limit:             certexchange.NoLimit,
+				IncludePowerTable: true,
+				AcceptCompression: compressed,
+			})
+			require.NoError(t, err)
+			require.Equal(t, compressed, head.Compressed)
+			require.EqualValues(t, certCount, head.PendingInstance)
+			require.EqualValues(t, pt, head.PowerTable)
+
+			var instances []uint64
+			for c := range received {
+				require.Equal(t, gpbft.TipSetKey("tsk0"), c.ECChain.Head().Key)
+				instances = append(instances, c.GPBFTInstance)
+			}
+			require.Len(t, instances, certCount)
+			for i, instance := range instances {
+				require.EqualValues(t, i, instance)
+			}
+		})
+	}
+
+	t.Run("power tables only", func(t *testing.T) {
+		head, powerTables, err := client.RequestPowerTables(ctx, server, 0, certexchange.NoLimit)
+		require.NoError(t, err)
+		require.True(t, head.Compressed)
+		require.Len(t, powerTables, certCount+1)
+		for _, got := range powerTables {
+			require.EqualValues(t, pt, got)
+		}
+	})
+}
+
+// newV1TestServer starts a peer that only serves the first version of the
+// protocol, responding with the given power table and certificates for the
+// instances requested, and returns a client of it.
+func newV1TestServer(t *testing.T, pt gpbft.PowerEntries, certCount uint64) (*certexchange.Client, peer.ID) {
+	pcid, err := certs.MakePowerTableCID(pt)
+	require.NoError(t, err)
+
+	mocknet := mocknetwork.New()
+	h1, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	h2, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	require.NoError(t, mocknet.LinkAll())
+
+	h1.SetStreamHandler(certexchange.FetchProtocolNameV1(testNetworkName), func(stream network.Stream) {
+		defer func() { _ = stream.Close() }()
+		var req certexchange.RequestV1
+		if err := req.UnmarshalCBOR(stream); err != nil {
+			_ = stream.Reset()
+			return
+		}
+		resp := certexchange.ResponseHeaderV1{PendingInstance: certCount}
+		if req.IncludePowerTable {
+			resp.PowerTable = pt
+		}
+		if err := resp.MarshalCBOR(stream); err != nil {
+			_ = stream.Reset()
+			return
+		}
+		for instance := req.FirstInstance; instance < certCount && instance-req.FirstInstance < req.Limit; instance++ {
+			cert := certs.FinalityCertificate{
+				GPBFTInstance:    instance,
+				SupplementalData: gpbft.SupplementalData{PowerTable: pcid},
+				ECChain: &gpbft.ECChain{
+					TipSets: []*gpbft.TipSet{{Epoch: 0, Key: gpbft.TipSetKey("tsk0"), PowerTable: pcid}},
+				},
+			}
+			if err := cert.MarshalCBOR(stream); err != nil {
+				_ = stream.Reset()
+				return
+			}
+		}
+	})
+	require.NoError(t, mocknet.ConnectAllButSelf())
+
+	return &certexchange.Client{Host: h2, NetworkName: testNetworkName}, h1.ID()
+}
+
+func TestClientServer_V1(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const certCount = 3
+	pt, _ := testPowerTable(10)
+
+	t.Run("old client", func(t *testing.T) {
+		client, server, _ := newThrottledTestServer(t, ctx, certCount, func(int) certexchange.Server {
+			return certexchange.Server{}
+		})
+		stream, err := client.Host.NewStream(ctx, server, certexchange.FetchProtocolNameV1(testNetworkName))
+		require.NoError(t, err)
+		defer func() { _ = stream.Reset() }()
+		req := certexchange.RequestV1{Limit: certexchange.NoLimit, IncludePowerTable: true}
+		require.NoError(t, req.MarshalCBOR(stream))
+		require.NoError(t, stream.CloseWrite())
+
+		br := bufio.NewReader(stream)
+		var head certexchange.ResponseHeaderV1
+		require.NoError(t, head.UnmarshalCBOR(br))
+		require.EqualValues(t, certCount, head.PendingInstance)
+		require.EqualValues(t, pt, head.PowerTable)
+		for instance := range uint64(certCount) {
+			var cert certs.FinalityCertificate
+			require.NoError(t, cert.UnmarshalCBOR(br))
+			require.Equal(t, instance, cert.GPBFTInstance)
+		}
+		_, err = br.ReadByte()
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("old server", func(t *testing.T) {
+		client, server := newV1TestServer(t, pt, certCount)
+		head, received, err := client.Request(ctx, server, &certexchange.Request{
+			Limit:             certexchange.NoLimit,
+			IncludePowerTable: true,
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, certCount, head.PendingInstance)
+		require.EqualValues(t, pt, head.PowerTable)
+		var instances []uint64
+		for c := range received {
+			instances = append(instances, c.GPBFTInstance)
+		}
+		require.Equal(t, []uint64{0, 1, 2}, instances)
+
+		// Power tables alone are not served by the first version of the protocol.
+		_, _, err = client.RequestPowerTables(ctx, server, 0, certexchange.NoLimit)
+		require.Error(t, err)
+	})
+}
+
+func TestClientServer_V1PowerTableDelta(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pt, _ := testPowerTable(10)
+	client, server := newV1TestServer(t, pt, 3)
+
+	// The base is not sent to the peer, which serves the full power table instead.
+	head, _, err := client.Request(ctx, server, &certexchange.Request{
+		FirstInstance:          2,
+		IncludePowerTable:      true,
+		BasePowerTableInstance: 1,
+	})
+	require.NoError(t, err)
+	require.False(t, head.PowerTableIsDelta)
+	require.Empty(t, head.PowerTableDelta)
+	resolved, err := head.ResolvePowerTable(nil)
+	require.NoError(t, err)
+	require.EqualValues(t, pt, resolved)
+}
+
+func TestClientServer_V1Compression(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const certCount = 3
+	pt, _ := testPowerTable(10)
+
+	t.Run("old client", func(t *testing.T) {
+		client, server, _ := newThrottledTestServer(t, ctx, certCount, func(int) certexchange.Server {
+			return certexchange.Server{}
+		})
+		// Peers of the first version of the protocol cannot accept compression, so
+		// certificates are served to them uncompressed.
+		stream, err := client.Host.NewStream(ctx, server, certexchange.FetchProtocolNameV1(testNetworkName))
+		require.NoError(t, err)
+		defer func() { _ = stream.Reset() }()
+		req := certexchange.RequestV1{Limit: certexchange.NoLimit}
+		require.NoError(t, req.MarshalCBOR(stream))
+		require.NoError(t, stream.CloseWrite())
+
+		br := bufio.NewReader(stream)
+		var head certexchange.ResponseHeaderV1
+		require.NoError(t, head.UnmarshalCBOR(br))
+		for instance := range uint64(certCount) {
+			var cert certs.FinalityCertificate
+			require.NoError(t, cert.UnmarshalCBOR(br))
+			require.Equal(t, instance, cert.GPBFTInstance)
+		}
+	})
+
+	t.Run("old server", func(t *testing.T) {
+		client, server := newV1TestServer(t, pt, certCount)
+		head, received, err := client.Request(ctx, server, &certexchange.Request{
+			Limit:             certexchange.NoLimit,
+			AcceptCompression: true,
+		})
+		require.NoError(t, err)
+		require.False(t, head.Compressed)
+		var instances []uint64
+		for c := range received {
+			instances = append(instances, c.GPBFTInstance)
+		}
+		require.Equal(t, []uint64{0, 1, 2}, instances)
+	})
+}
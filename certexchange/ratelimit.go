@@ -0,0 +1,48 @@
+package certexchange
+
+import (
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-f3/internal/clock"
+)
+
+// tokenBucket is a token bucket that refills at a fixed rate of tokens per
+// second, holding at most one second's worth of tokens. A full bucket lends any
+// number of tokens taken beyond those it holds, which are repaid as it refills,
+// such that takes larger than the rate are possible but no more frequent than
+// the rate allows.
+type tokenBucket struct {
+	clock clock.Clock
+	rate  float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(clk clock.Clock, perSecond int) *tokenBucket {
+	return &tokenBucket{
+		clock:  clk,
+		rate:   float64(perSecond),
+		tokens: float64(perSecond),
+		last:   clk.Now(),
+	}
+}
+
+// take takes n tokens from the bucket if available, or if the bucket is full,
+// and returns whether it did. It never blocks.
+func (b *tokenBucket) take(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.rate, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	if b.tokens < float64(n) && b.tokens < b.rate {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
@@ -2,16 +2,23 @@ package certexchange
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/filecoin-project/go-f3/certs"
 	"github.com/filecoin-project/go-f3/certstore"
 	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/internal/clock"
 	"github.com/filecoin-project/go-f3/internal/measurements"
+	"github.com/klauspost/compress/zstd"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"go.opentelemetry.io/otel/metric"
 
 	logging "github.com/ipfs/go-log/v2"
@@ -30,6 +37,19 @@ type Server struct {
 	NetworkName    gpbft.NetworkName
 	Host           host.Host
 	Store          *certstore.Store
+	// MaxBytesPerSecond caps the rate, in bytes per second, at which certificates
+	// and power tables are served across all requests. Once the cap is reached,
+	// requests are still answered with a response header but fewer certificates
+	// and no power table, rather than blocked. A certificate or power table larger
+	// than the cap is served once a second's worth of bandwidth has accrued, at the
+	// expense of those that follow. Zero means no cap.
+	MaxBytesPerSecond int
+	// MaxConcurrentStreams caps the number of requests served at once. Requests
+	// beyond the cap are answered with a response header only. Zero means no cap.
+	MaxConcurrentStreams int
+
+	bandwidth     *tokenBucket
+	activeStreams atomic.Int64
 
 	// - held (read) by all active requests.
 	// - taken (write) on shutdown to block until said requests complete.
@@ -44,7 +64,9 @@ func (s *Server) withDeadline(ctx context.Context) (context.Context, context.Can
 	return ctx, func() {}
 }
 
-func (s *Server) handleRequest(ctx context.Context, stream network.Stream) (_err error) {
+// handleRequest serves a request read from the given stream, encoded as of the
+// first version of the protocol if v1.
+func (s *Server) handleRequest(ctx context.Context, stream network.Stream, v1, tooManyStreams bool) (_err error) {
 	start := time.Now()
 	servedPowerTable := false
 	internalError := false
@@ -57,11 +79,13 @@ func (s *Server) handleRequest(ctx context.Context, stream network.Stream) (_err
 		if internalError {
 			metrics.serveTime.Record(ctx, d, metric.WithAttributes(
 				measurements.AttrStatusInternalError,
+				measurements.AttrNetwork.String(string(s.NetworkName)),
 			))
 		} else {
 			metrics.serveTime.Record(ctx, d, metric.WithAttributes(
 				measurements.Status(ctx, _err),
 				attrWithPowerTable.Bool(servedPowerTable),
+				measurements.AttrNetwork.String(string(s.NetworkName)),
 			))
 		}
 	}()
@@ -76,7 +100,7 @@ func (s *Server) handleRequest(ctx context.Context, stream network.Stream) (_err
 
 	// Request has no variable-length fields, so we don't need a limited reader.
 	var req Request
-	if err := req.UnmarshalCBOR(br); err != nil {
+	if err := readRequest(br, v1, &req); err != nil {
 		log.Debugf("failed to read request from stream: %v", err)
 		return err
 	}
@@ -97,20 +121,101 @@ func (s *Server) handleRequest(ctx context.Context, stream network.Stream) (_err
 			log.Errorf("failed to load power table: %v", err)
 			return err
 		}
-		resp.PowerTable = pt
+		if base := s.basePowerTable(ctx, &req, resp.PendingInstance); base != nil {
+			resp.PowerTableIsDelta = true
+			resp.PowerTableDelta = certs.MakePowerTableDiff(base, pt)
+		} else {
+			resp.PowerTable = pt
+		}
 	}
 
-	if err := resp.MarshalCBOR(bw); err != nil {
+	// A power table in the header counts towards the bandwidth limit like any
+	// other, so is omitted along with the body if there isn't enough bandwidth.
+	throttledHeader := false
+	if servedPowerTable {
+		var buf bytes.Buffer
+		if err := writeResponseHeader(&buf, v1, &resp); err != nil {
+			log.Debugf("failed to marshal header: %v", err)
+			return err
+		}
+		if s.bandwidth != nil && !s.bandwidth.take(buf.Len()) {
+			throttledHeader = true
+			servedPowerTable = false
+			resp.PowerTable, resp.PowerTableIsDelta, resp.PowerTableDelta = nil, false, nil
+		}
+	}
+
+	// There is no body to compress when responding with the header alone.
+	headerOnly := tooManyStreams || throttledHeader
+	resp.Compressed = req.AcceptCompression && !headerOnly
+
+	if err := writeResponseHeader(bw, v1, &resp); err != nil {
 		log.Debugf("failed to write header to stream: %v", err)
 		return err
 	}
 
+	if headerOnly {
+		// Respond with the header alone so that the client learns of the latest
+		// instance without being served anything else.
+		if tooManyStreams {
+			s.recordThrottled(ctx, throttledByStreams)
+		} else {
+			s.recordThrottled(ctx, throttledByBandwidth)
+		}
+		return bw.Flush()
+	}
+
+	body := io.Writer(bw)
+	flush := bw.Flush
+	if resp.Compressed {
+		zw, err := zstd.NewWriter(bw,
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithWindowSize(compressionWindowSize))
+		if err != nil {
+			log.Errorf("failed to create compressor: %v", err)
+			internalError = true
+			return err
+		}
+		body = zw
+		flush = func() error {
+			if err := zw.Close(); err != nil {
+				return err
+			}
+			return bw.Flush()
+		}
+	}
+
+	if req.PowerTablesOnly {
+		servedPowerTable = true
+		// Serve power tables up to and including the pending instance, whose power
+		// table is already known.
+		for instance := req.FirstInstance; instance <= resp.PendingInstance && instance-req.FirstInstance < limit; instance++ {
+			pt, err := s.Store.GetPowerTable(ctx, instance)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Errorf("failed to load power table for instance %d: %v", instance, err)
+					internalError = true
+				}
+				break
+			}
+			if written, err := s.writeThrottled(body, &pt); err != nil {
+				log.Debugf("failed to write power table to stream: %v", err)
+				return err
+			} else if !written {
+				s.recordThrottled(ctx, throttledByBandwidth)
+				break
+			}
+		}
+		return flush()
+	}
+
 	certsServed := 0
 	defer func() {
 		metrics.certificatesServed.Record(ctx, int64(certsServed),
 			metric.WithAttributes(
 				measurements.Status(ctx, _err),
 				attrWithPowerTable.Bool(servedPowerTable),
+				measurements.AttrNetwork.String(string(s.NetworkName)),
 			),
 		)
 	}()
@@ -124,22 +229,68 @@ func (s *Server) handleRequest(ctx context.Context, stream network.Stream) (_err
 			end = resp.PendingInstance - 1
 		}
 
-		certs, err := s.Store.GetRange(ctx, req.FirstInstance, end)
-		if err == nil || errors.Is(err, certstore.ErrCertNotFound) {
-			for i := range certs {
-				if err := certs[i].MarshalCBOR(bw); err != nil {
-					log.Debugf("failed to write certificate to stream: %v", err)
-					return err
+		// Stream the certificates as they are read rather than loading the whole
+		// range into memory.
+		for cert, err := range s.Store.GetRangeIter(ctx, req.FirstInstance, end) {
+			if err != nil {
+				if !errors.Is(err, certstore.ErrCertNotFound) && ctx.Err() == nil {
+					log.Errorf("failed to load finality certificates: %v", err)
+					internalError = true
 				}
-				certsServed++
+				break
 			}
-		} else if ctx.Err() == nil {
-			log.Errorf("failed to load finality certificates: %v", err)
-			internalError = true
+			if written, err := s.writeThrottled(body, cert); err != nil {
+				log.Debugf("failed to write certificate to stream: %v", err)
+				return err
+			} else if !written {
+				s.recordThrottled(ctx, throttledByBandwidth)
+				break
+			}
+			certsServed++
 		}
 	}
 
-	return bw.Flush()
+	return flush()
+}
+
+// writeThrottled writes the given value to the stream unless doing so would
+// exceed MaxBytesPerSecond, and returns whether it was written.
+func (s *Server) writeThrottled(w io.Writer, v cbg.CBORMarshaler) (bool, error) {
+	if s.bandwidth == nil {
+		return true, v.MarshalCBOR(w)
+	}
+	var buf bytes.Buffer
+	if err := v.MarshalCBOR(&buf); err != nil {
+		return false, err
+	}
+	if !s.bandwidth.take(buf.Len()) {
+		return false, nil
+	}
+	_, err := w.Write(buf.Bytes())
+	return err == nil, err
+}
+
+func (s *Server) recordThrottled(ctx context.Context, reason string) {
+	metrics.throttledRequests.Add(ctx, 1, metric.WithAttributes(
+		attrThrottledBy.String(reason),
+		measurements.AttrNetwork.String(string(s.NetworkName)),
+	))
+}
+
+// basePowerTable returns the power table relative to which the one requested
+// is served as a diff, or nil if it is to be served in full.
+func (s *Server) basePowerTable(ctx context.Context, req *Request, pendingInstance uint64) gpbft.PowerEntries {
+	if req.BasePowerTableInstance == 0 || req.BasePowerTableInstance > pendingInstance {
+		return nil
+	}
+	base, err := s.Store.GetPowerTable(ctx, req.BasePowerTableInstance)
+	if err != nil {
+		// The base may be too old to be retained, in which case the power table is
+		// served in full, as if no base was requested.
+		log.Debugf("failed to load base power table at instance %d: %v", req.BasePowerTableInstance, err)
+		return nil
+	}
+	return base
 }
 
 // Start the server.
@@ -152,7 +303,19 @@ func (s *Server) Start(startCtx context.Context) error {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	s.stopFunc = cancel
-	s.Host.SetStreamHandler(FetchProtocolName(s.NetworkName), func(stream network.Stream) {
+	if s.MaxBytesPerSecond > 0 {
+		s.bandwidth = newTokenBucket(clock.GetClock(startCtx), s.MaxBytesPerSecond)
+	}
+	// Serve peers that predate the current version of the protocol too.
+	s.Host.SetStreamHandler(FetchProtocolName(s.NetworkName), s.streamHandler(ctx, false))
+	s.Host.SetStreamHandler(FetchProtocolNameV1(s.NetworkName), s.streamHandler(ctx, true))
+	return nil
+}
+
+// streamHandler returns the handler of streams of the protocol version given by
+// v1, which serves requests until the given context is cancelled.
+func (s *Server) streamHandler(ctx context.Context, v1 bool) network.StreamHandler {
+	return func(stream network.Stream) {
 		// Hold the read-lock for the duration of the request so shutdown can block on
 		// closing all request handlers.
 		if !s.runningLk.TryRLock() {
@@ -175,14 +338,16 @@ func (s *Server) Start(startCtx context.Context) error {
 		ctx, cancel := s.withDeadline(ctx)
 		defer cancel()
 
-		if err := s.handleRequest(ctx, stream); err != nil {
+		active := s.activeStreams.Add(1)
+		defer s.activeStreams.Add(-1)
+		tooManyStreams := s.MaxConcurrentStreams > 0 && active > int64(s.MaxConcurrentStreams)
+
+		if err := s.handleRequest(ctx, stream, v1, tooManyStreams); err != nil {
 			_ = stream.Reset()
 		} else {
 			_ = stream.Close()
 		}
-
-	})
-	return nil
+	}
 }
 
 // Stop the server.
@@ -202,6 +367,7 @@ func (s *Server) Stop(stopCtx context.Context) error {
 	}
 	s.stopFunc = nil
 	s.Host.RemoveStreamHandler(FetchProtocolName(s.NetworkName))
+	s.Host.RemoveStreamHandler(FetchProtocolNameV1(s.NetworkName))
 
 	return nil
 }
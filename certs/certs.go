@@ -68,6 +68,12 @@ func NewFinalityCertificate(powerDelta PowerTableDiff, justification *gpbft.Just
 		return nil, fmt.Errorf("got a decision for bottom for instance %d", justification.Vote.Instance)
 	}
 
+	// Finality certificates carry no aggregate scheme, and so can only be verified
+	// under the default one.
+	if justification.Scheme != gpbft.AggregateSchemeBLSG2 {
+		return nil, fmt.Errorf("unsupported aggregate scheme for a finality certificate: %d", justification.Scheme)
+	}
+
 	return &FinalityCertificate{
 		GPBFTInstance:    justification.Vote.Instance,
 		SupplementalData: justification.Vote.SupplementalData,
@@ -88,6 +94,9 @@ func NewFinalityCertificate(powerDelta PowerTableDiff, justification *gpbft.Just
 // certificates, this function will return a (possibly empty) prefix of the EC chain correctly
 // finalized, the instance of the first invalid finality certificate, and the power table that
 // should be used to validate that finality certificate, along with the error encountered.
+//
+// Light clients and other external tools verifying certificates from a trusted power table should
+// use VerifyFrom instead.
 func ValidateFinalityCertificates(verifier gpbft.Verifier, network gpbft.NetworkName, prevPowerTable gpbft.PowerEntries, nextInstance uint64, base *gpbft.TipSet,
 	certs ...*FinalityCertificate) (_nextInstance uint64, chain *gpbft.ECChain, newPowerTable gpbft.PowerEntries, err error) {
 	for _, cert := range certs {
@@ -129,7 +138,7 @@ func ValidateFinalityCertificates(verifier gpbft.Verifier, network gpbft.Network
 
 		if cert.SupplementalData.PowerTable != powerTableCid {
 			return nextInstance, chain, prevPowerTable, fmt.Errorf(
-				"incorrect power diff from finality certificate for instance %d: expected %s, got %s",
+				"incorrect power diff from finality certificate for instance %d: supplemental data commits to power table %s, but applying the diff yields %s",
 				cert.GPBFTInstance, cert.SupplementalData.PowerTable, powerTableCid)
 		}
 		nextInstance++
@@ -138,7 +147,35 @@ func ValidateFinalityCertificates(verifier gpbft.Verifier, network gpbft.Network
 		base = cert.ECChain.Head()
 	}
 
-	return nextInstance, chain, newPowerTable, nil
+	return nextInstance, chain, prevPowerTable, nil
+}
+
+// VerifyFrom verifies the finality certificates that follow a trusted power table snapshot, as a
+// light client does without replaying every certificate since genesis. It is the entry point for
+// any verification of certificates outside of a running participant. The trusted power table must
+// be the one that signs the certificate for the trusted instance, and the certificates must be
+// contiguous starting at that instance. The signature of each certificate is verified against the
+// power table that results from applying the power table deltas of every certificate before it,
+// starting with the trusted one.
+//
+// Returns the instance after the last certificate, the chain finalized by the certificates, and
+// the power table to use for that instance. Returns an error if the trusted power table is empty
+// or any certificate is invalid, along with the instance of the first invalid certificate, and the
+// chain finalized and power table as of the last valid certificate.
+func VerifyFrom(nn gpbft.NetworkName, verifier gpbft.Verifier, trustedInstance uint64, trustedPowerTable gpbft.PowerEntries,
+	certsFromTrusted []FinalityCertificate) (uint64, *gpbft.ECChain, gpbft.PowerEntries, error) {
+	if len(trustedPowerTable) == 0 {
+		return trustedInstance, nil, nil, fmt.Errorf("trusted power table for instance %d is empty", trustedInstance)
+	}
+	certs := make([]*FinalityCertificate, len(certsFromTrusted))
+	for i := range certsFromTrusted {
+		certs[i] = &certsFromTrusted[i]
+	}
+	nextInstance, chain, powerTable, err := ValidateFinalityCertificates(verifier, nn, trustedPowerTable, trustedInstance, nil, certs...)
+	if err != nil {
+		return nextInstance, chain, powerTable, fmt.Errorf("verifying certificates from trusted instance %d: %w", trustedInstance, err)
+	}
+	return nextInstance, chain, powerTable, nil
 }
 
 // Verify the signature of the given finality certificate. This doesn't validate the power delta, or
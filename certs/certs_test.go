@@ -2,6 +2,7 @@ package certs_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"slices"
@@ -248,6 +249,166 @@ func TestFinalityCertificates(t *testing.T) {
 	require.Equal(t, powerTable, newPowerTable)
 	require.True(t, certificates[len(certificates)-1].ECChain.Head().Equal(chain.Head()))
 	require.True(t, certificates[4].ECChain.TipSets[1].Equal(chain.Base()))
+
+	// Validate none, which leaves the power table as is.
+	nextInstance, chain, newPowerTable, err = certs.ValidateFinalityCertificates(backend, networkName, powerTables[3], 3, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, nextInstance)
+	require.True(t, chain.IsZero())
+	require.Equal(t, powerTables[3], newPowerTable)
+
+	// Validate a range with an invalid certificate mid-chain, which stops at the
+	// invalid certificate with everything before it applied.
+	invalid := *certificates[6]
+	invalid.Signature = slices.Clone(invalid.Signature)
+	invalid.Signature[0] ^= 0xff
+	withInvalid := slices.Clone(certificates)
+	withInvalid[6] = &invalid
+	nextInstance, chain, newPowerTable, err = certs.ValidateFinalityCertificates(backend, networkName, powerTables[2], 2, nil, withInvalid[2:]...)
+	require.ErrorContains(t, err, "invalid signature on finality certificate for instance 6")
+	require.EqualValues(t, 6, nextInstance)
+	require.Equal(t, powerTables[6], newPowerTable)
+	require.True(t, certificates[2].ECChain.TipSets[1].Equal(chain.Base()))
+	require.True(t, certificates[5].ECChain.Head().Equal(chain.Head()))
+}
+
+func TestVerifyFrom(t *testing.T) {
+	const trustedInstance = 6
+	backend := signing.NewFakeBackend()
+	certificates, powerTables := makeFinalityCertificates(t, backend, 10)
+	fromTrusted := make([]certs.FinalityCertificate, 0, len(certificates)-trustedInstance)
+	for _, cert := range certificates[trustedInstance:] {
+		fromTrusted = append(fromTrusted, *cert)
+	}
+
+	// Verify from a snapshot several instances after genesis.
+	nextInstance, chain, powerTable, err := certs.VerifyFrom(networkName, backend, trustedInstance, powerTables[trustedInstance], fromTrusted)
+	require.NoError(t, err)
+	require.EqualValues(t, len(certificates), nextInstance)
+	require.Equal(t, powerTables[len(certificates)], powerTable)
+	require.True(t, certificates[trustedInstance].ECChain.TipSets[1].Equal(chain.Base()))
+	require.True(t, certificates[len(certificates)-1].ECChain.Head().Equal(chain.Head()))
+
+	// Verify up to an instance before the latest.
+	nextInstance, chain, powerTable, err = certs.VerifyFrom(networkName, backend, trustedInstance, powerTables[trustedInstance], fromTrusted[:2])
+	require.NoError(t, err)
+	require.EqualValues(t, trustedInstance+2, nextInstance)
+	require.Equal(t, powerTables[trustedInstance+2], powerTable)
+	require.True(t, certificates[trustedInstance+1].ECChain.Head().Equal(chain.Head()))
+
+	// The signatures don't verify against a snapshot of another instance.
+	nextInstance, chain, powerTable, err = certs.VerifyFrom(networkName, backend, trustedInstance, powerTables[trustedInstance-1], fromTrusted)
+	require.ErrorContains(t, err, "verifying certificates from trusted instance 6")
+	require.EqualValues(t, trustedInstance, nextInstance)
+	require.True(t, chain.IsZero())
+	require.Equal(t, powerTables[trustedInstance-1], powerTable)
+
+	// The certificates must start at the trusted instance.
+	_, _, _, err = certs.VerifyFrom(networkName, backend, trustedInstance-1, powerTables[trustedInstance], fromTrusted)
+	require.ErrorContains(t, err, "expected instance 5, found instance 6")
+
+	// The certificates must be contiguous, and those before a gap are applied.
+	withGap := slices.Delete(slices.Clone(fromTrusted), 2, 3)
+	nextInstance, chain, powerTable, err = certs.VerifyFrom(networkName, backend, trustedInstance, powerTables[trustedInstance], withGap)
+	require.ErrorContains(t, err, "expected instance 8, found instance 9")
+	require.EqualValues(t, trustedInstance+2, nextInstance)
+	require.Equal(t, powerTables[trustedInstance+2], powerTable)
+	require.True(t, certificates[trustedInstance+1].ECChain.Head().Equal(chain.Head()))
+
+	// Verification stops at an invalid certificate mid-chain, with those before it
+	// applied.
+	invalid := fromTrusted[1]
+	invalid.Signature = slices.Clone(invalid.Signature)
+	invalid.Signature[0] ^= 0xff
+	withInvalid := slices.Clone(fromTrusted)
+	withInvalid[1] = invalid
+	nextInstance, chain, powerTable, err = certs.VerifyFrom(networkName, backend, trustedInstance, powerTables[trustedInstance], withInvalid)
+	require.ErrorContains(t, err, "invalid signature on finality certificate for instance 7")
+	require.EqualValues(t, trustedInstance+1, nextInstance)
+	require.Equal(t, powerTables[trustedInstance+1], powerTable)
+	require.True(t, certificates[trustedInstance].ECChain.Head().Equal(chain.Head()))
+
+	// An empty snapshot can't be trusted.
+	_, _, _, err = certs.VerifyFrom(networkName, backend, trustedInstance, nil, fromTrusted)
+	require.ErrorContains(t, err, "trusted power table for instance 6 is empty")
+}
+
+func TestFinalityCertificateJSON(t *testing.T) {
+	backend := signing.NewFakeBackend()
+
+	powerTable := randomPowerTable(backend, 100)
+	maxPower := int64(len(powerTable) * 2)
+	tableCid, err := certs.MakePowerTableCID(powerTable)
+	require.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1413))
+	tsg := sim.NewTipSetGenerator(rng.Uint64())
+	base := &gpbft.TipSet{Epoch: 0, Key: tsg.Sample(), PowerTable: tableCid}
+
+	var removedPowerEntries []gpbft.PowerEntry
+	for i := range 5 {
+		prevPowerTable := powerTable
+		// Leave the power table of the first certificate as is, to cover empty diffs.
+		if i > 0 {
+			powerTable, removedPowerEntries = randomizePowerTable(rng, backend, maxPower, powerTable, removedPowerEntries)
+		}
+		justification := makeJustification(t, rng, tsg, backend, base, uint64(i), prevPowerTable, powerTable)
+		cert, err := certs.NewFinalityCertificate(certs.MakePowerTableDiff(prevPowerTable, powerTable), justification)
+		require.NoError(t, err)
+		base = justification.Vote.Value.Head()
+
+		// Tipset keys are concatenated CIDs in practice, as their JSON representation
+		// requires.
+		tipsets := make([]*gpbft.TipSet, len(cert.ECChain.TipSets))
+		for j, ts := range cert.ECChain.TipSets {
+			withCidKey := *ts
+			withCidKey.Key = gpbft.MakeCid(ts.Key).Bytes()
+			tipsets[j] = &withCidKey
+		}
+		cert.ECChain = &gpbft.ECChain{TipSets: tipsets}
+
+		var wantCBOR bytes.Buffer
+		require.NoError(t, cert.MarshalCBOR(&wantCBOR))
+
+		encoded, err := json.Marshal(cert)
+		require.NoError(t, err)
+		var fields map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(encoded, &fields))
+		var signers []uint64
+		require.NoError(t, json.Unmarshal(fields["Signers"], &signers))
+		wantSigners, err := cert.Signers.All(uint64(len(prevPowerTable)))
+		require.NoError(t, err)
+		require.Equal(t, wantSigners, signers)
+		if i == 0 {
+			require.NotContains(t, fields, "PowerTableDelta")
+		}
+
+		var decoded certs.FinalityCertificate
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		var gotCBOR bytes.Buffer
+		require.NoError(t, decoded.MarshalCBOR(&gotCBOR))
+		require.Equal(t, wantCBOR.Bytes(), gotCBOR.Bytes())
+	}
+}
+
+func TestPowerTableDiffJSON(t *testing.T) {
+	diff := certs.PowerTableDiff{
+		{ParticipantID: 1, PowerDelta: gpbft.NewStoragePower(-10)},
+		{ParticipantID: 2, PowerDelta: gpbft.NewStoragePower(20), SigningKey: gpbft.PubKey("fish")},
+	}
+	encoded, err := json.Marshal(diff)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"ParticipantID":1,"PowerDelta":"-10"},{"ParticipantID":2,"PowerDelta":"20","SigningKey":"ZmlzaA=="}]`, string(encoded))
+
+	var decoded certs.PowerTableDiff
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.Equal(t, diff, decoded)
+
+	encoded, err = json.Marshal(certs.PowerTableDiff(nil))
+	require.NoError(t, err)
+	require.JSONEq(t, `[]`, string(encoded))
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.Nil(t, decoded)
 }
 
 func TestBadFinalityCertificates(t *testing.T) {
@@ -341,6 +502,19 @@ func TestBadFinalityCertificates(t *testing.T) {
 		require.Empty(t, chain)
 	}
 
+	// Sign supplemental data that commits to a power table other than that which the
+	// delta yields.
+	{
+		mismatched := makeJustification(t, rng, tsg, backend, base, 1, powerTable, powerTable)
+		mismatchedCert, err := certs.NewFinalityCertificate(certs.MakePowerTableDiff(powerTable, nextPowerTable), mismatched)
+		require.NoError(t, err)
+		nextInstance, chain, newPowerTable, err := certs.ValidateFinalityCertificates(backend, networkName, powerTable, 1, nil, mismatchedCert)
+		require.ErrorContains(t, err, fmt.Sprintf("supplemental data commits to power table %s, but applying the diff yields", tableCid))
+		require.EqualValues(t, 1, nextInstance)
+		require.Equal(t, powerTable, newPowerTable)
+		require.Empty(t, chain)
+	}
+
 	// Give one signer enough power such that all others round to zero.
 	{
 		powerTableCpy := slices.Clone(powerTable)
@@ -421,6 +595,34 @@ func TestBadFinalityCertificates(t *testing.T) {
 	}
 }
 
+// makeFinalityCertificates makes a chain of the given number of finality certificates, starting at
+// instance zero, with the power table changing randomly across instances. Returns the certificates
+// and the power table for each instance, including the instance after the last certificate.
+func makeFinalityCertificates(t *testing.T, backend signing.Backend, count int) ([]*certs.FinalityCertificate, []gpbft.PowerEntries) {
+	powerTable := randomPowerTable(backend, 100)
+	maxPower := int64(len(powerTable) * 2)
+	tableCid, err := certs.MakePowerTableCID(powerTable)
+	require.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1234))
+	tsg := sim.NewTipSetGenerator(rng.Uint64())
+	base := &gpbft.TipSet{Epoch: 0, Key: tsg.Sample(), PowerTable: tableCid}
+
+	certificates := make([]*certs.FinalityCertificate, count)
+	powerTables := make([]gpbft.PowerEntries, count+1)
+	powerTables[0] = powerTable
+	var removedPowerEntries []gpbft.PowerEntry
+	for i := range certificates {
+		powerTables[i+1], removedPowerEntries = randomizePowerTable(rng, backend, maxPower, powerTables[i], removedPowerEntries)
+		justification := makeJustification(t, rng, tsg, backend, base, uint64(i), powerTables[i], powerTables[i+1])
+		cert, err := certs.NewFinalityCertificate(certs.MakePowerTableDiff(powerTables[i], powerTables[i+1]), justification)
+		require.NoError(t, err)
+		certificates[i] = cert
+		base = justification.Vote.Value.Head()
+	}
+	return certificates, powerTables
+}
+
 func randomizePowerTable(rng *rand.Rand, backend signing.Backend, maxPower int64, livePowerEntries, deadPowerEntries gpbft.PowerEntries) (_livePowerEntries, _deadPowerEntries gpbft.PowerEntries) {
 	const (
 		Power int = iota
@@ -0,0 +1,96 @@
+package certs
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-f3/gpbft"
+)
+
+var (
+	_ json.Marshaler   = (*FinalityCertificate)(nil)
+	_ json.Unmarshaler = (*FinalityCertificate)(nil)
+	_ json.Marshaler   = (*PowerTableDelta)(nil)
+	_ json.Unmarshaler = (*PowerTableDelta)(nil)
+	_ json.Marshaler   = (PowerTableDiff)(nil)
+	_ json.Unmarshaler = (*PowerTableDiff)(nil)
+)
+
+// Custom JSON marshalling of finality certificates for consumption by tooling, to
+// achieve:
+// 1. signers that are presented as a list of indices in the base power table.
+// 2. bytes that are presented as base64-encoded strings, omitting empty signing
+//    keys of power table deltas.
+// The JSON representation round-trips losslessly, but CBOR remains the wire format.
+
+type finalityCertificateSub FinalityCertificate
+type finalityCertificateJson struct {
+	Signers []uint64
+	*finalityCertificateSub
+}
+
+func (fc FinalityCertificate) MarshalJSON() ([]byte, error) {
+	signers := make([]uint64, 0)
+	if err := fc.Signers.ForEach(func(index uint64) error {
+		signers = append(signers, index)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("listing signers: %w", err)
+	}
+	return json.Marshal(&finalityCertificateJson{
+		Signers:                signers,
+		finalityCertificateSub: (*finalityCertificateSub)(&fc),
+	})
+}
+
+func (fc *FinalityCertificate) UnmarshalJSON(b []byte) error {
+	aux := &finalityCertificateJson{finalityCertificateSub: (*finalityCertificateSub)(fc)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	fc.Signers = bitfield.NewFromSet(aux.Signers)
+	return nil
+}
+
+type powerTableDeltaSub PowerTableDelta
+type powerTableDeltaJson struct {
+	SigningKey []byte `json:",omitempty"`
+	*powerTableDeltaSub
+}
+
+func (d PowerTableDelta) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&powerTableDeltaJson{
+		SigningKey:         d.SigningKey,
+		powerTableDeltaSub: (*powerTableDeltaSub)(&d),
+	})
+}
+
+func (d *PowerTableDelta) UnmarshalJSON(b []byte) error {
+	aux := &powerTableDeltaJson{powerTableDeltaSub: (*powerTableDeltaSub)(d)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	d.SigningKey = gpbft.PubKey(aux.SigningKey)
+	return nil
+}
+
+func (d PowerTableDiff) MarshalJSON() ([]byte, error) {
+	if d == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]PowerTableDelta(d))
+}
+
+func (d *PowerTableDiff) UnmarshalJSON(b []byte) error {
+	var deltas []PowerTableDelta
+	if err := json.Unmarshal(b, &deltas); err != nil {
+		return err
+	}
+	if len(deltas) == 0 {
+		// Empty diffs decode as nil, as they do from CBOR.
+		deltas = nil
+	}
+	*d = deltas
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"iter"
 	"math"
 	"sync"
 
@@ -19,6 +20,7 @@ import (
 
 var ErrCertNotFound = errors.New("certificate not found")
 var ErrNotInitialized = errors.New("certstore is not initialized")
+var ErrNonContiguousCertificate = errors.New("certificate does not extend the latest finalized chain")
 
 const defaultPowerTableFrequency = 60 * 24 // expected twice a day for Filecoin
 
@@ -224,39 +226,72 @@ func (cs *Store) Get(ctx context.Context, instance uint64) (*certs.FinalityCerti
 //
 // If it encounters missing cert, it returns a wrapped ErrCertNotFound and the available certs.
 func (cs *Store) GetRange(ctx context.Context, start uint64, end uint64) ([]certs.FinalityCertificate, error) {
-	if start > end {
-		return nil, fmt.Errorf("start is larger than end: %d > %d", start, end)
-	}
-	if end-start >= math.MaxInt {
+	if end >= start && end-start >= math.MaxInt {
 		return nil, fmt.Errorf("range %d to %d is too large", start, end)
 	}
 
-	bCerts := make([][]byte, 0, end-start+1)
-
-	for i := start; i <= end; i++ {
-		b, err := cs.ds.Get(ctx, cs.keyForCert(i))
-		if errors.Is(err, datastore.ErrNotFound) {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("accessing cert at %d for range request: %w", i, err)
+	var certificates []certs.FinalityCertificate
+	for cert, err := range cs.GetRangeIter(ctx, start, end) {
+		if errors.Is(err, ErrCertNotFound) {
+			return certificates, err
+		} else if err != nil {
+			return nil, err
 		}
-
-		bCerts = append(bCerts, b)
+		certificates = append(certificates, *cert)
 	}
+	return certificates, nil
+}
 
-	certs := make([]certs.FinalityCertificate, len(bCerts))
-	for j, bCert := range bCerts {
-		err := certs[j].UnmarshalCBOR(bytes.NewReader(bCert))
-		if err != nil {
-			return nil, fmt.Errorf("unmarshalling a cert at j=%d, instance %d: %w", j, start+uint64(j), err)
+// GetRangeIter returns an iterator over the certs from start to end inclusive by
+// instance numbers in the increasing order, which are read from the datastore
+// lazily as iteration proceeds.
+//
+// Iteration stops after yielding the first error, which is a wrapped
+// ErrCertNotFound if it encounters a missing cert, or the error of the context if
+// it is cancelled.
+func (cs *Store) GetRangeIter(ctx context.Context, start uint64, end uint64) iter.Seq2[*certs.FinalityCertificate, error] {
+	return func(yield func(*certs.FinalityCertificate, error) bool) {
+		if start > end {
+			yield(nil, fmt.Errorf("start is larger than end: %d > %d", start, end))
+			return
+		}
+		for i := start; ; i++ {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			b, err := cs.ds.Get(ctx, cs.keyForCert(i))
+			if errors.Is(err, datastore.ErrNotFound) {
+				yield(nil, fmt.Errorf("cert at %d: %w", i, ErrCertNotFound))
+				return
+			}
+			if err != nil {
+				yield(nil, fmt.Errorf("accessing cert at %d for range request: %w", i, err))
+				return
+			}
+			var cert certs.FinalityCertificate
+			if err := cert.UnmarshalCBOR(bytes.NewReader(b)); err != nil {
+				yield(nil, fmt.Errorf("unmarshalling a cert at instance %d: %w", i, err))
+				return
+			}
+			if !yield(&cert, nil) || i == end {
+				return
+			}
 		}
 	}
+}
 
-	if len(certs) < cap(bCerts) {
-		return certs, fmt.Errorf("cert at %d: %w", start+uint64(len(bCerts)), ErrCertNotFound)
-	}
-	return certs, nil
+// Heads returns the head of the chain finalized by each instance from start to end
+// inclusive, in the increasing order of instance numbers. It is a projection over
+// GetRange, and follows the same semantics: if it encounters a missing cert, it
+// returns a wrapped ErrCertNotFound and the heads of the available certs.
+func (cs *Store) Heads(ctx context.Context, start uint64, end uint64) ([]gpbft.TipSet, error) {
+	certs, err := cs.GetRange(ctx, start, end)
+	heads := make([]gpbft.TipSet, 0, len(certs))
+	for _, cert := range certs {
+		heads = append(heads, *cert.ECChain.Head())
+	}
+	return heads, err
 }
 
 func (cs *Store) readPowerTable(ctx context.Context, instance uint64) (gpbft.PowerEntries, error) {
@@ -271,11 +306,16 @@ func (cs *Store) readPowerTable(ctx context.Context, instance uint64) (gpbft.Pow
 
 // Store the specified power table.
 func (cs *Store) putPowerTable(ctx context.Context, instance uint64, powerTable gpbft.PowerEntries) error {
+	return cs.putPowerTableTo(ctx, cs.ds, instance, powerTable)
+}
+
+// Write the specified power table with the given writer.
+func (cs *Store) putPowerTableTo(ctx context.Context, w datastore.Write, instance uint64, powerTable gpbft.PowerEntries) error {
 	var buf bytes.Buffer
 	if err := powerTable.MarshalCBOR(&buf); err != nil {
 		return fmt.Errorf("marshalling power table instance %d: %w", instance, err)
 	}
-	if err := cs.ds.Put(ctx, cs.keyForPowerTable(instance), buf.Bytes()); err != nil {
+	if err := w.Put(ctx, cs.keyForPowerTable(instance), buf.Bytes()); err != nil {
 		return fmt.Errorf("putting power table instance %d: %w", instance, err)
 	}
 	return nil
@@ -342,6 +382,70 @@ func (cs *Store) GetPowerTable(ctx context.Context, instance uint64) (gpbft.Powe
 	return powerTable, err
 }
 
+// GetPowerTableRange returns the power tables used to validate the instances from
+// first to last inclusive, in the increasing order of instance numbers. The
+// result is the same as calling GetPowerTable for each instance in turn, except
+// that the power tables are reconstructed in a single pass from the nearest
+// stored power table by applying the power table delta of each certificate.
+//
+// It returns an error if the power table of any instance in the range is
+// unavailable, in which case no power tables are returned.
+func (cs *Store) GetPowerTableRange(ctx context.Context, first, last uint64) ([]gpbft.PowerEntries, error) {
+	if first > last {
+		return nil, fmt.Errorf("first is larger than last: %d > %d", first, last)
+	}
+	if first < cs.firstInstance {
+		return nil, fmt.Errorf("cannot return a power table before the first instance: %d", cs.firstInstance)
+	}
+	if last-first >= math.MaxInt {
+		return nil, fmt.Errorf("range %d to %d is too large", first, last)
+	}
+
+	cs.mu.RLock()
+	latestCert := cs.latestCertificate
+	latestPowerTable := cs.latestPowerTable
+	cs.mu.RUnlock()
+
+	nextCertInstance := cs.firstInstance
+	if latestCert != nil {
+		nextCertInstance = latestCert.GPBFTInstance + 1
+	}
+	if last > nextCertInstance {
+		return nil, fmt.Errorf("cannot return future power table for instance %d > %d", last, nextCertInstance)
+	}
+
+	startInstance := max(first-first%cs.powerTableFrequency, cs.firstInstance)
+	powerTable, err := cs.readPowerTable(ctx, startInstance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expected power table for instance %d: %w", startInstance, err)
+	}
+	var certificates []certs.FinalityCertificate
+	if last > startInstance {
+		// Load the power table diffs up till (but not including) the last instance.
+		if certificates, err = cs.GetRange(ctx, startInstance, last-1); err != nil {
+			return nil, err
+		}
+	}
+
+	powerTables := make([]gpbft.PowerEntries, 0, last-first+1)
+	for instance := startInstance; ; instance++ {
+		if instance >= first {
+			if instance == nextCertInstance && len(latestPowerTable) != 0 {
+				powerTable = latestPowerTable
+			}
+			powerTables = append(powerTables, powerTable)
+		}
+		if instance == last {
+			break
+		}
+		powerTable, err = certs.ApplyPowerTableDiffs(powerTable, certificates[instance-startInstance].PowerTableDelta)
+		if err != nil {
+			return nil, fmt.Errorf("applying power delta of instance %d: %w", instance, err)
+		}
+	}
+	return powerTables, nil
+}
+
 func (*Store) keyForCert(i uint64) datastore.Key {
 	return datastore.NewKey(fmt.Sprintf("/certs/%016X", i))
 }
@@ -356,42 +460,123 @@ func (*Store) keyForPowerTable(i uint64) datastore.Key {
 // 1. Before the initial instance that the certificate store was initialized with.
 // 2. More than one instance after the last certificate stored.
 func (cs *Store) Put(ctx context.Context, cert *certs.FinalityCertificate) error {
-	if cert.GPBFTInstance < cs.firstInstance {
-		return fmt.Errorf("certificate store only stores certificates on or after instance %d", cs.firstInstance)
-	}
+	return cs.PutRange(ctx, []*certs.FinalityCertificate{cert})
+}
+
+// PutRange saves a range of certificates, ordered by increasing instance, in a
+// single datastore batch and notifies listeners of the latest one. Certificates
+// at or before the latest stored instance are skipped. Every remaining
+// certificate is checked just as by Put, and nothing is stored unless all of
+// them pass. Falls back to writing each certificate individually if the
+// datastore does not support batching, in which case a failed write may leave
+// some certificates stored, but never advances the latest instance.
+func (cs *Store) PutRange(ctx context.Context, certificates []*certs.FinalityCertificate) error {
+	for _, cert := range certificates {
+		if cert.GPBFTInstance < cs.firstInstance {
+			return fmt.Errorf("certificate store only stores certificates on or after instance %d", cs.firstInstance)
+		}
 
-	// Basic validation just to make sure the certificate is sane. We don't do a full validation
-	// because that should already have been done by the caller.
-	if cert.ECChain.IsZero() {
-		return fmt.Errorf("finality certificate for instance %d is for bottom", cert.GPBFTInstance)
-	} else if err := cert.ECChain.Validate(); err != nil {
-		return fmt.Errorf("invalid chain in finality certificate: %w", err)
+		// Basic validation just to make sure the certificate is sane. We don't do a full validation
+		// because that should already have been done by the caller.
+		if cert.ECChain.IsZero() {
+			return fmt.Errorf("finality certificate for instance %d is for bottom", cert.GPBFTInstance)
+		} else if err := cert.ECChain.Validate(); err != nil {
+			return fmt.Errorf("invalid chain in finality certificate: %w", err)
+		}
 	}
 
 	// Take a lock to ensure ordering.
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
-	nextCert := cs.firstInstance
-	if latestCert := cs.latestCertificate; latestCert != nil {
-		nextCert = latestCert.GPBFTInstance + 1
-	}
-	if cert.GPBFTInstance > nextCert {
-		return fmt.Errorf("attempted to add cert at %d, expected %d", cert.GPBFTInstance, nextCert)
+	// Check every certificate before writing any, so that nothing is stored if one fails
+	// even when writes are not batched.
+	latestCert, latestPowerTable := cs.latestCertificate, cs.latestPowerTable
+	var stored []*certs.FinalityCertificate
+	var powerTables []gpbft.PowerEntries
+	for _, cert := range certificates {
+		nextCert := cs.firstInstance
+		if latestCert != nil {
+			nextCert = latestCert.GPBFTInstance + 1
+		}
+		if cert.GPBFTInstance > nextCert {
+			return fmt.Errorf("attempted to add cert at %d, expected %d", cert.GPBFTInstance, nextCert)
+		}
+		if cert.GPBFTInstance < nextCert {
+			continue
+		}
+
+		// The instance is exactly latest + 1
+		newPowerTable, err := cs.checkNext(latestCert, latestPowerTable, cert)
+		if err != nil {
+			return err
+		}
+		latestCert, latestPowerTable = cert, newPowerTable
+		stored = append(stored, cert)
+		powerTables = append(powerTables, newPowerTable)
 	}
-	if cert.GPBFTInstance < nextCert {
+	if len(stored) == 0 {
 		return nil
 	}
 
-	// The instance is exactly latest + 1
+	batch, err := cs.batch(ctx)
+	if err != nil {
+		return fmt.Errorf("starting a batch: %w", err)
+	}
+	for i, cert := range stored {
+		if err := cs.writeCert(ctx, batch, cert, powerTables[i]); err != nil {
+			return err
+		}
+	}
+
+	// Finally, advance the latest instance pointer (always do this last) and publish.
+	if err := batch.Put(ctx, certStoreLatestKey, binary.BigEndian.AppendUint64(nil, latestCert.GPBFTInstance)); err != nil {
+		return fmt.Errorf("putting recording the latest GPBFT instance: %w", err)
+	}
+	if err := batch.Commit(ctx); err != nil {
+		return fmt.Errorf("committing certificates up to instance %d: %w", latestCert.GPBFTInstance, err)
+	}
+
+	cs.latestPowerTable = latestPowerTable
+	cs.latestCertificate = latestCert
+	for ch := range cs.subscribers {
+		// Always drain first.
+		select {
+		case <-ch:
+		default:
+		}
+		// Then write the latest certificate.
+		ch <- cs.latestCertificate
+	}
+
+	for _, cert := range stored {
+		metrics.tipsetsPerInstance.Record(ctx, int64(len(cert.ECChain.Suffix())))
+	}
+	metrics.latestInstance.Record(ctx, int64(latestCert.GPBFTInstance))
+	metrics.latestFinalizedEpoch.Record(ctx, latestCert.ECChain.Head().Epoch)
+
+	return nil
+}
+
+// checkNext checks that the given certificate may follow the latest one, and
+// returns the power table for the instance after it.
+func (cs *Store) checkNext(latestCert *certs.FinalityCertificate, latestPowerTable gpbft.PowerEntries, cert *certs.FinalityCertificate) (gpbft.PowerEntries, error) {
+	// Check that the certificate extends the chain finalized by the latest one, so
+	// that no gap or fork is ever stored.
+	if latestCert != nil {
+		if head, base := latestCert.ECChain.Head(), cert.ECChain.Base(); !head.Equal(base) {
+			return nil, fmt.Errorf("%w: base of instance %d at epoch %d does not match head of instance %d at epoch %d",
+				ErrNonContiguousCertificate, cert.GPBFTInstance, base.Epoch, latestCert.GPBFTInstance, head.Epoch)
+		}
+	}
 
 	// Compute the next power table (if it has changed).
-	newPowerTable := cs.latestPowerTable
+	newPowerTable := latestPowerTable
 	if len(cert.PowerTableDelta) > 0 {
 		var err error
-		newPowerTable, err = certs.ApplyPowerTableDiffs(cs.latestPowerTable, cert.PowerTableDelta)
+		newPowerTable, err = certs.ApplyPowerTableDiffs(latestPowerTable, cert.PowerTableDelta)
 		if err != nil {
-			return fmt.Errorf("failed to apply power table delta for instance %d: %w", cert.GPBFTInstance, err)
+			return nil, fmt.Errorf("failed to apply power table delta for instance %d: %w", cert.GPBFTInstance, err)
 		}
 	}
 
@@ -399,63 +584,65 @@ func (cs *Store) Put(ctx context.Context, cert *certs.FinalityCertificate) error
 	// the entire finality certificate, but errors here will compound and be difficult to fix
 	// later.
 	if ptCid, err := certs.MakePowerTableCID(newPowerTable); err != nil {
-		return err
+		return nil, err
 	} else if ptCid != cert.SupplementalData.PowerTable {
-		return fmt.Errorf("new power table differs from expected power table: %s != %s", ptCid, cert.SupplementalData.PowerTable)
+		return nil, fmt.Errorf("new power table differs from expected power table: %s != %s", ptCid, cert.SupplementalData.PowerTable)
 	}
 
 	// Double check that we're not killing the network.
 	if len(newPowerTable) == 0 {
-		return fmt.Errorf("finality certificate for instance %d would empty the power table", cert.GPBFTInstance)
+		return nil, fmt.Errorf("finality certificate for instance %d would empty the power table", cert.GPBFTInstance)
 	}
+	return newPowerTable, nil
+}
 
-	// Write the cert/power table.
+// writeCert writes the certificate, along with the power table for the next
+// instance if it is due to be stored.
+func (cs *Store) writeCert(ctx context.Context, w datastore.Write, cert *certs.FinalityCertificate, newPowerTable gpbft.PowerEntries) error {
 	var buf bytes.Buffer
 	if err := cert.MarshalCBOR(&buf); err != nil {
 		return fmt.Errorf("marshalling cert instance %d: %w", cert.GPBFTInstance, err)
 	}
-
-	if err := cs.ds.Put(ctx, cs.keyForCert(cert.GPBFTInstance), buf.Bytes()); err != nil {
+	if err := w.Put(ctx, cs.keyForCert(cert.GPBFTInstance), buf.Bytes()); err != nil {
 		return fmt.Errorf("putting the cert: %w", err)
 	}
 
 	// The new power table is the power table to validate the _next_ instance.
 	if (cert.GPBFTInstance+1)%cs.powerTableFrequency == 0 {
-		if err := cs.putPowerTable(ctx, cert.GPBFTInstance+1, newPowerTable); err != nil {
-			return err
-		}
-	}
-
-	// Finally, advance the latest instance pointer (always do this last) and publish.
-	if err := cs.writeInstanceNumber(ctx, certStoreLatestKey, cert.GPBFTInstance); err != nil {
-		return fmt.Errorf("putting recording the latest GPBFT instance: %w", err)
+		return cs.putPowerTableTo(ctx, w, cert.GPBFTInstance+1, newPowerTable)
 	}
+	return nil
+}
 
-	cs.latestPowerTable = newPowerTable
-	cs.latestCertificate = cert
-	for ch := range cs.subscribers {
-		// Always drain first.
-		select {
-		case <-ch:
-		default:
+// batch returns a batch of writes to the datastore, or a batch that writes
+// through to the datastore if it does not support batching.
+func (cs *Store) batch(ctx context.Context) (datastore.Batch, error) {
+	if batching, ok := cs.ds.(datastore.Batching); ok {
+		batch, err := batching.Batch(ctx)
+		if !errors.Is(err, datastore.ErrBatchUnsupported) {
+			return batch, err
 		}
-		// Then write the latest certificate.
-		ch <- cs.latestCertificate
 	}
+	return writeThroughBatch{cs.ds}, nil
+}
 
-	metrics.latestInstance.Record(ctx, int64(cert.GPBFTInstance))
-	metrics.tipsetsPerInstance.Record(ctx, int64(len(cert.ECChain.Suffix())))
-	metrics.latestFinalizedEpoch.Record(ctx, cert.ECChain.Head().Epoch)
-
-	return nil
+// writeThroughBatch writes to the datastore directly rather than on commit.
+type writeThroughBatch struct {
+	datastore.Datastore
 }
 
+func (writeThroughBatch) Commit(context.Context) error { return nil }
+
 // Subscribe subscribes to new certificate notifications. When read, it will always return the
 // latest not-yet-seen certificate (including the latest certificate when Subscribe is first
 // called, if we have any) but it will drop intermediate certificates. If you need all the
 // certificates, you should keep track of the last certificate you received and call GetRange to get
 // the ones between.
 //
+// Certificates are received in strictly increasing order of instance, no matter how slowly they
+// are read: a certificate is never received twice, nor after one for a later instance. If the
+// store is rewound by Restore, the channel is closed instead, and the caller must subscribe again.
+//
 // The caller must call the closer to unsubscribe and release resources.
 func (cs *Store) Subscribe() (out <-chan *certs.FinalityCertificate, closer func()) {
 	cs.mu.Lock()
@@ -526,3 +713,37 @@ func (cs *Store) Delete(ctx context.Context, instance uint64) error {
 	}
 	return cs.ds.Delete(ctx, cs.keyForPowerTable(instance))
 }
+
+// Diff compares the certificates in two stores and returns the earliest
+// instance at which they disagree on the finalized chain or supplemental data,
+// and whether such divergence was found at all.
+//
+// The comparison starts at the later of the first instances of the two stores
+// and ends at the earlier of their latest certificates; certificates outside
+// the range common to both stores are not compared. Diff is intended as a
+// forensic tool and reads every certificate in the common range.
+func Diff(ctx context.Context, a, b *Store) (uint64, bool, error) {
+	latestA, latestB := a.Latest(), b.Latest()
+	if latestA == nil || latestB == nil {
+		return 0, false, nil
+	}
+	start := max(a.firstInstance, b.firstInstance)
+	end := min(latestA.GPBFTInstance, latestB.GPBFTInstance)
+	for instance := start; instance <= end; instance++ {
+		if err := ctx.Err(); err != nil {
+			return 0, false, err
+		}
+		certA, err := a.Get(ctx, instance)
+		if err != nil {
+			return 0, false, fmt.Errorf("getting cert from first store: %w", err)
+		}
+		certB, err := b.Get(ctx, instance)
+		if err != nil {
+			return 0, false, fmt.Errorf("getting cert from second store: %w", err)
+		}
+		if !certA.ECChain.Eq(certB.ECChain) || !certA.SupplementalData.Eq(&certB.SupplementalData) {
+			return instance, true, nil
+		}
+	}
+	return 0, false, nil
+}
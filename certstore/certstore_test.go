@@ -2,8 +2,10 @@ package certstore
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"slices"
+	"sync"
 	"testing"
 
 	"github.com/filecoin-project/go-f3/certs"
@@ -12,17 +14,21 @@ import (
 	datastore "github.com/ipfs/go-datastore"
 	"github.com/ipfs/go-datastore/query"
 	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// testBase is the tipset every certificate made by makeCert finalizes, such that
+// consecutive certificates form a contiguous chain.
+var testBase = gpbft.TipSet{Epoch: 0, Key: gpbft.TipSetKey("tsk0"), PowerTable: gpbft.MakeCid([]byte("pt0"))}
+
 func makeCert(instance uint64, supp gpbft.SupplementalData) *certs.FinalityCertificate {
+	base := testBase
 	return &certs.FinalityCertificate{
 		GPBFTInstance:    instance,
 		SupplementalData: supp,
 		ECChain: &gpbft.ECChain{
-			TipSets: []*gpbft.TipSet{
-				{Epoch: 0, Key: gpbft.TipSetKey("tsk0"), PowerTable: supp.PowerTable},
-			},
+			TipSets: []*gpbft.TipSet{&base},
 		},
 	}
 }
@@ -100,6 +106,40 @@ func TestPut(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestPutNonContiguous(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+
+	pt, ptCid := testPowerTable(10)
+	supp := gpbft.SupplementalData{PowerTable: ptCid}
+	cs, err := CreateStore(ctx, ds, 1, pt)
+	require.NoError(t, err)
+
+	first := makeCert(1, supp)
+	head := gpbft.TipSet{Epoch: 1, Key: gpbft.TipSetKey("tsk1"), PowerTable: ptCid}
+	first.ECChain = first.ECChain.Append(&head)
+	require.NoError(t, cs.Put(ctx, first))
+
+	// A certificate that does not extend the head finalized by its predecessor is
+	// rejected, whether it leaves a gap or forks.
+	gap := makeCert(2, supp)
+	gap.ECChain.TipSets[0] = &gpbft.TipSet{Epoch: 2, Key: gpbft.TipSetKey("tsk2"), PowerTable: ptCid}
+	require.ErrorIs(t, cs.Put(ctx, gap), ErrNonContiguousCertificate)
+	fork := makeCert(2, supp)
+	fork.ECChain.TipSets[0] = &gpbft.TipSet{Epoch: 1, Key: gpbft.TipSetKey("fork"), PowerTable: ptCid}
+	require.ErrorIs(t, cs.Put(ctx, fork), ErrNonContiguousCertificate)
+	require.Equal(t, first, cs.Latest())
+
+	// One based on that head is accepted.
+	next := makeCert(2, supp)
+	next.ECChain.TipSets[0] = &head
+	next.ECChain = next.ECChain.Append(&gpbft.TipSet{Epoch: 2, Key: gpbft.TipSetKey("tsk2"), PowerTable: ptCid})
+	require.NoError(t, cs.Put(ctx, next))
+	require.Equal(t, next, cs.Latest())
+}
+
 func TestPutWrongPowerDelta(t *testing.T) {
 	t.Parallel()
 
@@ -185,6 +225,132 @@ func TestGetRange(t *testing.T) {
 	require.ErrorContains(t, err, "is too large")
 }
 
+func TestGetRangeIter(t *testing.T) {
+	t.Parallel()
+
+	const count = 5000
+	ctx := context.Background()
+	ds := &countingDatastore{Batching: ds_sync.MutexWrap(datastore.NewMapDatastore())}
+	pt, ptCid := testPowerTable(10)
+	supp := gpbft.SupplementalData{PowerTable: ptCid}
+	cs, err := CreateStore(ctx, ds, 1, pt)
+	require.NoError(t, err)
+
+	certificates := make([]*certs.FinalityCertificate, count)
+	for i := range certificates {
+		certificates[i] = makeCert(uint64(i+1), supp)
+	}
+	require.NoError(t, cs.PutRange(ctx, certificates))
+
+	t.Run("yields all", func(t *testing.T) {
+		next := uint64(1)
+		for cert, err := range cs.GetRangeIter(ctx, 1, count) {
+			require.NoError(t, err)
+			require.Equal(t, next, cert.GPBFTInstance)
+			next++
+		}
+		require.EqualValues(t, count+1, next)
+	})
+
+	t.Run("reads lazily", func(t *testing.T) {
+		// Certificates are read one at a time, so that breaking out early never
+		// reads the rest of the range.
+		readsBefore := ds.reads
+		var seen int
+		for _, err := range cs.GetRangeIter(ctx, 1, count) {
+			require.NoError(t, err)
+			seen++
+			require.Equal(t, seen, ds.reads-readsBefore)
+			if seen == 10 {
+				break
+			}
+		}
+		require.Equal(t, 10, ds.reads-readsBefore)
+	})
+
+	t.Run("stops on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		readsBefore := ds.reads
+		var seen int
+		var lastErr error
+		for cert, err := range cs.GetRangeIter(ctx, 1, count) {
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			require.NotNil(t, cert)
+			seen++
+			if seen == 3 {
+				cancel()
+			}
+		}
+		require.Equal(t, 3, seen)
+		require.ErrorIs(t, lastErr, context.Canceled)
+		require.Equal(t, 3, ds.reads-readsBefore)
+	})
+
+	t.Run("stops at missing cert", func(t *testing.T) {
+		var seen int
+		var lastErr error
+		for _, err := range cs.GetRangeIter(ctx, count-1, count+10) {
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			seen++
+		}
+		require.Equal(t, 2, seen)
+		require.ErrorIs(t, lastErr, ErrCertNotFound)
+	})
+
+	t.Run("stops at end of maximum range", func(t *testing.T) {
+		var seen int
+		for _, err := range cs.GetRangeIter(ctx, math.MaxUint64, math.MaxUint64) {
+			require.ErrorIs(t, err, ErrCertNotFound)
+			seen++
+		}
+		require.Equal(t, 1, seen)
+	})
+}
+
+func TestHeads(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+
+	pt, ptCid := testPowerTable(10)
+	supp := gpbft.SupplementalData{PowerTable: ptCid}
+	cs, err := CreateStore(ctx, ds, 1, pt)
+	require.NoError(t, err)
+
+	var want []gpbft.TipSet
+	base := testBase
+	for i := uint64(1); i <= 5; i++ {
+		cert := makeCert(i, supp)
+		head := gpbft.TipSet{Epoch: int64(i), Key: gpbft.TipSetKey(fmt.Sprintf("tsk%d", i)), PowerTable: ptCid}
+		cert.ECChain, err = gpbft.NewChain(&base, &head)
+		require.NoError(t, err)
+		require.NoError(t, cs.Put(ctx, cert))
+		want = append(want, head)
+		base = head
+	}
+
+	heads, err := cs.Heads(ctx, 1, 5)
+	require.NoError(t, err)
+	require.Equal(t, want, heads)
+
+	heads, err = cs.Heads(ctx, 2, 3)
+	require.NoError(t, err)
+	require.Equal(t, want[1:3], heads)
+
+	// Heads of the available certs are returned along with the error.
+	heads, err = cs.Heads(ctx, 4, 7)
+	require.ErrorIs(t, err, ErrCertNotFound)
+	require.Equal(t, want[3:], heads)
+}
+
 func TestDeleteAll(t *testing.T) {
 	t.Parallel()
 
@@ -303,6 +469,117 @@ func TestPutSequential(t *testing.T) {
 	require.Error(t, err)
 }
 
+// countingDatastore counts the reads and writes made to the datastore, each
+// batch commit counting as a single write.
+type countingDatastore struct {
+	datastore.Batching
+	reads  int
+	writes int
+}
+
+type countingBatch struct {
+	datastore.Batch
+	ds *countingDatastore
+}
+
+func (c *countingDatastore) Put(ctx context.Context, key datastore.Key, value []byte) error {
+	c.writes++
+	return c.Batching.Put(ctx, key, value)
+}
+
+func (c *countingDatastore) Get(ctx context.Context, key datastore.Key) ([]byte, error) {
+	c.reads++
+	return c.Batching.Get(ctx, key)
+}
+
+func (c *countingDatastore) Batch(ctx context.Context) (datastore.Batch, error) {
+	batch, err := c.Batching.Batch(ctx)
+	return &countingBatch{Batch: batch, ds: c}, err
+}
+
+func (b *countingBatch) Commit(ctx context.Context) error {
+	b.ds.writes++
+	return b.Batch.Commit(ctx)
+}
+
+func TestPutRange(t *testing.T) {
+	t.Parallel()
+
+	const count = 2 * defaultPowerTableFrequency
+	ctx := context.Background()
+	pt, ptCid := testPowerTable(10)
+	supp := gpbft.SupplementalData{PowerTable: ptCid}
+	certificates := make([]*certs.FinalityCertificate, count)
+	for i := range certificates {
+		certificates[i] = makeCert(uint64(i+1), supp)
+	}
+	newStore := func(t *testing.T) (*Store, *countingDatastore) {
+		ds := &countingDatastore{Batching: ds_sync.MutexWrap(datastore.NewMapDatastore())}
+		cs, err := CreateStore(ctx, ds, 1, pt)
+		require.NoError(t, err)
+		ds.writes = 0
+		return cs, ds
+	}
+
+	individually, individualWrites := newStore(t)
+	for _, cert := range certificates {
+		require.NoError(t, individually.Put(ctx, cert))
+	}
+	batched, batchedWrites := newStore(t)
+	require.NoError(t, batched.PutRange(ctx, certificates))
+
+	// The whole range is written at once, with the same outcome as putting each
+	// certificate individually.
+	require.Equal(t, count, individualWrites.writes)
+	require.Equal(t, 1, batchedWrites.writes)
+	require.Equal(t, individually.Latest(), batched.Latest())
+	gotRange, err := batched.GetRange(ctx, 1, count)
+	require.NoError(t, err)
+	wantRange, err := individually.GetRange(ctx, 1, count)
+	require.NoError(t, err)
+	require.Equal(t, wantRange, gotRange)
+	for _, instance := range []uint64{defaultPowerTableFrequency, count + 1} {
+		gotPowerTable, err := batched.GetPowerTable(ctx, instance)
+		require.NoError(t, err)
+		require.Equal(t, pt, gotPowerTable)
+	}
+
+	t.Run("skips stored certificates", func(t *testing.T) {
+		cs, ds := newStore(t)
+		require.NoError(t, cs.PutRange(ctx, certificates[:10]))
+		require.NoError(t, cs.PutRange(ctx, certificates[5:20]))
+		require.NoError(t, cs.PutRange(ctx, certificates[5:20]))
+		require.Equal(t, 2, ds.writes)
+		require.Equal(t, certificates[19], cs.Latest())
+	})
+	t.Run("stores nothing unless all certificates are valid", func(t *testing.T) {
+		cs, ds := newStore(t)
+		fork := makeCert(11, supp)
+		fork.ECChain.TipSets[0] = &gpbft.TipSet{Epoch: 1, Key: gpbft.TipSetKey("fork"), PowerTable: ptCid}
+		invalid := append(slices.Clone(certificates[:10]), fork)
+		require.ErrorIs(t, cs.PutRange(ctx, invalid), ErrNonContiguousCertificate)
+		require.ErrorContains(t, cs.PutRange(ctx, append(slices.Clone(certificates[:10]), certificates[11])), "expected 11")
+		require.Zero(t, ds.writes)
+		require.Nil(t, cs.Latest())
+		_, err := cs.Get(ctx, 1)
+		require.ErrorIs(t, err, ErrCertNotFound)
+	})
+	t.Run("stores nothing unless all certificates are valid without batching", func(t *testing.T) {
+		ds := &countingDatastore{Batching: ds_sync.MutexWrap(datastore.NewMapDatastore())}
+		cs, err := CreateStore(ctx, struct{ datastore.Datastore }{ds}, 1, pt)
+		require.NoError(t, err)
+		ds.writes = 0
+		fork := makeCert(11, supp)
+		fork.ECChain.TipSets[0] = &gpbft.TipSet{Epoch: 1, Key: gpbft.TipSetKey("fork"), PowerTable: ptCid}
+		require.ErrorIs(t, cs.PutRange(ctx, append(slices.Clone(certificates[:10]), fork)), ErrNonContiguousCertificate)
+		require.Zero(t, ds.writes)
+		require.Nil(t, cs.Latest())
+
+		require.NoError(t, cs.PutRange(ctx, certificates[:10]))
+		require.Equal(t, certificates[9], cs.Latest())
+	})
+}
+
 func TestPersistency(t *testing.T) {
 	t.Parallel()
 
@@ -560,3 +837,246 @@ func testPowerInner(t *testing.T, firstInstance uint64) {
 		require.ErrorContains(t, err, "cannot return a power table before the first instance")
 	}
 }
+
+func TestGetPowerTableRange(t *testing.T) {
+	t.Parallel()
+	const (
+		firstInstance = 2
+		certCount     = 16
+	)
+	ctx := context.Background()
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	pt, ptCid := testPowerTable(10)
+	cs, err := CreateStore(ctx, ds, firstInstance, pt)
+	require.NoError(t, err)
+	cs.powerTableFrequency = 5
+
+	// Change the power table every few instances, such that ranges span both
+	// stored power tables and changes in power.
+	for instance := uint64(firstInstance); instance < firstInstance+certCount; instance++ {
+		newPt, newPtCid := pt, ptCid
+		if instance%3 == 0 {
+			newPt = slices.Clone(pt)
+			newPt[instance%uint64(len(pt))].PubKey = []byte(fmt.Sprintf("key at %d", instance))
+			newPtCid, err = certs.MakePowerTableCID(newPt)
+			require.NoError(t, err)
+		}
+		cert := makeCert(instance, gpbft.SupplementalData{PowerTable: newPtCid})
+		cert.PowerTableDelta = certs.MakePowerTableDiff(pt, newPt)
+		require.NoError(t, cs.Put(ctx, cert))
+		pt, ptCid = newPt, newPtCid
+	}
+
+	const nextInstance = firstInstance + certCount
+	for first := uint64(firstInstance); first <= nextInstance; first++ {
+		for last := first; last <= nextInstance; last++ {
+			got, err := cs.GetPowerTableRange(ctx, first, last)
+			require.NoError(t, err)
+			require.Len(t, got, int(last-first+1))
+			for i, instance := 0, first; instance <= last; i, instance = i+1, instance+1 {
+				want, err := cs.GetPowerTable(ctx, instance)
+				require.NoError(t, err)
+				require.Equal(t, want, got[i], "power table at instance %d in range %d to %d", instance, first, last)
+			}
+		}
+	}
+
+	_, err = cs.GetPowerTableRange(ctx, 5, 4)
+	require.ErrorContains(t, err, "first is larger than last")
+	_, err = cs.GetPowerTableRange(ctx, firstInstance-1, 4)
+	require.ErrorContains(t, err, "cannot return a power table before the first instance")
+	_, err = cs.GetPowerTableRange(ctx, firstInstance, nextInstance+1)
+	require.ErrorContains(t, err, "cannot return future power table")
+
+	// Missing certificates within the range fail the whole range.
+	require.NoError(t, cs.ds.Delete(ctx, cs.keyForCert(12)))
+	got, err := cs.GetPowerTableRange(ctx, 11, 14)
+	require.ErrorIs(t, err, ErrCertNotFound)
+	require.Nil(t, got)
+	_, err = cs.GetPowerTableRange(ctx, 11, 12)
+	require.NoError(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	const divergentInstance = 7
+	ctx := context.Background()
+	pt, ptCid := testPowerTable(10)
+	supp := gpbft.SupplementalData{PowerTable: ptCid}
+
+	csA, err := CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 1, pt)
+	require.NoError(t, err)
+	csB, err := CreateStore(ctx, ds_sync.MutexWrap(datastore.NewMapDatastore()), 3, pt)
+	require.NoError(t, err)
+
+	// Stores with no certificates have nothing to diverge on.
+	_, found, err := Diff(ctx, csA, csB)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	for i := uint64(1); i <= 10; i++ {
+		require.NoError(t, csA.Put(ctx, makeCert(i, supp)))
+	}
+	divergent := gpbft.TipSet{Epoch: 1, Key: gpbft.TipSetKey("divergent"), PowerTable: ptCid}
+	for i := uint64(3); i <= 12; i++ {
+		cert := makeCert(i, supp)
+		switch {
+		case i == divergentInstance:
+			cert.ECChain = cert.ECChain.Append(&divergent)
+		case i > divergentInstance:
+			cert.ECChain.TipSets[0] = &divergent
+		}
+		require.NoError(t, csB.Put(ctx, cert))
+	}
+
+	got, found, err := Diff(ctx, csA, csB)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(divergentInstance), got)
+
+	// Diff is symmetric.
+	got, found, err = Diff(ctx, csB, csA)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(divergentInstance), got)
+
+	// A store does not diverge from itself.
+	_, found, err = Diff(ctx, csA, csA)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestSubscribe_SlowSubscriberSkipsToLatest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+
+	pt, ptCid := testPowerTable(10)
+	supp := gpbft.SupplementalData{PowerTable: ptCid}
+	cs, err := CreateStore(ctx, ds, 1, pt)
+	require.NoError(t, err)
+
+	ch, closer := cs.Subscribe()
+	defer closer()
+
+	// A subscriber that does not keep up misses all but the latest certificate.
+	const lastInstance = 10
+	for i := uint64(1); i <= lastInstance; i++ {
+		require.NoError(t, cs.Put(ctx, makeCert(i, supp)))
+	}
+	require.Equal(t, uint64(lastInstance), (<-ch).GPBFTInstance)
+	select {
+	case cert := <-ch:
+		require.Failf(t, "unexpected certificate", "instance %d", cert.GPBFTInstance)
+	default:
+	}
+
+	// Certificates are received in order once the subscriber keeps up.
+	require.NoError(t, cs.Put(ctx, makeCert(lastInstance+1, supp)))
+	require.Equal(t, uint64(lastInstance+1), (<-ch).GPBFTInstance)
+}
+
+func TestNewInMemory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pt, ptCid := testPowerTable(10)
+	supp := gpbft.SupplementalData{PowerTable: ptCid}
+
+	_, err := NewInMemory(1, nil)
+	require.ErrorContains(t, err, "empty initial power table")
+
+	cs, err := NewInMemory(1, pt)
+	require.NoError(t, err)
+	require.Nil(t, cs.Latest())
+	gotPt, err := cs.GetPowerTable(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, pt, gotPt)
+
+	// Concurrent readers observe certificates as they are put.
+	const count = 100
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				latest := cs.Latest()
+				if latest == nil {
+					continue
+				}
+				cert, err := cs.Get(ctx, latest.GPBFTInstance)
+				assert.NoError(t, err)
+				assert.Equal(t, latest.GPBFTInstance, cert.GPBFTInstance)
+				if latest.GPBFTInstance == count {
+					return
+				}
+			}
+		}()
+	}
+	for i := uint64(1); i <= count; i++ {
+		require.NoError(t, cs.Put(ctx, makeCert(i, supp)))
+	}
+	wg.Wait()
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pt, ptCid := testPowerTable(10)
+	supp := gpbft.SupplementalData{PowerTable: ptCid}
+
+	cs, err := NewInMemory(1, pt)
+	require.NoError(t, err)
+	for i := uint64(1); i <= 5; i++ {
+		require.NoError(t, cs.Put(ctx, makeCert(i, supp)))
+	}
+	snapshot, err := cs.Snapshot(ctx)
+	require.NoError(t, err)
+
+	// Explore one branch, then rewind to the snapshot.
+	for i := uint64(6); i <= 8; i++ {
+		require.NoError(t, cs.Put(ctx, makeCert(i, supp)))
+	}
+	updates, closer := cs.Subscribe()
+	defer closer()
+	require.EqualValues(t, 8, (<-updates).GPBFTInstance)
+	require.NoError(t, cs.Restore(ctx, snapshot))
+	require.EqualValues(t, 5, cs.Latest().GPBFTInstance)
+	// Rewinding closes the subscription rather than going backwards.
+	_, ok := <-updates
+	require.False(t, ok)
+	updates, closer = cs.Subscribe()
+	defer closer()
+	require.EqualValues(t, 5, (<-updates).GPBFTInstance)
+	_, err = cs.Get(ctx, 6)
+	require.ErrorIs(t, err, ErrCertNotFound)
+	gotPt, err := cs.GetPowerTable(ctx, 6)
+	require.NoError(t, err)
+	require.Equal(t, pt, gotPt)
+
+	// The store continues from the snapshot as if the branch never happened.
+	require.NoError(t, cs.Put(ctx, makeCert(6, supp)))
+	require.EqualValues(t, 6, cs.Latest().GPBFTInstance)
+
+	// Snapshots may be restored to another store, regardless of its first instance.
+	other, err := NewInMemory(42, pt)
+	require.NoError(t, err)
+	otherUpdates, otherCloser := other.Subscribe()
+	defer otherCloser()
+	require.NoError(t, other.Restore(ctx, snapshot))
+	require.EqualValues(t, 5, other.Latest().GPBFTInstance)
+	// Restoring forwards notifies subscribers as usual.
+	require.EqualValues(t, 5, (<-otherUpdates).GPBFTInstance)
+	certs, err := other.GetRange(ctx, 1, 5)
+	require.NoError(t, err)
+	require.Len(t, certs, 5)
+	require.NoError(t, other.Put(ctx, makeCert(6, supp)))
+
+	// Restoring one store leaves the other untouched.
+	require.NoError(t, cs.Restore(ctx, snapshot))
+	require.EqualValues(t, 6, other.Latest().GPBFTInstance)
+}
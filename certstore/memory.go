@@ -0,0 +1,127 @@
+package certstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+)
+
+// NewInMemory creates a certificate store backed by a thread safe in-memory datastore, e.g. for
+// tests and ephemeral nodes that need not retain certificates across restarts.
+func NewInMemory(firstInstance uint64, initialPowerTable gpbft.PowerEntries) (*Store, error) {
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	return CreateStore(context.Background(), ds, firstInstance, initialPowerTable)
+}
+
+// Snapshot is a copy of the contents of a certificate store at a point in time.
+type Snapshot struct {
+	entries map[datastore.Key][]byte
+}
+
+// Snapshot copies the contents of the store, such that they can later be restored to it, or
+// to another store, using Restore. Certificates put while the snapshot is taken are either
+// entirely included or excluded.
+func (cs *Store) Snapshot(ctx context.Context) (*Snapshot, error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	results, err := cs.ds.Query(ctx, query.Query{})
+	if err != nil {
+		return nil, fmt.Errorf("querying store contents: %w", err)
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return nil, fmt.Errorf("querying store contents: %w", err)
+	}
+	snapshot := &Snapshot{entries: make(map[datastore.Key][]byte, len(entries))}
+	for _, entry := range entries {
+		snapshot.entries[datastore.NewKey(entry.Key)] = entry.Value
+	}
+	return snapshot, nil
+}
+
+// Restore replaces the contents of the store with those of the given snapshot, which may have
+// been taken of a different store. Subscribers are notified of the latest certificate restored
+// if it follows the latest certificate before the restore. Otherwise, their subscriptions are
+// closed, since they may already have received a later certificate, and they must subscribe again.
+func (cs *Store) Restore(ctx context.Context, snapshot *Snapshot) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	results, err := cs.ds.Query(ctx, query.Query{KeysOnly: true})
+	if err != nil {
+		return fmt.Errorf("querying store contents: %w", err)
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return fmt.Errorf("querying store contents: %w", err)
+	}
+	batch, err := cs.batch(ctx)
+	if err != nil {
+		return fmt.Errorf("starting a batch: %w", err)
+	}
+	for _, entry := range entries {
+		key := datastore.NewKey(entry.Key)
+		if _, ok := snapshot.entries[key]; ok {
+			continue
+		}
+		if err := batch.Delete(ctx, key); err != nil {
+			return fmt.Errorf("deleting %q: %w", key, err)
+		}
+	}
+	for key, value := range snapshot.entries {
+		if err := batch.Put(ctx, key, value); err != nil {
+			return fmt.Errorf("restoring %q: %w", key, err)
+		}
+	}
+	if err := batch.Commit(ctx); err != nil {
+		return fmt.Errorf("committing restored contents: %w", err)
+	}
+
+	// Reload the state held in memory from the restored contents, as when opening the store,
+	// using a store that shares the datastore but not the lock we hold.
+	restored := &Store{ds: cs.ds, powerTableFrequency: cs.powerTableFrequency}
+	if restored.firstInstance, err = restored.readInstanceNumber(ctx, certStoreFirstKey); err != nil {
+		return fmt.Errorf("getting restored first instance: %w", err)
+	}
+	nextInstance := restored.firstInstance
+	if latestInstance, err := restored.readInstanceNumber(ctx, certStoreLatestKey); err == nil {
+		if restored.latestCertificate, err = restored.Get(ctx, latestInstance); err != nil {
+			return fmt.Errorf("loading restored latest cert: %w", err)
+		}
+		nextInstance = latestInstance + 1
+	} else if !errors.Is(err, datastore.ErrNotFound) {
+		return fmt.Errorf("determining restored latest cert: %w", err)
+	}
+	if restored.latestPowerTable, err = restored.GetPowerTable(ctx, nextInstance); err != nil {
+		return fmt.Errorf("getting restored latest power table: %w", err)
+	}
+	previous := cs.latestCertificate
+	cs.firstInstance = restored.firstInstance
+	cs.latestCertificate = restored.latestCertificate
+	cs.latestPowerTable = restored.latestPowerTable
+	switch {
+	case cs.latestCertificate == nil && previous == nil:
+	case cs.latestCertificate == nil || (previous != nil && cs.latestCertificate.GPBFTInstance <= previous.GPBFTInstance):
+		// The store went backwards, so publishing would break the ordering promised to
+		// subscribers.
+		for ch := range cs.subscribers {
+			delete(cs.subscribers, ch)
+			close(ch)
+		}
+	default:
+		for ch := range cs.subscribers {
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- cs.latestCertificate
+		}
+	}
+	return nil
+}
@@ -21,28 +21,42 @@ import (
 type gpbftInputs struct {
 	manifest  *manifest.Manifest
 	certStore *certstore.Store
-	ec        ec.Backend
-	verifier  gpbft.Verifier
-	clock     clock.Clock
+	// ec is the source of chain and power tables. In practice, it is the power
+	// store wrapping the EC backend, which retains power tables EC may have
+	// forgotten. See powerstore.Store.
+	ec       ec.Backend
+	verifier gpbft.Verifier
+	clock    clock.Clock
 
 	ptCache *lru.Cache[string, cid.Cid]
+	// parents caches the parent of tipsets by the key of their child, so that
+	// walking back from head to base re-fetches only the tipsets not seen walking
+	// back in previous instances.
+	parents     *lru.Cache[string, ec.TipSet]
+	powerTables *PowerTableResolver
 }
 
-func newInputs(manifest *manifest.Manifest, certStore *certstore.Store, ec ec.Backend,
+func newInputs(manifest *manifest.Manifest, certStore *certstore.Store, backend ec.Backend,
 	verifier gpbft.Verifier, clk clock.Clock) gpbftInputs {
 	cache, err := lru.New[string, cid.Cid](256) // keep a bit more than 2x max ECChain size
 	if err != nil {
 		// panic as it only depends on the size
 		panic(fmt.Errorf("could not create cache: %w", err))
 	}
+	parents, err := lru.New[string, ec.TipSet](256)
+	if err != nil {
+		panic(fmt.Errorf("could not create cache: %w", err))
+	}
 
 	return gpbftInputs{
-		manifest:  manifest,
-		certStore: certStore,
-		ec:        ec,
-		verifier:  verifier,
-		clock:     clk,
-		ptCache:   cache,
+		manifest:    manifest,
+		certStore:   certStore,
+		ec:          backend,
+		verifier:    verifier,
+		clock:       clk,
+		ptCache:     cache,
+		parents:     parents,
+		powerTables: NewPowerTableResolver(manifest, certStore, backend),
 	}
 }
 
@@ -50,8 +64,10 @@ func (h *gpbftInputs) getPowerTableCIDForTipset(ctx context.Context, tsk gpbft.T
 	sTSK := string(tsk)
 	ptCid, ok := h.ptCache.Get(sTSK)
 	if ok {
+		metrics.chainCache.Add(ctx, 1, metric.WithAttributes(attrCacheHit, attrCacheKindPowerTable))
 		return ptCid, nil
 	}
+	metrics.chainCache.Add(ctx, 1, metric.WithAttributes(attrCacheMiss, attrCacheKindPowerTable))
 
 	pt, err := h.ec.GetPowerTable(ctx, tsk)
 	if err != nil {
@@ -77,10 +93,13 @@ func (h *gpbftInputs) collectChain(ctx context.Context, base ec.TipSet, head ec.
 			metrics.headDiverged.Add(ctx, 1)
 			log.Infow("reorg-ed away from base, proposing just base",
 				"head", head.String(), "base", base.String())
+			// Tipsets of the abandoned fork are unlikely to be walked again.
+			h.parents.Purge()
+			h.ptCache.Purge()
 			return nil, nil
 		}
 		var err error
-		current, err = h.ec.GetParent(ctx, current)
+		current, err = h.getParent(ctx, current)
 		if err != nil {
 			return nil, fmt.Errorf("walking back the chain: %w", err)
 		}
@@ -90,6 +109,20 @@ func (h *gpbftInputs) collectChain(ctx context.Context, base ec.TipSet, head ec.
 	return res[1:], nil
 }
 
+func (h *gpbftInputs) getParent(ctx context.Context, ts ec.TipSet) (ec.TipSet, error) {
+	if parent, ok := h.parents.Get(string(ts.Key())); ok {
+		metrics.chainCache.Add(ctx, 1, metric.WithAttributes(attrCacheHit, attrCacheKindParent))
+		return parent, nil
+	}
+	metrics.chainCache.Add(ctx, 1, metric.WithAttributes(attrCacheMiss, attrCacheKindParent))
+	parent, err := h.ec.GetParent(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+	h.parents.Add(string(ts.Key()), parent)
+	return parent, nil
+}
+
 // Returns inputs to the next GPBFT instance.
 // These are:
 // - the supplemental data.
@@ -103,6 +136,7 @@ func (h *gpbftInputs) GetProposal(ctx context.Context, instance uint64) (_ *gpbf
 	}(time.Now())
 
 	var baseTsk gpbft.TipSetKey
+	var previousDecision *gpbft.TipSet
 	if instance == h.manifest.InitialInstance {
 		ts, err := h.ec.GetTipsetByEpoch(ctx,
 			h.manifest.BootstrapEpoch-h.manifest.EC.Finality)
@@ -115,13 +149,27 @@ func (h *gpbftInputs) GetProposal(ctx context.Context, instance uint64) (_ *gpbf
 		if err != nil {
 			return nil, nil, fmt.Errorf("getting cert for previous instance(%d): %w", instance-1, err)
 		}
-		baseTsk = cert.ECChain.Head().Key
+		previousDecision = cert.ECChain.Head()
+		baseTsk = previousDecision.Key
 	}
 
 	baseTs, err := h.ec.GetTipset(ctx, baseTsk)
 	if err != nil {
 		return nil, nil, fmt.Errorf("getting base TS: %w", err)
 	}
+	if previousDecision != nil {
+		// The base is fetched by the key of the previous decision, and so matches it
+		// even if EC has since reorged away from it. Compare it to the tipset EC now
+		// considers canonical at its epoch instead.
+		canonical, err := h.ec.GetTipsetByEpoch(ctx, previousDecision.Epoch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting canonical TS at base epoch: %w", err)
+		}
+		if !bytes.Equal(canonical.Key(), previousDecision.Key) {
+			return nil, nil, fmt.Errorf("%w: expected base %s at instance %d but EC has %s at its epoch",
+				gpbft.ErrProposalWrongBase, previousDecision, instance, canonical)
+		}
+	}
 	headTs, err := h.ec.GetHead(ctx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("getting head TS: %w", err)
@@ -188,45 +236,9 @@ func (h *gpbftInputs) GetCommittee(ctx context.Context, instance uint64) (_ *gpb
 		metrics.committeeFetchTime.Record(context.TODO(), time.Since(start).Seconds(), metric.WithAttributes(attrStatusFromErr(_err)))
 	}(time.Now())
 
-	var powerTsk gpbft.TipSetKey
-	var powerEntries gpbft.PowerEntries
-	var err error
-
-	if instance < h.manifest.InitialInstance+h.manifest.CommitteeLookback {
-		//boostrap phase
-		powerEntries, err = h.certStore.GetPowerTable(ctx, h.manifest.InitialInstance)
-		if err != nil {
-			return nil, fmt.Errorf("getting power table: %w", err)
-		}
-		if h.certStore.Latest() == nil {
-			ts, err := h.ec.GetTipsetByEpoch(ctx, h.manifest.BootstrapEpoch-h.manifest.EC.Finality)
-			if err != nil {
-				return nil, fmt.Errorf("getting tipset for boostrap epoch with lookback: %w", err)
-			}
-			powerTsk = ts.Key()
-		} else {
-			cert, err := h.certStore.Get(ctx, h.manifest.InitialInstance)
-			if err != nil {
-				return nil, fmt.Errorf("getting finality certificate: %w", err)
-			}
-			powerTsk = cert.ECChain.Base().Key
-		}
-	} else {
-		cert, err := h.certStore.Get(ctx, instance-h.manifest.CommitteeLookback)
-		if err != nil {
-			return nil, fmt.Errorf("getting finality certificate: %w", err)
-		}
-		powerTsk = cert.ECChain.Head().Key
-
-		powerEntries, err = h.certStore.GetPowerTable(ctx, instance)
-		if err != nil {
-			log.Debugf("failed getting power table from certstore: %v, falling back to EC", err)
-
-			powerEntries, err = h.ec.GetPowerTable(ctx, powerTsk)
-			if err != nil {
-				return nil, fmt.Errorf("getting power table: %w", err)
-			}
-		}
+	powerEntries, powerTsk, err := h.powerTables.resolve(ctx, instance)
+	if err != nil {
+		return nil, err
 	}
 
 	ts, err := h.ec.GetTipset(ctx, powerTsk)
@@ -0,0 +1,266 @@
+package f3
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/certs"
+	"github.com/filecoin-project/go-f3/certstore"
+	"github.com/filecoin-project/go-f3/ec"
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/internal/clock"
+	"github.com/filecoin-project/go-f3/internal/consensus"
+	"github.com/filecoin-project/go-f3/internal/powerstore"
+	"github.com/filecoin-project/go-f3/manifest"
+	"github.com/filecoin-project/go-f3/sim/signing"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// testConsensusInputs is the fixture shared by tests of the consensus inputs: a
+// power table of three participants with equal power, a fake EC that starts
+// with it at the bootstrap epoch of the manifest, and a certstore initialised
+// with it.
+type testConsensusInputs struct {
+	ctx        context.Context
+	clock      clock.Clock
+	m          *manifest.Manifest
+	backend    *signing.FakeBackend
+	powerTable gpbft.PowerEntries
+	ptCid      cid.Cid
+	ec         *consensus.FakeEC
+	ds         datastore.Datastore
+	cs         *certstore.Store
+}
+
+func newTestConsensusInputs(tb testing.TB, ctx context.Context, options ...consensus.FakeECOption) *testConsensusInputs {
+	tb.Helper()
+	f := &testConsensusInputs{
+		ctx:     ctx,
+		clock:   clock.GetClock(ctx),
+		m:       manifest.LocalDevnetManifest(),
+		backend: signing.NewFakeBackend(),
+		ds:      ds_sync.MutexWrap(datastore.NewMapDatastore()),
+	}
+	for id := gpbft.ActorID(1); id <= 3; id++ {
+		pubKey, _ := f.backend.GenerateKey()
+		f.powerTable = append(f.powerTable, gpbft.PowerEntry{ID: id, Power: gpbft.NewStoragePower(10), PubKey: pubKey})
+	}
+	var err error
+	f.ptCid, err = certs.MakePowerTableCID(f.powerTable)
+	require.NoError(tb, err)
+	f.ec = consensus.NewFakeEC(f.ctx, append([]consensus.FakeECOption{
+		consensus.WithBootstrapEpoch(f.m.BootstrapEpoch),
+		consensus.WithECPeriod(f.m.EC.Period),
+		consensus.WithInitialPowerTable(f.powerTable),
+	}, options...)...)
+	f.cs, err = certstore.CreateStore(f.ctx, f.ds, f.m.InitialInstance, f.powerTable)
+	require.NoError(tb, err)
+	return f
+}
+
+// bootstrapBase returns the epoch of the base of the chain proposed at the
+// initial instance.
+func (f *testConsensusInputs) bootstrapBase() int64 {
+	return f.m.BootstrapEpoch - f.m.EC.Finality
+}
+
+// decideInitialInstance finalizes the first three tipsets from the bootstrap
+// base at the initial instance, and returns the decided chain.
+func (f *testConsensusInputs) decideInitialInstance(tb testing.TB) *gpbft.ECChain {
+	tb.Helper()
+	var decided *gpbft.ECChain
+	for epoch := f.bootstrapBase(); epoch <= f.bootstrapBase()+2; epoch++ {
+		ts, err := f.ec.GetTipsetByEpoch(f.ctx, epoch)
+		require.NoError(tb, err)
+		decided = decided.Append(&gpbft.TipSet{Epoch: ts.Epoch(), Key: ts.Key(), PowerTable: f.ptCid})
+	}
+	require.NoError(tb, f.cs.Put(f.ctx, &certs.FinalityCertificate{
+		GPBFTInstance:    f.m.InitialInstance,
+		ECChain:          decided,
+		SupplementalData: gpbft.SupplementalData{PowerTable: f.ptCid},
+	}))
+	return decided
+}
+
+// headCountingEC counts the heads fetched from the wrapped EC, which the power
+// store does once on every tick.
+type headCountingEC struct {
+	ec.Backend
+	heads atomic.Int64
+}
+
+func (h *headCountingEC) GetHead(ctx context.Context) (ec.TipSet, error) {
+	h.heads.Add(1)
+	return h.Backend.GetHead(ctx)
+}
+
+// startPowerStore starts a power store following the EC of the fixture, and
+// returns a function that advances the given clock by at least a duration, one
+// power store tick at a time. The function waits for the power store to handle
+// each tick before the next, such that none are dropped as EC moves ahead.
+func (f *testConsensusInputs) startPowerStore(t *testing.T, clk *clock.Mock) (*powerstore.Store, func(time.Duration)) {
+	t.Helper()
+	counting := &headCountingEC{Backend: f.ec}
+	ps, err := powerstore.New(f.ctx, counting, f.ds, f.cs, f.m)
+	require.NoError(t, err)
+	require.NoError(t, ps.Start(f.ctx))
+	t.Cleanup(func() { require.NoError(t, ps.Stop(context.Background())) })
+
+	// The power store ticks every other EC period, once it has asynchronously
+	// set up its ticker.
+	tick := 2 * f.m.EC.Period
+	require.Eventually(t, func() bool {
+		clk.Add(tick)
+		return counting.heads.Load() > 0
+	}, 10*time.Second, time.Millisecond)
+	return ps, func(d time.Duration) {
+		t.Helper()
+		for elapsed := time.Duration(0); elapsed < d; elapsed += tick {
+			heads := counting.heads.Load()
+			clk.Add(tick)
+			require.Eventually(t, func() bool { return counting.heads.Load() > heads }, 10*time.Second, time.Millisecond)
+		}
+	}
+}
+
+func TestGetProposal_FailsOnBaseMismatchWithPreviousDecision(t *testing.T) {
+	ctx, _ := clock.WithMockClock(context.Background())
+	f := newTestConsensusInputs(t, ctx)
+	m, fakeEC := f.m, f.ec
+	decided := f.decideInitialInstance(t)
+	inputs := newInputs(m, f.cs, fakeEC, f.backend, f.clock)
+
+	_, chain, err := inputs.GetProposal(ctx, m.InitialInstance+1)
+	require.NoError(t, err)
+	require.True(t, chain.Base().Equal(decided.Head()))
+
+	// Reorg EC away from the head of the decided chain.
+	fakeEC.InjectReorg(decided.Head().Epoch-1, 5)
+	_, _, err = inputs.GetProposal(ctx, m.InitialInstance+1)
+	require.ErrorIs(t, err, gpbft.ErrProposalWrongBase)
+
+	// The base is accepted again once EC is back on the decided chain.
+	require.True(t, fakeEC.RevertReorg())
+	_, chain, err = inputs.GetProposal(ctx, m.InitialInstance+1)
+	require.NoError(t, err)
+	require.True(t, chain.Base().Equal(decided.Head()))
+}
+
+func TestGetCommittee_FallsBackToPowerStoreWhenECForgets(t *testing.T) {
+	ctx, clk := clock.WithMockClock(context.Background())
+	f := newTestConsensusInputs(t, ctx, consensus.WithMaxLookback(2*manifest.LocalDevnetManifest().EC.Finality))
+	m, backend, powerTable, forgetfulEC, cs := f.m, f.backend, f.powerTable, f.ec, f.cs
+	decided := f.decideInitialInstance(t)
+
+	ps, advance := f.startPowerStore(t, clk)
+
+	// Advance EC far enough for it to forget the power table at the head of the
+	// decided chain.
+	advance(m.EC.Period * time.Duration(m.EC.Finality*3/2))
+	_, err := forgetfulEC.GetPowerTable(ctx, decided.Head().Key)
+	require.Error(t, err)
+
+	// The committee of an instance for which the certstore has no power table yet is
+	// derived from the head of the decided chain.
+	instance := m.InitialInstance + m.CommitteeLookback
+	withoutPowerStore := newInputs(m, cs, forgetfulEC, backend, clk)
+	_, err = withoutPowerStore.GetCommittee(ctx, instance)
+	require.Error(t, err)
+	withPowerStore := newInputs(m, cs, ps, backend, clk)
+	require.Eventually(t, func() bool {
+		committee, err := withPowerStore.GetCommittee(ctx, instance)
+		return err == nil && committee.PowerTable.Entries.Len() == len(powerTable)
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+// BenchmarkGetProposal_ECLatency measures the time to fetch the inputs needed to
+// start an instance as EC latency increases.
+func BenchmarkGetProposal_ECLatency(b *testing.B) {
+	for _, latency := range []time.Duration{0, 10 * time.Microsecond, 100 * time.Microsecond, time.Millisecond} {
+		b.Run(latency.String(), func(b *testing.B) {
+			f := newTestConsensusInputs(b, context.Background(), consensus.WithLatency(consensus.Latency{
+				GetTipsetByEpoch: latency,
+				GetTipset:        latency,
+				GetHead:          latency,
+				GetParent:        latency,
+				GetPowerTable:    latency,
+			}))
+			f.m.EC.Finality = 20
+			b.ResetTimer()
+			for range b.N {
+				// Use fresh inputs every time, so that no power table is cached.
+				inputs := newInputs(f.m, f.cs, f.ec, f.backend, f.clock)
+				if _, _, err := inputs.GetProposal(f.ctx, f.m.InitialInstance); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// countingEC counts the parents fetched from the wrapped EC, and reports head
+// as the head of the chain.
+type countingEC struct {
+	*consensus.FakeEC
+	parents int
+	head    ec.TipSet
+}
+
+func (c *countingEC) GetParent(ctx context.Context, ts ec.TipSet) (ec.TipSet, error) {
+	c.parents++
+	return c.FakeEC.GetParent(ctx, ts)
+}
+
+func (c *countingEC) GetHead(context.Context) (ec.TipSet, error) {
+	return c.head, nil
+}
+
+func TestGetProposal_CachesParentsUntilReorg(t *testing.T) {
+	ctx, clk := clock.WithMockClock(context.Background())
+	f := newTestConsensusInputs(t, ctx)
+	m := f.m
+	fakeEC := &countingEC{FakeEC: f.ec}
+	clk.Add(10 * m.EC.Period)
+	bootstrapBase := f.bootstrapBase()
+	f.decideInitialInstance(t)
+	inputs := newInputs(m, f.cs, fakeEC, f.backend, f.clock)
+	// In steady state, the head is a few epochs ahead of the latest decision.
+	head, err := fakeEC.GetTipsetByEpoch(ctx, bootstrapBase+20)
+	require.NoError(t, err)
+	fakeEC.head = head
+
+	_, want, err := inputs.GetProposal(ctx, m.InitialInstance+1)
+	require.NoError(t, err)
+	require.True(t, want.HasSuffix())
+	walked := fakeEC.parents
+	require.NotZero(t, walked)
+	require.Equal(t, walked, inputs.parents.Len())
+
+	// Walking back the same chain again is served entirely from the cache.
+	_, got, err := inputs.GetProposal(ctx, m.InitialInstance+1)
+	require.NoError(t, err)
+	require.True(t, want.Eq(got))
+	require.Equal(t, walked, fakeEC.parents)
+
+	// A head behind the base signals a reorg, which flushes the cached tipsets.
+	fakeEC.head, err = fakeEC.GetTipsetByEpoch(ctx, bootstrapBase+1)
+	require.NoError(t, err)
+	_, got, err = inputs.GetProposal(ctx, m.InitialInstance+1)
+	require.NoError(t, err)
+	require.False(t, got.HasSuffix())
+	require.Zero(t, inputs.parents.Len())
+	// Only the power table of the base, proposed alone, is cached afresh.
+	require.Equal(t, 1, inputs.ptCache.Len())
+
+	// So the chain is walked afresh once the head is ahead of the base again.
+	fakeEC.head = head
+	_, got, err = inputs.GetProposal(ctx, m.InitialInstance+1)
+	require.NoError(t, err)
+	require.True(t, want.Eq(got))
+	require.Equal(t, 2*walked, fakeEC.parents)
+}
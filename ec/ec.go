@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-state-types/big"
 )
 
 type Backend interface {
@@ -23,6 +24,10 @@ type Backend interface {
 	GetParent(context.Context, TipSet) (TipSet, error)
 	// GetPowerTable returns the power table at the tipset given as an argument.
 	GetPowerTable(context.Context, gpbft.TipSetKey) (gpbft.PowerEntries, error)
+	// TipSetWeight returns the weight of the chain ending at the tipset with the
+	// given key, by which EC chooses between forks. Of two tipsets, the one with
+	// the larger weight is on the heavier, and so preferred, chain.
+	TipSetWeight(context.Context, gpbft.TipSetKey) (big.Int, error)
 	// Finalize marks the tipset that corresponds to the given key as finalised
 	// beyond which no forks are allowed to occur. The finalised tipset overrides the
 	// head tipset if it is not an ancestor of the current head.
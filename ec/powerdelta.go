@@ -72,6 +72,39 @@ func (b *withModifiedPower) GetPowerTable(ctx context.Context, ts gpbft.TipSetKe
 	return trimPowerTable(pt), nil
 }
 
+// WithCommitteeTruncation truncates the power tables returned by the given
+// backend to the entries with the most power, up to the given maximum, with ties
+// broken in favour of the lower actor ID. A maximum of zero leaves the power
+// tables unlimited. Unlike gpbft.WithMaxCommitteeSize, which rejects committees
+// that are too large, the truncated power tables are always accepted.
+func WithCommitteeTruncation(backend Backend, maxSize int) Backend {
+	if maxSize <= 0 {
+		return backend
+	}
+	return &withCommitteeTruncation{
+		Backend: backend,
+		maxSize: maxSize,
+	}
+}
+
+type withCommitteeTruncation struct {
+	Backend
+	maxSize int
+}
+
+func (b *withCommitteeTruncation) GetPowerTable(ctx context.Context, ts gpbft.TipSetKey) (gpbft.PowerEntries, error) {
+	pt, err := b.Backend.GetPowerTable(ctx, ts)
+	if err != nil {
+		return nil, fmt.Errorf("getting power table: %w", err)
+	}
+	if len(pt) <= b.maxSize && sort.IsSorted(pt) {
+		return pt, nil
+	}
+	pt = slices.Clone(pt)
+	sort.Sort(pt)
+	return pt[:min(len(pt), b.maxSize)], nil
+}
+
 func trimPowerTable(pt gpbft.PowerEntries) gpbft.PowerEntries {
 	newLen := len(pt)
 	for newLen > 0 && pt[newLen-1].Power.Sign() == 0 {
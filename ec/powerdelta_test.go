@@ -2,11 +2,14 @@ package ec_test
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/filecoin-project/go-f3/ec"
 	"github.com/filecoin-project/go-f3/gpbft"
 	"github.com/filecoin-project/go-f3/internal/consensus"
+	"github.com/filecoin-project/go-state-types/big"
 
 	"github.com/stretchr/testify/require"
 )
@@ -59,3 +62,70 @@ func TestBypassModifiedPowerTable(t *testing.T) {
 	modifiedBackend := ec.WithModifiedPower(backend, nil, false)
 	require.Equal(t, backend, modifiedBackend)
 }
+
+func TestCommitteeTruncation(t *testing.T) {
+	ctx := context.Background()
+	entry := func(id gpbft.ActorID, power int64) gpbft.PowerEntry {
+		return gpbft.PowerEntry{ID: id, Power: gpbft.NewStoragePower(power), PubKey: gpbft.PubKey(fmt.Sprint("pk", id))}
+	}
+	powerTable := gpbft.PowerEntries{
+		entry(5, 10),
+		entry(1, 50),
+		entry(4, 30),
+		entry(2, 30),
+		entry(3, 30),
+	}
+	reversed := slices.Clone(powerTable)
+	slices.Reverse(reversed)
+	for _, test := range []struct {
+		name    string
+		maxSize int
+		want    gpbft.PowerEntries
+	}{
+		{
+			name:    "ties broken by ID",
+			maxSize: 3,
+			want: gpbft.PowerEntries{
+				entry(1, 50),
+				entry(2, 30),
+				entry(3, 30),
+			},
+		},
+		{
+			name:    "larger than table",
+			maxSize: 10,
+			want: gpbft.PowerEntries{
+				entry(1, 50),
+				entry(2, 30),
+				entry(3, 30),
+				entry(4, 30),
+				entry(5, 10),
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// The selection does not depend on the order of entries.
+			for _, order := range []gpbft.PowerEntries{powerTable, reversed} {
+				backend := consensus.NewFakeEC(ctx, consensus.WithInitialPowerTable(order))
+				limited := ec.WithCommitteeTruncation(backend, test.maxSize)
+				head, err := limited.GetHead(ctx)
+				require.NoError(t, err)
+				pt, err := limited.GetPowerTable(ctx, head.Key())
+				require.NoError(t, err)
+				require.EqualValues(t, test.want, pt)
+
+				// The committee accounts for the power of included entries only.
+				committee := gpbft.NewPowerTable()
+				require.NoError(t, committee.Add(pt...))
+				wantTotal := gpbft.NewStoragePower(0)
+				for _, entry := range test.want {
+					wantTotal = big.Add(wantTotal, entry.Power)
+				}
+				require.True(t, committee.Total.Equals(wantTotal), "want %s, got %s", wantTotal, committee.Total)
+			}
+		})
+	}
+
+	backend := consensus.NewFakeEC(ctx, consensus.WithInitialPowerTable(powerTable))
+	require.Equal(t, backend, ec.WithCommitteeTruncation(backend, 0))
+}
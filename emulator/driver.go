@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"testing"
 
 	"github.com/filecoin-project/go-f3/gpbft"
@@ -42,7 +43,14 @@ func (d *Driver) SetSigning(signing Signing) { d.host.Signing = signing }
 //
 // See NewInstance.
 func (d *Driver) StartInstance(id uint64) error {
-	if err := d.subject.StartInstanceAt(id, d.host.Time()); err != nil {
+	return d.StartInstanceFrom(id, nil)
+}
+
+// StartInstanceFrom is like StartInstance, and additionally requires the chain
+// proposed for the instance to extend the given base, as if learnt from the
+// decision of the instance before it by other means.
+func (d *Driver) StartInstanceFrom(id uint64, base *gpbft.TipSet) error {
+	if err := d.subject.StartInstanceFrom(id, base, d.host.Time()); err != nil {
 		return err
 	}
 	// Trigger alarm once based on the implicit assumption that go-f3 uses alarm to
@@ -66,6 +74,37 @@ func (d *Driver) PeekLastBroadcastRequest() *gpbft.GMessage {
 	return d.host.peekLastBroadcast()
 }
 
+// Broadcasts returns every message broadcast or rebroadcast by the subject
+// participant so far, in the order they were requested, regardless of whether
+// they have since been asserted on.
+func (d *Driver) Broadcasts() []*gpbft.GMessage {
+	return append([]*gpbft.GMessage(nil), d.host.broadcastHistory...)
+}
+
+// Progress returns the current instance, round and phase of the subject
+// participant.
+func (d *Driver) Progress() gpbft.Instant {
+	return d.subject.Progress()
+}
+
+// Finalized returns the latest decision reached by the subject participant, if
+// any.
+func (d *Driver) Finalized() (*gpbft.Justification, bool) {
+	return d.subject.Finalized()
+}
+
+// SerializeQueuedMessages writes the messages queued by the subject participant
+// for future instances to the given writer.
+func (d *Driver) SerializeQueuedMessages(w io.Writer) error {
+	return d.subject.SerializeQueuedMessages(w)
+}
+
+// RestoreQueuedMessages restores messages written by SerializeQueuedMessages,
+// possibly of another driver, to the subject participant.
+func (d *Driver) RestoreQueuedMessages(r io.Reader) error {
+	return d.subject.RestoreQueuedMessages(r)
+}
+
 func (d *Driver) DeliverAlarm() (bool, error) {
 	if d.host.maybeReceiveAlarm() {
 		return true, d.subject.ReceiveAlarm()
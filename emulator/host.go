@@ -10,7 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-const networkName = "emulator-net"
+// NetworkName is the name of the network over which emulated messages are signed.
+const NetworkName = "emulator-net"
 
 var _ gpbft.Host = (*driverHost)(nil)
 
@@ -22,6 +23,7 @@ type driverHost struct {
 	now                time.Time
 	pendingAlarm       *time.Time
 	receivedBroadcasts []*gpbft.GMessage
+	broadcastHistory   []*gpbft.GMessage
 	chain              map[uint64]*Instance
 	messages           MessageCache
 }
@@ -50,6 +52,7 @@ func (h *driverHost) RequestBroadcast(mb *gpbft.MessageBuilder) error {
 		return err
 	}
 	h.receivedBroadcasts = append(h.receivedBroadcasts, msg)
+	h.broadcastHistory = append(h.broadcastHistory, msg)
 	require.True(h.t, h.messages.PutIfAbsent(msg))
 	return nil
 }
@@ -58,6 +61,7 @@ func (h *driverHost) RequestRebroadcast(instant gpbft.Instant) error {
 	message, found := h.messages.Get(instant)
 	if found {
 		h.receivedBroadcasts = append(h.receivedBroadcasts, message)
+		h.broadcastHistory = append(h.broadcastHistory, message)
 	}
 	return nil
 }
@@ -131,6 +135,6 @@ func (h *driverHost) peekLastBroadcast() *gpbft.GMessage {
 	}
 }
 
-func (h *driverHost) NetworkName() gpbft.NetworkName { return networkName }
+func (h *driverHost) NetworkName() gpbft.NetworkName { return NetworkName }
 func (h *driverHost) Time() time.Time                { return h.now }
 func (h *driverHost) SetAlarm(at time.Time)          { h.pendingAlarm = &at }
@@ -22,6 +22,7 @@ import (
 	"github.com/filecoin-project/go-f3/manifest"
 
 	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/host"
 
@@ -61,6 +62,16 @@ type F3 struct {
 
 	manifest atomic.Pointer[manifest.Manifest]
 	state    atomic.Pointer[f3State]
+
+	// participationPaused signals whether participation in gpbft is paused, and is
+	// carried over to any runner started subsequently.
+	participationPaused atomic.Bool
+	// followOnly signals whether gpbft runners follow finality via certificate
+	// exchange only.
+	followOnly atomic.Bool
+	// catchUpFirst signals whether gpbft runners catch up with the network via
+	// certificate exchange before participating.
+	catchUpFirst atomic.Bool
 }
 
 // New creates and setups f3 with libp2p
@@ -219,11 +230,14 @@ func (m *F3) Start(startCtx context.Context) (_err error) {
 				if hasPendingManifest && !manifestChangeTimer.Stop() {
 					<-manifestChangeTimer.C
 				}
+				// Stop on every update, so that nothing derived from the previous
+				// manifest, e.g. the pubsub topic named after its network, outlives it.
 				if err := m.stopInternal(m.runningCtx); err != nil {
 					// Don't fail here, just log and move on.
 					log.Errorw("failed to stop running F3 instance", "error", err)
 				}
 				metrics.manifestsReceived.Add(m.runningCtx, 1)
+				log.Infow("applying manifest update", "changes", m.manifest.Load().Diff(update))
 				m.manifest.Store(update)
 			case <-manifestChangeTimer.C:
 			case <-m.runningCtx.Done():
@@ -320,7 +334,7 @@ func (m *F3) startInternal(ctx context.Context) error {
 		return nil
 	}
 
-	mPowerEc := ec.WithModifiedPower(m.ec, state.manifest.ExplicitPower, state.manifest.IgnoreECPower)
+	mPowerEc := withManifestPower(m.ec, state.manifest)
 
 	// We don't reset these fields if we only pause/resume.
 	certClient := certexchange.Client{
@@ -339,6 +353,9 @@ func (m *F3) startInternal(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to construct the oshitstore: %w", err)
 	}
+	if err := validateBootstrap(ctx, state.ps, state.cs, state.manifest); err != nil {
+		return err
+	}
 
 	state.certserv = &certexchange.Server{
 		NetworkName:    state.manifest.NetworkName,
@@ -358,6 +375,8 @@ func (m *F3) startInternal(ctx context.Context) error {
 		InitialPollInterval: state.manifest.EC.Period,
 		MaximumPollInterval: state.manifest.CertificateExchange.MaximumPollInterval,
 		MinimumPollInterval: state.manifest.CertificateExchange.MinimumPollInterval,
+		PollJitter:          certexpoll.DefaultPollJitter,
+		Datastore:           namespace.Wrap(m.ds, state.manifest.DatastorePrefix().ChildString("cxpeers")),
 	}
 	cleanName := strings.ReplaceAll(string(state.manifest.NetworkName), "/", "-")
 	cleanName = strings.ReplaceAll(cleanName, ".", "")
@@ -368,13 +387,33 @@ func (m *F3) startInternal(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("opening WAL: %w", err)
 	}
+	journal, err := openReceivedJournal(filepath.Join(m.diskPath, "received-wal", cleanName))
+	if err != nil {
+		return multierr.Append(fmt.Errorf("opening received message journal: %w", err), wal.Close())
+	}
+	// The runner closes both logs once started. Until then, they are closed here on
+	// failure, so as not to leak their files or the journal's write loop.
+	closeLogs := func(err error) error {
+		return multierr.Combine(err, wal.Close(), journal.Close())
+	}
 
 	state.runner, err = newRunner(
 		ctx, state.cs, state.ps, m.pubsub, m.verifier,
-		m.outboundMessages, state.manifest, wal, m.host.ID(),
+		m.outboundMessages, state.manifest, wal, journal,
+		namespace.Wrap(m.ds, state.manifest.DatastorePrefix()), m.host.ID(),
 	)
 	if err != nil {
-		return err
+		return closeLogs(err)
+	}
+	state.runner.followOnly = m.followOnly.Load()
+	state.runner.certAnnounced = state.certsub.Announce
+	if m.participationPaused.Load() {
+		if err := state.runner.Pause(ctx); err != nil {
+			return closeLogs(err)
+		}
+	}
+	if m.catchUpFirst.Load() {
+		state.runner.catchUpBeforeParticipating(state.certsub.CaughtUp())
 	}
 
 	if err := state.start(ctx); err != nil {
@@ -388,6 +427,78 @@ func (m *F3) startInternal(ctx context.Context) error {
 	return nil
 }
 
+// PauseParticipation suspends this node's gpbft participant, which neither
+// advances instances nor broadcasts messages until resumed, e.g. during a
+// maintenance window. The pubsub subscription, certificate exchange and
+// certificate store remain active, so that the node keeps receiving finality
+// certificates, and participation stays paused across restarts of the gpbft
+// runner until ResumeParticipation is called.
+func (m *F3) PauseParticipation(ctx context.Context) error {
+	m.participationPaused.Store(true)
+	if st := m.state.Load(); st != nil {
+		return st.runner.Pause(ctx)
+	}
+	return nil
+}
+
+// ResumeParticipation restarts this node's gpbft participant after
+// PauseParticipation, from the instance following the latest certificate should
+// this node have fallen behind.
+func (m *F3) ResumeParticipation(ctx context.Context) error {
+	m.participationPaused.Store(false)
+	if st := m.state.Load(); st != nil {
+		return st.runner.Resume(ctx)
+	}
+	return nil
+}
+
+// SetFollowOnly configures whether this node follows finality solely via
+// certificate exchange, without joining any gpbft pubsub topic or participating in
+// instances. This is a lighter configuration than PauseParticipation, suited to
+// private or low-bandwidth deployments. It takes effect the next time F3 starts,
+// including upon a manifest change, and should therefore be set before Start.
+func (m *F3) SetFollowOnly(enabled bool) {
+	m.followOnly.Store(enabled)
+}
+
+// SetCatchUpBeforeParticipating configures whether this node first catches up
+// with the network via certificate exchange before participating in gpbft. When
+// enabled, the node follows consensus without broadcasting anything until it has
+// received the certificates of every instance up to the pending instance
+// reported by its peers, and then participates from the instance it caught up
+// to. This spares a node that is far behind from broadcasting messages for
+// instances that the network has long decided. It takes effect the next time F3
+// starts, including upon a manifest change, and should therefore be set before
+// Start.
+func (m *F3) SetCatchUpBeforeParticipating(enabled bool) {
+	m.catchUpFirst.Store(enabled)
+}
+
+// RunLoop is the lifecycle of the gpbft run loop of a running F3, for embedders
+// to supervise. See F3.RunLoop.
+type RunLoop interface {
+	// Context returns the context that the run loop and its supporting goroutines
+	// run with. It is cancelled once they stop, whether normally or not.
+	Context() context.Context
+	// Done returns a channel that is closed once the run loop has exited.
+	Done() <-chan struct{}
+	// Err returns the error that the run loop exited with once Done is closed, or
+	// nil if it has not exited or exited because F3 stopped it.
+	Err() error
+}
+
+// RunLoop returns the gpbft run loop of this node, or ErrF3NotRunning if F3 is
+// not running. F3 starts a new run loop whenever it restarts, e.g. upon a
+// manifest change, so the returned one only covers the current run.
+//
+// This API is safe for concurrent use.
+func (m *F3) RunLoop() (RunLoop, error) {
+	if st := m.state.Load(); st != nil {
+		return st.runner, nil
+	}
+	return nil, ErrF3NotRunning
+}
+
 // IsRunning returns true if gpbft is running
 // Used mainly for testing purposes
 func (m *F3) IsRunning() bool {
@@ -401,15 +512,30 @@ func (m *F3) GetPowerTable(ctx context.Context, ts gpbft.TipSetKey) (gpbft.Power
 		return st.ps.GetPowerTable(ctx, ts)
 	}
 	if manif := m.manifest.Load(); manif != nil {
-		return ec.WithModifiedPower(m.ec, manif.ExplicitPower, manif.IgnoreECPower).
-			GetPowerTable(ctx, ts)
+		return withManifestPower(m.ec, manif).GetPowerTable(ctx, ts)
 	}
 	return nil, manifest.ErrNoManifest
 }
 
+// withManifestPower returns the given backend with its power tables modified as
+// specified by the manifest.
+func withManifestPower(backend ec.Backend, m *manifest.Manifest) ec.Backend {
+	backend = ec.WithModifiedPower(backend, m.ExplicitPower, m.IgnoreECPower)
+	return ec.WithCommitteeTruncation(backend, m.MaxCommitteeSize)
+}
+
 func (m *F3) Progress() (instant gpbft.Instant) {
 	if st := m.state.Load(); st != nil && st.runner != nil {
 		instant = st.runner.Progress()
 	}
 	return
 }
+
+// Finalized returns the justification of the latest decision reached locally by
+// gpbft, and whether any decision has been reached since F3 last started.
+func (m *F3) Finalized() (*gpbft.Justification, bool) {
+	if st := m.state.Load(); st != nil && st.runner != nil {
+		return st.runner.Finalized()
+	}
+	return nil, false
+}
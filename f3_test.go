@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -131,6 +132,143 @@ func TestF3PauseResumeCatchup(t *testing.T) {
 	env.requireInstanceEventually(node0failInstance+3, eventualCheckTimeout, false)
 }
 
+func TestF3PauseResumeParticipation(t *testing.T) {
+	t.Parallel()
+	env := newTestEnvironment(t).withNodes(4).start()
+	env.requireInstanceEventually(1, eventualCheckTimeout, true)
+
+	// With one node paused, the rest can still agree, while the paused node neither
+	// advances nor stops receiving certificates.
+	require.NoError(t, env.nodes[3].f3.PauseParticipation(env.testCtx))
+	pausedAt := env.nodes[3].currentGpbftInstance()
+	target := env.nodes[0].currentGpbftInstance() + 3
+	env.whileAdvancingClock(func() {
+		require.Eventually(t, func() bool {
+			cert, err := env.nodes[3].f3.GetLatestCert(env.testCtx)
+			return err == nil && cert != nil && cert.GPBFTInstance >= target &&
+				env.nodes[0].currentGpbftInstance() >= target
+		}, eventualCheckTimeout, eventualCheckInterval)
+	})
+	require.Equal(t, pausedAt, env.nodes[3].currentGpbftInstance())
+
+	// With two nodes paused, there is no strong quorum and the network stalls,
+	// while the paused nodes stay subscribed and running.
+	require.NoError(t, env.nodes[2].f3.PauseParticipation(env.testCtx))
+	require.Eventually(t, func() bool {
+		before := env.nodes[0].status()
+		env.clock.Add(env.manifest.EC.Period)
+		after := env.nodes[0].status()
+		return before == after
+	}, eventualCheckTimeout, eventualCheckInterval)
+	env.requireF3RunningEventually(eventualCheckTimeout, nodeMatchers.byID(2, 3))
+
+	// Resuming restarts the paused nodes no earlier than the instance following
+	// the latest certificate.
+	require.NoError(t, env.nodes[2].f3.ResumeParticipation(env.testCtx))
+	require.NoError(t, env.nodes[3].f3.ResumeParticipation(env.testCtx))
+	cert, err := env.nodes[3].f3.GetLatestCert(env.testCtx)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, env.nodes[3].currentGpbftInstance(), cert.GPBFTInstance+1)
+	target = env.nodes[0].currentGpbftInstance() + 3
+	env.requireInstanceEventually(target, eventualCheckTimeout, true)
+}
+
+func TestF3RunLoop(t *testing.T) {
+	t.Parallel()
+	env := newTestEnvironment(t).withNodes(2).start()
+	env.requireInstanceEventually(1, eventualCheckTimeout, true)
+
+	runLoop, err := env.nodes[0].f3.RunLoop()
+	require.NoError(t, err)
+	require.NoError(t, runLoop.Context().Err())
+	select {
+	case <-runLoop.Done():
+		require.Fail(t, "run loop exited while running")
+	default:
+	}
+
+	// Stopping F3 exits the run loop without an error, and cancels its context.
+	env.nodes[0].pause()
+	select {
+	case <-runLoop.Done():
+	case <-time.After(eventualCheckTimeout):
+		require.Fail(t, "run loop did not exit once stopped")
+	}
+	require.NoError(t, runLoop.Err())
+	require.Error(t, runLoop.Context().Err())
+	_, err = env.nodes[0].f3.RunLoop()
+	require.ErrorIs(t, err, f3.ErrF3NotRunning)
+
+	// Restarting starts a new run loop.
+	env.nodes[0].resume()
+	restarted, err := env.nodes[0].f3.RunLoop()
+	require.NoError(t, err)
+	require.NotSame(t, runLoop, restarted)
+	require.NoError(t, restarted.Context().Err())
+}
+
+func TestF3FollowOnly(t *testing.T) {
+	t.Parallel()
+	env := newTestEnvironment(t).withNodes(4).initialize()
+	env.nodes[3].f3.SetFollowOnly(true)
+	env.start()
+
+	// The remaining nodes agree, and the follower keeps up via certificate exchange
+	// alone without ever joining a gpbft topic.
+	env.requireInstanceEventually(5, eventualCheckTimeout, true)
+	require.NotContains(t, env.nodes[3].ps.GetTopics(), env.manifest.PubSubTopic())
+	cert, err := env.nodes[3].f3.GetLatestCert(env.testCtx)
+	require.NoError(t, err)
+	require.NotNil(t, cert)
+
+	// Only participants reach decisions of their own.
+	decision, found := env.nodes[0].f3.Finalized()
+	require.True(t, found)
+	require.NotNil(t, decision)
+	_, found = env.nodes[3].f3.Finalized()
+	require.False(t, found)
+}
+
+func TestF3CatchUpBeforeParticipating(t *testing.T) {
+	t.Parallel()
+	env := newTestEnvironment(t).withNodes(4)
+
+	var (
+		broadcastMu        sync.Mutex
+		broadcastInstances []uint64
+	)
+	env.nodes[3].onSign = func(mb *gpbft.MessageBuilder) {
+		broadcastMu.Lock()
+		defer broadcastMu.Unlock()
+		broadcastInstances = append(broadcastInstances, mb.Payload.Instance)
+	}
+	env.initialize().connectAll()
+
+	// The remaining nodes agree on instances well before the last node starts.
+	for _, n := range env.nodes[:3] {
+		require.NoError(t, n.f3.Start(env.testCtx))
+	}
+	env.requireF3RunningEventually(eventualCheckTimeout, nodeMatchers.byID(0, 1, 2))
+	env.requireInstanceEventually(5, eventualCheckTimeout, false)
+	decided, err := env.nodes[0].f3.GetLatestCert(env.testCtx)
+	require.NoError(t, err)
+	require.NotNil(t, decided)
+
+	// The far behind node catches up via certificate exchange first, and only then
+	// participates from the instance it caught up to.
+	env.nodes[3].f3.SetCatchUpBeforeParticipating(true)
+	require.NoError(t, env.nodes[3].f3.Start(env.testCtx))
+	env.requireF3RunningEventually(eventualCheckTimeout, nodeMatchers.byID(3))
+	env.requireInstanceEventually(decided.GPBFTInstance+3, eventualCheckTimeout, true)
+
+	broadcastMu.Lock()
+	defer broadcastMu.Unlock()
+	require.NotEmpty(t, broadcastInstances)
+	for _, instance := range broadcastInstances {
+		require.Greater(t, instance, decided.GPBFTInstance)
+	}
+}
+
 func TestF3FailRecover(t *testing.T) {
 	t.Parallel()
 	env := newTestEnvironment(t).withNodes(2)
@@ -219,6 +357,39 @@ func TestF3DynamicManifest_WithRebootstrap(t *testing.T) {
 	require.Equal(t, len(pt), 4)
 }
 
+func TestF3DynamicManifest_WithNetworkNameChange(t *testing.T) {
+	t.Parallel()
+	env := newTestEnvironment(t).withNodes(2).withDynamicManifest().start()
+	env.requireInstanceEventually(3, eventualCheckTimeout, true)
+	prevTopic := env.nodes[0].f3.Manifest().PubSubTopic()
+
+	// Manifests of the test environment differ in network name only, unless
+	// changed otherwise.
+	env.updateManifest()
+	env.requireManifestPropagatedEventually(eventualCheckTimeout)
+	newTopic := env.currentManifest().PubSubTopic()
+	require.NotEqual(t, prevTopic, newTopic)
+
+	// Every node leaves the topic of the previous network for that of the new one,
+	// and keeps hearing its peers there: no instance would complete otherwise, as
+	// neither node holds a strong quorum of power on its own.
+	env.whileAdvancingClock(func() {
+		require.Eventually(t, func() bool {
+			for _, n := range env.nodes {
+				if c, err := n.f3.GetCert(env.testCtx, 3); err != nil || c == nil {
+					return false
+				}
+			}
+			return true
+		}, eventualCheckTimeout, eventualCheckInterval, "Instance 3 of the new network not finalized in time. Environment: %s", env)
+	})
+	for _, n := range env.nodes {
+		require.NotContains(t, n.ps.GetTopics(), prevTopic)
+		require.Contains(t, n.ps.GetTopics(), newTopic)
+	}
+	env.requireConsistentManifest(true)
+}
+
 func TestF3DynamicManifest_WithPauseAndRebootstrap(t *testing.T) {
 	t.Parallel()
 	env := newTestEnvironment(t).withNodes(2).withDynamicManifest().start()
@@ -364,7 +535,11 @@ type testNode struct {
 	h         host.Host
 	id        int
 	f3        *f3.F3
+	ps        *pubsub.PubSub
 	dsErrFunc func(string) error
+	// onSign, if set, observes every message the node requests to sign and
+	// broadcast. It must be set before the node is initialised.
+	onSign func(*gpbft.MessageBuilder)
 }
 
 func (n *testNode) currentGpbftInstance() uint64 {
@@ -380,6 +555,7 @@ func (n *testNode) init() *f3.F3 {
 	// We disable message signing in tests to make things faster.
 	ps, err := pubsub.NewGossipSub(n.e.testCtx, n.h, pubsub.WithMessageSignaturePolicy(pubsub.StrictNoSign))
 	require.NoError(n.e.t, err)
+	n.ps = ps
 
 	ds := ds_sync.MutexWrap(failstore.NewFailstore(datastore.NewMapDatastore(), func(s string) error {
 		if n.dsErrFunc != nil {
@@ -407,7 +583,7 @@ func (n *testNode) init() *f3.F3 {
 	require.NoError(n.e.t, err)
 
 	n.e.errgrp.Go(func() error {
-		return runMessageSubscription(n.e.testCtx, n.f3, gpbft.ActorID(n.id), n.e.signingBackend)
+		return runMessageSubscription(n.e.testCtx, n.f3, gpbft.ActorID(n.id), n.e.signingBackend, n.onSign)
 	})
 
 	return n.f3
@@ -458,11 +634,20 @@ func (n *testNode) status() testNodeStatus {
 	return current
 }
 
+// standardSigningBackend signs payloads as marshalled by the protocol rather
+// than by the fake backend, since the validation of partial messages
+// reconstructs the standard signing payload from the key of the vote value.
+type standardSigningBackend struct{ *signing.FakeBackend }
+
+func (standardSigningBackend) MarshalPayloadForSigning(nn gpbft.NetworkName, p *gpbft.Payload) []byte {
+	return p.MarshalForSigning(nn)
+}
+
 type testEnv struct {
 	t              *testing.T
 	errgrp         *errgroup.Group
 	testCtx        context.Context
-	signingBackend *signing.FakeBackend
+	signingBackend standardSigningBackend
 	nodes          []*testNode
 	ec             *consensus.FakeEC
 	manifestSender *manifest.ManifestSender
@@ -605,7 +790,7 @@ func newTestEnvironment(t *testing.T) *testEnv {
 		clock:          clk,
 		tempDir:        t.TempDir(),
 		manifest:       base,
-		signingBackend: signing.NewFakeBackend(),
+		signingBackend: standardSigningBackend{signing.NewFakeBackend()},
 	}
 
 	// Cleanup on exit.
@@ -799,13 +984,16 @@ func (e *testEnv) String() string {
 
 // TODO: This code is copy-pasta from cmd/f3/run.go, consider taking it out into a shared testing lib.
 // We could do the same to the F3 test instantiation
-func runMessageSubscription(ctx context.Context, module *f3.F3, actorID gpbft.ActorID, signer gpbft.Signer) error {
+func runMessageSubscription(ctx context.Context, module *f3.F3, actorID gpbft.ActorID, signer gpbft.Signer, onSign func(*gpbft.MessageBuilder)) error {
 	for ctx.Err() == nil {
 		select {
 		case mb, ok := <-module.MessagesToSign():
 			if !ok {
 				return nil
 			}
+			if onSign != nil {
+				onSign(mb)
+			}
 			signatureBuilder, err := mb.PrepareSigningInputs(actorID)
 			if err != nil {
 				// This isn't an error, it just means we have no power.
@@ -9,6 +9,7 @@ import (
 	"github.com/filecoin-project/go-f3/certs"
 	"github.com/filecoin-project/go-f3/chainexchange"
 	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
 	gen "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/sync/errgroup"
 )
@@ -24,7 +25,6 @@ func main() {
 			gpbft.GMessage{},
 			gpbft.SupplementalData{},
 			gpbft.Payload{},
-			gpbft.Justification{},
 			gpbft.PowerEntry{},
 			gpbft.PowerEntries{},
 		)
@@ -40,6 +40,8 @@ func main() {
 		return gen.WriteTupleEncodersToFile("../certexchange/cbor_gen.go", "certexchange",
 			certexchange.Request{},
 			certexchange.ResponseHeader{},
+			certexchange.RequestV1{},
+			certexchange.ResponseHeaderV1{},
 		)
 	})
 	eg.Go(func() error {
@@ -47,6 +49,11 @@ func main() {
 			chainexchange.Message{},
 		)
 	})
+	eg.Go(func() error {
+		return gen.WriteTupleEncodersToFile("../sim/cbor_gen.go", "sim",
+			sim.RecordedMessage{},
+		)
+	})
 	eg.Go(func() error {
 		return gen.WriteTupleEncodersToFile("../cbor_gen.go", "f3",
 			f3.PartialGMessage{},
@@ -142,6 +142,17 @@ type Aggregate interface {
 	VerifyAggregate(signerMask []int, payload, aggSig []byte) error
 }
 
+// SchemeAggregate may optionally be implemented by an Aggregate that can verify
+// aggregate signatures under schemes other than AggregateSchemeBLSG2. See
+// VerifyAggregate.
+type SchemeAggregate interface {
+	// VerifySchemeAggregate verifies an aggregate signature under the given scheme,
+	// returning an error if the scheme is not supported.
+	//
+	// Implementations must be safe for concurrent use.
+	VerifySchemeAggregate(scheme AggregateScheme, signerMask []int, payload, aggSig []byte) error
+}
+
 type Verifier interface {
 	// Verifies a signature for the given public key.
 	//
@@ -154,6 +165,19 @@ type Verifier interface {
 	Aggregate(pubKeys []PubKey) (Aggregate, error)
 }
 
+// BatchVerifier may optionally be implemented by a Verifier that can verify
+// many signatures at once more cheaply than one at a time. See
+// WithTicketBatchVerification.
+type BatchVerifier interface {
+	// BatchVerify verifies each signature over the message at the same index by
+	// the public key at the same index. It returns one error per signature, which
+	// is nil only if that signature is valid, so that an invalid signature fails
+	// no other in the batch.
+	//
+	// Implementations must be safe for concurrent use.
+	BatchVerify(pubKeys []PubKey, msgs, sigs [][]byte) []error
+}
+
 type Signatures interface {
 	SigningMarshaler
 	Verifier
@@ -169,6 +193,55 @@ type DecisionReceiver interface {
 	ReceiveDecision(decision *Justification) (time.Time, error)
 }
 
+// DecisionSink receives the chains finalized by each instance synchronously,
+// before the next instance is scheduled.
+//
+// See WithDecisionSink.
+type DecisionSink interface {
+	// Receives the chain finalized at the given instance. The call must return
+	// once the chain has been processed, or the context is cancelled. An error
+	// causes the same decision to be delivered again after the timeout, and the
+	// next instance not to be scheduled until a delivery succeeds. A call that
+	// does not return once the context is cancelled fails every delivery until it
+	// does.
+	ReceiveFinalizedChain(ctx context.Context, instance uint64, chain *ECChain) error
+}
+
+// EquivocationEvidence holds two signed messages sent by the same participant
+// for the same instance, round and phase but with differing values. Each message
+// carries the sender's signature over its payload, so the evidence can be
+// verified independently against the sender's public key.
+type EquivocationEvidence struct {
+	Sender   ActorID
+	Instance uint64
+	Round    uint64
+	Phase    Phase
+	First    *GMessage
+	Second   *GMessage
+}
+
+// EquivocationReporter is notified of every equivocation detected while
+// processing messages of an instance, at any phase. Embedders may forward the
+// evidence to a penalty subsystem.
+//
+// See WithEquivocationReporter.
+type EquivocationReporter interface {
+	// Reports evidence of equivocation. The call must not block, since it is made
+	// while processing messages.
+	ReportEquivocation(evidence *EquivocationEvidence)
+}
+
+// PhaseObserver is notified of the time spent by an instance in each phase, as
+// measured by the host clock, as the phase ends. The duration is recorded as a
+// metric regardless.
+//
+// See WithPhaseObserver.
+type PhaseObserver interface {
+	// Observes that the phase at the given instant ended after the given duration.
+	// The call must not block, since it is made while processing messages.
+	ObservePhaseDuration(instant Instant, duration time.Duration)
+}
+
 // Tracer collects trace logs that capture logical state changes.
 // The primary purpose of Tracer is to aid debugging and simulation.
 type Tracer interface {
@@ -655,112 +655,6 @@ func (t *Payload) UnmarshalCBOR(r io.Reader) (err error) {
 	return nil
 }
 
-var lengthBufJustification = []byte{131}
-
-func (t *Justification) MarshalCBOR(w io.Writer) error {
-	if t == nil {
-		_, err := w.Write(cbg.CborNull)
-		return err
-	}
-
-	cw := cbg.NewCborWriter(w)
-
-	if _, err := cw.Write(lengthBufJustification); err != nil {
-		return err
-	}
-
-	// t.Vote (gpbft.Payload) (struct)
-	if err := t.Vote.MarshalCBOR(cw); err != nil {
-		return err
-	}
-
-	// t.Signers (bitfield.BitField) (struct)
-	if err := t.Signers.MarshalCBOR(cw); err != nil {
-		return err
-	}
-
-	// t.Signature ([]uint8) (slice)
-	if len(t.Signature) > 96 {
-		return xerrors.Errorf("Byte array in field t.Signature was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Signature))); err != nil {
-		return err
-	}
-
-	if _, err := cw.Write(t.Signature); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (t *Justification) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = Justification{}
-
-	cr := cbg.NewCborReader(r)
-
-	maj, extra, err := cr.ReadHeader()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err == io.EOF {
-			err = io.ErrUnexpectedEOF
-		}
-	}()
-
-	if maj != cbg.MajArray {
-		return fmt.Errorf("cbor input should be of type array")
-	}
-
-	if extra != 3 {
-		return fmt.Errorf("cbor input had wrong number of fields")
-	}
-
-	// t.Vote (gpbft.Payload) (struct)
-
-	{
-
-		if err := t.Vote.UnmarshalCBOR(cr); err != nil {
-			return xerrors.Errorf("unmarshaling t.Vote: %w", err)
-		}
-
-	}
-	// t.Signers (bitfield.BitField) (struct)
-
-	{
-
-		if err := t.Signers.UnmarshalCBOR(cr); err != nil {
-			return xerrors.Errorf("unmarshaling t.Signers: %w", err)
-		}
-
-	}
-	// t.Signature ([]uint8) (slice)
-
-	maj, extra, err = cr.ReadHeader()
-	if err != nil {
-		return err
-	}
-
-	if extra > 96 {
-		return fmt.Errorf("t.Signature: byte array too large (%d)", extra)
-	}
-	if maj != cbg.MajByteString {
-		return fmt.Errorf("expected byte array")
-	}
-
-	if extra > 0 {
-		t.Signature = make([]uint8, extra)
-	}
-
-	if _, err := io.ReadFull(cr, t.Signature); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 var lengthBufPowerEntry = []byte{131}
 
 func (t *PowerEntry) MarshalCBOR(w io.Writer) error {
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"sync"
 
@@ -289,6 +290,20 @@ func (c *ECChain) Head() *TipSet {
 	return c.TipSets[c.Len()-1]
 }
 
+// Returns the epoch of each tipset in the chain, starting with the base.
+//
+// Returns nil if the chain is zero.
+func (c *ECChain) Epochs() []int64 {
+	if c.IsZero() {
+		return nil
+	}
+	epochs := make([]int64, c.Len())
+	for i, ts := range c.TipSets {
+		epochs[i] = ts.Epoch
+	}
+	return epochs
+}
+
 // Returns a new chain with the same base and no suffix.
 //
 // Returns nil if the chain is zero.
@@ -335,6 +350,20 @@ func (c *ECChain) Prefix(to int) *ECChain {
 	return &prefix
 }
 
+// clone returns a deep copy of the chain, sharing no memory with it.
+func (c *ECChain) clone() *ECChain {
+	if c == nil {
+		return nil
+	}
+	clone := ECChain{TipSets: make([]*TipSet, len(c.TipSets))}
+	for i, ts := range c.TipSets {
+		tsClone := *ts
+		tsClone.Key = slices.Clone(ts.Key)
+		clone.TipSets[i] = &tsClone
+	}
+	return &clone
+}
+
 // Eq Compares two ECChains for equality.
 // Note that two zero chains are considered equal. See IsZero.
 func (c *ECChain) Eq(other *ECChain) bool {
@@ -362,7 +391,7 @@ func (c *ECChain) HasBase(t *TipSet) bool {
 // Validates a chain value, returning an error if it finds any issues.
 // A chain is valid if it meets the following criteria:
 // 1) All contained tipsets are non-empty.
-// 2) All epochs are >= 0 and increasing.
+// 2) All epochs are >= 0 and strictly increasing, i.e. no two tipsets share an epoch.
 // 3) The chain is not longer than ChainMaxLen.
 // An entirely zero-valued chain itself is deemed valid. See ECChain.IsZero.
 func (c *ECChain) Validate() error {
@@ -378,7 +407,7 @@ func (c *ECChain) Validate() error {
 			return fmt.Errorf("tipset %d: %w", i, err)
 		}
 		if ts.Epoch <= lastEpoch {
-			return fmt.Errorf("chain must have increasing epochs %d <= %d", ts.Epoch, lastEpoch)
+			return fmt.Errorf("chain must have increasing epochs: tipset %d at epoch %d <= %d", i, ts.Epoch, lastEpoch)
 		}
 		lastEpoch = ts.Epoch
 	}
@@ -402,6 +431,34 @@ func (c *ECChain) HasPrefix(other *ECChain) bool {
 	return true
 }
 
+// CommonPrefix returns the longest prefix, including base, shared by the chain
+// and all the given chains. The base chain is returned if they share only the
+// base.
+//
+// Returns the zero chain if any of the chains is zero or their bases differ.
+func (c *ECChain) CommonPrefix(others ...*ECChain) *ECChain {
+	if c.IsZero() {
+		return nil
+	}
+	length := c.Len()
+	for _, other := range others {
+		if other.IsZero() {
+			return nil
+		}
+		length = min(length, other.Len())
+		for i := range length {
+			if !c.TipSets[i].Equal(other.TipSets[i]) {
+				length = i
+				break
+			}
+		}
+		if length == 0 {
+			return nil
+		}
+	}
+	return c.Prefix(length - 1)
+}
+
 func (c *ECChain) Len() int {
 	if c == nil {
 		return 0
@@ -39,6 +39,23 @@ func TestECChain(t *testing.T) {
 		// A nil chain and an empty chain are both zero and therefore should be equal.
 		require.True(t, subject.Eq(new(gpbft.ECChain)))
 	})
+	t.Run("epochs are listed from base to head", func(t *testing.T) {
+		var zero *gpbft.ECChain
+		require.Nil(t, zero.Epochs())
+		require.Nil(t, (&gpbft.ECChain{}).Epochs())
+
+		base := &gpbft.TipSet{Epoch: 7, Key: []byte("fish"), PowerTable: ptCid}
+		subject, err := gpbft.NewChain(base)
+		require.NoError(t, err)
+		require.Equal(t, []int64{7}, subject.Epochs())
+
+		subject, err = gpbft.NewChain(base,
+			&gpbft.TipSet{Epoch: 8, Key: []byte("lobster"), PowerTable: ptCid},
+			&gpbft.TipSet{Epoch: 10, Key: []byte("clam"), PowerTable: ptCid},
+		)
+		require.NoError(t, err)
+		require.Equal(t, []int64{7, 8, 10}, subject.Epochs())
+	})
 	t.Run("NewChain with zero-value base is error", func(t *testing.T) {
 		subject, err := gpbft.NewChain(zeroTipSet)
 		require.Error(t, err)
@@ -84,6 +101,30 @@ func TestECChain(t *testing.T) {
 		}, oneTipSet}}
 		require.Error(t, subject.Validate())
 	})
+	t.Run("non-increasing epochs are invalid", func(t *testing.T) {
+		at := func(epoch int64, key byte) *gpbft.TipSet {
+			return &gpbft.TipSet{Epoch: epoch, Key: []byte{key}, PowerTable: ptCid}
+		}
+		for _, test := range []struct {
+			name    string
+			tipSets []*gpbft.TipSet
+		}{
+			{name: "suffix at base epoch", tipSets: []*gpbft.TipSet{at(5, 0), at(5, 1)}},
+			{name: "suffix before base", tipSets: []*gpbft.TipSet{at(5, 0), at(4, 1), at(6, 2)}},
+			{name: "duplicate suffix epochs", tipSets: []*gpbft.TipSet{at(5, 0), at(6, 1), at(6, 2)}},
+			{name: "backwards suffix epochs", tipSets: []*gpbft.TipSet{at(5, 0), at(7, 1), at(6, 2), at(8, 3)}},
+			{name: "duplicate head", tipSets: []*gpbft.TipSet{at(5, 0), at(6, 1), at(7, 2), at(7, 2)}},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				subject := gpbft.ECChain{TipSets: test.tipSets}
+				require.ErrorContains(t, subject.Validate(), "increasing epochs")
+			})
+		}
+
+		// Gaps between epochs, e.g. due to null rounds, are valid.
+		subject := gpbft.ECChain{TipSets: []*gpbft.TipSet{at(5, 0), at(6, 1), at(9, 2), at(10, 3)}}
+		require.NoError(t, subject.Validate())
+	})
 	t.Run("too long a chain is invalid", func(t *testing.T) {
 		var subject gpbft.ECChain
 		subject.TipSets = make([]*gpbft.TipSet, gpbft.ChainMaxLen+3)
@@ -269,6 +310,94 @@ func TestECChain_Eq(t *testing.T) {
 	}
 }
 
+func TestECChain_CommonPrefix(t *testing.T) {
+	t.Parallel()
+	var (
+		pt       = gpbft.MakeCid([]byte("fish"))
+		base     = &gpbft.TipSet{Epoch: 0, Key: []byte("barreleye0"), PowerTable: pt}
+		ts1      = &gpbft.TipSet{Epoch: 1, Key: []byte("barreleye1"), PowerTable: pt}
+		ts2      = &gpbft.TipSet{Epoch: 2, Key: []byte("barreleye2"), PowerTable: pt}
+		ts3      = &gpbft.TipSet{Epoch: 3, Key: []byte("barreleye3"), PowerTable: pt}
+		ts2Fork  = &gpbft.TipSet{Epoch: 2, Key: []byte("lanternfish2"), PowerTable: pt}
+		baseFork = &gpbft.TipSet{Epoch: 0, Key: []byte("lanternfish0"), PowerTable: pt}
+		chainOf  = func(tipsets ...*gpbft.TipSet) *gpbft.ECChain {
+			return &gpbft.ECChain{TipSets: tipsets}
+		}
+	)
+	for _, tt := range []struct {
+		name   string
+		one    *gpbft.ECChain
+		others []*gpbft.ECChain
+		expect *gpbft.ECChain
+	}{
+		{
+			name: "Nil chain",
+			one:  nil,
+		},
+		{
+			name:   "Zero other",
+			one:    chainOf(base, ts1),
+			others: []*gpbft.ECChain{chainOf(base), {}},
+		},
+		{
+			name:   "No others",
+			one:    chainOf(base, ts1, ts2),
+			expect: chainOf(base, ts1, ts2),
+		},
+		{
+			name:   "Different bases",
+			one:    chainOf(base, ts1),
+			others: []*gpbft.ECChain{chainOf(baseFork, ts1)},
+		},
+		{
+			name:   "Shared base only",
+			one:    chainOf(base, ts1, ts2),
+			others: []*gpbft.ECChain{chainOf(base, ts2Fork)},
+			expect: chainOf(base),
+		},
+		{
+			name:   "Shared up to fork",
+			one:    chainOf(base, ts1, ts2, ts3),
+			others: []*gpbft.ECChain{chainOf(base, ts1, ts2Fork)},
+			expect: chainOf(base, ts1),
+		},
+		{
+			name:   "Other is prefix",
+			one:    chainOf(base, ts1, ts2, ts3),
+			others: []*gpbft.ECChain{chainOf(base, ts1, ts2)},
+			expect: chainOf(base, ts1, ts2),
+		},
+		{
+			name:   "Is prefix of other",
+			one:    chainOf(base, ts1),
+			others: []*gpbft.ECChain{chainOf(base, ts1, ts2, ts3)},
+			expect: chainOf(base, ts1),
+		},
+		{
+			name: "Shortest shared prefix among many",
+			one:  chainOf(base, ts1, ts2, ts3),
+			others: []*gpbft.ECChain{
+				chainOf(base, ts1, ts2, ts3),
+				chainOf(base, ts1, ts2Fork),
+				chainOf(base, ts1, ts2),
+			},
+			expect: chainOf(base, ts1),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := tt.one.CommonPrefix(tt.others...)
+			require.True(t, tt.expect.Eq(got), "want %s, got %s", tt.expect, got)
+			if !got.IsZero() {
+				require.True(t, tt.one.HasPrefix(got))
+				for _, other := range tt.others {
+					require.True(t, other.HasPrefix(got))
+				}
+			}
+		})
+	}
+}
+
 func TestTipSetSerialization(t *testing.T) {
 	t.Parallel()
 	var (
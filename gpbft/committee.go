@@ -9,15 +9,19 @@ var _ CommitteeProvider = (*cachedCommitteeProvider)(nil)
 
 type cachedCommitteeProvider struct {
 	delegate CommitteeProvider
+	// maxSize is the maximum number of entries in the power table of a committee,
+	// or zero if unlimited.
+	maxSize int
 
 	// mu guards access to committees.
 	mu         sync.Mutex
 	committees map[uint64]*Committee
 }
 
-func newCachedCommitteeProvider(delegate CommitteeProvider) *cachedCommitteeProvider {
+func newCachedCommitteeProvider(delegate CommitteeProvider, maxSize int) *cachedCommitteeProvider {
 	return &cachedCommitteeProvider{
 		delegate:   delegate,
+		maxSize:    maxSize,
 		committees: make(map[uint64]*Committee),
 	}
 }
@@ -33,6 +37,8 @@ func (c *cachedCommitteeProvider) GetCommittee(instance uint64) (*Committee, err
 		return nil, fmt.Errorf("instance %d: %w: %w", instance, ErrValidationNoCommittee, err)
 	case committee == nil:
 		return nil, fmt.Errorf("unexpected nil committee for instance %d", instance)
+	case c.maxSize > 0 && committee.PowerTable != nil && committee.PowerTable.Len() > c.maxSize:
+		return nil, fmt.Errorf("instance %d: %w: committee size %d exceeds maximum of %d", instance, ErrValidationNoCommittee, committee.PowerTable.Len(), c.maxSize)
 	default:
 		c.committees[instance] = committee
 		return committee, nil
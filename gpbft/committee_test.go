@@ -50,7 +50,7 @@ func TestCachedCommitteeProvider_GetCommittee(t *testing.T) {
 		}
 
 		mockDelegate = new(mockCommitteeProvider)
-		subject      = newCachedCommitteeProvider(mockDelegate)
+		subject      = newCachedCommitteeProvider(mockDelegate, 0)
 	)
 
 	mockDelegate.On("GetCommittee", instance1).Return(committeeWithValidPowerTable, nil)
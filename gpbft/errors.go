@@ -14,8 +14,29 @@ var (
 	// See: CommitteeProvider.
 	ErrValidationNoCommittee = newValidationError("no committee for instance")
 	// ErrValidationInvalid signals that a message violates the validity rules of
-	// gpbft protocol.
+	// gpbft protocol. The errors for each of the reasons a message may be invalid,
+	// e.g. ErrValidationBadSignature, are all ErrValidationInvalid according to
+	// errors.Is.
 	ErrValidationInvalid = newValidationError("message invalid")
+	// ErrValidationMalformed signals that a message is invalid because its vote is
+	// inconsistent with its phase, or its value is not a valid chain.
+	ErrValidationMalformed = newInvalidityError("malformed message")
+	// ErrValidationNoPower signals that a message is invalid because its sender
+	// has no power in the committee for its instance.
+	ErrValidationNoPower = newInvalidityError("sender has no power")
+	// ErrValidationBadTicket signals that a CONVERGE message is invalid because
+	// its ticket does not verify.
+	ErrValidationBadTicket = newInvalidityError("invalid ticket")
+	// ErrValidationBadSignature signals that a message is invalid because either
+	// its signature or the aggregate signature of its justification does not
+	// verify.
+	ErrValidationBadSignature = newInvalidityError("invalid signature")
+	// ErrValidationBadJustification signals that a message is invalid because its
+	// justification is missing, unexpected or does not justify the message.
+	ErrValidationBadJustification = newInvalidityError("invalid justification")
+	// ErrValidationWeakJustification signals that a message is invalid because its
+	// justification is not signed by a strong quorum of power.
+	ErrValidationWeakJustification = newInvalidityError("justification has insufficient power")
 	// ErrValidationWrongBase signals that a message is invalid due to having an
 	// unexpected base ECChain.
 	//
@@ -35,10 +56,30 @@ var (
 	ErrReceivedAfterTermination = errors.New("received message after terminating")
 	// ErrReceivedInternalError signals that an error has occurred during message processing.
 	ErrReceivedInternalError = errors.New("error processing message")
+	// ErrProposalWrongBase signals that the chain proposed by the host for an
+	// instance does not extend the decision of the instance before it.
+	ErrProposalWrongBase = errors.New("proposal does not extend the previous decision")
+	// ErrBroadcastValueTooLong signals that a value about to be broadcast exceeds
+	// ChainMaxLen, which indicates a bug in how the value was derived.
+	ErrBroadcastValueTooLong = errors.New("broadcast value exceeds maximum chain length")
 )
 
 // ValidationError signals that an error has occurred while validating a GMessage.
-type ValidationError struct{ message string }
+type ValidationError struct {
+	message string
+	// invalid is whether the error is a reason for which a message is
+	// ErrValidationInvalid.
+	invalid bool
+}
 
 func newValidationError(message string) ValidationError { return ValidationError{message: message} }
-func (e ValidationError) Error() string                 { return e.message }
+func newInvalidityError(message string) ValidationError {
+	return ValidationError{message: message, invalid: true}
+}
+func (e ValidationError) Error() string { return e.message }
+
+// Is reports whether the target is ErrValidationInvalid and this error is one of
+// the reasons for which a message is invalid.
+func (e ValidationError) Is(target error) bool {
+	return e.invalid && target == ErrValidationInvalid
+}
@@ -2,6 +2,7 @@ package gpbft
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -18,6 +19,12 @@ func TestValidationError_SentinelValues(t *testing.T) {
 		{name: "ErrValidationWrongBase", subject: ErrValidationWrongBase},
 		{name: "ErrValidationWrongSupplement", subject: ErrValidationWrongSupplement},
 		{name: "ErrValidationNotRelevant", subject: ErrValidationNotRelevant},
+		{name: "ErrValidationMalformed", subject: ErrValidationMalformed},
+		{name: "ErrValidationNoPower", subject: ErrValidationNoPower},
+		{name: "ErrValidationBadTicket", subject: ErrValidationBadTicket},
+		{name: "ErrValidationBadSignature", subject: ErrValidationBadSignature},
+		{name: "ErrValidationBadJustification", subject: ErrValidationBadJustification},
+		{name: "ErrValidationWeakJustification", subject: ErrValidationWeakJustification},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -26,3 +33,30 @@ func TestValidationError_SentinelValues(t *testing.T) {
 		})
 	}
 }
+
+func TestValidationError_InvalidityReasons(t *testing.T) {
+	reasons := []error{
+		ErrValidationMalformed,
+		ErrValidationNoPower,
+		ErrValidationBadTicket,
+		ErrValidationBadSignature,
+		ErrValidationBadJustification,
+		ErrValidationWeakJustification,
+	}
+	for _, reason := range reasons {
+		t.Run(reason.Error(), func(t *testing.T) {
+			wrapped := fmt.Errorf("fish: %w", reason)
+			require.ErrorIs(t, wrapped, ErrValidationInvalid)
+			require.ErrorIs(t, wrapped, reason)
+			for _, other := range reasons {
+				if other != reason {
+					require.NotErrorIs(t, wrapped, other)
+				}
+			}
+		})
+	}
+	for _, other := range []error{ErrValidationTooOld, ErrValidationNoCommittee, ErrValidationWrongBase, ErrValidationWrongSupplement, ErrValidationNotRelevant} {
+		require.NotErrorIs(t, other, ErrValidationInvalid)
+	}
+	require.NotErrorIs(t, ErrValidationInvalid, ErrValidationBadSignature)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/filecoin-project/go-bitfield"
 	rlepluslazy "github.com/filecoin-project/go-bitfield/rle"
 	"github.com/ipfs/go-cid"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
@@ -79,6 +80,10 @@ type Justification struct {
 	Signers bitfield.BitField
 	// BLS aggregate signature of signers
 	Signature []byte `cborgen:"maxlen=96"`
+	// Scheme by which Signature aggregates the signatures of signers. The zero
+	// value is the original BLS-on-G2 scheme, which is encoded without a tag for
+	// compatibility with justifications that predate the field.
+	Scheme AggregateScheme
 }
 
 type SupplementalData struct {
@@ -151,6 +156,10 @@ func (p *Payload) Eq(other *Payload) bool {
 		p.Value.Eq(other.Value)
 }
 
+// MarshalForSigning encodes the payload as the bytes signed by participants. The
+// encoding covers the supplemental data, i.e. both the commitments and the CID
+// of the next power table, so that a strong quorum of signatures over a payload
+// provably agrees on the committee of the instances that follow.
 func (p *Payload) MarshalForSigning(nn NetworkName) []byte {
 	var buf bytes.Buffer
 	buf.WriteString(DomainSeparationTag)
@@ -168,6 +177,42 @@ func (p *Payload) MarshalForSigning(nn NetworkName) []byte {
 	return buf.Bytes()
 }
 
+// CanonicalBytes returns a deterministic encoding of the justification that is
+// independent of how it was built, suitable for comparing the decisions of
+// different nodes by hash. The encoding comprises the vote, the indices of the
+// signers in ascending order and the aggregate signature, each encoded with
+// fixed width big-endian integers or length-prefixed bytes, followed by the
+// aggregate scheme unless it is the default.
+func (j *Justification) CanonicalBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, j.Vote.Instance)
+	_ = binary.Write(&buf, binary.BigEndian, j.Vote.Round)
+	_ = binary.Write(&buf, binary.BigEndian, j.Vote.Phase)
+	_, _ = buf.Write(j.Vote.SupplementalData.Commitments[:])
+	ptCid := j.Vote.SupplementalData.PowerTable.Bytes()
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(ptCid)))
+	_, _ = buf.Write(ptCid)
+	key := j.Vote.Value.Key()
+	_, _ = buf.Write(key[:])
+
+	signerCount, err := j.Signers.Count()
+	if err != nil {
+		return nil, fmt.Errorf("counting signers: %w", err)
+	}
+	_ = binary.Write(&buf, binary.BigEndian, signerCount)
+	if err := j.Signers.ForEach(func(signer uint64) error {
+		return binary.Write(&buf, binary.BigEndian, signer)
+	}); err != nil {
+		return nil, fmt.Errorf("encoding signers: %w", err)
+	}
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(j.Signature)))
+	_, _ = buf.Write(j.Signature)
+	if j.Scheme != AggregateSchemeBLSG2 {
+		_ = binary.Write(&buf, binary.BigEndian, uint64(j.Scheme))
+	}
+	return buf.Bytes(), nil
+}
+
 func (m GMessage) String() string {
 	return fmt.Sprintf("%s{%d}(%d %s)", m.Vote.Phase, m.Vote.Instance, m.Vote.Round, m.Vote.Value)
 }
@@ -190,6 +235,9 @@ type instance struct {
 	// For QUALITY, PREPARE, and COMMIT, this is the latest time (the phase can end sooner).
 	// For CONVERGE, this is the exact time (the timeout solely defines the phase end).
 	phaseTimeout time.Time
+	// phaseStartedAt is the host time at which the current phase began, used only
+	// to measure phase durations.
+	phaseStartedAt time.Time
 	// rebroadcastTimeout is the time at which the current phase should attempt to
 	// rebroadcast messages in order to further its progress.
 	//
@@ -214,7 +262,11 @@ type instance struct {
 	// strong quorum of support in the QUALITY phase or late arriving quality
 	// messages, including any chains that could possibly have been decided by
 	// another participant.
-	candidates map[ECChainKey]struct{}
+	candidates map[ECChainKey]*ECChain
+	// candidateOrder holds the candidates in the order in which they were added. It
+	// is only ever appended to, so that its prefixes can be published to the
+	// participant without copying. See putCandidate.
+	candidateOrder []*ECChain
 	// The final termination value of the instance, for communication to the participant.
 	// This field is an alternative to plumbing an optional decision value out through
 	// all the method calls, or holding a callback handle to receive it here.
@@ -224,9 +276,26 @@ type instance struct {
 	// State for each round of phases.
 	// State from prior rounds must be maintained to provide justification for values in subsequent rounds.
 	rounds map[uint64]*roundState
+	// The lowest round for which state is retained. The state of rounds below it
+	// has been discarded, as they fell outside the configured COMMIT grace window.
+	prunedRounds uint64
 	// Decision state. Collects DECIDE messages until a decision can be made,
 	// independently of protocol phases/rounds.
 	decision *quorumState
+	// The first message received from each sender at each round and phase, kept
+	// to detect equivocation.
+	firstMessages map[messageKey]*GMessage
+	// The power table CID implied by the first message received from each sender,
+	// kept to detect divergence in the committee across participants.
+	committees map[ActorID]cid.Cid
+	// The total power of senders in committees, and of those among them that
+	// imply a power table CID different from this instance's.
+	committeePower, divergentCommitteePower int64
+	// Whether committee divergence has been reported for this instance.
+	committeeDivergenceReported bool
+	// The number of distinct values tracked across all quorum states, bounded by
+	// the configured maximum.
+	trackedValues *valueBudget
 	// tracer traces logic logs for debugging and simulation purposes.
 	tracer Tracer
 }
@@ -242,12 +311,14 @@ func newInstance(
 	if input.IsZero() {
 		return nil, fmt.Errorf("input is empty")
 	}
-	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrInitialPhase))
-	metrics.currentInstance.Record(context.TODO(), int64(instanceID))
-	metrics.currentPhase.Record(context.TODO(), int64(INITIAL_PHASE))
-	metrics.currentRound.Record(context.TODO(), 0)
+	trackedValues := &valueBudget{limit: participant.maxTrackedValuesPerInstance, attrNetwork: participant.attrNetwork}
+	quorum := participant.quorum
+	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrInitialPhase, participant.attrNetwork))
+	metrics.currentInstance.Record(context.TODO(), int64(instanceID), metric.WithAttributes(participant.attrNetwork))
+	metrics.currentPhase.Record(context.TODO(), int64(INITIAL_PHASE), metric.WithAttributes(participant.attrNetwork))
+	metrics.currentRound.Record(context.TODO(), 0, metric.WithAttributes(participant.attrNetwork))
 
-	return &instance{
+	i := &instance{
 		participant:       participant,
 		input:             input,
 		powerTable:        powerTable,
@@ -261,16 +332,22 @@ func newInstance(
 		supplementalData: data,
 		proposal:         input,
 		value:            &ECChain{},
-		candidates: map[ECChainKey]struct{}{
-			input.BaseChain().Key(): {},
-		},
-		quality: newQuorumState(powerTable),
+		candidates:       map[ECChainKey]*ECChain{},
+		quality:          newQuorumState(powerTable, quorum, trackedValues),
 		rounds: map[uint64]*roundState{
-			0: newRoundState(powerTable),
+			0: newRoundState(powerTable, quorum, trackedValues),
 		},
-		decision: newQuorumState(powerTable),
-		tracer:   participant.tracer,
-	}, nil
+		decision:       newQuorumState(powerTable, quorum, trackedValues),
+		firstMessages:  map[messageKey]*GMessage{},
+		committees:     map[ActorID]cid.Cid{},
+		trackedValues:  trackedValues,
+		tracer:         participant.tracer,
+		phaseStartedAt: participant.host.Time(),
+	}
+	// The base is a candidate to begin with, without tracing it as an addition.
+	participant.candidates.Store(nil)
+	i.putCandidate(input.BaseChain())
+	return i, nil
 }
 
 type roundState struct {
@@ -279,11 +356,11 @@ type roundState struct {
 	committed *quorumState
 }
 
-func newRoundState(powerTable *PowerTable) *roundState {
+func newRoundState(powerTable *PowerTable, quorum quorumFractions, trackedValues *valueBudget) *roundState {
 	return &roundState{
 		converged: newConvergeState(),
-		prepared:  newQuorumState(powerTable),
-		committed: newQuorumState(powerTable),
+		prepared:  newQuorumState(powerTable, quorum, trackedValues),
+		committed: newQuorumState(powerTable, quorum, trackedValues),
 	}
 }
 
@@ -304,7 +381,7 @@ func (i *instance) Receive(msg *GMessage) error {
 	if stateChanged {
 		// Further process the message's round only if it may have had an effect.
 		// This avoids loading state for dropped messages (including spam).
-		i.postReceive(msg.Vote.Round)
+		return i.postReceive(msg.Vote.Round)
 	}
 	return nil
 }
@@ -338,8 +415,7 @@ func (i *instance) ReceiveMany(msgs []*GMessage) error {
 		rounds = append(rounds, r)
 	}
 	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
-	i.postReceive(rounds...)
-	return nil
+	return i.postReceive(rounds...)
 }
 
 func (i *instance) ReceiveAlarm() error {
@@ -362,7 +438,9 @@ func (i *instance) receiveOne(msg *GMessage) (bool, error) {
 			ErrReceivedWrongInstance, msg.Vote.Instance, i.current.ID)
 	}
 	// Perform validation that could not be done until the instance started.
-	// Check supplemental data matches this instance's expectation.
+	// Check supplemental data matches this instance's expectation, having first
+	// tracked the committee it implies.
+	i.detectCommitteeDivergence(msg)
 	if !msg.Vote.SupplementalData.Eq(i.supplementalData) {
 		return false, fmt.Errorf("%w: message supplement %s, expected %s",
 			ErrValidationWrongSupplement, msg.Vote.SupplementalData, i.supplementalData)
@@ -376,10 +454,12 @@ func (i *instance) receiveOne(msg *GMessage) (bool, error) {
 	if i.current.Phase == TERMINATED_PHASE {
 		return false, nil // No-op
 	}
-	// Ignore CONVERGE and PREPARE messages for prior rounds.
+	// Ignore CONVERGE and PREPARE messages for prior rounds, and COMMIT messages
+	// for rounds whose state has been discarded.
 	forPriorRound := msg.Vote.Round < i.current.Round
 	if (forPriorRound && msg.Vote.Phase == CONVERGE_PHASE) ||
-		(forPriorRound && msg.Vote.Phase == PREPARE_PHASE) {
+		(forPriorRound && msg.Vote.Phase == PREPARE_PHASE) ||
+		(msg.Vote.Round < i.prunedRounds && msg.Vote.Phase == COMMIT_PHASE) {
 		return false, nil
 	}
 
@@ -391,9 +471,13 @@ func (i *instance) receiveOne(msg *GMessage) (bool, error) {
 		return false, nil
 	}
 
-	// Load the round state and process further only valid, non-spammable messages.
-	// Equivocations are handled by the quorum state.
-	msgRound := i.getRound(msg.Vote.Round)
+	// Report equivocations before processing further. The quorum states count
+	// only the first value received from each sender, so equivocating values are
+	// otherwise ignored.
+	i.detectEquivocation(msg)
+
+	// Process further only valid, non-spammable messages, loading the round state
+	// for the phases that are tracked per round.
 	switch msg.Vote.Phase {
 	case QUALITY_PHASE:
 		// Receive each prefix of the proposal independently, which is accepted at any
@@ -405,12 +489,13 @@ func (i *instance) receiveOne(msg *GMessage) (bool, error) {
 			return true, i.updateCandidatesFromQuality()
 		}
 	case CONVERGE_PHASE:
-		if err := msgRound.converged.Receive(msg.Sender, i.powerTable, msg.Vote.Value, msg.Ticket, msg.Justification); err != nil {
+		if err := i.getRound(msg.Vote.Round).converged.Receive(msg.Sender, i.powerTable, msg.Vote.Value, msg.Ticket, msg.Justification); err != nil {
 			return false, fmt.Errorf("failed processing CONVERGE message: %w", err)
 		}
 	case PREPARE_PHASE:
-		msgRound.prepared.Receive(msg.Sender, msg.Vote.Value, msg.Signature)
+		i.getRound(msg.Vote.Round).prepared.Receive(msg.Sender, msg.Vote.Value, msg.Signature)
 	case COMMIT_PHASE:
+		msgRound := i.getRound(msg.Vote.Round)
 		msgRound.committed.Receive(msg.Sender, msg.Vote.Value, msg.Signature)
 		// The only justifications that need to be stored for future propagation are for COMMITs
 		// to non-bottom values.
@@ -423,12 +508,19 @@ func (i *instance) receiveOne(msg *GMessage) (bool, error) {
 		// *in that round*. Try to complete the COMMIT phase for the round specified by
 		// the message.
 		if i.current.Phase != DECIDE_PHASE {
+			if i.participant.skipToCommitOnWeakQuorum && !msg.Vote.Value.IsZero() {
+				if err := i.trySkipToCommit(msg.Vote.Round, msg.Vote.Value); err != nil {
+					return true, err
+				}
+			}
 			return true, i.tryCommit(msg.Vote.Round)
 		}
 	case DECIDE_PHASE:
 		i.decision.Receive(msg.Sender, msg.Vote.Value, msg.Signature)
 		if i.current.Phase != DECIDE_PHASE {
-			i.skipToDecide(msg.Vote.Value, msg.Justification)
+			if err := i.skipToDecide(msg.Vote.Value, msg.Justification); err != nil {
+				return true, err
+			}
 		}
 	default:
 		return false, fmt.Errorf("unexpected message phase %s", msg.Vote.Phase)
@@ -438,16 +530,92 @@ func (i *instance) receiveOne(msg *GMessage) (bool, error) {
 	return true, i.tryCurrentPhase()
 }
 
-func (i *instance) postReceive(roundsReceived ...uint64) {
+// messageKey identifies the messages a sender may send at most one value for.
+type messageKey struct {
+	sender ActorID
+	round  uint64
+	phase  Phase
+}
+
+// detectEquivocation reports an equivocation if a message with a differing
+// value has already been received from the same sender at the same round and
+// phase. DECIDE payloads are always at round 0, so a sender deciding in a
+// different round sends an identical payload, which is a harmless duplicate.
+func (i *instance) detectEquivocation(msg *GMessage) {
+	key := messageKey{sender: msg.Sender, round: msg.Vote.Round, phase: msg.Vote.Phase}
+	first, found := i.firstMessages[key]
+	switch {
+	case !found:
+		i.firstMessages[key] = msg
+	case !first.Vote.Value.Eq(msg.Vote.Value):
+		i.reportEquivocation(first, msg)
+	}
+}
+
+// reportEquivocation records evidence of equivocation by the sender of the
+// given messages.
+func (i *instance) reportEquivocation(first, second *GMessage) {
+	i.log("equivocation by P%d at %s: %s vs %s", second.Sender, second.Vote.Phase, first.Vote.Value, second.Vote.Value)
+	metrics.equivocationCounter.Add(context.TODO(), 1, metric.WithAttributes(attrPhase[second.Vote.Phase], i.participant.attrNetwork))
+	if i.participant.equivocationReporter != nil {
+		i.participant.equivocationReporter.ReportEquivocation(&EquivocationEvidence{
+			Sender:   second.Sender,
+			Instance: second.Vote.Instance,
+			Round:    second.Vote.Round,
+			Phase:    second.Vote.Phase,
+			First:    first,
+			Second:   second,
+		})
+	}
+}
+
+// detectCommitteeDivergence tracks the power table CID implied by the first
+// message from each sender, and reports once per instance if senders holding a
+// weak quorum of power between them disagree on it with this instance. Honest
+// participants compute different committees for the same instance only if
+// their views of EC have diverged, in which case the instance may stall without
+// ever forming a quorum. Less than a weak quorum of power may be held by faulty
+// participants alone, and so does not indicate divergence.
+func (i *instance) detectCommitteeDivergence(msg *GMessage) {
+	if i.committeeDivergenceReported {
+		return
+	}
+	if _, found := i.committees[msg.Sender]; found {
+		return
+	}
+	committee := msg.Vote.SupplementalData.PowerTable
+	i.committees[msg.Sender] = committee
+	senderPower, _ := i.powerTable.Get(msg.Sender)
+	i.committeePower += senderPower
+	if committee != i.supplementalData.PowerTable {
+		i.divergentCommitteePower += senderPower
+	}
+	if !i.participant.quorum.isWeak(i.divergentCommitteePower, i.powerTable.ScaledTotal) {
+		return
+	}
+
+	i.committeeDivergenceReported = true
+	i.log("⚠️ committee divergence: %d of %d power implies a power table other than %s",
+		i.divergentCommitteePower, i.committeePower, i.supplementalData.PowerTable)
+	log.Errorw("Senders disagree on the committee for instance, indicating likely EC divergence",
+		"instance", i.current.ID, "powerTable", i.supplementalData.PowerTable,
+		"divergentPower", i.divergentCommitteePower, "observedPower", i.committeePower, "totalPower", i.powerTable.ScaledTotal)
+	metrics.committeeDivergenceCounter.Add(context.TODO(), 1, metric.WithAttributes(i.participant.attrNetwork))
+}
+
+func (i *instance) postReceive(roundsReceived ...uint64) error {
 	// Check whether the instance should skip ahead to future round, in descending order.
 	slices.Reverse(roundsReceived)
 	for _, r := range roundsReceived {
+		if r < i.prunedRounds {
+			continue
+		}
 		round := i.getRound(r)
 		if chain, justification, skip := i.shouldSkipToRound(r, round); skip {
-			i.skipToRound(r, chain, justification)
-			return
+			return i.skipToRound(r, chain, justification)
 		}
 	}
+	return nil
 }
 
 // shouldSkipToRound determines whether to skip to round, and justification
@@ -471,6 +639,11 @@ func (i *instance) shouldSkipToRound(round uint64, state *roundState) (*ECChain,
 		// have a non-nil justification.
 		return nil, nil, false
 	}
+	if i.participant.noSway && proposal.Justification.Vote.Phase == PREPARE_PHASE && !proposal.Chain.Eq(i.proposal) {
+		// Skipping would sway to the proposal justified by PREPAREs.
+		i.log("⚠️ refusing to sway from %s to %s by skip to round %d", i.proposal, proposal.Chain, round)
+		return nil, nil, false
+	}
 	return proposal.Chain, proposal.Justification, true
 }
 
@@ -501,14 +674,14 @@ func (i *instance) beginQuality() error {
 		return fmt.Errorf("cannot transition from %s to %s", i.current.Phase, QUALITY_PHASE)
 	}
 	// Broadcast input value and wait to receive from others.
+	i.recordPhaseDuration()
 	i.current.Phase = QUALITY_PHASE
 	i.participant.progression.NotifyProgress(i.current)
 	i.phaseTimeout = i.alarmAfterSynchronyWithMulti(i.participant.qualityDeltaMulti)
 	i.resetRebroadcastParams()
-	i.broadcast(i.current.Round, QUALITY_PHASE, i.proposal, false, nil)
-	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrQualityPhase))
-	metrics.currentPhase.Record(context.TODO(), int64(QUALITY_PHASE))
-	return nil
+	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrQualityPhase, i.participant.attrNetwork))
+	metrics.currentPhase.Record(context.TODO(), int64(QUALITY_PHASE), metric.WithAttributes(i.participant.attrNetwork))
+	return i.broadcast(i.current.Round, QUALITY_PHASE, i.proposal, false, nil)
 }
 
 // Attempts to end the QUALITY phase and begin PREPARE based on current state.
@@ -531,7 +704,7 @@ func (i *instance) tryQuality() error {
 		i.addCandidatePrefixes(i.proposal)
 		i.value = i.proposal
 		i.log("adopting proposal/value %s", i.proposal)
-		i.beginPrepare(nil)
+		return i.beginPrepare(nil)
 	}
 	return nil
 }
@@ -550,15 +723,17 @@ func (i *instance) updateCandidatesFromQuality() error {
 }
 
 // beginConverge initiates CONVERGE_PHASE justified by the given justification.
-func (i *instance) beginConverge(justification *Justification) {
+func (i *instance) beginConverge(justification *Justification) error {
 	if justification.Vote.Round != i.current.Round-1 {
 		// For safety assert that the justification given belongs to the right round.
 		panic("justification for which to begin converge does not belong to expected round")
 	}
+	i.pruneRounds()
 
+	i.recordPhaseDuration()
 	i.current.Phase = CONVERGE_PHASE
 	i.participant.progression.NotifyProgress(i.current)
-	i.phaseTimeout = i.alarmAfterSynchrony()
+	i.phaseTimeout = i.alarmAfterSynchronyWithMulti(i.participant.convergeDeltaMulti)
 	i.resetRebroadcastParams()
 
 	// Notify the round's convergeState that the self participant has begun the
@@ -566,9 +741,9 @@ func (i *instance) beginConverge(justification *Justification) {
 	// broadcasts are delivered to self synchronously.
 	i.getRound(i.current.Round).converged.SetSelfValue(i.proposal, justification)
 
-	i.broadcast(i.current.Round, CONVERGE_PHASE, i.proposal, true, justification)
-	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrConvergePhase))
-	metrics.currentPhase.Record(context.TODO(), int64(CONVERGE_PHASE))
+	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrConvergePhase, i.participant.attrNetwork))
+	metrics.currentPhase.Record(context.TODO(), int64(CONVERGE_PHASE), metric.WithAttributes(i.participant.attrNetwork))
+	return i.broadcast(i.current.Round, CONVERGE_PHASE, i.proposal, true, justification)
 }
 
 // Attempts to end the CONVERGE phase and begin PREPARE based on current state.
@@ -597,12 +772,26 @@ func (i *instance) tryConverge() error {
 		return possibleDecision
 	}
 
-	winner := i.getRound(i.current.Round).converged.FindBestTicketProposal(isValidConvergeValue)
+	converged := i.getRound(i.current.Round).converged
+	winner := converged.FindBestTicketProposal(isValidConvergeValue)
 	if !winner.IsValid() {
 		return fmt.Errorf("no values at CONVERGE")
 	}
+	if math.IsInf(winner.Rank, 1) {
+		// No acceptable value was received before the timeout, e.g. over a lossy
+		// network. The participant's own proposal, set as its self value when CONVERGE
+		// began, is subject to the same check as any other and wins by default.
+		i.log("no acceptable values at CONVERGE, proceeding with own proposal %s", winner.Chain)
+	}
 
-	if !i.isCandidate(winner.Chain) {
+	if !i.isCandidate(winner.Chain) && i.participant.noSway {
+		// Stick to own proposal, for which this participant has the justification
+		// it sent at CONVERGE.
+		i.log("⚠️ refusing to sway from %s to %s by CONVERGE", i.proposal, winner.Chain)
+		if winner = converged.FindProposalFor(i.proposal); !winner.IsValid() {
+			return fmt.Errorf("no CONVERGE for own proposal")
+		}
+	} else if !i.isCandidate(winner.Chain) {
 		// if winner.Chain is not in candidate set then it means we got swayed
 		i.log("⚠️ swaying from %s to %s by CONVERGE", i.proposal, winner.Chain)
 		i.addCandidate(winner.Chain)
@@ -612,21 +801,21 @@ func (i *instance) tryConverge() error {
 
 	i.proposal = winner.Chain
 	i.value = winner.Chain
-	i.beginPrepare(winner.Justification)
-	return nil
+	return i.beginPrepare(winner.Justification)
 }
 
 // Sends this node's PREPARE message and begins the PREPARE phase.
-func (i *instance) beginPrepare(justification *Justification) {
+func (i *instance) beginPrepare(justification *Justification) error {
 	// Broadcast preparation of value and wait for everyone to respond.
+	i.recordPhaseDuration()
 	i.current.Phase = PREPARE_PHASE
 	i.participant.progression.NotifyProgress(i.current)
-	i.phaseTimeout = i.alarmAfterSynchrony()
+	i.phaseTimeout = i.alarmAfterSynchronyWithMulti(i.participant.prepareDeltaMulti)
 	i.resetRebroadcastParams()
 
-	i.broadcast(i.current.Round, PREPARE_PHASE, i.value, false, justification)
-	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrPreparePhase))
-	metrics.currentPhase.Record(context.TODO(), int64(PREPARE_PHASE))
+	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrPreparePhase, i.participant.attrNetwork))
+	metrics.currentPhase.Record(context.TODO(), int64(PREPARE_PHASE), metric.WithAttributes(i.participant.attrNetwork))
+	return i.broadcast(i.current.Round, PREPARE_PHASE, i.value, false, justification)
 }
 
 // Attempts to end the PREPARE phase and begin COMMIT based on current state.
@@ -645,23 +834,24 @@ func (i *instance) tryPrepare() error {
 	if foundQuorum {
 		i.value = i.proposal
 	} else if quorumNotPossible || phaseComplete {
+		if !phaseComplete {
+			// Exiting early, without waiting for the timeout, is a sign that the
+			// network disagrees on the proposal.
+			i.log("strong quorum for %s impossible at PREPARE", i.proposal)
+			metrics.impossibleQuorumExits.Add(context.TODO(), 1, metric.WithAttributes(attrPreparePhase, i.participant.attrNetwork))
+		}
 		i.value = &ECChain{}
 	}
 
 	if foundQuorum || quorumNotPossible || phaseComplete {
-		i.beginCommit()
+		return i.beginCommit()
 	} else if timedOut {
 		i.tryRebroadcast()
 	}
 	return nil
 }
 
-func (i *instance) beginCommit() {
-	i.current.Phase = COMMIT_PHASE
-	i.participant.progression.NotifyProgress(i.current)
-	i.phaseTimeout = i.alarmAfterSynchrony()
-	i.resetRebroadcastParams()
-
+func (i *instance) beginCommit() error {
 	// The PREPARE phase exited either with i.value == i.proposal having a strong quorum agreement,
 	// or with i.value == bottom otherwise.
 	// No justification is required for committing bottom.
@@ -674,10 +864,53 @@ func (i *instance) beginCommit() {
 			panic("beginCommit with no strong quorum for non-bottom value")
 		}
 	}
+	return i.beginCommitWithJustification(justification)
+}
 
-	i.broadcast(i.current.Round, COMMIT_PHASE, i.value, false, justification)
-	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrCommitPhase))
-	metrics.currentPhase.Record(context.TODO(), int64(COMMIT_PHASE))
+// beginCommitWithJustification sends this node's COMMIT message for the current
+// value, justified by the given justification, and begins the COMMIT phase.
+func (i *instance) beginCommitWithJustification(justification *Justification) error {
+	i.recordPhaseDuration()
+	i.current.Phase = COMMIT_PHASE
+	i.participant.progression.NotifyProgress(i.current)
+	i.phaseTimeout = i.alarmAfterSynchronyWithMulti(i.participant.commitDeltaMulti)
+	i.resetRebroadcastParams()
+
+	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrCommitPhase, i.participant.attrNetwork))
+	metrics.currentPhase.Record(context.TODO(), int64(COMMIT_PHASE), metric.WithAttributes(i.participant.attrNetwork))
+	return i.broadcast(i.current.Round, COMMIT_PHASE, i.value, false, justification)
+}
+
+// trySkipToCommit skips ahead from PREPARE to COMMIT phase of the current round if
+// a weak quorum of COMMIT messages for the current proposal has been received.
+//
+// Every COMMIT for a non-bottom value carries a justification of a strong
+// quorum of PREPAREs for that value in the same round. Such justification is
+// exactly what this participant would have built itself had it received the
+// PREPAREs, so re-using it to COMMIT to its own proposal does not change the
+// value it commits to. Observing a weak quorum of COMMITs for the value implies
+// that at least one honest participant has committed to it, and that no strong
+// quorum can be formed for any other value in the round. The DECIDE phase is
+// still reached only once a strong quorum of COMMITs is received.
+//
+// See WithSkipToCommitOnWeakQuorum.
+func (i *instance) trySkipToCommit(round uint64, value *ECChain) error {
+	if round != i.current.Round || i.current.Phase != PREPARE_PHASE || !value.Eq(i.proposal) {
+		return nil
+	}
+	committed := i.getRound(round).committed
+	if !committed.HasWeakQuorumFor(value.Key()) {
+		return nil
+	}
+	justification, found := committed.receivedJustification[value.Key()]
+	if !found {
+		// Every COMMIT for non-bottom value is stored along with its justification.
+		panic("weak quorum of COMMIT with no justification")
+	}
+	i.log("skipping to COMMIT with %s by weak quorum of COMMIT", value)
+	i.value = value
+	metrics.skipCounter.Add(context.TODO(), 1, metric.WithAttributes(attrSkipToCommit, i.participant.attrNetwork))
+	return i.beginCommitWithJustification(justification)
 }
 
 func (i *instance) tryCommit(round uint64) error {
@@ -696,13 +929,19 @@ func (i *instance) tryCommit(round uint64) error {
 		// forced to decide a value that's not its preferred chain. The participant isn't
 		// influencing that decision against their interest, just accepting it.
 		i.value = quorumValue
-		i.beginDecide(round)
+		return i.beginDecide(round)
 	case i.current.Round != round, i.current.Phase != COMMIT_PHASE:
 		// We are at a phase other than COMMIT or round does not match the current one;
 		// nothing else to do.
 	case foundStrongQuorum:
 		// There is a strong quorum for bottom, carry forward the existing proposal.
-		i.beginNextRound()
+		return i.beginNextRound()
+	case phaseComplete && i.participant.noSway && committed.receivedJustification[i.proposal.Key()] == nil:
+		// Refuse to sway to the value committed to by others. Without a COMMIT
+		// justification for its own proposal, the participant cannot begin the next
+		// round, and so waits for further COMMITs while rebroadcasting.
+		i.log("⚠️ refusing to sway from %s by COMMIT", i.proposal)
+		i.tryRebroadcast()
 	case phaseComplete:
 		// There is no strong quorum for bottom, which implies there must be a COMMIT for
 		// some other value. There can only be one such value since it must be justified
@@ -723,7 +962,7 @@ func (i *instance) tryCommit(round uint64) error {
 				break
 			}
 		}
-		i.beginNextRound()
+		return i.beginNextRound()
 	case timedOut:
 		// The phase has timed out. Attempt to re-broadcast messages.
 		i.tryRebroadcast()
@@ -731,7 +970,8 @@ func (i *instance) tryCommit(round uint64) error {
 	return nil
 }
 
-func (i *instance) beginDecide(round uint64) {
+func (i *instance) beginDecide(round uint64) error {
+	i.recordPhaseDuration()
 	i.current.Phase = DECIDE_PHASE
 	i.participant.progression.NotifyProgress(i.current)
 	i.resetRebroadcastParams()
@@ -749,25 +989,26 @@ func (i *instance) beginDecide(round uint64) {
 	// in different rounds (but for the same value).
 	// Since each node sends only one DECIDE message, they must share the same vote
 	// in order to be aggregated.
-	i.broadcast(0, DECIDE_PHASE, i.value, false, justification)
-	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrDecidePhase))
-	metrics.currentPhase.Record(context.TODO(), int64(DECIDE_PHASE))
+	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrDecidePhase, i.participant.attrNetwork))
+	metrics.currentPhase.Record(context.TODO(), int64(DECIDE_PHASE), metric.WithAttributes(i.participant.attrNetwork))
+	return i.broadcast(0, DECIDE_PHASE, i.value, false, justification)
 }
 
 // Skips immediately to the DECIDE phase and sends a DECIDE message
 // without waiting for a strong quorum of COMMITs in any round.
 // The provided justification must justify the value being decided.
-func (i *instance) skipToDecide(value *ECChain, justification *Justification) {
+func (i *instance) skipToDecide(value *ECChain, justification *Justification) error {
+	i.recordPhaseDuration()
 	i.current.Phase = DECIDE_PHASE
 	i.participant.progression.NotifyProgress(i.current)
 	i.proposal = value
 	i.value = i.proposal
 	i.resetRebroadcastParams()
-	i.broadcast(0, DECIDE_PHASE, i.value, false, justification)
 
-	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrDecidePhase))
-	metrics.currentPhase.Record(context.TODO(), int64(DECIDE_PHASE))
-	metrics.skipCounter.Add(context.TODO(), 1, metric.WithAttributes(attrSkipToDecide))
+	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrDecidePhase, i.participant.attrNetwork))
+	metrics.currentPhase.Record(context.TODO(), int64(DECIDE_PHASE), metric.WithAttributes(i.participant.attrNetwork))
+	metrics.skipCounter.Add(context.TODO(), 1, metric.WithAttributes(attrSkipToDecide, i.participant.attrNetwork))
+	return i.broadcast(0, DECIDE_PHASE, i.value, false, justification)
 }
 
 func (i *instance) tryDecide() error {
@@ -788,18 +1029,61 @@ func (i *instance) tryDecide() error {
 func (i *instance) getRound(r uint64) *roundState {
 	round, ok := i.rounds[r]
 	if !ok {
-		round = newRoundState(i.powerTable)
+		round = newRoundState(i.powerTable, i.participant.quorum, i.trackedValues)
 		i.rounds[r] = round
 	}
 	return round
 }
 
+// pruneRounds discards the messages kept to detect equivocation at rounds that
+// are no longer relevant, and the state of rounds that fall outside the
+// configured COMMIT grace window behind the current round.
+func (i *instance) pruneRounds() {
+	i.pruneFirstMessages()
+	grace := i.participant.commitGraceRounds
+	if grace == 0 || i.current.Round <= grace {
+		return
+	}
+	floor := i.current.Round - grace
+	if floor <= i.prunedRounds {
+		return
+	}
+	for r := range i.rounds {
+		if r < floor {
+			delete(i.rounds, r)
+		}
+	}
+	i.log("discarded state of rounds before %d", floor)
+	i.prunedRounds = floor
+}
+
+// pruneFirstMessages discards the messages kept to detect equivocation at
+// rounds for which no further messages are relevant, i.e. rounds before the
+// previous one, except for COMMITs within the COMMIT grace window. Messages at
+// such rounds are rejected by validation, so no equivocation can be detected
+// at them anyway. This bounds the messages kept per sender to those of the
+// rounds that are still relevant, regardless of the grace window.
+func (i *instance) pruneFirstMessages() {
+	for key := range i.firstMessages {
+		switch {
+		case key.phase == QUALITY_PHASE, key.phase == DECIDE_PHASE:
+			// Not tracked per round.
+		case key.round+1 >= i.current.Round:
+			// At the current, previous or a future round.
+		case key.phase == COMMIT_PHASE && key.round+i.participant.commitGraceRounds >= i.current.Round:
+			// A COMMIT within the grace window.
+		default:
+			delete(i.firstMessages, key)
+		}
+	}
+}
+
 var bottomECChain = &ECChain{}
 
-func (i *instance) beginNextRound() {
+func (i *instance) beginNextRound() error {
 	i.log("moving to round %d with %s", i.current.Round+1, i.proposal.String())
 	i.current.Round += 1
-	metrics.currentRound.Record(context.TODO(), int64(i.current.Round))
+	metrics.currentRound.Record(context.TODO(), int64(i.current.Round), metric.WithAttributes(i.participant.attrNetwork))
 
 	prevRound := i.getRound(i.current.Round - 1)
 	// Proposal was updated at the end of COMMIT phase to be some value for which
@@ -817,24 +1101,24 @@ func (i *instance) beginNextRound() {
 		}
 	}
 
-	i.beginConverge(justification)
+	return i.beginConverge(justification)
 }
 
 // skipToRound jumps ahead to the given round by initiating CONVERGE with the given justification.
 //
 // See shouldSkipToRound.
-func (i *instance) skipToRound(round uint64, chain *ECChain, justification *Justification) {
+func (i *instance) skipToRound(round uint64, chain *ECChain, justification *Justification) error {
 	i.log("skipping from round %d to round %d with %s", i.current.Round, round, i.proposal.String())
 	i.current.Round = round
-	metrics.currentRound.Record(context.TODO(), int64(i.current.Round))
-	metrics.skipCounter.Add(context.TODO(), 1, metric.WithAttributes(attrSkipToRound))
+	metrics.currentRound.Record(context.TODO(), int64(i.current.Round), metric.WithAttributes(i.participant.attrNetwork))
+	metrics.skipCounter.Add(context.TODO(), 1, metric.WithAttributes(attrSkipToRound, i.participant.attrNetwork))
 
 	if justification.Vote.Phase == PREPARE_PHASE {
 		i.log("⚠️ swaying from %s to %s by skip to round %d", i.proposal, chain, i.current.Round)
 		i.addCandidate(chain)
 		i.proposal = chain
 	}
-	i.beginConverge(justification)
+	return i.beginConverge(justification)
 }
 
 // Returns whether a chain is acceptable as a proposal for this instance to vote for.
@@ -853,32 +1137,64 @@ func (i *instance) addCandidatePrefixes(c *ECChain) bool {
 }
 
 func (i *instance) addCandidate(c *ECChain) bool {
-	key := c.Key()
-	if _, exists := i.candidates[key]; !exists {
-		i.candidates[key] = struct{}{}
+	if _, exists := i.candidates[c.Key()]; !exists {
+		i.putCandidate(c)
+		i.log("added candidate %s", c)
 		return true
 	}
 	return false
 }
 
+// putCandidate adds the given chain to candidates, and publishes the candidates
+// to the participant. See Participant.CurrentCandidates.
+func (i *instance) putCandidate(c *ECChain) {
+	i.candidates[c.Key()] = c
+	i.candidateOrder = append(i.candidateOrder, c)
+	// The published snapshot may be read concurrently. Capping its capacity at its
+	// length ensures that subsequent appends never write to any element visible
+	// through it, so that it need not be copied.
+	published := i.candidateOrder[:len(i.candidateOrder):len(i.candidateOrder)]
+	i.participant.candidates.Store(&published)
+}
+
 func (i *instance) terminate(decision *Justification) {
 	i.log("✅ terminated %s during round %d", i.value, i.current.Round)
+	i.recordPhaseDuration()
 	i.current.Phase = TERMINATED_PHASE
 	i.participant.progression.NotifyProgress(i.current)
 	i.value = decision.Vote.Value
 	i.terminationValue = decision
 	i.resetRebroadcastParams()
 
-	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrTerminatedPhase))
-	metrics.roundHistogram.Record(context.TODO(), int64(i.current.Round))
-	metrics.currentPhase.Record(context.TODO(), int64(TERMINATED_PHASE))
+	metrics.phaseCounter.Add(context.TODO(), 1, metric.WithAttributes(attrTerminatedPhase, i.participant.attrNetwork))
+	metrics.roundHistogram.Record(context.TODO(), int64(i.current.Round), metric.WithAttributes(i.participant.attrNetwork))
+	metrics.currentPhase.Record(context.TODO(), int64(TERMINATED_PHASE), metric.WithAttributes(i.participant.attrNetwork))
+}
+
+// recordPhaseDuration records the time spent in the current phase, which is
+// about to end, reports it to the phase observer if any, and marks the start of
+// the next one.
+func (i *instance) recordPhaseDuration() {
+	now := i.participant.host.Time()
+	duration := now.Sub(i.phaseStartedAt)
+	metrics.phaseDuration.Record(context.TODO(), duration.Seconds(), metric.WithAttributes(attrPhase[i.current.Phase], i.participant.attrNetwork))
+	if i.participant.phaseObserver != nil {
+		i.participant.phaseObserver.ObservePhaseDuration(i.current, duration)
+	}
+	i.phaseStartedAt = now
 }
 
 func (i *instance) terminated() bool {
 	return i.current.Phase == TERMINATED_PHASE
 }
 
-func (i *instance) broadcast(round uint64, phase Phase, value *ECChain, createTicket bool, justification *Justification) {
+func (i *instance) broadcast(round uint64, phase Phase, value *ECChain, createTicket bool, justification *Justification) error {
+	if value.Len() > ChainMaxLen {
+		// Catch the value before the work of signing a message that would only be
+		// rejected once encoded.
+		return fmt.Errorf("%w: %s value at round %d has length %d, exceeding maximum of %d",
+			ErrBroadcastValueTooLong, phase, round, value.Len(), ChainMaxLen)
+	}
 	p := Payload{
 		Instance:         i.current.ID,
 		Round:            round,
@@ -898,10 +1214,11 @@ func (i *instance) broadcast(round uint64, phase Phase, value *ECChain, createTi
 		mb.BeaconForTicket = i.beacon
 	}
 
-	metrics.broadcastCounter.Add(context.TODO(), 1, metric.WithAttributes(attrPhase[p.Phase]))
+	metrics.broadcastCounter.Add(context.TODO(), 1, metric.WithAttributes(attrPhase[p.Phase], i.participant.attrNetwork))
 	if err := i.participant.host.RequestBroadcast(mb); err != nil {
 		i.log("failed to request broadcast: %v", err)
 	}
+	return nil
 }
 
 // tryRebroadcast checks whether re-broadcast timeout has elapsed, and if so
@@ -994,24 +1311,15 @@ func (i *instance) rebroadcastQuietly(round uint64, phase Phase) {
 		i.log("failed to request rebroadcast %s at round %d: %v", phase, round, err)
 	} else {
 		i.log("rebroadcasting %s at round %d", phase, round)
-		metrics.reBroadcastCounter.Add(context.TODO(), 1)
+		metrics.reBroadcastCounter.Add(context.TODO(), 1, metric.WithAttributes(i.participant.attrNetwork))
 	}
 }
 
-// Sets an alarm to be delivered after a synchrony delay.
-// The delay duration increases with each round.
-// Returns the absolute time at which the alarm will fire.
-func (i *instance) alarmAfterSynchrony() time.Time {
-	return i.alarmAfterSynchronyWithMulti(1)
-}
-
 // Sets an alarm to be delivered after a synchrony delay including a multiplier on the duration.
 // The delay duration increases with each round.
 // Returns the absolute time at which the alarm will fire.
 func (i *instance) alarmAfterSynchronyWithMulti(multi float64) time.Time {
-	delta := time.Duration(float64(i.participant.delta) * multi *
-		math.Pow(i.participant.deltaBackOffExponent, float64(i.current.Round)))
-	timeout := i.participant.host.Time().Add(2 * delta)
+	timeout := i.participant.host.Time().Add(i.participant.phaseTimeout(multi, i.current.Round))
 	i.participant.host.SetAlarm(timeout)
 	return timeout
 }
@@ -1058,8 +1366,28 @@ type quorumState struct {
 	chainSupport map[ECChainKey]chainSupport
 	// Table of senders' power.
 	powerTable *PowerTable
+	// The fractions of power that form strong and weak quorums.
+	quorum quorumFractions
 	// Stores justifications received for some value.
 	receivedJustification map[ECChainKey]*Justification
+	// The budget of distinct values shared with the other quorum states of the
+	// instance, or nil if unbounded.
+	trackedValues *valueBudget
+	// Senders of values left untracked for lack of budget, and their total power.
+	untrackedSenders     map[ActorID]struct{}
+	untrackedSenderPower int64
+}
+
+// valueBudget bounds the number of distinct values tracked across the quorum
+// states of an instance.
+type valueBudget struct {
+	// The maximum number of values to track, where zero means no limit.
+	limit int
+	// The number of values tracked so far.
+	tracked int
+	// attrNetwork attributes the metrics of values left untracked to the network
+	// of the instance.
+	attrNetwork attribute.KeyValue
 }
 
 // A chain value and the total power supporting it
@@ -1071,12 +1399,15 @@ type chainSupport struct {
 }
 
 // Creates a new, empty quorum state.
-func newQuorumState(powerTable *PowerTable) *quorumState {
+func newQuorumState(powerTable *PowerTable, quorum quorumFractions, trackedValues *valueBudget) *quorumState {
 	return &quorumState{
 		senders:               map[ActorID]struct{}{},
 		chainSupport:          map[ECChainKey]chainSupport{},
 		powerTable:            powerTable,
+		quorum:                quorum,
 		receivedJustification: map[ECChainKey]*Justification{},
+		trackedValues:         trackedValues,
+		untrackedSenders:      map[ActorID]struct{}{},
 	}
 }
 
@@ -1123,6 +1454,10 @@ func (q *quorumState) receiveInner(sender ActorID, value *ECChain, power int64,
 	key := value.Key()
 	candidate, ok := q.chainSupport[key]
 	if !ok {
+		if !q.trackNewValue(sender, power) {
+			metrics.untrackedValueCounter.Add(context.TODO(), 1, metric.WithAttributes(q.trackedValues.attrNetwork))
+			return
+		}
 		candidate = chainSupport{
 			chain:           value,
 			signatures:      map[ActorID][]byte{},
@@ -1135,10 +1470,39 @@ func (q *quorumState) receiveInner(sender ActorID, value *ECChain, power int64,
 		panic("duplicate message should have been dropped")
 	}
 	candidate.signatures[sender] = signature
-	candidate.hasStrongQuorum = IsStrongQuorum(candidate.power, q.powerTable.ScaledTotal)
+	candidate.hasStrongQuorum = q.quorum.isStrong(candidate.power, q.powerTable.ScaledTotal)
 	q.chainSupport[key] = candidate
 }
 
+// trackNewValue checks whether a value not yet received from any sender can be
+// tracked, consuming the budget of tracked values if so.
+//
+// Once the budget is exhausted, a new value is still tracked for as long as any
+// untracked value could reach a strong quorum, even with an equivocating
+// adversary: an untracked value is at most supported by the senders of
+// untracked values, including this one, and by senders yet to be heard from.
+// That possible support never grows as more senders are received, so no
+// untracked value ever reaches a strong quorum.
+func (q *quorumState) trackNewValue(sender ActorID, power int64) bool {
+	if q.trackedValues == nil || q.trackedValues.limit == 0 || q.trackedValues.tracked < q.trackedValues.limit {
+		if q.trackedValues != nil {
+			q.trackedValues.tracked++
+		}
+		return true
+	}
+	untrackedPower := q.untrackedSenderPower
+	if _, found := q.untrackedSenders[sender]; !found {
+		untrackedPower += power
+	}
+	if q.couldReachStrongQuorumWith(untrackedPower, true) {
+		q.trackedValues.tracked++
+		return true
+	}
+	q.untrackedSenders[sender] = struct{}{}
+	q.untrackedSenderPower = untrackedPower
+	return false
+}
+
 // Receives and stores justification for a value from another participant.
 func (q *quorumState) ReceiveJustification(value *ECChain, justification *Justification) {
 	if justification == nil {
@@ -1163,13 +1527,13 @@ func (q *quorumState) ListAllValues() []*ECChain {
 
 // Checks whether at least one message has been senders from a strong quorum of senders.
 func (q *quorumState) ReceivedFromStrongQuorum() bool {
-	return IsStrongQuorum(q.sendersTotalPower, q.powerTable.ScaledTotal)
+	return q.quorum.isStrong(q.sendersTotalPower, q.powerTable.ScaledTotal)
 }
 
 // ReceivedFromWeakQuorum checks whether at least one message has been received
 // from a weak quorum of senders.
 func (q *quorumState) ReceivedFromWeakQuorum() bool {
-	return hasWeakQuorum(q.sendersTotalPower, q.powerTable.ScaledTotal)
+	return q.quorum.isWeak(q.sendersTotalPower, q.powerTable.ScaledTotal)
 }
 
 // Checks whether a chain has reached a strong quorum.
@@ -1178,6 +1542,13 @@ func (q *quorumState) HasStrongQuorumFor(key ECChainKey) bool {
 	return ok && supportForChain.hasStrongQuorum
 }
 
+// HasWeakQuorumFor checks whether a chain has been received from a weak quorum of
+// senders.
+func (q *quorumState) HasWeakQuorumFor(key ECChainKey) bool {
+	supportForChain, ok := q.chainSupport[key]
+	return ok && q.quorum.isWeak(supportForChain.power, q.powerTable.ScaledTotal)
+}
+
 // CouldReachStrongQuorumFor checks whether the given chain can possibly reach
 // strong quorum given the locally received messages.
 // If withAdversary is true, an additional ⅓ of total power is added to the possible support,
@@ -1188,19 +1559,27 @@ func (q *quorumState) CouldReachStrongQuorumFor(key ECChainKey, withAdversary bo
 	if supportForChain, found := q.chainSupport[key]; found {
 		supportingPower = supportForChain.power
 	}
+	return q.couldReachStrongQuorumWith(supportingPower, withAdversary)
+}
+
+// couldReachStrongQuorumWith checks whether a value with the given supporting
+// power can possibly reach strong quorum given the locally received messages.
+// See CouldReachStrongQuorumFor.
+func (q *quorumState) couldReachStrongQuorumWith(supportingPower int64, withAdversary bool) bool {
 	// A strong quorum is only feasible when the total support for the given chain,
-	// combined with the aggregate power of not yet voted participants, exceeds ⅔ of
-	// total power.
+	// combined with the aggregate power of not yet voted participants, reaches the
+	// strong quorum fraction of total power.
 	unvotedPower := q.powerTable.ScaledTotal - q.sendersTotalPower
 	adversaryPower := int64(0)
 	if withAdversary {
-		// Account for the fact that the adversary may have double-voted here.
-		adversaryPower = q.powerTable.ScaledTotal / 3
+		// Account for the fact that the adversary, holding at most the weak quorum
+		// fraction of power, may have double-voted here.
+		adversaryPower = q.quorum.weakPower(q.powerTable.ScaledTotal)
 	}
 	// We're double-counting adversary power, so we need to cap the power at the total available
 	// power.
 	possibleSupport := min(supportingPower+unvotedPower+adversaryPower, q.powerTable.ScaledTotal)
-	return IsStrongQuorum(possibleSupport, q.powerTable.ScaledTotal)
+	return q.quorum.isStrong(possibleSupport, q.powerTable.ScaledTotal)
 }
 
 type QuorumResult struct {
@@ -1256,7 +1635,7 @@ func (q *quorumState) FindStrongQuorumFor(key ECChainKey) (QuorumResult, bool) {
 		entry := q.powerTable.Entries[idx]
 		justificationPower += power
 		signatures = append(signatures, chainSupport.signatures[entry.ID])
-		if IsStrongQuorum(justificationPower, q.powerTable.ScaledTotal) {
+		if q.quorum.isStrong(justificationPower, q.powerTable.ScaledTotal) {
 			return QuorumResult{
 				Signers:    signers[:i+1],
 				Signatures: signatures,
@@ -1319,9 +1698,18 @@ type ConvergeValue struct {
 	Rank          float64
 }
 
-// IsOtherBetter returns true if the argument is better than self
+// IsOtherBetter returns true if the argument is better than self. Values of
+// exactly equal rank are ordered by their chain key, so that the best value is
+// the same regardless of the order in which values are compared.
 func (cv *ConvergeValue) IsOtherBetter(other ConvergeValue) bool {
-	return !cv.IsValid() || other.Rank < cv.Rank
+	if !cv.IsValid() || other.Rank < cv.Rank {
+		return true
+	}
+	if other.Rank > cv.Rank || other.Chain.IsZero() {
+		return false
+	}
+	selfKey, otherKey := cv.Chain.Key(), other.Chain.Key()
+	return bytes.Compare(otherKey[:], selfKey[:]) < 0
 }
 
 func (cv *ConvergeValue) IsValid() bool {
@@ -1391,10 +1779,11 @@ func (c *convergeState) Receive(sender ActorID, table *PowerTable, value *ECChai
 // nil value filter is equivalent to consider all.
 // Returns an invalid (zero-value) ConvergeValue if no converge value is found.
 func (c *convergeState) FindBestTicketProposal(filter func(ConvergeValue) bool) ConvergeValue {
-	// Non-determinism in case of matching tickets from an equivocation is ok.
-	// If the same ticket is used for two different values then either we get a decision on one of them
-	// only or we go to a new round. Eventually there is a round where the max ticket is held by a
-	// correct participant, who will not double vote.
+	// Matching tickets from an equivocation are broken by chain key, so that the
+	// result does not depend on map iteration order. If the same ticket is used for
+	// two different values then either we get a decision on one of them only or we
+	// go to a new round. Eventually there is a round where the max ticket is held by
+	// a correct participant, who will not double vote.
 	var bestValue ConvergeValue
 
 	for _, value := range c.values {
@@ -1453,10 +1842,56 @@ func IsStrongQuorum(part int64, whole int64) bool {
 	return part >= divCeil(2*whole, 3)
 }
 
-// Check whether a portion of storage power is a weak quorum of the total
-func hasWeakQuorum(part, whole int64) bool {
-	// Must be strictly greater than 1/3. Otherwise, there could be a strong quorum.
-	return part > divCeil(whole, 3)
+// quorumFractions are the fractions of total power that form strong and weak
+// quorums. A strong quorum is at least the strong fraction of the total, and a
+// weak quorum strictly more than the weak fraction, since otherwise the rest
+// could form a strong quorum. The default strong fraction of ⅔ is equivalent to
+// IsStrongQuorum.
+type quorumFractions struct {
+	strongNumerator, strongDenominator int64
+	weakNumerator, weakDenominator     int64
+}
+
+var defaultQuorumFractions = quorumFractions{
+	strongNumerator:   2,
+	strongDenominator: 3,
+	weakNumerator:     1,
+	weakDenominator:   3,
+}
+
+// Check whether a portion of storage power is a strong quorum of the total.
+func (q quorumFractions) isStrong(part, whole int64) bool {
+	return part >= divCeil(q.strongNumerator*whole, q.strongDenominator)
+}
+
+// Check whether a portion of storage power is a weak quorum of the total.
+func (q quorumFractions) isWeak(part, whole int64) bool {
+	return part > divCeil(q.weakNumerator*whole, q.weakDenominator)
+}
+
+// weakPower returns the weak quorum fraction of the total, rounded down.
+func (q quorumFractions) weakPower(whole int64) int64 {
+	return q.weakNumerator * whole / q.weakDenominator
+}
+
+// maxMinimalSigners returns the number of the least powerful participants in the
+// given power table that together hold a strong quorum, i.e. the largest number
+// of signers of a strong quorum from which no signer can be dropped. Entries of
+// power tables are ordered by decreasing power.
+func (q quorumFractions) maxMinimalSigners(powerTable *PowerTable) int {
+	var power int64
+	var signers int
+	for i := len(powerTable.ScaledPower) - 1; i >= 0; i-- {
+		if powerTable.ScaledPower[i] == 0 {
+			continue
+		}
+		power += powerTable.ScaledPower[i]
+		signers++
+		if q.isStrong(power, powerTable.ScaledTotal) {
+			break
+		}
+	}
+	return signers
 }
 
 // Tests whether lhs is equal to or greater than rhs.
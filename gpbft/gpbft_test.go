@@ -2,10 +2,13 @@ package gpbft_test
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/filecoin-project/go-f3/emulator"
 	"github.com/filecoin-project/go-f3/gpbft"
@@ -21,6 +24,26 @@ var (
 	tipSet4 = &gpbft.TipSet{Epoch: 4, Key: []byte("lobstermucher"), PowerTable: ptCid}
 )
 
+// equalPowerTable returns a power table of the given number of participants,
+// each with unit power.
+func equalPowerTable(size int) gpbft.PowerEntries {
+	powerTable := make(gpbft.PowerEntries, 0, size)
+	for id := gpbft.ActorID(0); id < gpbft.ActorID(size); id++ {
+		powerTable = append(powerTable, gpbft.PowerEntry{ID: id, Power: gpbft.NewStoragePower(1)})
+	}
+	return powerTable
+}
+
+// newTestInstanceAndDriver returns a driver with the given options, to which an
+// instance over the given power table has been added.
+func newTestInstanceAndDriver(t *testing.T, powerTable gpbft.PowerEntries, options ...gpbft.Option) (*emulator.Instance, *emulator.Driver) {
+	driver := emulator.NewDriver(t, options...)
+	instance := emulator.NewInstance(t, 0, powerTable, tipset0, tipSet1, tipSet2)
+	driver.AddInstance(instance)
+	driver.RequireNoBroadcast()
+	return instance, driver
+}
+
 func TestGPBFT_UnevenPowerDistribution(t *testing.T) {
 	t.Parallel()
 	newInstanceAndDriver := func(t *testing.T) (*emulator.Instance, *emulator.Driver) {
@@ -261,6 +284,59 @@ func TestGPBFT_WithEvenPowerDistribution(t *testing.T) {
 		driver.RequireDecision(instance.ID(), baseChain)
 	})
 
+	t.Run("Proceeds with own proposal when no acceptable CONVERGE is received", func(t *testing.T) {
+		for _, test := range []struct {
+			name     string
+			converge bool
+		}{
+			{name: "none received"},
+			{name: "only unacceptable received", converge: true},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				instance, driver := newInstanceAndDriver(t)
+				driver.RequireStartInstance(instance.ID())
+				driver.RequireQuality()
+				driver.RequireNoBroadcast()
+
+				baseChain := instance.Proposal().BaseChain()
+				alternativeProposal := baseChain.Extend([]byte("barreleye"))
+
+				driver.RequireDeliverMessage(&gpbft.GMessage{
+					Sender: 1,
+					Vote:   instance.NewQuality(alternativeProposal),
+				})
+				driver.RequireDeliverAlarm()
+				driver.RequirePrepare(baseChain)
+				driver.RequireDeliverMessage(&gpbft.GMessage{
+					Sender: 1,
+					Vote:   instance.NewPrepare(0, alternativeProposal),
+				})
+
+				driver.RequireCommitForBottom(0)
+				driver.RequireDeliverMessage(&gpbft.GMessage{
+					Sender: 1,
+					Vote:   instance.NewCommit(0, &gpbft.ECChain{}),
+				})
+
+				evidenceOfCommitForBottom := instance.NewJustification(0, gpbft.COMMIT_PHASE, &gpbft.ECChain{}, 0, 1)
+				driver.RequireConverge(1, baseChain, evidenceOfCommitForBottom)
+				if test.converge {
+					// The alternative proposal is neither a candidate nor justified by a
+					// PREPARE that could have led to its decision, so it must be ignored
+					// regardless of its ticket.
+					driver.RequireDeliverMessage(&gpbft.GMessage{
+						Sender:        1,
+						Vote:          instance.NewConverge(1, alternativeProposal),
+						Justification: evidenceOfCommitForBottom,
+						Ticket:        emulator.ValidTicket,
+					})
+				}
+				driver.RequireDeliverAlarm()
+				driver.RequirePrepareAtRound(1, baseChain, evidenceOfCommitForBottom)
+			})
+		}
+	})
+
 	t.Run("Dequeues received messages after start", func(t *testing.T) {
 		instance, driver := newInstanceAndDriver(t)
 		driver.RequireDeliverMessage(&gpbft.GMessage{
@@ -282,7 +358,7 @@ func TestGPBFT_WithEvenPowerDistribution(t *testing.T) {
 	t.Run("Queues future instance messages during current instance", func(t *testing.T) {
 		instance, driver := newInstanceAndDriver(t)
 		futureInstance := emulator.NewInstance(t,
-			8,
+			3,
 			gpbft.PowerEntries{
 				gpbft.PowerEntry{
 					ID:    0,
@@ -740,6 +816,30 @@ func TestGPBFT_SoloParticipant(t *testing.T) {
 		driver.RequireDecision(instance.ID(), instance.Proposal())
 	})
 
+	t.Run("Reports progress through each phase", func(t *testing.T) {
+		instance, driver := newInstanceAndDriver(t)
+		require.Equal(t, gpbft.Instant{}, driver.Progress())
+		driver.RequireStartInstance(instance.ID())
+		require.Equal(t, gpbft.Instant{ID: instance.ID(), Phase: gpbft.QUALITY_PHASE}, driver.Progress())
+		driver.RequireQuality()
+		require.Equal(t, gpbft.Instant{ID: instance.ID(), Phase: gpbft.PREPARE_PHASE}, driver.Progress())
+		driver.RequirePrepare(instance.Proposal())
+		require.Equal(t, gpbft.Instant{ID: instance.ID(), Phase: gpbft.COMMIT_PHASE}, driver.Progress())
+		driver.RequireCommit(
+			0,
+			instance.Proposal(),
+			instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0),
+		)
+		require.Equal(t, gpbft.Instant{ID: instance.ID(), Phase: gpbft.DECIDE_PHASE}, driver.Progress())
+		driver.RequireDecide(
+			instance.Proposal(),
+			instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0),
+		)
+		// Once terminated, the participant moves straight on to the next instance.
+		require.Equal(t, gpbft.Instant{ID: instance.ID() + 1, Phase: gpbft.INITIAL_PHASE}, driver.Progress())
+		driver.RequireDecision(instance.ID(), instance.Proposal())
+	})
+
 	t.Run("Decides base on QUALITY timeout", func(t *testing.T) {
 		instance, driver := newInstanceAndDriver(t)
 		baseChain := instance.Proposal().BaseChain()
@@ -760,6 +860,147 @@ func TestGPBFT_SoloParticipant(t *testing.T) {
 	})
 }
 
+func TestGPBFT_Finalized(t *testing.T) {
+	driver := emulator.NewDriver(t)
+	instance := emulator.NewInstance(t,
+		0,
+		gpbft.PowerEntries{
+			gpbft.PowerEntry{
+				ID:    0,
+				Power: gpbft.NewStoragePower(1),
+			},
+		},
+		tipset0, tipSet1, tipSet2,
+	)
+	driver.AddInstance(instance)
+	driver.RequireNoBroadcast()
+
+	requireNotFinalized := func() {
+		decision, found := driver.Finalized()
+		require.False(t, found)
+		require.Nil(t, decision)
+	}
+	requireNotFinalized()
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+	driver.RequirePrepare(instance.Proposal())
+	driver.RequireCommit(
+		0,
+		instance.Proposal(),
+		instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0),
+	)
+	requireNotFinalized()
+	driver.RequireDecide(
+		instance.Proposal(),
+		instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0),
+	)
+	driver.RequireDecision(instance.ID(), instance.Proposal())
+
+	decision, found := driver.Finalized()
+	require.True(t, found)
+	require.Equal(t, instance.GetDecision(), decision)
+	require.Equal(t, instance.ID(), decision.Vote.Instance)
+	require.True(t, instance.Proposal().Eq(decision.Vote.Value))
+}
+
+// failingDecisionSink fails to receive any finalized chain.
+type failingDecisionSink struct{}
+
+func (failingDecisionSink) ReceiveFinalizedChain(context.Context, uint64, *gpbft.ECChain) error {
+	return errors.New("sink unavailable")
+}
+
+func TestGPBFT_NotFinalizedUntilDelivered(t *testing.T) {
+	driver := emulator.NewDriver(t, gpbft.WithDecisionSink(failingDecisionSink{}, time.Second))
+	instance := emulator.NewInstance(t,
+		0,
+		gpbft.PowerEntries{
+			gpbft.PowerEntry{
+				ID:    0,
+				Power: gpbft.NewStoragePower(1),
+			},
+		},
+		tipset0, tipSet1, tipSet2,
+	)
+	driver.AddInstance(instance)
+	driver.RequireNoBroadcast()
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+	driver.RequirePrepare(instance.Proposal())
+	driver.RequireCommit(
+		0,
+		instance.Proposal(),
+		instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0),
+	)
+	driver.RequireDecide(
+		instance.Proposal(),
+		instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0),
+	)
+
+	// The decision was reached but never delivered to the sink, and so neither to
+	// the host.
+	require.Nil(t, instance.GetDecision())
+	decision, found := driver.Finalized()
+	require.False(t, found)
+	require.Nil(t, decision)
+	require.Equal(t, instance.ID(), driver.Progress().ID)
+}
+
+// flakyDecisionSink fails to receive the first finalized chain delivered to it,
+// and records the instances of those it receives thereafter.
+type flakyDecisionSink struct {
+	failed   bool
+	received []uint64
+}
+
+func (s *flakyDecisionSink) ReceiveFinalizedChain(_ context.Context, instance uint64, _ *gpbft.ECChain) error {
+	if !s.failed {
+		s.failed = true
+		return errors.New("sink unavailable")
+	}
+	s.received = append(s.received, instance)
+	return nil
+}
+
+func TestGPBFT_RetriesFailedDecisionDelivery(t *testing.T) {
+	sink := &flakyDecisionSink{}
+	driver := emulator.NewDriver(t, gpbft.WithDecisionSink(sink, time.Second))
+	instance := emulator.NewInstance(t,
+		0,
+		gpbft.PowerEntries{
+			gpbft.PowerEntry{
+				ID:    0,
+				Power: gpbft.NewStoragePower(1),
+			},
+		},
+		tipset0, tipSet1, tipSet2,
+	)
+	driver.AddInstance(instance)
+	driver.RequireNoBroadcast()
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+	driver.RequirePrepare(instance.Proposal())
+	driver.RequireCommit(
+		0,
+		instance.Proposal(),
+		instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0),
+	)
+	driver.RequireDecide(
+		instance.Proposal(),
+		instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0),
+	)
+	require.True(t, sink.failed)
+	require.Nil(t, instance.GetDecision())
+
+	// The failed delivery is retried upon the next alarm, rather than the instance
+	// being restarted.
+	driver.RequireDeliverAlarm()
+	require.Equal(t, []uint64{instance.ID()}, sink.received)
+	driver.RequireDecision(instance.ID(), instance.Proposal())
+	driver.RequireNoBroadcast()
+	require.Equal(t, instance.ID()+1, driver.Progress().ID)
+}
+
 func TestGPBFT_SkipsToRound(t *testing.T) {
 	newInstanceAndDriver := func(t *testing.T) (*emulator.Instance, *emulator.Driver) {
 		driver := emulator.NewDriver(t)
@@ -900,6 +1141,134 @@ func TestGPBFT_SkipsToRound(t *testing.T) {
 	})
 }
 
+func TestGPBFT_CommitGraceRounds(t *testing.T) {
+	const graceRounds = 2
+	newInstanceAndDriverAtRound := func(t *testing.T, round uint64) (*emulator.Instance, *emulator.Driver) {
+		driver := emulator.NewDriver(t, gpbft.WithCommitGraceRounds(graceRounds))
+		instance := emulator.NewInstance(t,
+			0,
+			gpbft.PowerEntries{
+				gpbft.PowerEntry{
+					ID:    0,
+					Power: gpbft.NewStoragePower(1),
+				},
+				gpbft.PowerEntry{
+					ID:    1,
+					Power: gpbft.NewStoragePower(4),
+				},
+			},
+			tipset0, tipSet1, tipSet2,
+		)
+		driver.AddInstance(instance)
+		driver.RequireNoBroadcast()
+		driver.RequireStartInstance(instance.ID())
+		driver.RequireQuality()
+		driver.RequireNoBroadcast()
+
+		// Skip ahead to the given round, beyond the grace window of round zero.
+		futureRoundProposal := instance.Proposal().Extend(tipSet4.Key)
+		driver.RequireDeliverMessage(&gpbft.GMessage{
+			Sender:        1,
+			Vote:          instance.NewPrepare(round, futureRoundProposal),
+			Justification: instance.NewJustification(round-1, gpbft.PREPARE_PHASE, futureRoundProposal, 1),
+		})
+		driver.RequireDeliverMessage(&gpbft.GMessage{
+			Sender:        1,
+			Vote:          instance.NewConverge(round, futureRoundProposal),
+			Justification: instance.NewJustification(round-1, gpbft.PREPARE_PHASE, futureRoundProposal, 1),
+			Ticket:        emulator.ValidTicket,
+		})
+		driver.RequireConverge(round, futureRoundProposal, instance.NewJustification(round-1, gpbft.PREPARE_PHASE, futureRoundProposal, 1))
+		return instance, driver
+	}
+
+	t.Run("Decides on late COMMIT within grace window", func(t *testing.T) {
+		instance, driver := newInstanceAndDriverAtRound(t, 5)
+		lateRound := uint64(5 - graceRounds)
+		driver.RequireDeliverMessage(&gpbft.GMessage{
+			Sender:        1,
+			Vote:          instance.NewCommit(lateRound, instance.Proposal()),
+			Justification: instance.NewJustification(lateRound, gpbft.PREPARE_PHASE, instance.Proposal(), 1),
+		})
+		evidence := instance.NewJustification(lateRound, gpbft.COMMIT_PHASE, instance.Proposal(), 1)
+		driver.RequireDecide(instance.Proposal(), evidence)
+		driver.RequireDeliverMessage(&gpbft.GMessage{
+			Sender:        1,
+			Vote:          instance.NewDecide(0, instance.Proposal()),
+			Justification: evidence,
+		})
+		driver.RequireDecision(instance.ID(), instance.Proposal())
+	})
+
+	t.Run("Ignores COMMIT for round beyond grace window", func(t *testing.T) {
+		instance, driver := newInstanceAndDriverAtRound(t, 5)
+		// A strong quorum of COMMITs at a round before the window would decide, had
+		// its state been retained.
+		staleRound := uint64(5 - graceRounds - 1)
+		driver.RequireErrOnDeliverMessage(&gpbft.GMessage{
+			Sender:        1,
+			Vote:          instance.NewCommit(staleRound, instance.Proposal()),
+			Justification: instance.NewJustification(staleRound, gpbft.PREPARE_PHASE, instance.Proposal(), 1),
+		}, gpbft.ErrValidationNotRelevant, "")
+		driver.RequireNoBroadcast()
+		require.Equal(t, gpbft.Instant{ID: instance.ID(), Round: 5, Phase: gpbft.CONVERGE_PHASE}, driver.Progress())
+	})
+}
+
+func TestGPBFT_SkipToCommitOnWeakQuorum(t *testing.T) {
+	newInstanceAndDriverAtPrepare := func(t *testing.T, o ...gpbft.Option) (*emulator.Instance, *emulator.Driver) {
+		driver := emulator.NewDriver(t, o...)
+		instance := emulator.NewInstance(t,
+			0,
+			gpbft.PowerEntries{
+				gpbft.PowerEntry{ID: 0, Power: gpbft.NewStoragePower(1)},
+				gpbft.PowerEntry{ID: 1, Power: gpbft.NewStoragePower(1)},
+				gpbft.PowerEntry{ID: 2, Power: gpbft.NewStoragePower(1)},
+				gpbft.PowerEntry{ID: 3, Power: gpbft.NewStoragePower(1)},
+			},
+			tipset0, tipSet1, tipSet2,
+		)
+		driver.AddInstance(instance)
+		driver.RequireStartInstance(instance.ID())
+		driver.RequireQuality()
+		for _, sender := range []gpbft.ActorID{1, 2} {
+			driver.RequireDeliverMessage(&gpbft.GMessage{
+				Sender: sender,
+				Vote:   instance.NewQuality(instance.Proposal()),
+			})
+		}
+		driver.RequirePrepare(instance.Proposal())
+		return instance, driver
+	}
+	// The PREPAREs justifying the COMMITs include one from participant 3, which
+	// the local participant never receives. A COMMIT carrying this justification
+	// can therefore only have been built from the COMMITs received.
+	deliverWeakQuorumOfCommits := func(instance *emulator.Instance, driver *emulator.Driver) *gpbft.Justification {
+		evidenceOfPrepare := instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 1, 2, 3)
+		for _, sender := range []gpbft.ActorID{1, 2} {
+			driver.RequireDeliverMessage(&gpbft.GMessage{
+				Sender:        sender,
+				Vote:          instance.NewCommit(0, instance.Proposal()),
+				Justification: evidenceOfPrepare,
+			})
+		}
+		return evidenceOfPrepare
+	}
+
+	t.Run("Skips to COMMIT on weak quorum of COMMIT", func(t *testing.T) {
+		instance, driver := newInstanceAndDriverAtPrepare(t, gpbft.WithSkipToCommitOnWeakQuorum(true))
+		evidenceOfPrepare := deliverWeakQuorumOfCommits(instance, driver)
+		driver.RequireCommit(0, instance.Proposal(), evidenceOfPrepare)
+	})
+
+	t.Run("Waits for PREPARE quorum when disabled", func(t *testing.T) {
+		instance, driver := newInstanceAndDriverAtPrepare(t)
+		deliverWeakQuorumOfCommits(instance, driver)
+		driver.RequireNoBroadcast()
+		require.Equal(t, gpbft.Instant{ID: instance.ID(), Round: 0, Phase: gpbft.PREPARE_PHASE}, driver.Progress())
+	})
+}
+
 func TestGPBFT_Equivocations(t *testing.T) {
 	t.Parallel()
 	newInstanceAndDriver := func(t *testing.T) (*emulator.Instance, *emulator.Driver) {
@@ -1087,6 +1456,48 @@ func TestGPBFT_Equivocations(t *testing.T) {
 	})
 }
 
+var _ gpbft.EquivocationReporter = (*recordingEquivocationReporter)(nil)
+
+type recordingEquivocationReporter struct {
+	evidence []*gpbft.EquivocationEvidence
+}
+
+func (r *recordingEquivocationReporter) ReportEquivocation(evidence *gpbft.EquivocationEvidence) {
+	r.evidence = append(r.evidence, evidence)
+}
+
+func TestGPBFT_DecideEquivocation(t *testing.T) {
+	t.Parallel()
+	sink := &recordingEquivocationReporter{}
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(4), gpbft.WithEquivocationReporter(sink))
+
+	wantDecision := instance.Proposal()
+	equivocation := instance.Proposal().Extend(tipSet3.Key)
+	newDecide := func(sender gpbft.ActorID, value *gpbft.ECChain) *gpbft.GMessage {
+		return &gpbft.GMessage{
+			Sender:        sender,
+			Vote:          instance.NewDecide(0, value),
+			Justification: instance.NewJustification(0, gpbft.COMMIT_PHASE, value, 1, 2, 3),
+		}
+	}
+
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireDeliverMessage(newDecide(3, wantDecision))
+	driver.RequireDeliverMessage(newDecide(3, equivocation))
+	// An identical DECIDE from the same sender is a harmless duplicate.
+	driver.RequireDeliverMessage(newDecide(3, wantDecision))
+	require.Len(t, sink.evidence, 1)
+	require.Equal(t, gpbft.ActorID(3), sink.evidence[0].First.Sender)
+	require.Equal(t, wantDecision, sink.evidence[0].First.Vote.Value)
+	require.Equal(t, equivocation, sink.evidence[0].Second.Vote.Value)
+
+	// The honest quorum still decides, with the equivocator counted once.
+	driver.RequireDeliverMessage(newDecide(1, wantDecision))
+	driver.RequireDeliverMessage(newDecide(2, wantDecision))
+	driver.RequireDecision(instance.ID(), wantDecision)
+	require.Len(t, sink.evidence, 1)
+}
+
 func TestGPBFT_ImpossibleQuorum(t *testing.T) {
 	t.Parallel()
 	newInstanceAndDriver := func(t *testing.T) (*emulator.Instance, *emulator.Driver) {
@@ -1795,3 +2206,438 @@ func TestSupplementalDataSerialization(t *testing.T) {
 		req.ErrorContains(err, "32 bytes")
 	})
 }
+
+func TestGPBFT_LateCommitRelay(t *testing.T) {
+	t.Parallel()
+	newInstanceAndDriver := func(t *testing.T, relay bool) (*emulator.Instance, *emulator.Driver) {
+		driver := emulator.NewDriver(t, gpbft.WithLateCommitRelay(relay))
+		instance := emulator.NewInstance(t,
+			0,
+			gpbft.PowerEntries{
+				gpbft.PowerEntry{
+					ID:    0,
+					Power: gpbft.NewStoragePower(1),
+				},
+				gpbft.PowerEntry{
+					ID: 1,
+					// Set majority power.
+					Power: gpbft.NewStoragePower(4),
+				},
+				gpbft.PowerEntry{
+					ID:    2,
+					Power: gpbft.NewStoragePower(1),
+				},
+			},
+			tipset0, tipSet1, tipSet2,
+		)
+		driver.AddInstance(instance)
+		driver.RequireNoBroadcast()
+		driver.RequireStartInstance(instance.ID())
+		driver.RequireQuality()
+		return instance, driver
+	}
+	decide := func(driver *emulator.Driver, instance *emulator.Instance) *gpbft.ECChain {
+		wantDecision := instance.Proposal()
+		driver.RequireDeliverMessage(&gpbft.GMessage{
+			Sender:        1,
+			Vote:          instance.NewDecide(0, wantDecision),
+			Justification: instance.NewJustification(0, gpbft.COMMIT_PHASE, wantDecision, 1),
+		})
+		driver.RequireDecision(instance.ID(), wantDecision)
+		return wantDecision
+	}
+	lateCommit := func(sender gpbft.ActorID, instance *emulator.Instance, value *gpbft.ECChain) *gpbft.GMessage {
+		return &gpbft.GMessage{
+			Sender:        sender,
+			Vote:          instance.NewCommit(0, value),
+			Justification: instance.NewJustification(0, gpbft.PREPARE_PHASE, value, 1),
+		}
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		instance, driver := newInstanceAndDriver(t, true)
+		wantDecision := decide(driver, instance)
+		driver.RequireDecide(wantDecision, instance.NewJustification(0, gpbft.COMMIT_PHASE, wantDecision, 1))
+
+		// A late COMMIT is accepted and answered with a rebroadcast of the DECIDE.
+		driver.RequireDeliverMessage(lateCommit(1, instance, wantDecision))
+		driver.RequirePeekAtLastVote(gpbft.DECIDE_PHASE, 0, wantDecision)
+		driver.RequireDecide(wantDecision, instance.NewJustification(0, gpbft.COMMIT_PHASE, wantDecision, 1))
+
+		// Further late COMMITs from the same sender are accepted but not answered.
+		driver.RequireDeliverMessage(&gpbft.GMessage{
+			Sender: 1,
+			Vote:   instance.NewCommit(1, &gpbft.ECChain{}),
+		})
+		driver.RequireNoBroadcast()
+
+		// Nor are late COMMITs from other senders, since the single rebroadcast
+		// reaches them too.
+		driver.RequireDeliverMessage(lateCommit(2, instance, wantDecision))
+		driver.RequireDeliverMessage(lateCommit(0, instance, wantDecision))
+		driver.RequireNoBroadcast()
+	})
+	t.Run("disabled", func(t *testing.T) {
+		instance, driver := newInstanceAndDriver(t, false)
+		wantDecision := decide(driver, instance)
+		driver.RequireDecide(wantDecision, instance.NewJustification(0, gpbft.COMMIT_PHASE, wantDecision, 1))
+
+		driver.RequireErrOnDeliverMessage(lateCommit(1, instance, wantDecision), gpbft.ErrValidationTooOld, "")
+		driver.RequireNoBroadcast()
+	})
+}
+
+func TestGPBFT_MaxCommitteeSize(t *testing.T) {
+	t.Parallel()
+	newInstanceAndDriver := func(t *testing.T, maxSize int) (*emulator.Instance, *emulator.Driver) {
+		return newTestInstanceAndDriver(t, equalPowerTable(4), gpbft.WithMaxCommitteeSize(maxSize))
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		instance, driver := newInstanceAndDriver(t, 4)
+		driver.RequireStartInstance(instance.ID())
+		driver.RequireQuality()
+	})
+	t.Run("exceeding limit", func(t *testing.T) {
+		instance, driver := newInstanceAndDriver(t, 3)
+		err := driver.StartInstance(instance.ID())
+		require.ErrorIs(t, err, gpbft.ErrValidationNoCommittee)
+		require.ErrorContains(t, err, "exceeds maximum of 3")
+		driver.RequireNoBroadcast()
+	})
+}
+
+func TestGPBFT_StrongQuorumFraction(t *testing.T) {
+	t.Parallel()
+	// Under a strong quorum of ⅘, all four participants of equal power are
+	// needed where three would otherwise suffice.
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(4), gpbft.WithStrongQuorum(4, 5))
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+
+	deliverFromOthers := func(vote func(sender gpbft.ActorID) *gpbft.GMessage) {
+		for sender := gpbft.ActorID(1); sender < 3; sender++ {
+			driver.RequireDeliverMessage(vote(sender))
+		}
+		driver.RequireNoBroadcast()
+		driver.RequireDeliverMessage(vote(3))
+	}
+	deliverFromOthers(func(sender gpbft.ActorID) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: sender, Vote: instance.NewQuality(instance.Proposal())}
+	})
+	driver.RequirePrepare(instance.Proposal())
+
+	deliverFromOthers(func(sender gpbft.ActorID) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: sender, Vote: instance.NewPrepare(0, instance.Proposal())}
+	})
+	evidenceOfPrepare := instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0, 1, 2, 3)
+	driver.RequireCommit(0, instance.Proposal(), evidenceOfPrepare)
+
+	// Evidence short of the raised strong quorum is rejected.
+	driver.RequireErrOnDeliverMessage(&gpbft.GMessage{
+		Sender:        1,
+		Vote:          instance.NewCommit(0, instance.Proposal()),
+		Justification: instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0, 1, 2),
+	}, gpbft.ErrValidationWeakJustification, "insufficient power")
+
+	deliverFromOthers(func(sender gpbft.ActorID) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: sender, Vote: instance.NewCommit(0, instance.Proposal()), Justification: evidenceOfPrepare}
+	})
+	evidenceOfCommit := instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0, 1, 2, 3)
+	driver.RequireDecide(instance.Proposal(), evidenceOfCommit)
+
+	deliverFromOthers(func(sender gpbft.ActorID) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: sender, Vote: instance.NewDecide(0, instance.Proposal()), Justification: evidenceOfCommit}
+	})
+	driver.RequireDecision(instance.ID(), instance.Proposal())
+}
+
+func TestGPBFT_EquivocationReporter(t *testing.T) {
+	t.Parallel()
+	reporter := &recordingEquivocationReporter{}
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(2), gpbft.WithEquivocationReporter(reporter))
+
+	equivocations := []*gpbft.ECChain{
+		instance.Proposal().Extend(tipSet3.Key),
+		instance.Proposal().Extend(tipSet4.Key),
+	}
+	// The adversary sends the proposal followed by each equivocation at every
+	// phase, all of which are delivered before the subject progresses.
+	equivocate := func(newMessage func(value *gpbft.ECChain) *gpbft.GMessage) {
+		driver.RequireDeliverMessage(newMessage(instance.Proposal()))
+		for _, equivocation := range equivocations {
+			driver.RequireDeliverMessage(newMessage(equivocation))
+		}
+	}
+
+	driver.RequireStartInstance(instance.ID())
+	equivocate(func(value *gpbft.ECChain) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: 1, Vote: instance.NewQuality(value)}
+	})
+	driver.RequireQuality()
+	equivocate(func(value *gpbft.ECChain) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: 1, Vote: instance.NewPrepare(0, value)}
+	})
+	driver.RequirePrepare(instance.Proposal())
+	equivocate(func(value *gpbft.ECChain) *gpbft.GMessage {
+		return &gpbft.GMessage{
+			Sender:        1,
+			Vote:          instance.NewCommit(0, value),
+			Justification: instance.NewJustification(0, gpbft.PREPARE_PHASE, value, 0, 1),
+		}
+	})
+	evidenceOfCommit := instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0, 1)
+	driver.RequireCommit(0, instance.Proposal(), instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0, 1))
+	equivocate(func(value *gpbft.ECChain) *gpbft.GMessage {
+		return &gpbft.GMessage{
+			Sender:        1,
+			Vote:          instance.NewDecide(0, value),
+			Justification: instance.NewJustification(0, gpbft.COMMIT_PHASE, value, 0, 1),
+		}
+	})
+	driver.RequireDecide(instance.Proposal(), evidenceOfCommit)
+	driver.RequireDecision(instance.ID(), instance.Proposal())
+
+	signing := emulator.AdhocSigning()
+	requireSignedBySender := func(msg *gpbft.GMessage) {
+		payload := signing.MarshalPayloadForSigning(emulator.NetworkName, &msg.Vote)
+		pubKey := instance.PowerTable().Entries[instance.PowerTable().Lookup[msg.Sender]].PubKey
+		require.NoError(t, signing.Verify(pubKey, payload, msg.Signature))
+	}
+	phases := []gpbft.Phase{gpbft.QUALITY_PHASE, gpbft.PREPARE_PHASE, gpbft.COMMIT_PHASE, gpbft.DECIDE_PHASE}
+	require.Len(t, reporter.evidence, len(phases)*len(equivocations))
+	for i, evidence := range reporter.evidence {
+		wantPhase := phases[i/len(equivocations)]
+		require.Equal(t, gpbft.ActorID(1), evidence.Sender)
+		require.Equal(t, instance.ID(), evidence.Instance)
+		require.Equal(t, uint64(0), evidence.Round)
+		require.Equal(t, wantPhase, evidence.Phase)
+		for _, msg := range []*gpbft.GMessage{evidence.First, evidence.Second} {
+			require.Equal(t, evidence.Sender, msg.Sender)
+			require.Equal(t, evidence.Instance, msg.Vote.Instance)
+			require.Equal(t, evidence.Round, msg.Vote.Round)
+			require.Equal(t, evidence.Phase, msg.Vote.Phase)
+			requireSignedBySender(msg)
+		}
+		require.True(t, instance.Proposal().Eq(evidence.First.Vote.Value))
+		require.True(t, equivocations[i%len(equivocations)].Eq(evidence.Second.Vote.Value))
+	}
+}
+
+func TestGPBFT_RedeliveredMessagesAreIdempotent(t *testing.T) {
+	t.Parallel()
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(4))
+
+	var delivered []*gpbft.GMessage
+	deliverFromOthers := func(vote func(sender gpbft.ActorID) *gpbft.GMessage) {
+		for sender := gpbft.ActorID(1); sender <= 2; sender++ {
+			msg := vote(sender)
+			driver.RequireDeliverMessage(msg)
+			delivered = append(delivered, msg)
+		}
+	}
+	// Every message delivered so far is delivered again, as it would be when
+	// replayed from the journal after a restart, and then received once more from
+	// the network.
+	redeliver := func() {
+		for _, msg := range delivered {
+			driver.RequireDeliverMessage(msg)
+		}
+		driver.RequireNoBroadcast()
+	}
+
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+	deliverFromOthers(func(sender gpbft.ActorID) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: sender, Vote: instance.NewQuality(instance.Proposal())}
+	})
+	driver.RequirePrepare(instance.Proposal())
+	redeliver()
+
+	deliverFromOthers(func(sender gpbft.ActorID) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: sender, Vote: instance.NewPrepare(0, instance.Proposal())}
+	})
+	evidenceOfPrepare := instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0, 1, 2)
+	driver.RequireCommit(0, instance.Proposal(), evidenceOfPrepare)
+	redeliver()
+
+	deliverFromOthers(func(sender gpbft.ActorID) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: sender, Vote: instance.NewCommit(0, instance.Proposal()), Justification: evidenceOfPrepare}
+	})
+	evidenceOfCommit := instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0, 1, 2)
+	driver.RequireDecide(instance.Proposal(), evidenceOfCommit)
+	// Once deciding, messages of prior phases are no longer relevant.
+	for _, msg := range delivered {
+		driver.RequireErrOnDeliverMessage(msg, gpbft.ErrValidationNotRelevant, "")
+	}
+	driver.RequireNoBroadcast()
+
+	deliverFromOthers(func(sender gpbft.ActorID) *gpbft.GMessage {
+		return &gpbft.GMessage{Sender: sender, Vote: instance.NewDecide(0, instance.Proposal()), Justification: evidenceOfCommit}
+	})
+	driver.RequireDecision(instance.ID(), instance.Proposal())
+}
+
+func TestGPBFT_MaxJustificationSigners(t *testing.T) {
+	t.Parallel()
+	// The least powerful participants holding a strong quorum are the six of unit
+	// power, one more than the five most powerful.
+	powerTable := gpbft.PowerEntries{gpbft.PowerEntry{ID: 0, Power: gpbft.NewStoragePower(2)}}
+	for id := gpbft.ActorID(1); id <= 6; id++ {
+		powerTable = append(powerTable, gpbft.PowerEntry{ID: id, Power: gpbft.NewStoragePower(1)})
+	}
+	instance, driver := newTestInstanceAndDriver(t, powerTable, gpbft.WithMaxJustificationSigners(1))
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+
+	newCommit := func(sender gpbft.ActorID, signers ...gpbft.ActorID) *gpbft.GMessage {
+		return &gpbft.GMessage{
+			Sender:        sender,
+			Vote:          instance.NewCommit(0, instance.Proposal()),
+			Justification: instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), signers...),
+		}
+	}
+	// A minimal justification is accepted, even if larger than the smallest strong
+	// quorum because the most powerful participant did not sign.
+	driver.RequireDeliverMessage(newCommit(1, 0, 1, 2, 3, 4))
+	driver.RequireDeliverMessage(newCommit(2, 1, 2, 3, 4, 5, 6))
+	// A justification with more signers than any honest one is rejected.
+	driver.RequireErrOnDeliverMessage(newCommit(3, 0, 1, 2, 3, 4, 5, 6), gpbft.ErrValidationBadJustification, "too many signers")
+}
+
+func TestGPBFT_CleanDecisionBroadcastSequence(t *testing.T) {
+	t.Parallel()
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(2))
+	require.Empty(t, driver.Broadcasts())
+
+	evidenceOfPrepare := instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0, 1)
+	evidenceOfCommit := instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0, 1)
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+	driver.RequireDeliverMessage(&gpbft.GMessage{
+		Sender: 1,
+		Vote:   instance.NewQuality(instance.Proposal()),
+	})
+	driver.RequirePrepare(instance.Proposal())
+	driver.RequireDeliverMessage(&gpbft.GMessage{
+		Sender: 1,
+		Vote:   instance.NewPrepare(0, instance.Proposal()),
+	})
+	driver.RequireCommit(0, instance.Proposal(), evidenceOfPrepare)
+	driver.RequireDeliverMessage(&gpbft.GMessage{
+		Sender:        1,
+		Vote:          instance.NewCommit(0, instance.Proposal()),
+		Justification: evidenceOfPrepare,
+	})
+	driver.RequireDecide(instance.Proposal(), evidenceOfCommit)
+	driver.RequireDeliverMessage(&gpbft.GMessage{
+		Sender:        1,
+		Vote:          instance.NewDecide(0, instance.Proposal()),
+		Justification: evidenceOfCommit,
+	})
+	driver.RequireDecision(instance.ID(), instance.Proposal())
+
+	// The history holds every broadcast in order, including those already
+	// consumed by the assertions above, and nothing else was broadcast in
+	// between.
+	type step struct {
+		phase gpbft.Phase
+		round uint64
+	}
+	var got []step
+	for _, msg := range driver.Broadcasts() {
+		require.Equal(t, instance.ID(), msg.Vote.Instance)
+		require.True(t, msg.Vote.Value.Eq(instance.Proposal()), "unexpected value in %s", msg.Vote.Phase)
+		got = append(got, step{phase: msg.Vote.Phase, round: msg.Vote.Round})
+	}
+	require.Equal(t, []step{
+		{phase: gpbft.QUALITY_PHASE},
+		{phase: gpbft.PREPARE_PHASE},
+		{phase: gpbft.COMMIT_PHASE},
+		{phase: gpbft.DECIDE_PHASE},
+	}, got)
+}
+
+func TestGPBFT_TicketBatchVerification(t *testing.T) {
+	t.Parallel()
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(2), gpbft.WithTicketBatchVerification(4))
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+
+	evidenceOfCommitForBottom := instance.NewJustification(0, gpbft.COMMIT_PHASE, &gpbft.ECChain{}, 0, 1)
+	driver.RequireErrOnDeliverMessage(&gpbft.GMessage{
+		Sender:        1,
+		Vote:          instance.NewConverge(1, instance.Proposal()),
+		Ticket:        []byte("lobster"),
+		Justification: evidenceOfCommitForBottom,
+	}, gpbft.ErrValidationBadTicket, "failed to verify ticket")
+	driver.RequireDeliverMessage(&gpbft.GMessage{
+		Sender:        1,
+		Vote:          instance.NewConverge(1, instance.Proposal()),
+		Ticket:        emulator.ValidTicket,
+		Justification: evidenceOfCommitForBottom,
+	})
+}
+
+func TestGPBFT_ProposalNotExtendingPreviousDecision(t *testing.T) {
+	t.Parallel()
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(1))
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+	driver.RequirePrepare(instance.Proposal())
+	driver.RequireCommit(0, instance.Proposal(), instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0))
+	driver.RequireDecide(instance.Proposal(), instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0))
+	driver.RequireDecision(instance.ID(), instance.Proposal())
+
+	// The host proposes a chain for the next instance on the base of the previous
+	// one, rather than on the head of its decision.
+	next := emulator.NewInstance(t, 1, equalPowerTable(1), tipset0, tipSet3)
+	driver.AddInstance(next)
+	triggered, err := driver.DeliverAlarm()
+	require.True(t, triggered)
+	require.ErrorIs(t, err, gpbft.ErrProposalWrongBase)
+	require.ErrorContains(t, err, "proposal for instance 1")
+	driver.RequireNoBroadcast()
+}
+
+func TestGPBFT_ProposalNotExtendingCaughtUpDecision(t *testing.T) {
+	t.Parallel()
+	powerTable := gpbft.PowerEntries{gpbft.PowerEntry{ID: 0, Power: gpbft.NewStoragePower(1)}}
+
+	// Having caught up to instance 5 from a decision of instance 4 headed at
+	// tipSet2, the host proposes a chain on some other base.
+	driver := emulator.NewDriver(t)
+	driver.AddInstance(emulator.NewInstance(t, 5, powerTable, tipset0, tipSet3))
+	err := driver.StartInstanceFrom(5, tipSet2)
+	require.ErrorIs(t, err, gpbft.ErrProposalWrongBase)
+	require.ErrorContains(t, err, "proposal for instance 5")
+	driver.RequireNoBroadcast()
+
+	// Whereas a proposal extending the decision caught up to begins the instance.
+	driver = emulator.NewDriver(t)
+	driver.AddInstance(emulator.NewInstance(t, 5, powerTable, tipSet2, tipSet3))
+	require.NoError(t, driver.StartInstanceFrom(5, tipSet2))
+	driver.RequireQuality()
+}
+
+func TestGPBFT_RestoresQueuedMessagesForCurrentInstance(t *testing.T) {
+	t.Parallel()
+	// Messages received before the instance starts are queued, and persisted as
+	// they would be on stopping.
+	instance, stopped := newTestInstanceAndDriver(t, equalPowerTable(4))
+	for sender := gpbft.ActorID(1); sender <= 2; sender++ {
+		stopped.RequireDeliverMessage(&gpbft.GMessage{Sender: sender, Vote: instance.NewQuality(instance.Proposal())})
+	}
+	stopped.RequireNoBroadcast()
+	var queued bytes.Buffer
+	require.NoError(t, stopped.SerializeQueuedMessages(&queued))
+
+	// Once restored after the instance has started, they are delivered to it
+	// rather than queued for an instance that has already begun.
+	driver := emulator.NewDriver(t)
+	driver.AddInstance(instance)
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+	require.NoError(t, driver.RestoreQueuedMessages(&queued))
+	driver.RequirePrepare(instance.Proposal())
+}
@@ -0,0 +1,168 @@
+package gpbft
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestInstance returns an instance of a participant configured with the
+// given options, and alone in its committee, which proposes a chain of the given
+// length. The instance is driven by the returned mock host.
+func newTestInstance(t *testing.T, chainLen int, o ...Option) (*instance, *MockHost) {
+	host := NewMockHost(t)
+	opts, err := newOptions(o...)
+	require.NoError(t, err)
+	participant := &Participant{options: opts, host: host, progression: newAtomicProgression()}
+
+	ptCid := MakeCid([]byte("pt"))
+	var input *ECChain
+	for epoch := int64(0); epoch < int64(chainLen); epoch++ {
+		input = input.Append(&TipSet{Epoch: epoch, Key: []byte(fmt.Sprint(epoch)), PowerTable: ptCid})
+	}
+	powerTable := NewPowerTable()
+	require.NoError(t, powerTable.Add(PowerEntry{ID: 0, Power: NewStoragePower(1), PubKey: PubKey("pk")}))
+	host.EXPECT().Time().Return(time.Now())
+	subject, err := newInstance(participant, 0, input, &SupplementalData{PowerTable: ptCid}, powerTable, nil, nil)
+	require.NoError(t, err)
+	return subject, host
+}
+
+func TestInstance_BroadcastRejectsValueTooLongBeforeSigning(t *testing.T) {
+	subject, host := newTestInstance(t, ChainMaxLen+1)
+	longest := subject.input
+
+	// A value of the maximum length is handed to the host to sign and broadcast.
+	atMaxLen := longest.Prefix(ChainMaxLen - 1)
+	require.Equal(t, ChainMaxLen, atMaxLen.Len())
+	host.EXPECT().NetworkName().Return("test")
+	host.EXPECT().RequestBroadcast(mock.Anything).Return(nil).Once()
+	require.NoError(t, subject.broadcast(0, PREPARE_PHASE, atMaxLen, false, nil))
+
+	// A longer value never reaches the host, so is never signed.
+	require.Equal(t, ChainMaxLen+1, longest.Len())
+	require.ErrorIs(t, subject.broadcast(0, PREPARE_PHASE, longest, false, nil), ErrBroadcastValueTooLong)
+}
+
+func TestInstance_BroadcastValueTooLongFailsPhaseTransition(t *testing.T) {
+	subject, host := newTestInstance(t, ChainMaxLen+1)
+	host.EXPECT().SetAlarm(mock.Anything)
+
+	// The error surfaces from the transition to the phase, rather than as a panic.
+	require.ErrorIs(t, subject.Start(), ErrBroadcastValueTooLong)
+}
+
+func TestInstance_PrunesRoundsBeyondCommitGraceWindow(t *testing.T) {
+	subject, _ := newTestInstance(t, 1, WithCommitGraceRounds(2))
+
+	for round := uint64(0); round < 5; round++ {
+		subject.getRound(round)
+		subject.detectEquivocation(&GMessage{Sender: 0, Vote: Payload{Round: round, Phase: COMMIT_PHASE}})
+	}
+	subject.detectEquivocation(&GMessage{Sender: 0, Vote: Payload{Phase: QUALITY_PHASE}})
+	subject.detectEquivocation(&GMessage{Sender: 0, Vote: Payload{Phase: DECIDE_PHASE}})
+
+	// Within the window nothing is pruned.
+	subject.current.Round = 2
+	subject.pruneRounds()
+	require.Len(t, subject.rounds, 5)
+	require.Zero(t, subject.prunedRounds)
+
+	// Rounds before the window are pruned, but the previous round is retained to
+	// justify the current one.
+	subject.current.Round = 5
+	subject.pruneRounds()
+	require.Equal(t, uint64(3), subject.prunedRounds)
+	require.Len(t, subject.rounds, 2)
+	require.Contains(t, subject.rounds, uint64(3))
+	require.Contains(t, subject.rounds, uint64(4))
+	for key := range subject.firstMessages {
+		require.True(t, key.round >= 3 || key.phase == QUALITY_PHASE || key.phase == DECIDE_PHASE, "unexpected message retained: %+v", key)
+	}
+	require.Len(t, subject.firstMessages, 4)
+
+	// COMMITs for pruned rounds are ignored without recreating their state.
+	changed, err := subject.receiveOne(&GMessage{Sender: 0, Vote: Payload{Instance: 0, Round: 2, Phase: COMMIT_PHASE, SupplementalData: *subject.supplementalData}})
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.NotContains(t, subject.rounds, uint64(2))
+}
+
+func TestInstance_PrunesEquivocationStateOfIrrelevantRounds(t *testing.T) {
+	subject, _ := newTestInstance(t, 1)
+
+	for round := uint64(0); round < 5; round++ {
+		for _, phase := range []Phase{CONVERGE_PHASE, PREPARE_PHASE, COMMIT_PHASE} {
+			subject.detectEquivocation(&GMessage{Sender: 0, Vote: Payload{Round: round, Phase: phase}})
+		}
+	}
+	subject.detectEquivocation(&GMessage{Sender: 0, Vote: Payload{Phase: QUALITY_PHASE}})
+	subject.detectEquivocation(&GMessage{Sender: 0, Vote: Payload{Phase: DECIDE_PHASE}})
+
+	// Without a grace window, only the messages at the current and previous rounds
+	// are retained, even though the state of rounds is not pruned.
+	subject.current.Round = 4
+	subject.pruneRounds()
+	require.Zero(t, subject.prunedRounds)
+	for key := range subject.firstMessages {
+		require.True(t, key.round >= 3 || key.phase == QUALITY_PHASE || key.phase == DECIDE_PHASE, "unexpected message retained: %+v", key)
+	}
+	require.Len(t, subject.firstMessages, 8)
+}
+
+func TestInstance_PublishesCandidatesWithoutCopying(t *testing.T) {
+	subject, _ := newTestInstance(t, 10)
+	input := subject.input
+
+	var snapshots [][]*ECChain
+	for l := 1; l < input.Len(); l++ {
+		require.True(t, subject.addCandidate(input.Prefix(l)))
+		snapshot := *subject.participant.candidates.Load()
+		require.Equal(t, len(snapshot), cap(snapshot))
+		snapshots = append(snapshots, snapshot)
+	}
+	// Every snapshot published remains as it was, despite later additions.
+	for i, snapshot := range snapshots {
+		require.Len(t, snapshot, i+2)
+		for l, candidate := range snapshot {
+			require.True(t, candidate.Eq(input.Prefix(l)))
+		}
+	}
+}
+
+func TestConvergeState_FindBestTicketProposalBreaksTiesByKey(t *testing.T) {
+	ptCid := MakeCid([]byte("pt"))
+	base := &TipSet{Epoch: 0, Key: []byte("fish"), PowerTable: ptCid}
+	powerTable := NewPowerTable()
+	var chains []*ECChain
+	for i := range 5 {
+		chain, err := NewChain(base, &TipSet{Epoch: 1, Key: []byte(fmt.Sprint("lobster", i)), PowerTable: ptCid})
+		require.NoError(t, err)
+		chains = append(chains, chain)
+		require.NoError(t, powerTable.Add(PowerEntry{ID: ActorID(i), Power: NewStoragePower(1), PubKey: PubKey(fmt.Sprint("pk", i))}))
+	}
+	want := chains[0]
+	for _, chain := range chains[1:] {
+		wantKey, key := want.Key(), chain.Key()
+		if bytes.Compare(key[:], wantKey[:]) < 0 {
+			want = chain
+		}
+	}
+
+	// The same ticket from senders of equal power ranks all proposals equally, in
+	// which case the winner must not depend on map iteration order.
+	ticket := Ticket("barreleye")
+	for range 20 {
+		subject := newConvergeState()
+		for i, chain := range chains {
+			require.NoError(t, subject.Receive(ActorID(i), powerTable, chain, ticket, &Justification{}))
+		}
+		got := subject.FindBestTicketProposal(nil)
+		require.True(t, got.IsValid())
+		require.True(t, want.Eq(got.Chain), "want %s, got %s", want, got.Chain)
+	}
+}
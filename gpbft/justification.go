@@ -0,0 +1,149 @@
+package gpbft
+
+import (
+	"fmt"
+	"io"
+
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+)
+
+// AggregateScheme identifies the rules by which the signatures in a
+// justification are aggregated, and therefore how the aggregate is verified.
+type AggregateScheme uint64
+
+const (
+	// AggregateSchemeBLSG2 is the BLS signature aggregation scheme with signatures
+	// on G2, used by all justifications that predate aggregate schemes.
+	AggregateSchemeBLSG2 AggregateScheme = 0
+)
+
+// maxJustificationSignatureLen is the maximum length of an aggregate signature
+// in bytes.
+const maxJustificationSignatureLen = 96
+
+var (
+	_ cbg.CBORMarshaler   = (*Justification)(nil)
+	_ cbg.CBORUnmarshaler = (*Justification)(nil)
+)
+
+// VerifyAggregate verifies an aggregate signature made by the given signers over
+// the payload under the given scheme. Aggregate signatures under the default
+// scheme are verified by the aggregate, and those under any other scheme only if
+// the aggregate implements SchemeAggregate.
+func VerifyAggregate(agg Aggregate, scheme AggregateScheme, signerMask []int, payload, aggSig []byte) error {
+	if scheme == AggregateSchemeBLSG2 {
+		return agg.VerifyAggregate(signerMask, payload, aggSig)
+	}
+	if sagg, ok := agg.(SchemeAggregate); ok {
+		return sagg.VerifySchemeAggregate(scheme, signerMask, payload, aggSig)
+	}
+	return fmt.Errorf("unsupported aggregate scheme: %d", scheme)
+}
+
+// MarshalCBOR encodes the justification as a tuple of vote, signers and
+// signature, followed by the aggregate scheme only if it is not the default.
+// Justifications under the default scheme are therefore encoded identically to
+// those that predate aggregate schemes.
+func (j *Justification) MarshalCBOR(w io.Writer) error {
+	if j == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	fields := uint64(3)
+	if j.Scheme != AggregateSchemeBLSG2 {
+		fields++
+	}
+	cw := cbg.NewCborWriter(w)
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, fields); err != nil {
+		return err
+	}
+	if err := j.Vote.MarshalCBOR(cw); err != nil {
+		return err
+	}
+	if err := j.Signers.MarshalCBOR(cw); err != nil {
+		return err
+	}
+	if len(j.Signature) > maxJustificationSignatureLen {
+		return xerrors.Errorf("Byte array in field j.Signature was too long")
+	}
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(j.Signature))); err != nil {
+		return err
+	}
+	if _, err := cw.Write(j.Signature); err != nil {
+		return err
+	}
+	if j.Scheme != AggregateSchemeBLSG2 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(j.Scheme)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalCBOR decodes a justification encoded by MarshalCBOR, where the
+// absence of an aggregate scheme implies the default scheme.
+func (j *Justification) UnmarshalCBOR(r io.Reader) (err error) {
+	*j = Justification{}
+
+	cr := cbg.NewCborReader(r)
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+	if extra != 3 && extra != 4 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+	tagged := extra == 4
+
+	if err := j.Vote.UnmarshalCBOR(cr); err != nil {
+		return xerrors.Errorf("unmarshaling j.Vote: %w", err)
+	}
+	if err := j.Signers.UnmarshalCBOR(cr); err != nil {
+		return xerrors.Errorf("unmarshaling j.Signers: %w", err)
+	}
+
+	maj, extra, err = cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if extra > maxJustificationSignatureLen {
+		return fmt.Errorf("j.Signature: byte array too large (%d)", extra)
+	}
+	if maj != cbg.MajByteString {
+		return fmt.Errorf("expected byte array")
+	}
+	if extra > 0 {
+		j.Signature = make([]uint8, extra)
+	}
+	if _, err := io.ReadFull(cr, j.Signature); err != nil {
+		return err
+	}
+
+	if tagged {
+		maj, extra, err = cr.ReadHeader()
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		// Reject the default scheme when explicitly tagged so that every
+		// justification has exactly one encoding.
+		if extra == uint64(AggregateSchemeBLSG2) {
+			return fmt.Errorf("j.Scheme: default aggregate scheme must not be tagged")
+		}
+		j.Scheme = AggregateScheme(extra)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package gpbft_test
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-f3/emulator"
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/stretchr/testify/require"
+)
+
+// reversedAggregateScheme stands in for a future aggregate scheme, under which
+// an aggregate signature is that of the default scheme in reverse.
+const reversedAggregateScheme gpbft.AggregateScheme = 1
+
+var _ gpbft.SchemeAggregate = (*schemeAggregate)(nil)
+
+// schemeSigning is an emulator.Signing that can also verify aggregate
+// signatures under reversedAggregateScheme.
+type schemeSigning struct{ emulator.Signing }
+
+func (s schemeSigning) Aggregate(pubKeys []gpbft.PubKey) (gpbft.Aggregate, error) {
+	agg, err := s.Signing.Aggregate(pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	return schemeAggregate{agg: agg}, nil
+}
+
+type schemeAggregate struct{ agg gpbft.Aggregate }
+
+func (a schemeAggregate) Aggregate(signerMask []int, sigs [][]byte) ([]byte, error) {
+	return a.agg.Aggregate(signerMask, sigs)
+}
+
+func (a schemeAggregate) VerifyAggregate(signerMask []int, payload, aggSig []byte) error {
+	return a.agg.VerifyAggregate(signerMask, payload, aggSig)
+}
+
+func (a schemeAggregate) VerifySchemeAggregate(scheme gpbft.AggregateScheme, signerMask []int, payload, aggSig []byte) error {
+	if scheme != reversedAggregateScheme {
+		return fmt.Errorf("unsupported aggregate scheme: %d", scheme)
+	}
+	return a.VerifyAggregate(signerMask, payload, reversed(aggSig))
+}
+
+func reversed(b []byte) []byte {
+	b = slices.Clone(b)
+	slices.Reverse(b)
+	return b
+}
+
+func TestJustification_Marshaling(t *testing.T) {
+	subject := gpbft.Justification{
+		Vote: gpbft.Payload{
+			Instance:         7,
+			Phase:            gpbft.COMMIT_PHASE,
+			SupplementalData: gpbft.SupplementalData{PowerTable: ptCid},
+			Value:            &gpbft.ECChain{},
+		},
+		Signers:   bitfield.NewFromSet([]uint64{0, 2}),
+		Signature: []byte("fish"),
+	}
+	roundTrip := func(t *testing.T, j *gpbft.Justification) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, j.MarshalCBOR(&buf))
+		encoded := slices.Clone(buf.Bytes())
+		var got gpbft.Justification
+		require.NoError(t, got.UnmarshalCBOR(&buf))
+		require.Equal(t, j.Scheme, got.Scheme)
+		require.Equal(t, j.Signature, got.Signature)
+		require.True(t, j.Vote.Eq(&got.Vote))
+		return encoded
+	}
+
+	t.Run("default scheme is untagged", func(t *testing.T) {
+		encoded := roundTrip(t, &subject)
+		// A tuple of three fields, as encoded before aggregate schemes.
+		require.Equal(t, byte(0x83), encoded[0])
+	})
+	t.Run("other schemes are tagged", func(t *testing.T) {
+		tagged := subject
+		tagged.Scheme = reversedAggregateScheme
+		encoded := roundTrip(t, &tagged)
+		require.Equal(t, byte(0x84), encoded[0])
+		require.Equal(t, byte(reversedAggregateScheme), encoded[len(encoded)-1])
+	})
+	t.Run("explicitly tagged default scheme is rejected", func(t *testing.T) {
+		tagged := subject
+		tagged.Scheme = reversedAggregateScheme
+		var buf bytes.Buffer
+		require.NoError(t, tagged.MarshalCBOR(&buf))
+		encoded := buf.Bytes()
+		encoded[len(encoded)-1] = byte(gpbft.AggregateSchemeBLSG2)
+		var got gpbft.Justification
+		require.ErrorContains(t, got.UnmarshalCBOR(bytes.NewReader(encoded)), "must not be tagged")
+	})
+}
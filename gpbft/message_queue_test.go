@@ -0,0 +1,205 @@
+package gpbft
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageQueue_SerializeDeserialize(t *testing.T) {
+	ptCid := MakeCid([]byte("pt"))
+	chain, err := NewChain(
+		&TipSet{Epoch: 0, Key: []byte("fish"), PowerTable: ptCid},
+		&TipSet{Epoch: 1, Key: []byte("lobster"), PowerTable: ptCid},
+	)
+	require.NoError(t, err)
+
+	const maxRound = 5
+	subject := newMessageQueue(maxRound, 0, 0)
+	for instance := uint64(1); instance <= 3; instance++ {
+		for sender := ActorID(0); sender < 4; sender++ {
+			for _, phase := range []Phase{QUALITY_PHASE, PREPARE_PHASE, COMMIT_PHASE} {
+				subject.Add(&GMessage{
+					Sender: sender,
+					Vote: Payload{
+						Instance: instance,
+						Round:    uint64(sender),
+						Phase:    phase,
+						Value:    chain,
+						SupplementalData: SupplementalData{
+							PowerTable: ptCid,
+						},
+					},
+					Signature: []byte{byte(instance), byte(sender), byte(phase)},
+				}, time.Time{})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, subject.Serialize(&buf))
+	restored := newMessageQueue(maxRound, 0, 0)
+	require.NoError(t, restored.Deserialize(&buf))
+
+	require.Equal(t, subject.All(), restored.All())
+	for instance := uint64(1); instance <= 3; instance++ {
+		want, got := subject.Drain(instance), restored.Drain(instance)
+		require.Len(t, got, 12)
+		require.ElementsMatch(t, want, got)
+	}
+	require.Empty(t, restored.All())
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, newMessageQueue(maxRound, 0, 0).Serialize(&buf))
+		restored := newMessageQueue(maxRound, 0, 0)
+		require.NoError(t, restored.Deserialize(&buf))
+		require.Empty(t, restored.All())
+	})
+}
+
+func TestParticipant_DropsMessagesQueuedForDistantInstances(t *testing.T) {
+	const maxAge = time.Minute
+	host := NewMockHost(t)
+	host.EXPECT().NetworkName().Return("test")
+	subject, err := NewParticipant(host, WithMaxQueuedMessageAge(maxAge))
+	require.NoError(t, err)
+
+	ptCid := MakeCid([]byte("pt"))
+	chain, err := NewChain(&TipSet{Epoch: 0, Key: []byte("fish"), PowerTable: ptCid})
+	require.NoError(t, err)
+	receiveAt := func(at time.Time, instance uint64) {
+		host.EXPECT().Time().Return(at).Once()
+		require.NoError(t, subject.ReceiveMessage(&validatedMessage{msg: &GMessage{
+			Sender: 1,
+			Vote: Payload{
+				Instance:         instance,
+				Phase:            QUALITY_PHASE,
+				Value:            chain,
+				SupplementalData: SupplementalData{PowerTable: ptCid},
+			},
+		}}))
+	}
+	queuedInstances := func() []uint64 {
+		var instances []uint64
+		for _, msg := range subject.mqueue.All() {
+			instances = append(instances, msg.Vote.Instance)
+		}
+		return instances
+	}
+
+	start := time.Now()
+	receiveAt(start, 5)
+	receiveAt(start.Add(maxAge/2), 1)
+	require.Equal(t, []uint64{1, 5}, queuedInstances())
+
+	// Messages for an instance that has not begun long after they were first queued
+	// are eventually dropped, while those queued more recently are kept.
+	receiveAt(start.Add(maxAge), 2)
+	require.Equal(t, []uint64{1, 2, 5}, queuedInstances())
+	receiveAt(start.Add(maxAge+time.Second), 3)
+	require.Equal(t, []uint64{1, 2, 3}, queuedInstances())
+	receiveAt(start.Add(2*maxAge), 4)
+	require.Equal(t, []uint64{2, 3, 4}, queuedInstances())
+
+	// The instance is queued afresh if messages for it arrive after the drop.
+	receiveAt(start.Add(2*maxAge), 5)
+	require.Equal(t, []uint64{2, 3, 4, 5}, queuedInstances())
+}
+
+func TestMessageQueue_Bounds(t *testing.T) {
+	ptCid := MakeCid([]byte("pt"))
+	chain, err := NewChain(&TipSet{Epoch: 0, Key: []byte("fish"), PowerTable: ptCid})
+	require.NoError(t, err)
+	message := func(sender ActorID, instance, round uint64) *GMessage {
+		return &GMessage{
+			Sender: sender,
+			Vote: Payload{
+				Instance:         instance,
+				Round:            round,
+				Phase:            PREPARE_PHASE,
+				Value:            chain,
+				SupplementalData: SupplementalData{PowerTable: ptCid},
+			},
+		}
+	}
+	queued := func(q *messageQueue) map[uint64]int {
+		counts := make(map[uint64]int)
+		for _, msg := range q.All() {
+			counts[msg.Vote.Instance]++
+		}
+		return counts
+	}
+
+	const (
+		maxRound           = 100
+		maxFutureInstances = 3
+		maxPerSender       = 2
+	)
+	subject := newMessageQueue(maxRound, maxFutureInstances, maxPerSender)
+	subject.Advance(10)
+
+	// Flood messages for many instances, from a sender flooding many rounds.
+	for instance := uint64(0); instance < 1000; instance++ {
+		for round := uint64(0); round < maxRound; round++ {
+			subject.Add(message(1, instance, round), time.Time{})
+		}
+		subject.Add(message(2, instance, 0), time.Time{})
+	}
+	require.Equal(t, map[uint64]int{10: 3, 11: 3, 12: 3, 13: 3}, queued(subject))
+	require.Len(t, subject.messages, 4)
+
+	// Advancing drops messages below the new current instance, and admits
+	// messages for instances further ahead.
+	subject.Advance(12)
+	subject.Add(message(1, 15, 0), time.Time{})
+	subject.Add(message(1, 16, 0), time.Time{})
+	require.Equal(t, map[uint64]int{12: 3, 13: 3, 15: 1}, queued(subject))
+
+	// Dropped messages leave no trace of the instance they were for.
+	subject.Add(message(1, 14, maxRound+1), time.Time{})
+	require.NotContains(t, subject.messages, uint64(14))
+	require.NotContains(t, subject.queuedSince, uint64(14))
+
+	t.Run("unbounded", func(t *testing.T) {
+		subject := newMessageQueue(maxRound, 0, 0)
+		for round := uint64(0); round < maxRound; round++ {
+			subject.Add(message(1, 1000, round), time.Time{})
+		}
+		require.Equal(t, map[uint64]int{1000: maxRound}, queued(subject))
+	})
+}
+
+func TestParticipant_BoundsMessagesQueuedForFutureInstances(t *testing.T) {
+	const maxFutureInstances = 5
+	host := NewMockHost(t)
+	host.EXPECT().NetworkName().Return("test")
+	subject, err := NewParticipant(host, WithMaxFutureInstances(maxFutureInstances), WithMaxQueuedMessagesPerSender(1))
+	require.NoError(t, err)
+
+	ptCid := MakeCid([]byte("pt"))
+	chain, err := NewChain(&TipSet{Epoch: 0, Key: []byte("fish"), PowerTable: ptCid})
+	require.NoError(t, err)
+	for instance := uint64(0); instance < 10_000; instance++ {
+		for _, phase := range []Phase{QUALITY_PHASE, PREPARE_PHASE} {
+			require.NoError(t, subject.ReceiveMessage(&validatedMessage{msg: &GMessage{
+				Sender: 1,
+				Vote: Payload{
+					Instance:         instance,
+					Phase:            phase,
+					Value:            chain,
+					SupplementalData: SupplementalData{PowerTable: ptCid},
+				},
+			}}))
+		}
+	}
+	require.Len(t, subject.mqueue.messages, maxFutureInstances+1)
+	require.Len(t, subject.mqueue.All(), maxFutureInstances+1)
+
+	_, err = NewParticipant(host, WithMaxFutureInstances(0))
+	require.ErrorContains(t, err, "max future instances must be larger than zero")
+	_, err = NewParticipant(host, WithMaxQueuedMessagesPerSender(0))
+	require.ErrorContains(t, err, "max queued messages per sender must be larger than zero")
+}
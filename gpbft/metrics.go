@@ -35,6 +35,7 @@ var (
 	}
 	attrSkipToRound  = attribute.String("to", "round")
 	attrSkipToDecide = attribute.String("to", "decide")
+	attrSkipToCommit = attribute.String("to", "commit")
 
 	attrCacheHit               = attribute.String("cache", "hit")
 	attrCacheMiss              = attribute.String("cache", "miss")
@@ -44,6 +45,7 @@ var (
 	metrics = struct {
 		phaseCounter       metric.Int64Counter
 		roundHistogram     metric.Int64Histogram
+		phaseDuration      metric.Float64Histogram
 		broadcastCounter   metric.Int64Counter
 		reBroadcastCounter metric.Int64Counter
 		errorCounter       metric.Int64Counter
@@ -52,12 +54,23 @@ var (
 		currentPhase       metric.Int64Gauge
 		skipCounter        metric.Int64Counter
 		validationCache    metric.Int64Counter
+
+		verificationSaturation     metric.Int64Counter
+		equivocationCounter        metric.Int64Counter
+		impossibleQuorumExits      metric.Int64Counter
+		committeeDivergenceCounter metric.Int64Counter
+		untrackedValueCounter      metric.Int64Counter
 	}{
 		phaseCounter: measurements.Must(meter.Int64Counter("f3_gpbft_phase_counter", metric.WithDescription("Number of times phases change"))),
 		roundHistogram: measurements.Must(meter.Int64Histogram("f3_gpbft_round_histogram",
 			metric.WithDescription("Histogram of rounds per instance"),
 			metric.WithExplicitBucketBoundaries(0.0, 1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0, 8.0, 9.0, 10.0, 20.0, 50.0, 100.0, 1000.0),
 		)),
+		phaseDuration: measurements.Must(meter.Float64Histogram("f3_gpbft_phase_duration",
+			metric.WithDescription("Histogram of time spent in each phase, as measured by the host clock, by phase."),
+			metric.WithExplicitBucketBoundaries(0.0, 0.1, 0.5, 1.0, 2.0, 5.0, 10.0, 20.0, 30.0, 60.0, 120.0, 300.0, 600.0),
+			metric.WithUnit("s"),
+		)),
 		broadcastCounter:   measurements.Must(meter.Int64Counter("f3_gpbft_broadcast_counter", metric.WithDescription("Number of broadcasted messages"))),
 		reBroadcastCounter: measurements.Must(meter.Int64Counter("f3_gpbft_rebroadcast_counter", metric.WithDescription("Number of rebroadcasted messages"))),
 		errorCounter:       measurements.Must(meter.Int64Counter("f3_gpbft_error_counter", metric.WithDescription("Number of errors"))),
@@ -70,6 +83,16 @@ var (
 			metric.WithDescription("The number of times GPBFT skip either round or phase"))),
 		validationCache: measurements.Must(meter.Int64Counter("f3_gpbft_validation_cache",
 			metric.WithDescription("The number of times GPBFT validation cache resulted in hit or miss."))),
+		verificationSaturation: measurements.Must(meter.Int64Counter("f3_gpbft_verification_saturation",
+			metric.WithDescription("The number of aggregate signature verifications that waited for the maximum concurrent verifications to free up."))),
+		equivocationCounter: measurements.Must(meter.Int64Counter("f3_gpbft_equivocation_counter",
+			metric.WithDescription("The number of equivocations detected, by phase."))),
+		impossibleQuorumExits: measurements.Must(meter.Int64Counter("f3_impossible_quorum_exits",
+			metric.WithDescription("The number of times a phase ended early because a strong quorum became impossible, by phase."))),
+		committeeDivergenceCounter: measurements.Must(meter.Int64Counter("f3_gpbft_committee_divergence_counter",
+			metric.WithDescription("The number of instances in which senders of a weak quorum of power disagreed on the committee, indicating likely EC divergence."))),
+		untrackedValueCounter: measurements.Must(meter.Int64Counter("f3_gpbft_untracked_value_counter",
+			metric.WithDescription("The number of values received but not tracked because the instance reached its maximum number of tracked values."))),
 	}
 )
 
@@ -80,6 +103,18 @@ func metricAttributeFromError(err error) attribute.KeyValue {
 		v = "invalid_too_old"
 	case errors.Is(err, ErrValidationNoCommittee):
 		v = "invalid_no_committee"
+	case errors.Is(err, ErrValidationMalformed):
+		v = "invalid_malformed"
+	case errors.Is(err, ErrValidationNoPower):
+		v = "invalid_no_power"
+	case errors.Is(err, ErrValidationBadTicket):
+		v = "invalid_ticket"
+	case errors.Is(err, ErrValidationBadSignature):
+		v = "invalid_signature"
+	case errors.Is(err, ErrValidationBadJustification):
+		v = "invalid_justification"
+	case errors.Is(err, ErrValidationWeakJustification):
+		v = "invalid_weak_justification"
 	case errors.Is(err, ErrValidationInvalid):
 		v = "invalid_msg"
 	case errors.Is(err, ErrValidationWrongBase):
@@ -0,0 +1,246 @@
+package gpbft_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/emulator"
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// recordingMeterProvider records the values added to Int64Counters and the
+// number of values recorded by Float64Histograms, by name and attributes,
+// discarding every other measurement.
+type recordingMeterProvider struct {
+	noop.MeterProvider
+
+	mu       sync.Mutex
+	counters map[string]map[attribute.Distinct]int64
+}
+
+type recordingMeter struct {
+	noop.Meter
+	provider *recordingMeterProvider
+}
+
+type recordingCounter struct {
+	noop.Int64Counter
+	provider *recordingMeterProvider
+	name     string
+}
+
+type recordingHistogram struct {
+	noop.Float64Histogram
+	provider *recordingMeterProvider
+	name     string
+}
+
+func (p *recordingMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return &recordingMeter{provider: p}
+}
+
+func (m *recordingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return &recordingCounter{provider: m.provider, name: name}, nil
+}
+
+func (m *recordingMeter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return &recordingHistogram{provider: m.provider, name: name}, nil
+}
+
+func (c *recordingCounter) Add(_ context.Context, incr int64, options ...metric.AddOption) {
+	c.provider.add(c.name, incr, metric.NewAddConfig(options).Attributes())
+}
+
+func (h *recordingHistogram) Record(_ context.Context, _ float64, options ...metric.RecordOption) {
+	h.provider.add(h.name, 1, metric.NewRecordConfig(options).Attributes())
+}
+
+func (p *recordingMeterProvider) add(name string, incr int64, attrs attribute.Set) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.counters[name] == nil {
+		p.counters[name] = make(map[attribute.Distinct]int64)
+	}
+	p.counters[name][attrs.Equivalent()] += incr
+}
+
+func (p *recordingMeterProvider) counter(name string, attrs ...attribute.KeyValue) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	set := attribute.NewSet(attrs...)
+	return p.counters[name][set.Equivalent()]
+}
+
+// attrEmulatorNetwork is the attribute of every metric recorded by participants
+// driven by the emulator.
+var attrEmulatorNetwork = attribute.String("network", emulator.NetworkName)
+
+// meterProvider records gpbft metrics. It is installed as the global meter
+// provider at most once, since instruments are delegated to the first provider
+// installed only.
+var meterProvider = sync.OnceValue(func() *recordingMeterProvider {
+	provider := &recordingMeterProvider{counters: make(map[string]map[attribute.Distinct]int64)}
+	otel.SetMeterProvider(provider)
+	return provider
+})
+
+// This test is deliberately not parallel, so that no other instance exits early
+// while it asserts on the global counter.
+func TestGPBFT_ImpossibleQuorumExitsMetric(t *testing.T) {
+	provider := meterProvider()
+	initialPrepareExits := provider.counter("f3_impossible_quorum_exits", attribute.String("phase", gpbft.PREPARE_PHASE.String()), attrEmulatorNetwork)
+	prepareExits := func() int64 {
+		return provider.counter("f3_impossible_quorum_exits", attribute.String("phase", gpbft.PREPARE_PHASE.String()), attrEmulatorNetwork) - initialPrepareExits
+	}
+
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(4))
+
+	driver.RequireStartInstance(instance.ID())
+	for sender := gpbft.ActorID(1); sender <= 2; sender++ {
+		driver.RequireDeliverMessage(&gpbft.GMessage{Sender: sender, Vote: instance.NewQuality(instance.Proposal())})
+	}
+	driver.RequireQuality()
+
+	// Two of four participants PREPARE for a different value, leaving too little
+	// power unvoted for the proposal to ever reach a strong quorum.
+	other := instance.Proposal().Extend(tipSet3.Key)
+	driver.RequireDeliverMessage(&gpbft.GMessage{Sender: 1, Vote: instance.NewPrepare(0, other)})
+	require.Zero(t, prepareExits())
+	driver.RequireDeliverMessage(&gpbft.GMessage{Sender: 2, Vote: instance.NewPrepare(0, other)})
+	require.Equal(t, int64(1), prepareExits())
+
+	driver.RequirePrepare(instance.Proposal())
+	driver.RequireCommitForBottom(0)
+	require.Equal(t, int64(1), prepareExits())
+}
+
+// This test is deliberately not parallel, so that no other instance reports
+// divergence while it asserts on the global counter.
+func TestGPBFT_CommitteeDivergenceMetric(t *testing.T) {
+	provider := meterProvider()
+	initialDivergences := provider.counter("f3_gpbft_committee_divergence_counter", attrEmulatorNetwork)
+	divergences := func() int64 {
+		return provider.counter("f3_gpbft_committee_divergence_counter", attrEmulatorNetwork) - initialDivergences
+	}
+
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(4))
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+
+	// Half of the network computed a different committee for the instance. Until
+	// divergent senders of a weak quorum of power are observed, divergence cannot
+	// be told apart from a minority of faulty participants.
+	divergent := instance.NewQuality(instance.Proposal())
+	divergent.SupplementalData.PowerTable = wrongPtCid
+	driver.RequireErrOnDeliverMessage(&gpbft.GMessage{Sender: 1, Vote: divergent}, gpbft.ErrValidationWrongSupplement, "")
+	require.Zero(t, divergences())
+
+	// Once they are, the split is reported exactly once.
+	driver.RequireErrOnDeliverMessage(&gpbft.GMessage{Sender: 2, Vote: divergent}, gpbft.ErrValidationWrongSupplement, "")
+	require.Equal(t, int64(1), divergences())
+	driver.RequireDeliverMessage(&gpbft.GMessage{Sender: 3, Vote: instance.NewQuality(instance.Proposal())})
+	require.Equal(t, int64(1), divergences())
+}
+
+// This test is deliberately not parallel, so that no other instance reports
+// divergence while it asserts on the global counter.
+func TestGPBFT_CommitteeDivergenceMetric_SingleDivergentSender(t *testing.T) {
+	provider := meterProvider()
+	initialDivergences := provider.counter("f3_gpbft_committee_divergence_counter", attrEmulatorNetwork)
+
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(4))
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+
+	// A single sender with less than a weak quorum of power disagrees on the
+	// committee, while everyone else agrees. Even once every sender is observed,
+	// that is no evidence of divergence.
+	divergent := instance.NewQuality(instance.Proposal())
+	divergent.SupplementalData.PowerTable = wrongPtCid
+	driver.RequireErrOnDeliverMessage(&gpbft.GMessage{Sender: 1, Vote: divergent}, gpbft.ErrValidationWrongSupplement, "")
+	driver.RequireDeliverMessage(&gpbft.GMessage{Sender: 2, Vote: instance.NewQuality(instance.Proposal())})
+	driver.RequireDeliverMessage(&gpbft.GMessage{Sender: 3, Vote: instance.NewQuality(instance.Proposal())})
+	require.Zero(t, provider.counter("f3_gpbft_committee_divergence_counter", attrEmulatorNetwork)-initialDivergences)
+}
+
+// This test is deliberately not parallel, so that no other instance broadcasts
+// while it asserts on the global counter.
+func TestGPBFT_MetricsAttributedToNetwork(t *testing.T) {
+	provider := meterProvider()
+	attrQuality := attribute.String("phase", gpbft.QUALITY_PHASE.String())
+	initialBroadcasts := provider.counter("f3_gpbft_broadcast_counter", attrQuality, attrEmulatorNetwork)
+
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(1))
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+
+	// The broadcast is attributed to the network of the participant, and never
+	// recorded without it.
+	require.Equal(t, int64(1), provider.counter("f3_gpbft_broadcast_counter", attrQuality, attrEmulatorNetwork)-initialBroadcasts)
+	require.Zero(t, provider.counter("f3_gpbft_broadcast_counter", attrQuality))
+}
+
+// This test is deliberately not parallel, so that no other instance changes
+// phase while it asserts on the global histogram.
+func TestGPBFT_PhaseDurationMetric(t *testing.T) {
+	provider := meterProvider()
+	phases := []gpbft.Phase{gpbft.INITIAL_PHASE, gpbft.QUALITY_PHASE, gpbft.PREPARE_PHASE, gpbft.COMMIT_PHASE, gpbft.DECIDE_PHASE}
+	durations := func() map[gpbft.Phase]int64 {
+		recorded := make(map[gpbft.Phase]int64, len(phases))
+		for _, phase := range phases {
+			recorded[phase] = provider.counter("f3_gpbft_phase_duration", attribute.String("phase", phase.String()), attrEmulatorNetwork)
+		}
+		return recorded
+	}
+	initial := durations()
+
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(1))
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+	driver.RequirePrepare(instance.Proposal())
+	driver.RequireCommit(0, instance.Proposal(), instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0))
+	driver.RequireDecide(instance.Proposal(), instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0))
+	driver.RequireDecision(instance.ID(), instance.Proposal())
+
+	// The duration of every phase the instance went through is recorded once, as
+	// the phase ends.
+	got := durations()
+	for _, phase := range phases {
+		require.Equal(t, int64(1), got[phase]-initial[phase], "phase %s", phase)
+	}
+}
+
+type recordingPhaseObserver struct {
+	durations map[gpbft.Phase][]time.Duration
+}
+
+func (o *recordingPhaseObserver) ObservePhaseDuration(instant gpbft.Instant, duration time.Duration) {
+	o.durations[instant.Phase] = append(o.durations[instant.Phase], duration)
+}
+
+func TestGPBFT_PhaseObserver(t *testing.T) {
+	t.Parallel()
+	observer := &recordingPhaseObserver{durations: make(map[gpbft.Phase][]time.Duration)}
+	instance, driver := newTestInstanceAndDriver(t, equalPowerTable(1), gpbft.WithPhaseObserver(observer))
+	driver.RequireStartInstance(instance.ID())
+	driver.RequireQuality()
+	driver.RequirePrepare(instance.Proposal())
+	driver.RequireCommit(0, instance.Proposal(), instance.NewJustification(0, gpbft.PREPARE_PHASE, instance.Proposal(), 0))
+	driver.RequireDecide(instance.Proposal(), instance.NewJustification(0, gpbft.COMMIT_PHASE, instance.Proposal(), 0))
+	driver.RequireDecision(instance.ID(), instance.Proposal())
+
+	// The duration of every phase the instance went through is observed once, as
+	// the phase ends. Durations are measured by the host clock, which the driver
+	// does not advance once the instance has started.
+	for _, phase := range []gpbft.Phase{gpbft.QUALITY_PHASE, gpbft.PREPARE_PHASE, gpbft.COMMIT_PHASE, gpbft.DECIDE_PHASE} {
+		require.Equal(t, []time.Duration{0}, observer.durations[phase], "phase %s", phase)
+	}
+	require.Len(t, observer.durations[gpbft.INITIAL_PHASE], 1)
+}
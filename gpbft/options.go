@@ -14,6 +14,9 @@ const (
 	defaultMaxCachedInstances           = 10
 	defaultMaxCachedMessagesPerInstance = 25_000
 	defaultCommitteeLookback            = 10
+	defaultMaxLookaheadInstances        = 4
+	defaultMaxFutureInstances           = 20
+	defaultMaxQueuedMessagesPerSender   = 64
 )
 
 // Option represents a configurable parameter.
@@ -23,15 +26,46 @@ type options struct {
 	delta                time.Duration
 	deltaBackOffExponent float64
 
-	qualityDeltaMulti float64
+	qualityDeltaMulti  float64
+	convergeDeltaMulti float64
+	prepareDeltaMulti  float64
+	commitDeltaMulti   float64
 
-	committeeLookback  uint64
-	maxLookaheadRounds uint64
-	rebroadcastAfter   func(int) time.Duration
+	committeeLookback     uint64
+	maxLookaheadRounds    uint64
+	commitGraceRounds     uint64
+	maxLookaheadInstances uint64
+	maxQueuedMessageAge   time.Duration
+	// maxFutureInstances and maxQueuedMessagesPerSender bound the messages queued
+	// for future instances.
+	maxFutureInstances         uint64
+	maxQueuedMessagesPerSender int
+	rebroadcastAfter           func(int) time.Duration
 
 	maxCachedInstances           int
 	maxCachedMessagesPerInstance int
 
+	maxConcurrentVerifications int
+	maxTicketBatchSize         int
+
+	maxCommitteeSize int
+
+	maxTrackedValuesPerInstance int
+
+	quorum quorumFractions
+
+	maxJustificationSignersFactor float64
+
+	skipToCommitOnWeakQuorum bool
+	relayLateCommits         bool
+	noSway                   bool
+
+	decisionSink        DecisionSink
+	decisionSinkTimeout time.Duration
+
+	equivocationReporter EquivocationReporter
+	phaseObserver        PhaseObserver
+
 	// tracer traces logic logs for debugging and simulation purposes.
 	tracer Tracer
 }
@@ -41,16 +75,50 @@ func newOptions(o ...Option) (*options, error) {
 		delta:                        defaultDelta,
 		deltaBackOffExponent:         defaultDeltaBackOffExponent,
 		qualityDeltaMulti:            1.0,
+		convergeDeltaMulti:           1.0,
+		prepareDeltaMulti:            1.0,
+		commitDeltaMulti:             1.0,
 		committeeLookback:            defaultCommitteeLookback,
+		maxFutureInstances:           defaultMaxFutureInstances,
+		maxQueuedMessagesPerSender:   defaultMaxQueuedMessagesPerSender,
 		rebroadcastAfter:             defaultRebroadcastAfter,
 		maxCachedInstances:           defaultMaxCachedInstances,
 		maxCachedMessagesPerInstance: defaultMaxCachedMessagesPerInstance,
+		quorum:                       defaultQuorumFractions,
 	}
 	for _, apply := range o {
 		if err := apply(opts); err != nil {
 			return nil, err
 		}
 	}
+	// Messages at or beyond the committee lookback are rejected regardless, so the
+	// lookahead only bounds committee resolution if below it.
+	if opts.maxLookaheadInstances == 0 {
+		opts.maxLookaheadInstances = defaultMaxLookaheadInstances
+		if opts.committeeLookback > 0 {
+			opts.maxLookaheadInstances = min(opts.maxLookaheadInstances, opts.committeeLookback-1)
+		}
+	} else if opts.maxLookaheadInstances >= opts.committeeLookback {
+		return nil, fmt.Errorf("max lookahead instances %d must be less than the committee lookback %d",
+			opts.maxLookaheadInstances, opts.committeeLookback)
+	}
+	// The negated comparison also rejects NaN.
+	if !(opts.deltaBackOffExponent >= 1) {
+		return nil, fmt.Errorf("delta backoff exponent must be at least 1, was %f", opts.deltaBackOffExponent)
+	}
+	// Compare the quorum fractions by cross multiplication, which is exact.
+	q := opts.quorum
+	if q.strongNumerator*q.weakDenominator <= q.weakNumerator*q.strongDenominator {
+		return nil, fmt.Errorf("strong quorum %d/%d must be larger than weak quorum %d/%d",
+			q.strongNumerator, q.strongDenominator, q.weakNumerator, q.weakDenominator)
+	}
+	// Any two strong quorums overlap by at least 2·strong − 1 of the total, which
+	// must be no less than the weak quorum fraction so that the overlap cannot be
+	// held by an adversary short of a weak quorum. The defaults are on the bound.
+	if (2*q.strongNumerator-q.strongDenominator)*q.weakDenominator < q.weakNumerator*q.strongDenominator {
+		return nil, fmt.Errorf("strong quorums of %d/%d must overlap by at least the weak quorum %d/%d",
+			q.strongNumerator, q.strongDenominator, q.weakNumerator, q.weakDenominator)
+	}
 	return opts, nil
 }
 
@@ -72,14 +140,13 @@ func WithDelta(d time.Duration) Option {
 }
 
 // WithDeltaBackOffExponent sets the delta back-off exponent for each round.
-// Defaults to 1.3 if unspecified. It must be larger than zero.
+// Defaults to 1.3 if unspecified. It must be at least 1, since a smaller exponent
+// would shrink the timeout of each round, which may prevent the network from
+// ever synchronising on a round.
 //
 // See: https://github.com/filecoin-project/FIPs/blob/master/FIPS/fip-0086.md#synchronization-of-participants-in-the-current-instance
 func WithDeltaBackOffExponent(e float64) Option {
 	return func(o *options) error {
-		if e < 0 {
-			return errors.New("delta backoff exponent cannot be less than zero")
-		}
 		o.deltaBackOffExponent = e
 		return nil
 	}
@@ -95,6 +162,33 @@ func WithQualityDeltaMultiplier(m float64) Option {
 	}
 }
 
+// WithPhaseDeltaMultiplier sets the multiplier applied to the timeout of the
+// given phase, which is otherwise 2*delta scaled by the delta back-off exponent
+// for each round. This allows the timeout of each phase to be tuned separately.
+// The phase must be one of QUALITY, CONVERGE, PREPARE or COMMIT, and the
+// multiplier must not be less than zero. Defaults to 1.0 for every phase if
+// unset.
+func WithPhaseDeltaMultiplier(phase Phase, m float64) Option {
+	return func(o *options) error {
+		if m < 0 {
+			return fmt.Errorf("%s duration multiplier cannot be less than zero", phase)
+		}
+		switch phase {
+		case QUALITY_PHASE:
+			o.qualityDeltaMulti = m
+		case CONVERGE_PHASE:
+			o.convergeDeltaMulti = m
+		case PREPARE_PHASE:
+			o.prepareDeltaMulti = m
+		case COMMIT_PHASE:
+			o.commitDeltaMulti = m
+		default:
+			return fmt.Errorf("phase %s has no timeout", phase)
+		}
+		return nil
+	}
+}
+
 // WithTracer sets the Tracer for this gPBFT instance, which receives diagnostic
 // logs about the state mutation. Defaults to no tracer if unspecified.
 func WithTracer(t Tracer) Option {
@@ -116,6 +210,89 @@ func WithMaxLookaheadRounds(r uint64) Option {
 	}
 }
 
+// WithCommitGraceRounds sets the number of rounds prior to the current round
+// for which COMMIT messages are accepted, so that late-arriving COMMITs for
+// those rounds can still cause a decision. The state of older rounds is
+// discarded as the instance advances. Since the window spans at least one
+// round, the COMMITs of the previous round that justify the current round are
+// always retained. Setting zero accepts COMMITs for the previous round only, and
+// retains the state of every round until the instance terminates. Defaults to
+// zero if unset.
+func WithCommitGraceRounds(r uint64) Option {
+	return func(o *options) error {
+		o.commitGraceRounds = r
+		return nil
+	}
+}
+
+// WithMaxLookaheadInstances sets the maximum number of instances ahead of the
+// current instance for which messages are validated. Validating a message
+// requires resolving the committee of its instance, which may be expensive for
+// instances ahead of current. Messages beyond the lookahead are rejected with
+// ErrValidationNoCommittee without resolving the committee. Messages at or
+// beyond the committee lookback are always rejected, so only values less than
+// the committee lookback have any effect, and larger ones are rejected.
+// Defaults to 4, or one less than the committee lookback if smaller. It must be
+// larger than zero.
+func WithMaxLookaheadInstances(i uint64) Option {
+	return func(o *options) error {
+		if i == 0 {
+			return errors.New("max lookahead instances must be larger than zero")
+		}
+		o.maxLookaheadInstances = i
+		return nil
+	}
+}
+
+// WithMaxQueuedMessageAge sets the maximum duration for which messages for a
+// future instance are queued while that instance has not begun. Once the
+// messages first queued for an instance are older than the given age, the
+// instance is expected to begin too far in the future and all of its queued
+// messages are dropped, reclaiming the space they occupy. This prevents messages
+// for instances within the lookahead that will not begin for a long time from
+// being held indefinitely. Stale messages are dropped as new messages are
+// queued. Defaults to zero if unset, i.e. queued messages are kept until their
+// instance begins or is skipped.
+func WithMaxQueuedMessageAge(age time.Duration) Option {
+	return func(o *options) error {
+		if age < 0 {
+			return fmt.Errorf("max queued message age must not be negative; got: %s", age)
+		}
+		o.maxQueuedMessageAge = age
+		return nil
+	}
+}
+
+// WithMaxFutureInstances sets the maximum number of instances ahead of the
+// current instance for which messages are queued until their instance begins.
+// Messages for instances further ahead are dropped, such that messages for
+// arbitrarily distant instances cannot exhaust memory. Defaults to 20 if unset.
+// It must be larger than zero.
+func WithMaxFutureInstances(i uint64) Option {
+	return func(o *options) error {
+		if i == 0 {
+			return errors.New("max future instances must be larger than zero")
+		}
+		o.maxFutureInstances = i
+		return nil
+	}
+}
+
+// WithMaxQueuedMessagesPerSender sets the maximum number of messages queued from
+// a single sender for each future instance. Further messages from the sender
+// for that instance are dropped, such that a sender cannot exhaust memory by
+// flooding messages for many distinct rounds. Defaults to 64 if unset. It must
+// be larger than zero.
+func WithMaxQueuedMessagesPerSender(n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("max queued messages per sender must be larger than zero; got: %d", n)
+		}
+		o.maxQueuedMessagesPerSender = n
+		return nil
+	}
+}
+
 // WithMaxCachedInstances sets the maximum number of instances for which
 // validated messages are cached. Defaults to 10 if unset.
 func WithMaxCachedInstances(v int) Option {
@@ -134,6 +311,144 @@ func WithMaxCachedMessagesPerInstance(v int) Option {
 	}
 }
 
+// WithMaxConcurrentVerifications sets the maximum number of aggregate signature
+// verifications performed concurrently during message validation. Aggregate
+// verification is CPU-bound; limiting its concurrency avoids scheduler thrash
+// under heavy validation load, e.g. by setting it to GOMAXPROCS. It must be
+// larger than zero. Defaults to no limit if unset.
+func WithMaxConcurrentVerifications(v int) Option {
+	return func(o *options) error {
+		if v <= 0 {
+			return fmt.Errorf("max concurrent verifications must be larger than zero; got: %d", v)
+		}
+		o.maxConcurrentVerifications = v
+		return nil
+	}
+}
+
+// WithTicketBatchVerification sets the maximum number of CONVERGE tickets
+// verified together as a batch during message validation. Tickets of messages
+// validated concurrently while a batch is being verified are verified together
+// as the next batch, via Verifier.BatchVerify if the host implements
+// BatchVerifier. An invalid ticket rejects only the message it belongs to.
+// Zero disables batching, verifying each ticket on its own. Defaults to zero if
+// unset.
+func WithTicketBatchVerification(maxBatchSize int) Option {
+	return func(o *options) error {
+		if maxBatchSize < 0 {
+			return fmt.Errorf("max ticket batch size cannot be less than zero; got: %d", maxBatchSize)
+		}
+		o.maxTicketBatchSize = maxBatchSize
+		return nil
+	}
+}
+
+// WithMaxCommitteeSize sets the maximum number of entries in the power table of
+// a committee. Committees larger than the maximum are rejected with an error
+// wrapping ErrValidationNoCommittee, which bounds the size of justification
+// bitfields and the cost of aggregate signature verification. Zero means no
+// limit. Defaults to zero if unset. See ec.WithCommitteeTruncation to truncate
+// committees to a maximum size instead.
+func WithMaxCommitteeSize(size int) Option {
+	return func(o *options) error {
+		if size < 0 {
+			return fmt.Errorf("max committee size cannot be less than zero; got: %d", size)
+		}
+		o.maxCommitteeSize = size
+		return nil
+	}
+}
+
+// WithMaxTrackedValuesPerInstance sets the maximum number of distinct values
+// an instance tracks the support of, across the QUALITY phase, every round and
+// DECIDE. Beyond the maximum, newly received values are left untracked as long
+// as they cannot possibly reach a strong quorum, which bounds the memory an
+// adversary can consume by sending many distinct values. Values that could
+// reach a strong quorum are always tracked. Zero means no limit. Defaults to
+// zero if unset.
+func WithMaxTrackedValuesPerInstance(count int) Option {
+	return func(o *options) error {
+		if count < 0 {
+			return fmt.Errorf("max tracked values per instance cannot be less than zero; got: %d", count)
+		}
+		o.maxTrackedValuesPerInstance = count
+		return nil
+	}
+}
+
+// maxQuorumDenominator bounds the denominator of quorum fractions, such that
+// scaling the total power of a power table by a fraction never overflows.
+const maxQuorumDenominator = math.MaxUint16
+
+// WithStrongQuorum sets the fraction of total power, given as numerator over
+// denominator, that forms a strong quorum. The fraction must be larger than ½,
+// so that no two strong quorums can be disjoint, larger than the weak quorum
+// fraction, and such that any two strong quorums overlap by at least the weak
+// quorum fraction, i.e. 2·strong − 1 ≥ weak. Note that finality certificates
+// validated outside of a participant always require the default strong quorum.
+// Defaults to ⅔ if unset.
+func WithStrongQuorum(numerator, denominator int64) Option {
+	return func(o *options) error {
+		if err := validateQuorumFraction(numerator, denominator); err != nil {
+			return fmt.Errorf("invalid strong quorum: %w", err)
+		}
+		if 2*numerator <= denominator {
+			return fmt.Errorf("strong quorum must be larger than 1/2; got: %d/%d", numerator, denominator)
+		}
+		o.quorum.strongNumerator, o.quorum.strongDenominator = numerator, denominator
+		return nil
+	}
+}
+
+// WithWeakQuorum sets the fraction of total power, given as numerator over
+// denominator, beyond which power forms a weak quorum. It is also the maximum
+// fraction of power assumed to be held by an adversary. The fraction must be
+// smaller than the strong quorum fraction, and no larger than 2·strong − 1.
+// Defaults to ⅓ if unset.
+func WithWeakQuorum(numerator, denominator int64) Option {
+	return func(o *options) error {
+		if err := validateQuorumFraction(numerator, denominator); err != nil {
+			return fmt.Errorf("invalid weak quorum: %w", err)
+		}
+		o.quorum.weakNumerator, o.quorum.weakDenominator = numerator, denominator
+		return nil
+	}
+}
+
+func validateQuorumFraction(numerator, denominator int64) error {
+	switch {
+	case denominator <= 0 || denominator > maxQuorumDenominator:
+		return fmt.Errorf("denominator must be within [1, %d]; got: %d", maxQuorumDenominator, denominator)
+	case numerator <= 0 || numerator > denominator:
+		return fmt.Errorf("fraction must be within (0, 1]; got: %d/%d", numerator, denominator)
+	}
+	return nil
+}
+
+// WithMaxJustificationSigners bounds the number of signers of a justification
+// to the given factor of the largest number of signers that an honest
+// participant ever aggregates into one, beyond which justifications are rejected
+// as invalid. Since they are costlier to verify, justifications with needlessly
+// many signers may otherwise be used to exhaust the resources of participants.
+//
+// Honest participants aggregate signers in decreasing order of power until they
+// reach a strong quorum. So, no honest justification has more signers than the
+// least powerful participants that together hold a strong quorum, which is the
+// number of signers the factor applies to. Under an even distribution of power,
+// that is exactly the number of signers of any strong quorum found.
+//
+// The factor must be at least 1, or zero to disable the bound. Defaults to zero
+// if unset.
+func WithMaxJustificationSigners(factor float64) Option {
+	return func(o *options) error {
+		if factor != 0 && factor < 1 {
+			return fmt.Errorf("justification signers factor must be zero or at least 1; got: %v", factor)
+		}
+		o.maxJustificationSignersFactor = factor
+		return nil
+	}
+}
+
 // WithCommitteeLookback sets the number of instances in the past from which the
 // committee for the latest instance is derived. Defaults to 10 if unset.
 func WithCommitteeLookback(lookback uint64) Option {
@@ -143,6 +458,88 @@ func WithCommitteeLookback(lookback uint64) Option {
 	}
 }
 
+// WithSkipToCommitOnWeakQuorum sets whether to skip ahead to the COMMIT phase
+// upon observing a weak quorum of COMMIT messages for the current proposal in
+// the current round, before a strong quorum of PREPARE messages is received
+// locally. The COMMIT message is justified by the strong quorum of PREPAREs
+// carried by the received COMMITs, and so commits to the same value the
+// participant would have committed to upon receiving the PREPAREs itself.
+//
+// Skipping straight to DECIDE instead is not possible: a DECIDE must be
+// justified by a strong quorum of COMMITs, which a weak quorum does not make,
+// and a strong quorum of COMMITs already causes a decision at any phase. Hence
+// the DECIDE that follows still requires a strong quorum of COMMITs. Defaults
+// to false if unset.
+func WithSkipToCommitOnWeakQuorum(enabled bool) Option {
+	return func(o *options) error {
+		o.skipToCommitOnWeakQuorum = enabled
+		return nil
+	}
+}
+
+// WithLateCommitRelay sets whether to accept COMMIT messages that arrive after
+// the instance they belong to has been decided locally, so that they are
+// relayed to other participants, and to respond to them by rebroadcasting the
+// local DECIDE message at most once per instance. This helps slower
+// participants that are yet to observe a strong quorum of COMMITs to decide.
+// Otherwise, such messages are rejected as too old. Defaults to false if unset.
+func WithLateCommitRelay(enabled bool) Option {
+	return func(o *options) error {
+		o.relayLateCommits = enabled
+		return nil
+	}
+}
+
+// WithNoSway sets whether the participant refuses to sway from its own
+// proposal to a value it did not originally prefer, where the protocol would
+// otherwise sway it at CONVERGE, at COMMIT, or when skipping ahead to a future
+// round. A participant that refuses to sway at COMMIT can only progress to the
+// next round once it observes a strong quorum of COMMITs for bottom. This is
+// purely for studying how swaying affects convergence in simulation:
+// refusing to sway is UNSAFE FOR PRODUCTION, since the participant may stall
+// and no longer help the network converge. Defaults to false if unset.
+func WithNoSway(enabled bool) Option {
+	return func(o *options) error {
+		o.noSway = enabled
+		return nil
+	}
+}
+
+// WithDecisionSink sets the DecisionSink to which the chain finalized by each
+// instance is delivered synchronously, before the next instance is scheduled.
+// Each delivery is bounded by the given timeout, which must be larger than
+// zero. A failed delivery is retried after the timeout, and so a sink that keeps
+// failing stalls the participant at the decided instance until it succeeds, or
+// the participant is started at another instance. Defaults to no sink if unset.
+func WithDecisionSink(sink DecisionSink, timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout <= 0 {
+			return fmt.Errorf("decision sink timeout must be larger than zero; got: %s", timeout)
+		}
+		o.decisionSink = sink
+		o.decisionSinkTimeout = timeout
+		return nil
+	}
+}
+
+// WithEquivocationReporter sets the EquivocationReporter to which evidence of
+// equivocation is reported as it is detected. Defaults to no reporter if unset.
+func WithEquivocationReporter(reporter EquivocationReporter) Option {
+	return func(o *options) error {
+		o.equivocationReporter = reporter
+		return nil
+	}
+}
+
+// WithPhaseObserver sets the PhaseObserver to which the time spent in each phase
+// is reported as the phase ends. Defaults to no observer if unset.
+func WithPhaseObserver(observer PhaseObserver) Option {
+	return func(o *options) error {
+		o.phaseObserver = observer
+		return nil
+	}
+}
+
 var defaultRebroadcastAfter = exponentialBackoffer(1.3, 0.1, 3*time.Second, 30*time.Second)
 
 // WithRebroadcastBackoff sets the duration after the gPBFT timeout has elapsed, at
@@ -1,6 +1,9 @@
 package gpbft
 
 import (
+	"context"
+	"math"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,3 +35,143 @@ func Test_exponentialBackofferSpread(t *testing.T) {
 		require.NotEqual(t, backoff1, backoff2, "backoffs were not randomized")
 	}
 }
+
+func TestOptions_QuorumFractions(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name    string
+		options []Option
+		wantErr string
+	}{
+		{name: "raised", options: []Option{WithStrongQuorum(3, 4), WithWeakQuorum(1, 4)}},
+		{name: "strong at half", options: []Option{WithStrongQuorum(1, 2)}, wantErr: "larger than 1/2"},
+		{name: "strong below weak", options: []Option{WithStrongQuorum(3, 5), WithWeakQuorum(2, 3)}, wantErr: "must be larger than weak quorum"},
+		{name: "strong equal to weak", options: []Option{WithWeakQuorum(2, 3)}, wantErr: "must be larger than weak quorum"},
+		{name: "strong overlap at weak", options: []Option{WithStrongQuorum(3, 5), WithWeakQuorum(1, 5)}},
+		{name: "strong overlap below weak", options: []Option{WithStrongQuorum(3, 5), WithWeakQuorum(1, 4)}, wantErr: "must overlap by at least the weak quorum"},
+		{name: "zero denominator", options: []Option{WithWeakQuorum(1, 0)}, wantErr: "denominator"},
+		{name: "beyond whole", options: []Option{WithStrongQuorum(4, 3)}, wantErr: "within (0, 1]"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newOptions(test.options...)
+			if test.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptions_MaxConcurrentVerifications(t *testing.T) {
+	t.Parallel()
+	defaults, err := newOptions()
+	require.NoError(t, err)
+	require.Zero(t, defaults.maxConcurrentVerifications, "verifications must be unbounded by default")
+
+	bounded, err := newOptions(WithMaxConcurrentVerifications(3))
+	require.NoError(t, err)
+	require.Equal(t, 3, bounded.maxConcurrentVerifications)
+
+	for _, v := range []int{0, -1} {
+		_, err := newOptions(WithMaxConcurrentVerifications(v))
+		require.ErrorContains(t, err, "max concurrent verifications must be larger than zero")
+	}
+}
+
+func TestOptions_DeltaBackOffExponent(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name     string
+		exponent float64
+		wantErr  bool
+	}{
+		{name: "constant", exponent: 1},
+		{name: "growing", exponent: 1.3},
+		{name: "shrinking", exponent: 0.9, wantErr: true},
+		{name: "zero", exponent: 0, wantErr: true},
+		{name: "negative", exponent: -1.3, wantErr: true},
+		{name: "NaN", exponent: math.NaN(), wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newOptions(WithDeltaBackOffExponent(test.exponent))
+			if test.wantErr {
+				require.ErrorContains(t, err, "must be at least 1")
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParticipant_RoundTimeout(t *testing.T) {
+	t.Parallel()
+	host := NewMockHost(t)
+	host.EXPECT().NetworkName().Return("test")
+	for _, test := range []struct {
+		name     string
+		exponent float64
+		want     []time.Duration
+	}{
+		{
+			name:     "constant",
+			exponent: 1,
+			want:     []time.Duration{2 * time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second},
+		},
+		{
+			name:     "growing",
+			exponent: 2,
+			want:     []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second},
+		},
+		{
+			name:     "growing fractionally",
+			exponent: 1.5,
+			want:     []time.Duration{2 * time.Second, 3 * time.Second, 4500 * time.Millisecond, 6750 * time.Millisecond},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			subject, err := NewParticipant(host, WithDelta(time.Second), WithDeltaBackOffExponent(test.exponent))
+			require.NoError(t, err)
+			for round, want := range test.want {
+				require.Equal(t, want, subject.RoundTimeout(uint64(round)), "at round %d", round)
+			}
+		})
+	}
+}
+
+// blockingDecisionSink blocks each delivery until released, regardless of its
+// context.
+type blockingDecisionSink struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *blockingDecisionSink) ReceiveFinalizedChain(context.Context, uint64, *ECChain) error {
+	s.calls.Add(1)
+	<-s.release
+	return nil
+}
+
+func TestParticipant_DecisionSinkDeliveriesDoNotOverlap(t *testing.T) {
+	t.Parallel()
+	host := NewMockHost(t)
+	host.EXPECT().NetworkName().Return("test")
+	sink := &blockingDecisionSink{release: make(chan struct{})}
+	subject, err := NewParticipant(host, WithDecisionSink(sink, 10*time.Millisecond))
+	require.NoError(t, err)
+	decision := &Justification{Vote: Payload{Instance: 1}}
+
+	// The first delivery times out, but is not abandoned.
+	require.ErrorIs(t, subject.sinkDecision(decision), context.DeadlineExceeded)
+	require.EqualValues(t, 1, sink.calls.Load())
+
+	// No further delivery is made until the first returns.
+	require.ErrorContains(t, subject.sinkDecision(decision), "previous delivery still in progress")
+	require.EqualValues(t, 1, sink.calls.Load())
+
+	close(sink.release)
+	require.Eventually(t, func() bool {
+		return subject.sinkDecision(decision) == nil
+	}, time.Second, time.Millisecond)
+	require.EqualValues(t, 2, sink.calls.Load())
+}
@@ -4,13 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"math"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/filecoin-project/go-f3/internal/caching"
+	"github.com/filecoin-project/go-f3/internal/measurements"
 	logging "github.com/ipfs/go-log/v2"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
@@ -25,6 +33,9 @@ var (
 type Participant struct {
 	*options
 	host Host
+	// attrNetwork attributes the metrics of this participant to the network it
+	// participates in.
+	attrNetwork attribute.KeyValue
 
 	// apiMutex prevents concurrent access to stateful API methods to ensure thread
 	// safety. This mutex should be locked at the beginning of each public API method
@@ -50,6 +61,36 @@ type Participant struct {
 	// See Participant.finishCurrentInstance, Participant.validator.
 	messageCache *caching.GroupedSet
 	validator    *cachingValidator
+	// finalized is the justification of the latest decision reached by this
+	// Participant, or nil if no decision has been reached yet.
+	finalized atomic.Pointer[Justification]
+	// expectedBase is the head of the chain finalized by the instance before
+	// baseInstance, which the proposal for baseInstance must extend, or nil if
+	// unknown. See StartInstanceFrom.
+	expectedBase *TipSet
+	baseInstance uint64
+	// candidates is a snapshot of the candidates of the current instance, in the
+	// order in which they were added, or nil if there is no current instance. See
+	// CurrentCandidates.
+	candidates atomic.Pointer[[]*ECChain]
+	// lateCommitRelays records the instances whose DECIDE has been rebroadcast in
+	// response to a late COMMIT, so that late COMMITs trigger at most one
+	// rebroadcast per instance. See relayLateCommit.
+	lateCommitRelays map[uint64]struct{}
+	// sinking is closed once the latest delivery to the decision sink returns, or
+	// nil if there has been none. A delivery that outlives its timeout is never
+	// abandoned, but blocks further deliveries until it returns.
+	sinking chan struct{}
+	// undelivered is the decision of the current instance that the decision sink
+	// failed to receive, to be delivered again upon the next alarm, or nil if
+	// there is none. See deliverDecision.
+	undelivered *Justification
+	// syncing signals whether this Participant is catching up with the network by
+	// other means, during which no instance is begun. See SetSyncing.
+	syncing bool
+	// beginPending signals whether beginning the current instance was deferred
+	// while syncing, to be begun once syncing stops.
+	beginPending bool
 }
 
 type validatedMessage struct {
@@ -83,21 +124,32 @@ func NewParticipant(host Host, o ...Option) (*Participant, error) {
 	if err != nil {
 		return nil, err
 	}
-	ccp := newCachedCommitteeProvider(host)
+	ccp := newCachedCommitteeProvider(host, opts.maxCommitteeSize)
 	messageCache := caching.NewGroupedSet(opts.maxCachedInstances, opts.maxCachedMessagesPerInstance)
 	progression := newAtomicProgression()
+	nn := host.NetworkName()
 	return &Participant{
 		options:           opts,
 		host:              host,
+		attrNetwork:       measurements.AttrNetwork.String(string(nn)),
 		committeeProvider: ccp,
-		mqueue:            newMessageQueue(opts.maxLookaheadRounds),
+		mqueue:            newMessageQueue(opts.maxLookaheadRounds, opts.maxFutureInstances, opts.maxQueuedMessagesPerSender),
 		messageCache:      messageCache,
 		progression:       progression,
-		validator:         newValidator(host, ccp, progression.Get, messageCache, opts.committeeLookback),
+		validator:         newValidator(nn, host, ccp, progression.Get, messageCache, opts),
 	}, nil
 }
 
 func (p *Participant) StartInstanceAt(instance uint64, when time.Time) (err error) {
+	return p.StartInstanceFrom(instance, nil, when)
+}
+
+// StartInstanceFrom is like StartInstanceAt, and additionally requires the chain
+// proposed for the instance to extend the given base, typically the head of the
+// chain finalized by the instance before it as learnt by the host, e.g. from a
+// finality certificate when catching up. A nil base leaves the proposal
+// unchecked, unless the instance follows one decided by this Participant.
+func (p *Participant) StartInstanceFrom(instance uint64, base *TipSet, when time.Time) (err error) {
 	if !p.apiMutex.TryLock() {
 		panic("concurrent API method invocation")
 	}
@@ -107,14 +159,19 @@ func (p *Participant) StartInstanceAt(instance uint64, when time.Time) (err erro
 			err = newPanicError(r)
 		}
 		if err != nil {
-			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err)))
+			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err), p.attrNetwork))
 		}
 	}()
 
 	// Finish current instance to clean old committees and old messages queued
 	// and prepare to begin a new instance.
 	_ = p.finishCurrentInstance()
+	p.undelivered = nil
 	p.beginNextInstance(instance)
+	p.beginPending = false
+	if base != nil {
+		p.expectBase(instance, base)
+	}
 
 	// Set the alarm to begin a new instance at the specified time.
 	p.host.SetAlarm(when)
@@ -122,6 +179,52 @@ func (p *Participant) StartInstanceAt(instance uint64, when time.Time) (err erro
 	return err
 }
 
+// SetSyncing sets whether this Participant is syncing, i.e. catching up with the
+// network by means other than GPBFT such as certificate exchange, typically
+// followed by StartInstanceAt to skip to the instance caught up to.
+//
+// While syncing, the current instance is abandoned and no instance is begun, so
+// that nothing is broadcast. Messages for the current instance are queued along
+// with those for future instances, and are dropped once the Participant skips
+// past their instance.
+//
+// Once syncing stops, the instance at which this Participant is, if due to have
+// begun, is begun immediately with any messages queued for it.
+func (p *Participant) SetSyncing(syncing bool) (err error) {
+	if !p.apiMutex.TryLock() {
+		panic("concurrent API method invocation")
+	}
+	defer p.apiMutex.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(r)
+		}
+		if err != nil {
+			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err), p.attrNetwork))
+		}
+	}()
+
+	if p.syncing == syncing {
+		return nil
+	}
+	p.syncing = syncing
+	if syncing {
+		if p.gpbft != nil {
+			currentInstance := p.Progress().ID
+			p.trace("abandoning instance %d to sync", currentInstance)
+			_ = p.finishCurrentInstance()
+			p.beginNextInstance(currentInstance)
+			p.beginPending = true
+		}
+		return nil
+	}
+	if p.beginPending {
+		p.beginPending = false
+		return p.beginInstance()
+	}
+	return nil
+}
+
 // Progress returns the latest progress of this Participant in terms of GPBFT
 // instance ID, round and phase.
 //
@@ -141,7 +244,7 @@ func (p *Participant) ValidateMessage(msg *GMessage) (valid ValidatedMessage, er
 			err = newPanicError(r)
 		}
 		if err != nil {
-			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err)))
+			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err), p.attrNetwork))
 		}
 	}()
 	return p.validator.ValidateMessage(msg)
@@ -158,20 +261,25 @@ func (p *Participant) ReceiveMessage(vmsg ValidatedMessage) (err error) {
 			err = newPanicError(r)
 		}
 		if err != nil {
-			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err)))
+			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err), p.attrNetwork))
 		}
 	}()
 	msg := vmsg.Message()
 
 	currentInstance := p.Progress().ID
-	// Drop messages for past instances.
+	// Drop messages for past instances. An instance is finished as soon as it
+	// terminates, so this is the only path by which late COMMITs are relayed.
 	if msg.Vote.Instance < currentInstance {
 		p.trace("dropping message from old instance %d while received in instance %d",
 			msg.Vote.Instance, currentInstance)
+		if msg.Vote.Phase == COMMIT_PHASE {
+			p.relayLateCommit(msg)
+		}
 		return nil
 	}
 
-	// If the message is for the current instance, deliver immediately.
+	// If the message is for the current instance, deliver immediately. While
+	// syncing, there is no current instance and so the message is queued.
 	if p.gpbft != nil && msg.Vote.Instance == currentInstance {
 		if err := p.gpbft.Receive(msg); err != nil {
 			return fmt.Errorf("%w: %w", ErrReceivedInternalError, err)
@@ -179,11 +287,48 @@ func (p *Participant) ReceiveMessage(vmsg ValidatedMessage) (err error) {
 		p.handleDecision()
 	} else {
 		// Otherwise queue it for a future instance.
-		p.mqueue.Add(msg)
+		p.mqueue.Add(msg, p.queueTime())
 	}
 	return nil
 }
 
+// queueTime returns the time at which messages queued now are considered queued,
+// having first dropped any queued messages that are too old. The zero time is
+// returned if queued messages never expire.
+func (p *Participant) queueTime() time.Time {
+	if p.maxQueuedMessageAge == 0 {
+		return time.Time{}
+	}
+	now := p.host.Time()
+	for _, instance := range p.mqueue.DropQueuedBefore(now.Add(-p.maxQueuedMessageAge)) {
+		p.trace("dropped messages queued for instance %d that has not begun for longer than %s", instance, p.maxQueuedMessageAge)
+	}
+	return now
+}
+
+// relayLateCommit responds to a COMMIT message that arrived after its instance
+// has been decided locally by rebroadcasting the local DECIDE message for that
+// instance, if late COMMITs are relayed.
+func (p *Participant) relayLateCommit(msg *GMessage) {
+	if !p.relayLateCommits {
+		return
+	}
+	// Any number of distinct COMMITs may be sent by any number of senders, all of
+	// which would be relayed. Respond to the first only, since a single rebroadcast
+	// of the DECIDE reaches every sender and is enough for all of them to decide.
+	if _, found := p.lateCommitRelays[msg.Vote.Instance]; found {
+		return
+	}
+	if p.lateCommitRelays == nil {
+		p.lateCommitRelays = make(map[uint64]struct{})
+	}
+	p.lateCommitRelays[msg.Vote.Instance] = struct{}{}
+	p.trace("rebroadcasting DECIDE at instance %d in response to late COMMIT from P%d", msg.Vote.Instance, msg.Sender)
+	if err := p.host.RequestRebroadcast(Instant{ID: msg.Vote.Instance, Round: 0, Phase: DECIDE_PHASE}); err != nil {
+		log.Warnw("failed to rebroadcast DECIDE in response to late COMMIT", "instance", msg.Vote.Instance, "err", err)
+	}
+}
+
 func (p *Participant) ReceiveAlarm() (err error) {
 	if !p.apiMutex.TryLock() {
 		panic("concurrent API method invocation")
@@ -194,11 +339,16 @@ func (p *Participant) ReceiveAlarm() (err error) {
 			err = newPanicError(r)
 		}
 		if err != nil {
-			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err)))
+			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err), p.attrNetwork))
 		}
 	}()
 
 	if p.gpbft == nil {
+		if p.undelivered != nil {
+			// The alarm is for retrying the delivery of the current decision.
+			p.deliverDecision(p.undelivered)
+			return nil
+		}
 		// The alarm is for fetching the next chain and beginning a new instance.
 		return p.beginInstance()
 	}
@@ -211,6 +361,11 @@ func (p *Participant) ReceiveAlarm() (err error) {
 
 func (p *Participant) beginInstance() error {
 	currentInstance := p.Progress().ID
+	if p.syncing {
+		p.trace("deferring beginning instance %d while syncing", currentInstance)
+		p.beginPending = true
+		return nil
+	}
 	data, chain, err := p.host.GetProposal(currentInstance)
 	if err != nil {
 		return fmt.Errorf("failed fetching chain for instance %d: %w", currentInstance, err)
@@ -219,6 +374,11 @@ func (p *Participant) beginInstance() error {
 	if chain.IsZero() {
 		return errors.New("canonical chain cannot be zero-valued")
 	}
+	// Every message for the instance must share the base of its proposal, so a
+	// proposal on the wrong base would stall the instance rather than fail.
+	if p.expectedBase != nil && p.baseInstance == currentInstance && !chain.HasBase(p.expectedBase) {
+		return fmt.Errorf("proposal for instance %d has base %s, expected %s: %w", currentInstance, chain.Base(), p.expectedBase, ErrProposalWrongBase)
+	}
 	chain = chain.Prefix(ChainMaxLen - 1)
 	if err := chain.Validate(); err != nil {
 		return fmt.Errorf("invalid canonical chain: %w", err)
@@ -252,23 +412,76 @@ func (p *Participant) handleDecision() {
 	if !p.terminated() {
 		return
 	}
-	decision := p.finishCurrentInstance()
+	p.deliverDecision(p.finishCurrentInstance())
+}
+
+// deliverDecision delivers the decision of the current instance to the decision
+// sink, if any, and then to the host, which schedules the next instance. Should
+// the sink fail, the delivery is retried upon an alarm set after the sink
+// timeout, such that the next instance is not begun until the sink succeeds.
+func (p *Participant) deliverDecision(decision *Justification) {
+	if err := p.sinkDecision(decision); err != nil {
+		p.trace("failed to sink decision, retrying after %s: %+v", p.decisionSinkTimeout, err)
+		p.undelivered = decision
+		p.host.SetAlarm(p.host.Time().Add(p.decisionSinkTimeout))
+		return
+	}
+	p.undelivered = nil
 	nextStart, err := p.host.ReceiveDecision(decision)
 	if err != nil {
 		p.trace("failed to receive decision: %+v", err)
 		p.host.SetAlarm(time.Time{})
 	} else {
+		// Only a decision that has been delivered is considered finalized.
+		p.finalized.Store(decision)
 		p.beginNextInstance(p.Progress().ID + 1)
+		p.expectBase(decision.Vote.Instance+1, decision.Vote.Value.Head())
 		p.host.SetAlarm(nextStart)
 	}
 }
 
+// sinkDecision delivers the finalized chain to the decision sink, if any, and
+// waits for it to be processed up to the configured timeout. It fails without
+// delivering if a previous delivery has yet to return.
+func (p *Participant) sinkDecision(decision *Justification) error {
+	if p.decisionSink == nil {
+		return nil
+	}
+	if p.sinking != nil {
+		select {
+		case <-p.sinking:
+		default:
+			return fmt.Errorf("delivering decision at instance %d: previous delivery still in progress", decision.Vote.Instance)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.decisionSinkTimeout)
+	defer cancel()
+	// Bound the wait regardless of whether the sink respects context cancellation.
+	result := make(chan error, 1)
+	sinking := make(chan struct{})
+	p.sinking = sinking
+	go func() {
+		defer close(sinking)
+		result <- p.decisionSink.ReceiveFinalizedChain(ctx, decision.Vote.Instance, decision.Vote.Value)
+	}()
+	select {
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("delivering decision at instance %d: %w", decision.Vote.Instance, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("delivering decision at instance %d: %w", decision.Vote.Instance, ctx.Err())
+	}
+}
+
 func (p *Participant) finishCurrentInstance() *Justification {
 	var decision *Justification
 	if p.gpbft != nil {
 		decision = p.gpbft.terminationValue
 	}
 	p.gpbft = nil
+	p.candidates.Store(nil)
 	if currentInstance := p.Progress().ID; currentInstance > 1 {
 		// Remove all cached messages that are older than the previous instance
 		p.messageCache.RemoveGroupsLessThan(currentInstance - 1)
@@ -278,19 +491,71 @@ func (p *Participant) finishCurrentInstance() *Justification {
 
 func (p *Participant) beginNextInstance(nextInstance uint64) {
 	// Clean all messages queued and for instances below the next one.
-	for inst := range p.mqueue.messages {
-		if inst < nextInstance {
-			delete(p.mqueue.messages, inst)
-		}
-	}
+	p.mqueue.Advance(nextInstance)
 	// Clean committees from instances below the previous one. We keep the last committee so we
 	// can continue to validate and propagate DECIDE messages.
 	if nextInstance > 0 {
 		p.committeeProvider.EvictCommitteesBefore(nextInstance - 1)
 	}
+	// Late COMMITs are accepted for the previous instance at most.
+	for instance := range p.lateCommitRelays {
+		if instance+1 < nextInstance {
+			delete(p.lateCommitRelays, instance)
+		}
+	}
 	p.progression.NotifyProgress(Instant{ID: nextInstance, Round: 0, Phase: INITIAL_PHASE})
 }
 
+// expectBase records the base that the proposal for the given instance must
+// extend.
+func (p *Participant) expectBase(instance uint64, base *TipSet) {
+	p.expectedBase = base
+	p.baseInstance = instance
+}
+
+// Finalized returns the justification of the latest decision reached by this
+// Participant, and whether any decision has been reached at all. Decisions
+// learnt otherwise, e.g. by skipping to a future instance, are not reflected.
+//
+// This API is safe for concurrent use.
+func (p *Participant) Finalized() (*Justification, bool) {
+	decision := p.finalized.Load()
+	return decision, decision != nil
+}
+
+// CurrentCandidates returns a copy of the candidates of the current instance,
+// i.e. the values it considers acceptable to decide, in the order in which they
+// were added. It returns nil if there is no current instance.
+//
+// This API is safe for concurrent use.
+func (p *Participant) CurrentCandidates() []*ECChain {
+	candidates := p.candidates.Load()
+	if candidates == nil {
+		return nil
+	}
+	clones := make([]*ECChain, len(*candidates))
+	for i, candidate := range *candidates {
+		clones[i] = candidate.clone()
+	}
+	return clones
+}
+
+// RoundTimeout returns the timeout of the phases of the given round, before the
+// delta multiplier of each phase is applied. The timeout starts at twice delta
+// and grows by the delta back-off exponent with each round.
+//
+// This API is safe for concurrent use.
+func (p *Participant) RoundTimeout(round uint64) time.Duration {
+	return p.phaseTimeout(1.0, round)
+}
+
+// phaseTimeout returns the timeout of a phase with the given delta multiplier
+// at the given round.
+func (p *Participant) phaseTimeout(multi float64, round uint64) time.Duration {
+	delta := time.Duration(float64(p.delta) * multi * math.Pow(p.deltaBackOffExponent, float64(round)))
+	return 2 * delta
+}
+
 func (p *Participant) terminated() bool {
 	return p.gpbft != nil && p.gpbft.current.Phase == TERMINATED_PHASE
 }
@@ -302,6 +567,68 @@ func (p *Participant) Describe() string {
 	return p.gpbft.Describe()
 }
 
+// SerializeQueuedMessages writes the messages queued for future instances to
+// the given writer, so that they may be restored after a restart via
+// RestoreQueuedMessages.
+func (p *Participant) SerializeQueuedMessages(w io.Writer) error {
+	if !p.apiMutex.TryLock() {
+		panic("concurrent API method invocation")
+	}
+	defer p.apiMutex.Unlock()
+	return p.mqueue.Serialize(w)
+}
+
+// RestoreQueuedMessages reads messages previously written by
+// SerializeQueuedMessages and queues them for future instances, or delivers them
+// straight away if for the current instance, just as ReceiveMessage does. Each
+// message is re-validated first, since the committees may have changed since
+// the messages were serialized. Messages that are no longer valid or are for
+// past instances are dropped.
+func (p *Participant) RestoreQueuedMessages(r io.Reader) (err error) {
+	if !p.apiMutex.TryLock() {
+		panic("concurrent API method invocation")
+	}
+	defer p.apiMutex.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(r)
+		}
+		if err != nil {
+			metrics.errorCounter.Add(context.TODO(), 1, metric.WithAttributes(metricAttributeFromError(err), p.attrNetwork))
+		}
+	}()
+
+	// The messages restored are bounded as they are queued for future instances
+	// below, and so are not bounded here.
+	restored := newMessageQueue(p.maxLookaheadRounds, 0, 0)
+	if err := restored.Deserialize(r); err != nil {
+		return fmt.Errorf("restoring queued messages: %w", err)
+	}
+	queuedAt := p.queueTime()
+	for _, msg := range restored.All() {
+		// The current instance may progress as restored messages are delivered.
+		currentInstance := p.Progress().ID
+		if msg.Vote.Instance < currentInstance {
+			continue
+		}
+		if _, err := p.validator.ValidateMessage(msg); err != nil {
+			p.trace("dropping invalid restored message from P%d at instance %d: %v", msg.Sender, msg.Vote.Instance, err)
+			continue
+		}
+		// The queue is only drained as instances begin, so messages for the current
+		// instance are delivered rather than queued, lest they never be.
+		if p.gpbft != nil && msg.Vote.Instance == currentInstance {
+			if err := p.gpbft.Receive(msg); err != nil {
+				return fmt.Errorf("%w: %w", ErrReceivedInternalError, err)
+			}
+			p.handleDecision()
+		} else {
+			p.mqueue.Add(msg, queuedAt)
+		}
+	}
+	return nil
+}
+
 func (p *Participant) tracingEnabled() bool {
 	return p.tracer != nil
 }
@@ -313,33 +640,68 @@ func (p *Participant) trace(format string, args ...any) {
 }
 
 // A collection of messages queued for delivery for a future instance.
-// The queue drops equivocations and unjustified messages beyond some round number.
+// The queue drops equivocations and unjustified messages beyond some round number,
+// along with messages for instances too far ahead and messages from senders
+// that have already queued too many for an instance.
 type messageQueue struct {
 	maxRound uint64
+	// maxFutureInstances is the maximum number of instances ahead of the current
+	// one for which messages are queued, or zero if unbounded.
+	maxFutureInstances uint64
+	// maxPerSender is the maximum number of messages queued per instance and
+	// sender, or zero if unbounded.
+	maxPerSender int
+	// current is the current instance, below which no messages are queued.
+	current uint64
 	// Maps instance -> sender -> messages.
 	// Note the relative order of messages is lost.
 	messages map[uint64]map[ActorID][]*GMessage
+	// Maps instance -> time at which messages were first queued for it.
+	queuedSince map[uint64]time.Time
 }
 
-func newMessageQueue(maxRound uint64) *messageQueue {
+func newMessageQueue(maxRound, maxFutureInstances uint64, maxPerSender int) *messageQueue {
 	return &messageQueue{
-		maxRound: maxRound,
-		messages: make(map[uint64]map[ActorID][]*GMessage),
+		maxRound:           maxRound,
+		maxFutureInstances: maxFutureInstances,
+		maxPerSender:       maxPerSender,
+		messages:           make(map[uint64]map[ActorID][]*GMessage),
+		queuedSince:        make(map[uint64]time.Time),
 	}
 }
 
-func (q *messageQueue) Add(msg *GMessage) {
-	instanceQueue, ok := q.messages[msg.Vote.Instance]
-	if !ok {
-		// There's no check on instance number being within a reasonable range here.
-		// It's assumed that spam messages for far future instances won't get this far.
-		instanceQueue = make(map[ActorID][]*GMessage)
-		q.messages[msg.Vote.Instance] = instanceQueue
+// Advance sets the current instance, dropping all messages queued for instances
+// below it.
+func (q *messageQueue) Advance(current uint64) {
+	q.current = current
+	for instance := range q.messages {
+		if instance < current {
+			delete(q.messages, instance)
+			delete(q.queuedSince, instance)
+		}
+	}
+}
+
+// Add queues the message at the given time, which is only used to determine the
+// age of the messages queued for an instance. See DropQueuedBefore.
+func (q *messageQueue) Add(msg *GMessage, at time.Time) {
+	// Drop messages for past instances, and for instances too far ahead so that
+	// spam messages for far future instances cannot exhaust memory.
+	if msg.Vote.Instance < q.current ||
+		(q.maxFutureInstances > 0 && msg.Vote.Instance > q.current+q.maxFutureInstances) {
+		return
 	}
 	// Drop unjustified messages beyond some round limit.
 	if msg.Vote.Round > q.maxRound && isSpammable(msg) {
 		return
 	}
+	// Reading a missing instance queue yields no messages, so it is only created
+	// once a message is actually queued below.
+	instanceQueue := q.messages[msg.Vote.Instance]
+	// Drop messages from senders that have queued too many for the instance.
+	if q.maxPerSender > 0 && len(instanceQueue[msg.Sender]) >= q.maxPerSender {
+		return
+	}
 	// Drop equivocations and duplicates (messages with the same sender, round and phase).
 	for _, m := range instanceQueue[msg.Sender] {
 		if m.Vote.Round == msg.Vote.Round && m.Vote.Phase == msg.Vote.Phase {
@@ -347,6 +709,11 @@ func (q *messageQueue) Add(msg *GMessage) {
 		}
 	}
 	// Queue remaining good messages.
+	if instanceQueue == nil {
+		instanceQueue = make(map[ActorID][]*GMessage)
+		q.messages[msg.Vote.Instance] = instanceQueue
+		q.queuedSince[msg.Vote.Instance] = at
+	}
 	instanceQueue[msg.Sender] = append(instanceQueue[msg.Sender], msg)
 }
 
@@ -364,5 +731,81 @@ func (q *messageQueue) Drain(instance uint64) []*GMessage {
 		return msgs[i].Vote.Phase < msgs[j].Vote.Phase
 	})
 	delete(q.messages, instance)
+	delete(q.queuedSince, instance)
 	return msgs
 }
+
+// DropQueuedBefore removes all messages for instances to which messages were
+// first queued before the given cutoff, and returns those instances in
+// ascending order.
+func (q *messageQueue) DropQueuedBefore(cutoff time.Time) []uint64 {
+	var dropped []uint64
+	for instance, since := range q.queuedSince {
+		if since.Before(cutoff) {
+			delete(q.messages, instance)
+			delete(q.queuedSince, instance)
+			dropped = append(dropped, instance)
+		}
+	}
+	slices.Sort(dropped)
+	return dropped
+}
+
+// All returns all queued messages without removing them. The returned messages
+// are ordered by instance, round, phase and sender.
+func (q *messageQueue) All() []*GMessage {
+	var msgs []*GMessage
+	for _, instance := range slices.Sorted(maps.Keys(q.messages)) {
+		for _, sender := range slices.Sorted(maps.Keys(q.messages[instance])) {
+			msgs = append(msgs, q.messages[instance][sender]...)
+		}
+	}
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if msgs[i].Vote.Instance != msgs[j].Vote.Instance {
+			return msgs[i].Vote.Instance < msgs[j].Vote.Instance
+		}
+		if msgs[i].Vote.Round != msgs[j].Vote.Round {
+			return msgs[i].Vote.Round < msgs[j].Vote.Round
+		}
+		return msgs[i].Vote.Phase < msgs[j].Vote.Phase
+	})
+	return msgs
+}
+
+// Serialize writes all queued messages to the given writer as a CBOR array of
+// messages.
+func (q *messageQueue) Serialize(w io.Writer) error {
+	msgs := q.All()
+	cw := cbg.NewCborWriter(w)
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(msgs))); err != nil {
+		return fmt.Errorf("writing queued message count: %w", err)
+	}
+	for _, msg := range msgs {
+		if err := msg.MarshalCBOR(cw); err != nil {
+			return fmt.Errorf("writing queued message: %w", err)
+		}
+	}
+	return nil
+}
+
+// Deserialize reads messages written by Serialize from the given reader and adds
+// them to the queue.
+func (q *messageQueue) Deserialize(r io.Reader) error {
+	cr := cbg.NewCborReader(r)
+	maj, count, err := cr.ReadHeader()
+	if err != nil {
+		return fmt.Errorf("reading queued message count: %w", err)
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected CBOR array of queued messages, got major type %d", maj)
+	}
+	for i := uint64(0); i < count; i++ {
+		var msg GMessage
+		if err := msg.UnmarshalCBOR(cr); err != nil {
+			return fmt.Errorf("reading queued message %d: %w", i, err)
+		}
+		// The time at which messages were originally queued is not serialized.
+		q.Add(&msg, time.Time{})
+	}
+	return nil
+}
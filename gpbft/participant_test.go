@@ -2,13 +2,16 @@ package gpbft_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-f3/emulator"
 	"github.com/filecoin-project/go-f3/gpbft"
 	"github.com/stretchr/testify/mock"
@@ -476,6 +479,155 @@ func TestParticipant(t *testing.T) {
 	})
 }
 
+func TestParticipant_Syncing(t *testing.T) {
+	t.Parallel()
+	const (
+		seed            = 984651320
+		initialInstance = 47
+	)
+	qualityFrom := func(subject *participantTestSubject, instance uint64) gpbft.ValidatedMessage {
+		require.NoError(subject.t, subject.powerTable.Add(somePowerEntry))
+		return Validated(&gpbft.GMessage{
+			Sender: somePowerEntry.ID,
+			Vote: gpbft.Payload{
+				Instance:         instance,
+				Phase:            gpbft.QUALITY_PHASE,
+				SupplementalData: *subject.supplementalData,
+				Value:            subject.canonicalChain,
+			},
+			Signature: []byte("barreleye"),
+		})
+	}
+	// syncFrom starts the subject syncing at its instance, with the alarm to begin
+	// the instance having fired.
+	syncFrom := func(subject *participantTestSubject) {
+		require.NoError(subject.t, subject.SetSyncing(true))
+		subject.host.EXPECT().SetAlarm(subject.time)
+		require.NoError(subject.t, subject.StartInstanceAt(subject.instance, subject.time))
+		require.NoError(subject.t, subject.ReceiveAlarm())
+	}
+	requireTraced := func(t *testing.T, subject *participantTestSubject, want string) {
+		t.Helper()
+		require.True(t, slices.ContainsFunc(subject.trace, func(msg string) bool {
+			return strings.Contains(msg, want)
+		}), "trace %q not found in %s", want, subject.trace)
+	}
+
+	t.Run("nothing is broadcast while syncing", func(t *testing.T) {
+		subject := newParticipantTestSubject(t, seed, initialInstance)
+		syncFrom(subject)
+		// The mock host fails on any unexpected attempt to fetch a proposal or
+		// broadcast.
+		subject.assertHostExpectations()
+		require.Equal(t, "nil", subject.Describe())
+		require.Equal(t, gpbft.Instant{ID: initialInstance, Phase: gpbft.INITIAL_PHASE}, subject.Progress())
+		requireTraced(t, subject, "deferring beginning instance 47 while syncing")
+	})
+	t.Run("current instance is abandoned", func(t *testing.T) {
+		subject := newParticipantTestSubject(t, seed, initialInstance)
+		subject.requireStart()
+		require.NoError(t, subject.SetSyncing(true))
+		require.Equal(t, "nil", subject.Describe())
+		require.Equal(t, gpbft.Instant{ID: initialInstance, Phase: gpbft.INITIAL_PHASE}, subject.Progress())
+		// The alarm for the abandoned instance neither progresses it nor begins it
+		// again.
+		require.NoError(t, subject.ReceiveAlarm())
+		subject.assertHostExpectations()
+		require.Equal(t, "nil", subject.Describe())
+	})
+	t.Run("queued messages are drained once synced", func(t *testing.T) {
+		subject := newParticipantTestSubject(t, seed, initialInstance)
+		syncFrom(subject)
+		require.NoError(t, subject.ReceiveMessage(qualityFrom(subject, initialInstance)))
+		require.Equal(t, "nil", subject.Describe())
+
+		subject.expectBeginInstance()
+		// The queued message may progress the instance beyond QUALITY.
+		subject.host.On("RequestBroadcast", mock.Anything).Return(nil).Maybe()
+		subject.host.On("SetAlarm", mock.Anything).Return().Maybe()
+		require.NoError(t, subject.SetSyncing(false))
+		subject.assertHostExpectations()
+		require.Equal(t, uint64(initialInstance), subject.Progress().ID)
+		require.NotEqual(t, "nil", subject.Describe())
+		requireTraced(t, subject, fmt.Sprintf("Delivering queued {%d} ← P%d", initialInstance, somePowerEntry.ID))
+	})
+	t.Run("queued messages are dropped once skipped past", func(t *testing.T) {
+		subject := newParticipantTestSubject(t, seed, initialInstance)
+		syncFrom(subject)
+		require.NoError(t, subject.ReceiveMessage(qualityFrom(subject, initialInstance)))
+
+		// Catch up to a later instance while still syncing.
+		subject.instance = initialInstance + 10
+		syncFrom(subject)
+		subject.expectBeginInstance()
+		require.NoError(t, subject.SetSyncing(false))
+		subject.assertHostExpectations()
+		subject.requireInstanceRoundPhase(subject.instance, 0, gpbft.QUALITY_PHASE)
+		require.False(t, slices.ContainsFunc(subject.trace, func(msg string) bool {
+			return strings.Contains(msg, "Delivering queued")
+		}))
+	})
+	t.Run("instance is not begun early once synced", func(t *testing.T) {
+		subject := newParticipantTestSubject(t, seed, initialInstance)
+		require.NoError(t, subject.SetSyncing(true))
+		subject.host.EXPECT().SetAlarm(subject.time)
+		require.NoError(t, subject.StartInstanceAt(subject.instance, subject.time))
+		// The alarm to begin the instance has not yet fired, and so the instance is
+		// begun once it does.
+		require.NoError(t, subject.SetSyncing(false))
+		require.Equal(t, "nil", subject.Describe())
+		subject.expectBeginInstance()
+		require.NoError(t, subject.ReceiveAlarm())
+		subject.assertHostExpectations()
+		subject.requireInstanceRoundPhase(initialInstance, 0, gpbft.QUALITY_PHASE)
+	})
+}
+
+func TestParticipant_CurrentCandidates(t *testing.T) {
+	t.Parallel()
+	const (
+		seed            = 984651320
+		initialInstance = 47
+	)
+	subject := newParticipantTestSubject(t, seed, initialInstance)
+	require.Nil(t, subject.CurrentCandidates())
+
+	base := subject.canonicalChain
+	subject.canonicalChain = base.Extend([]byte("lobster"))
+	subject.requireStart()
+	require.Equal(t, []*gpbft.ECChain{base}, subject.CurrentCandidates())
+
+	// A strong quorum of QUALITY for the proposal makes it a candidate.
+	require.NoError(t, subject.powerTable.Add(somePowerEntry))
+	subject.host.On("RequestBroadcast", mock.Anything).Return(nil).Maybe()
+	subject.host.On("SetAlarm", mock.Anything).Return().Maybe()
+	require.NoError(t, subject.ReceiveMessage(Validated(&gpbft.GMessage{
+		Sender: somePowerEntry.ID,
+		Vote: gpbft.Payload{
+			Instance:         initialInstance,
+			Phase:            gpbft.QUALITY_PHASE,
+			SupplementalData: *subject.supplementalData,
+			Value:            subject.canonicalChain,
+		},
+		Signature: []byte("barreleye"),
+	})))
+	got := subject.CurrentCandidates()
+	require.Len(t, got, 2)
+	require.True(t, got[0].Eq(base))
+	require.True(t, got[1].Eq(subject.canonicalChain))
+	require.True(t, slices.ContainsFunc(subject.trace, func(msg string) bool {
+		return strings.Contains(msg, "added candidate "+subject.canonicalChain.String())
+	}))
+
+	// Mutating the candidates returned does not affect the instance.
+	got[1].TipSets[1].Key[0] ^= 0xff
+	got[1].TipSets[1].Epoch++
+	got[0] = nil
+	require.True(t, subject.CurrentCandidates()[0].Eq(base))
+	require.True(t, subject.CurrentCandidates()[1].Eq(subject.canonicalChain))
+	require.Equal(t, []byte("lobster"), []byte(subject.canonicalChain.Head().Key))
+}
+
 func TestParticipant_ValidateMessage(t *testing.T) {
 	const (
 		seed                  = 894651320
@@ -490,10 +642,11 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 		signature = []byte("barreleye")
 	)
 	tests := []struct {
-		name    string
-		msg     func(*participantTestSubject) *gpbft.GMessage
-		msgs    func(*participantTestSubject) []*gpbft.GMessage
-		wantErr string
+		name      string
+		msg       func(*participantTestSubject) *gpbft.GMessage
+		msgs      func(*participantTestSubject) []*gpbft.GMessage
+		wantErr   string
+		wantErrIs error
 	}{
 		{
 			name: "valid message is accepted",
@@ -514,7 +667,6 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 		{
 			name: "far future instanceID is rejected",
 			msg: func(subject *participantTestSubject) *gpbft.GMessage {
-				subject.mockCommitteeUnavailableForInstance(initialInstanceNumber + 5)
 				return &gpbft.GMessage{
 					Vote: gpbft.Payload{
 						Instance:         initialInstanceNumber + 5,
@@ -522,7 +674,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: gpbft.ErrValidationNoCommittee.Error(),
+			wantErr:   gpbft.ErrValidationNoCommittee.Error(),
+			wantErrIs: gpbft.ErrValidationNoCommittee,
 		},
 		{
 			name: "zero message is error",
@@ -534,7 +687,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "sender 0 with zero power or not in power table",
+			wantErr:   "sender 0 with zero power or not in power table",
+			wantErrIs: gpbft.ErrValidationNoPower,
 		},
 		{
 			name: "unknown power is error",
@@ -547,7 +701,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "sender 42 with zero power or not in power table",
+			wantErr:   "sender 42 with zero power or not in power table",
+			wantErrIs: gpbft.ErrValidationNoPower,
 		},
 		{
 			name: "zero power is error",
@@ -560,7 +715,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "sender 1613 with zero power or not in power table",
+			wantErr:   "sender 1613 with zero power or not in power table",
+			wantErrIs: gpbft.ErrValidationNoPower,
 		},
 		{
 			name: "invalid value chain is error",
@@ -577,7 +733,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "invalid message vote value chain",
+			wantErr:   "invalid message vote value chain",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "zero vote is error",
@@ -590,7 +747,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "invalid vote phase: 0",
+			wantErr:   "invalid vote phase: 0",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "unknown vote phase is error",
@@ -604,7 +762,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "invalid vote phase: 42",
+			wantErr:   "invalid vote phase: 42",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "QUALITY with non-zero vote round is error",
@@ -619,7 +778,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "unexpected round 7 for quality phase",
+			wantErr:   "unexpected round 7 for quality phase",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "QUALITY with zero vote value is error",
@@ -633,7 +793,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "unexpected zero value for quality phase",
+			wantErr:   "unexpected zero value for quality phase",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "CONVERGE with zero vote round is error",
@@ -647,7 +808,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "unexpected round 0 for converge phase",
+			wantErr:   "unexpected round 0 for converge phase",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "CONVERGE with zero vote value is error",
@@ -662,7 +824,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "unexpected zero value for converge phase",
+			wantErr:   "unexpected zero value for converge phase",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "CONVERGE with invalid vote value is error",
@@ -678,7 +841,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "invalid message vote value chain",
+			wantErr:   "invalid message vote value chain",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "CONVERGE with unverified ticket is error",
@@ -697,7 +861,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					Ticket: ticket,
 				}
 			},
-			wantErr: "failed to verify ticket from 1513",
+			wantErr:   "failed to verify ticket from 1513",
+			wantErrIs: gpbft.ErrValidationBadTicket,
 		},
 		{
 			name: "DECIDE with non-zero vote round is error",
@@ -712,7 +877,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "unexpected non-zero round 42 for decide phase",
+			wantErr:   "unexpected non-zero round 42 for decide phase",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "DECIDE with zero vote value is error",
@@ -726,7 +892,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "unexpected zero value for decide phase",
+			wantErr:   "unexpected zero value for decide phase",
+			wantErrIs: gpbft.ErrValidationMalformed,
 		},
 		{
 			name: "invalid vote signature is error",
@@ -743,7 +910,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					Signature: signature,
 				}
 			},
-			wantErr: "invalid signature",
+			wantErr:   "invalid signature",
+			wantErrIs: gpbft.ErrValidationBadSignature,
 		},
 		{
 			name: "non nil Justification when not needed is error",
@@ -788,7 +956,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "has unexpected justification",
+			wantErr:   "has unexpected justification",
+			wantErrIs: gpbft.ErrValidationBadJustification,
 		},
 		{
 			name: "nil Justification when needed is error",
@@ -817,7 +986,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "has no justification",
+			wantErr:   "has no justification",
+			wantErrIs: gpbft.ErrValidationBadJustification,
 		},
 		{
 			name: "CONVERGE with nil Justification when needed is error",
@@ -840,7 +1010,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "has no justification",
+			wantErr:   "has no justification",
+			wantErrIs: gpbft.ErrValidationBadJustification,
 		},
 		{
 			name: "justification and vote instance mismatch is error",
@@ -865,7 +1036,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "has evidence from instanceID: 50",
+			wantErr:   "has evidence from instanceID: 50",
+			wantErrIs: gpbft.ErrValidationBadJustification,
 		},
 		{
 			name: "justification at unexpected phase is error",
@@ -930,7 +1102,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "has justification with unexpected phase",
+			wantErr:   "has justification with unexpected phase",
+			wantErrIs: gpbft.ErrValidationBadJustification,
 		},
 		{
 			name: "justification from wrong round is error",
@@ -980,7 +1153,8 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "has justification from wrong round",
+			wantErr:   "has justification from wrong round",
+			wantErrIs: gpbft.ErrValidationBadJustification,
 		},
 		{
 			name: "justification with invalid value is error",
@@ -1004,7 +1178,46 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 					},
 				}
 			},
-			wantErr: "invalid justification vote value chain",
+			wantErr:   "invalid justification vote value chain",
+			wantErrIs: gpbft.ErrValidationBadJustification,
+		},
+		{
+			name: "justification with zero power signer is error",
+			msg: func(subject *participantTestSubject) *gpbft.GMessage {
+				// Dwarf a new participant's power such that it scales down to zero.
+				dwarf := gpbft.PowerEntry{ID: 1614, Power: gpbft.NewStoragePower(1), PubKey: gpbft.PubKey("minnow")}
+				require.NoError(t, subject.powerTable.Add(dwarf, gpbft.PowerEntry{
+					ID:     1615,
+					Power:  gpbft.NewStoragePower(1 << 20),
+					PubKey: gpbft.PubKey("whale"),
+				}))
+				dwarfIndex := subject.powerTable.Lookup[dwarf.ID]
+				require.Zero(t, subject.powerTable.ScaledPower[dwarfIndex])
+
+				subject.mockValidSignature(somePowerEntry.PubKey, signature)
+				return &gpbft.GMessage{
+					Sender: somePowerEntry.ID,
+					Vote: gpbft.Payload{
+						Instance:         initialInstanceNumber,
+						Phase:            gpbft.COMMIT_PHASE,
+						Value:            subject.canonicalChain,
+						SupplementalData: *subject.supplementalData,
+					},
+					Signature: signature,
+					Justification: &gpbft.Justification{
+						Vote: gpbft.Payload{
+							Instance:         initialInstanceNumber,
+							Phase:            gpbft.PREPARE_PHASE,
+							Value:            subject.canonicalChain,
+							SupplementalData: *subject.supplementalData,
+						},
+						Signers:   bitfield.NewFromSet([]uint64{uint64(subject.powerTable.Lookup[somePowerEntry.ID]), uint64(dwarfIndex)}),
+						Signature: signature,
+					},
+				}
+			},
+			wantErr:   "signer with ID 1614 has no power",
+			wantErrIs: gpbft.ErrValidationBadJustification,
 		},
 	}
 	for _, test := range tests {
@@ -1032,6 +1245,7 @@ func TestParticipant_ValidateMessage(t *testing.T) {
 				subject.assertHostExpectations()
 				if test.wantErr != "" {
 					require.ErrorContains(t, gotValidateErr, test.wantErr)
+					require.ErrorIs(t, gotValidateErr, test.wantErrIs)
 				} else {
 					require.NoError(t, gotValidateErr)
 				}
@@ -1103,6 +1317,280 @@ func TestParticipant_ValidateMessageParallel(t *testing.T) {
 	subject.assertHostExpectations()
 }
 
+func TestParticipant_ValidateMessageVerifiesDuplicatesOnce(t *testing.T) {
+	const (
+		seed                  = 894651320
+		initialInstanceNumber = 47
+	)
+	signature := []byte("barreleye")
+	subject := newParticipantTestSubject(t, seed, initialInstanceNumber)
+	require.NoError(t, subject.powerTable.Add(somePowerEntry))
+	subject.requireStart()
+
+	msg := &gpbft.GMessage{
+		Sender: somePowerEntry.ID,
+		Vote: gpbft.Payload{
+			Instance:         initialInstanceNumber,
+			Phase:            gpbft.QUALITY_PHASE,
+			Value:            subject.canonicalChain,
+			SupplementalData: *subject.supplementalData,
+		},
+		Signature: signature,
+	}
+	subject.mockValidSignature(somePowerEntry.PubKey, signature).Once()
+	for range 3 {
+		// Decode a fresh copy each time, as a message arriving via several gossip
+		// paths would be.
+		var buf bytes.Buffer
+		require.NoError(t, msg.MarshalCBOR(&buf))
+		var duplicate gpbft.GMessage
+		require.NoError(t, duplicate.UnmarshalCBOR(&buf))
+		gotValidated, err := subject.ValidateMessage(&duplicate)
+		require.NoError(t, err)
+		require.NotNil(t, gotValidated)
+	}
+	subject.host.AssertNumberOfCalls(t, "Verify", 1)
+
+	// A copy with a different signature is verified in its own right.
+	otherSignature := []byte("lanternfish")
+	subject.mockInvalidSignature(somePowerEntry.PubKey, otherSignature)
+	forged := *msg
+	forged.Signature = otherSignature
+	_, err := subject.ValidateMessage(&forged)
+	require.ErrorIs(t, err, gpbft.ErrValidationInvalid)
+	subject.host.AssertNumberOfCalls(t, "Verify", 2)
+}
+
+func TestParticipant_ValidateMessageBeyondMaxLookaheadInstances(t *testing.T) {
+	const (
+		seed                  = 894651320
+		initialInstanceNumber = 47
+	)
+	defaults, err := gpbft.NewValidationPolicy()
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name                  string
+		options               []gpbft.Option
+		maxLookaheadInstances uint64
+	}{
+		{
+			name:                  "configured",
+			options:               []gpbft.Option{gpbft.WithMaxLookaheadInstances(2)},
+			maxLookaheadInstances: 2,
+		},
+		{
+			name:                  "default",
+			maxLookaheadInstances: defaults.MaxLookaheadInstances(),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			signature := []byte("barreleye")
+			subject := newParticipantTestSubject(t, seed, initialInstanceNumber)
+			var err error
+			subject.Participant, err = gpbft.NewParticipant(subject.host,
+				append([]gpbft.Option{
+					gpbft.WithTracer(subject),
+					gpbft.WithDelta(subject.delta),
+					gpbft.WithDeltaBackOffExponent(1.3),
+				}, test.options...)...)
+			require.NoError(t, err)
+			require.NoError(t, subject.powerTable.Add(somePowerEntry))
+			subject.requireStart()
+
+			newMessage := func(instance uint64) *gpbft.GMessage {
+				return &gpbft.GMessage{
+					Sender: somePowerEntry.ID,
+					Vote: gpbft.Payload{
+						Instance:         instance,
+						Phase:            gpbft.QUALITY_PHASE,
+						Value:            subject.canonicalChain,
+						SupplementalData: *subject.supplementalData,
+					},
+					Signature: signature,
+				}
+			}
+
+			t.Run("within lookahead is validated", func(t *testing.T) {
+				instance := initialInstanceNumber + test.maxLookaheadInstances
+				subject.mockCommitteeForInstance(instance, subject.powerTable, subject.beacon)
+				subject.mockValidSignature(somePowerEntry.PubKey, signature)
+				_, err := subject.ValidateMessage(newMessage(instance))
+				require.NoError(t, err)
+				subject.host.AssertCalled(t, "GetCommittee", instance)
+			})
+			t.Run("beyond lookahead is rejected without fetching committee", func(t *testing.T) {
+				instance := initialInstanceNumber + test.maxLookaheadInstances + 1
+				_, err := subject.ValidateMessage(newMessage(instance))
+				require.ErrorIs(t, err, gpbft.ErrValidationNoCommittee)
+				subject.host.AssertNotCalled(t, "GetCommittee", instance)
+			})
+		})
+	}
+}
+
+func TestValidateMessage_WithoutParticipant(t *testing.T) {
+	const networkName = "fish"
+	signing := schemeSigning{Signing: emulator.AdhocSigning()}
+	powerTable := gpbft.NewPowerTable()
+	for id := gpbft.ActorID(0); id < 4; id++ {
+		require.NoError(t, powerTable.Add(gpbft.PowerEntry{
+			ID:     id,
+			Power:  gpbft.NewStoragePower(1),
+			PubKey: gpbft.PubKey(fmt.Sprintf("🐠%d", id)),
+		}))
+	}
+	beacon := []byte("lobster")
+	chain, err := gpbft.NewChain(&gpbft.TipSet{Epoch: 0, Key: []byte("tsk0"), PowerTable: ptCid})
+	require.NoError(t, err)
+	chain = chain.Extend([]byte("tsk1"))
+
+	sign := func(sender gpbft.ActorID, payload *gpbft.Payload) []byte {
+		_, pubKey := powerTable.Get(sender)
+		sig, err := signing.Sign(context.Background(), pubKey, signing.MarshalPayloadForSigning(networkName, payload))
+		require.NoError(t, err)
+		return sig
+	}
+	newMessage := func(sender gpbft.ActorID, phase gpbft.Phase, justification *gpbft.Justification) *gpbft.GMessage {
+		msg := &gpbft.GMessage{
+			Sender: sender,
+			Vote: gpbft.Payload{
+				Instance:         7,
+				Phase:            phase,
+				SupplementalData: gpbft.SupplementalData{PowerTable: ptCid},
+				Value:            chain,
+			},
+			Justification: justification,
+		}
+		msg.Signature = sign(sender, &msg.Vote)
+		return msg
+	}
+	newJustification := func(phase gpbft.Phase, from ...gpbft.ActorID) *gpbft.Justification {
+		payload := gpbft.Payload{
+			Instance:         7,
+			Phase:            phase,
+			SupplementalData: gpbft.SupplementalData{PowerTable: ptCid},
+			Value:            chain,
+		}
+		var qr gpbft.QuorumResult
+		for _, id := range from {
+			qr.Signers = append(qr.Signers, powerTable.Lookup[id])
+			qr.Signatures = append(qr.Signatures, sign(id, &payload))
+		}
+		agg, err := signing.Aggregate(powerTable.Entries.PublicKeys())
+		require.NoError(t, err)
+		aggSig, err := agg.Aggregate(qr.Signers, qr.Signatures)
+		require.NoError(t, err)
+		return &gpbft.Justification{Vote: payload, Signers: qr.SignersBitfield(), Signature: aggSig}
+	}
+
+	for _, test := range []struct {
+		name    string
+		msg     func() *gpbft.GMessage
+		network gpbft.NetworkName
+		wantErr bool
+	}{
+		{
+			name: "valid quality",
+			msg:  func() *gpbft.GMessage { return newMessage(1, gpbft.QUALITY_PHASE, nil) },
+		},
+		{
+			name: "valid decide",
+			msg: func() *gpbft.GMessage {
+				return newMessage(1, gpbft.DECIDE_PHASE, newJustification(gpbft.COMMIT_PHASE, 0, 1, 2))
+			},
+		},
+		{
+			name: "valid decide with tagged aggregate scheme",
+			msg: func() *gpbft.GMessage {
+				justification := newJustification(gpbft.COMMIT_PHASE, 0, 1, 2)
+				justification.Scheme = reversedAggregateScheme
+				justification.Signature = reversed(justification.Signature)
+				return newMessage(1, gpbft.DECIDE_PHASE, justification)
+			},
+		},
+		{
+			name: "decide with aggregate under wrong scheme",
+			msg: func() *gpbft.GMessage {
+				justification := newJustification(gpbft.COMMIT_PHASE, 0, 1, 2)
+				justification.Scheme = reversedAggregateScheme
+				return newMessage(1, gpbft.DECIDE_PHASE, justification)
+			},
+			wantErr: true,
+		},
+		{
+			name: "decide with unsupported aggregate scheme",
+			msg: func() *gpbft.GMessage {
+				justification := newJustification(gpbft.COMMIT_PHASE, 0, 1, 2)
+				justification.Scheme = reversedAggregateScheme + 1
+				return newMessage(1, gpbft.DECIDE_PHASE, justification)
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid signature",
+			msg: func() *gpbft.GMessage {
+				msg := newMessage(1, gpbft.QUALITY_PHASE, nil)
+				msg.Signature = []byte("barreleye")
+				return msg
+			},
+			wantErr: true,
+		},
+		{
+			name: "sender not in committee",
+			msg: func() *gpbft.GMessage {
+				msg := newMessage(1, gpbft.QUALITY_PHASE, nil)
+				msg.Sender = 42
+				return msg
+			},
+			wantErr: true,
+		},
+		{
+			name:    "signed for different network",
+			msg:     func() *gpbft.GMessage { return newMessage(1, gpbft.QUALITY_PHASE, nil) },
+			network: "lobster",
+			wantErr: true,
+		},
+		{
+			name: "decide without strong quorum",
+			msg: func() *gpbft.GMessage {
+				return newMessage(1, gpbft.DECIDE_PHASE, newJustification(gpbft.COMMIT_PHASE, 0, 1))
+			},
+			wantErr: true,
+		},
+		{
+			name: "decide with corrupt signers bitfield",
+			msg: func() *gpbft.GMessage {
+				justification := newJustification(gpbft.COMMIT_PHASE, 0, 1, 2)
+				corrupt, err := bitfield.NewFromBytes([]byte{0, 0, 0})
+				require.NoError(t, err)
+				justification.Signers = corrupt
+				return newMessage(1, gpbft.DECIDE_PHASE, justification)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "nil",
+			msg:     func() *gpbft.GMessage { return nil },
+			wantErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			msg := test.msg()
+			network := test.network
+			if network == "" {
+				network = networkName
+			}
+			err := gpbft.ValidateMessage(powerTable, beacon, network, signing, msg)
+			if test.wantErr {
+				require.ErrorIs(t, err, gpbft.ErrValidationInvalid)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestParticipant_WithMisbehavingSigner(t *testing.T) {
 	newDriverAndInstance := func(t *testing.T) (*emulator.Driver, *emulator.Instance) {
 		driver := emulator.NewDriver(t)
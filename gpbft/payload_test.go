@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-f3/gpbft"
 	"github.com/stretchr/testify/require"
 )
@@ -151,3 +152,63 @@ func TestPayload_MarshalForSigning(t *testing.T) {
 		})
 	}
 }
+
+func TestJustification_CanonicalBytes(t *testing.T) {
+	someChain, err := gpbft.NewChain(tipset0, tipSet1)
+	require.NoError(t, err)
+	otherChain := someChain.Extend(tipSet2.Key)
+
+	newDecision := func(value *gpbft.ECChain, signers ...uint64) *gpbft.Justification {
+		return &gpbft.Justification{
+			Vote: gpbft.Payload{
+				Instance: 7,
+				Phase:    gpbft.COMMIT_PHASE,
+				SupplementalData: gpbft.SupplementalData{
+					Commitments: [32]byte{1, 2, 3},
+					PowerTable:  ptCid,
+				},
+				Value: value,
+			},
+			Signers:   bitfield.NewFromSet(signers),
+			Signature: []byte("aggregate"),
+		}
+	}
+	canonical := func(j *gpbft.Justification) []byte {
+		b, err := j.CanonicalBytes()
+		require.NoError(t, err)
+		return b
+	}
+
+	one := newDecision(someChain, 0, 2, 5)
+	// Built independently, with signers added in a different order and the chain
+	// reconstructed from copies of its tipsets.
+	base, head := *tipset0, *tipSet1
+	rebuiltChain, err := gpbft.NewChain(&base)
+	require.NoError(t, err)
+	other := newDecision(rebuiltChain.Append(&head), 5, 0, 2)
+	require.Equal(t, canonical(one), canonical(other))
+
+	// A justification that went over the wire encodes identically too.
+	var buf bytes.Buffer
+	require.NoError(t, one.MarshalCBOR(&buf))
+	var decoded gpbft.Justification
+	require.NoError(t, decoded.UnmarshalCBOR(&buf))
+	require.Equal(t, canonical(one), canonical(&decoded))
+
+	// Any difference in the decision yields different bytes.
+	differentSignature := newDecision(someChain, 0, 2, 5)
+	differentSignature.Signature = []byte("other")
+	differentInstance := newDecision(someChain, 0, 2, 5)
+	differentInstance.Vote.Instance++
+	differentScheme := newDecision(someChain, 0, 2, 5)
+	differentScheme.Scheme = 1
+	for name, different := range map[string]*gpbft.Justification{
+		"value":     newDecision(otherChain, 0, 2, 5),
+		"signers":   newDecision(someChain, 0, 2, 6),
+		"signature": differentSignature,
+		"instance":  differentInstance,
+		"scheme":    differentScheme,
+	} {
+		require.NotEqual(t, canonical(one), canonical(different), name)
+	}
+}
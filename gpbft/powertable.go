@@ -2,6 +2,7 @@ package gpbft
 
 import (
 	"bytes"
+	"cmp"
 	"errors"
 	"fmt"
 	"maps"
@@ -171,6 +172,23 @@ func (p *PowerTable) Has(id ActorID) bool {
 	return found
 }
 
+// MinStrongQuorumSize returns the minimum number of signers required to reach a
+// strong quorum, where signers are accumulated in descending order of power.
+// This mirrors the selection of signers when finding a strong quorum for a
+// value, assuming every participant in the table signs it.
+func (p *PowerTable) MinStrongQuorumSize() int {
+	powers := slices.Clone(p.ScaledPower)
+	slices.SortFunc(powers, func(one, other int64) int { return cmp.Compare(other, one) })
+	var accumulated int64
+	for i, power := range powers {
+		if IsStrongQuorum(accumulated, p.ScaledTotal) {
+			return i
+		}
+		accumulated += power
+	}
+	return len(powers)
+}
+
 // Copy creates a deep copy of this PowerTable.
 func (p *PowerTable) Copy() *PowerTable {
 	replica := NewPowerTable()
@@ -204,3 +204,32 @@ func requireAddedToPowerTable(t *testing.T, subject *gpbft.PowerTable, entry gpb
 	require.Equal(t, entry.Power, gotPower)
 	require.Equal(t, entry.PubKey, gotKey)
 }
+
+func TestPowerTable_MinStrongQuorumSize(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		powers []int64
+		want   int
+	}{
+		{name: "empty", want: 0},
+		{name: "single", powers: []int64{1}, want: 1},
+		{name: "uniform of 3", powers: []int64{1, 1, 1}, want: 2},
+		{name: "uniform of 4", powers: []int64{1, 1, 1, 1}, want: 3},
+		{name: "uniform of 10", powers: []int64{5, 5, 5, 5, 5, 5, 5, 5, 5, 5}, want: 7},
+		{name: "skewed with majority", powers: []int64{10, 10, 10, 70}, want: 1},
+		{name: "skewed", powers: []int64{10, 10, 50, 20, 10}, want: 2},
+		{name: "long tail", powers: []int64{30, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, want: 5},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			subject := gpbft.NewPowerTable()
+			for i, power := range test.powers {
+				require.NoError(t, subject.Add(gpbft.PowerEntry{
+					ID:     gpbft.ActorID(i),
+					Power:  gpbft.NewStoragePower(power),
+					PubKey: []byte("fish"),
+				}))
+			}
+			require.Equal(t, test.want, subject.MinStrongQuorumSize())
+		})
+	}
+}
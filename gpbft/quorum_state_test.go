@@ -0,0 +1,117 @@
+package gpbft
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuorumState_FindStrongQuorumValueForLongestPrefixOf(t *testing.T) {
+	powerTable := NewPowerTable()
+	for id := ActorID(0); id < 4; id++ {
+		require.NoError(t, powerTable.Add(PowerEntry{ID: id, Power: NewStoragePower(1), PubKey: PubKey("fish")}))
+	}
+	ptCid := MakeCid([]byte("pt"))
+	base := &TipSet{Epoch: 0, Key: []byte("base"), PowerTable: ptCid}
+	preferred, err := NewChain(base,
+		&TipSet{Epoch: 1, Key: []byte("1"), PowerTable: ptCid},
+		&TipSet{Epoch: 2, Key: []byte("2"), PowerTable: ptCid},
+		&TipSet{Epoch: 3, Key: []byte("3"), PowerTable: ptCid},
+		&TipSet{Epoch: 4, Key: []byte("4"), PowerTable: ptCid},
+	)
+	require.NoError(t, err)
+
+	// Every sender proposes a different prefix of preferred, such that the prefixes
+	// extending base by one and by two tipsets both have a strong quorum. The longest of them must be
+	// selected regardless of the order in which the proposals are received.
+	proposals := map[ActorID]*ECChain{
+		0: preferred.Prefix(2),
+		1: preferred.Prefix(3),
+		2: preferred.Prefix(4),
+		3: preferred.Prefix(1),
+	}
+	want := preferred.Prefix(2)
+	rng := rand.New(rand.NewSource(1413))
+	for range 20 {
+		senders := []ActorID{0, 1, 2, 3}
+		rng.Shuffle(len(senders), func(i, j int) { senders[i], senders[j] = senders[j], senders[i] })
+		subject := newQuorumState(powerTable, defaultQuorumFractions, nil)
+		for _, sender := range senders {
+			subject.ReceiveEachPrefix(sender, proposals[sender])
+		}
+		require.True(t, subject.HasStrongQuorumFor(preferred.Prefix(1).Key()))
+		got := subject.FindStrongQuorumValueForLongestPrefixOf(preferred)
+		require.True(t, want.Eq(got), "want %s, got %s for senders order: %v", want, got, senders)
+	}
+}
+
+func TestQuorumState_BoundsTrackedValues(t *testing.T) {
+	const (
+		honestCount = 4
+		floodCount  = 40
+		limit       = 4
+	)
+	// Honest participants hold nearly all the power, and each flooder a single
+	// unit with which it sends a distinct value.
+	powerTable := NewPowerTable()
+	for id := ActorID(0); id < honestCount; id++ {
+		require.NoError(t, powerTable.Add(PowerEntry{ID: id, Power: NewStoragePower(100), PubKey: PubKey("fish")}))
+	}
+	for id := ActorID(honestCount); id < honestCount+floodCount; id++ {
+		require.NoError(t, powerTable.Add(PowerEntry{ID: id, Power: NewStoragePower(1), PubKey: PubKey("lobster")}))
+	}
+	ptCid := MakeCid([]byte("pt"))
+	base, err := NewChain(&TipSet{Epoch: 0, Key: []byte("base"), PowerTable: ptCid})
+	require.NoError(t, err)
+	honest := base.Extend([]byte("1")).Extend([]byte("2"))
+	flood := func(subject *quorumState, prefixes bool) {
+		for id := ActorID(honestCount); id < honestCount+floodCount; id++ {
+			value := base.Extend([]byte(fmt.Sprint("flood", id)))
+			if prefixes {
+				subject.ReceiveEachPrefix(id, value)
+			} else {
+				subject.Receive(id, value, []byte("sig"))
+			}
+		}
+	}
+
+	t.Run("flood after most power is received", func(t *testing.T) {
+		trackedValues := &valueBudget{limit: limit}
+		quality := newQuorumState(powerTable, defaultQuorumFractions, trackedValues)
+		prepared := newQuorumState(powerTable, defaultQuorumFractions, trackedValues)
+		for id := ActorID(0); id < honestCount-1; id++ {
+			quality.ReceiveEachPrefix(id, honest)
+			prepared.Receive(id, honest, []byte("sig"))
+		}
+		flood(quality, true)
+		flood(prepared, false)
+		quality.ReceiveEachPrefix(honestCount-1, honest)
+		prepared.Receive(honestCount-1, honest, []byte("sig"))
+
+		// Once too little power is left for any flooded value to reach a strong
+		// quorum, the budget shared by both quorum states stays exhausted.
+		require.Equal(t, limit, trackedValues.tracked)
+		require.Len(t, quality.chainSupport, limit-1)
+		require.Len(t, prepared.chainSupport, 1)
+		require.True(t, quality.HasStrongQuorumFor(honest.Key()))
+		require.True(t, prepared.HasStrongQuorumFor(honest.Key()))
+		_, found := prepared.FindStrongQuorumFor(honest.Key())
+		require.True(t, found)
+	})
+	t.Run("flood before most power is received", func(t *testing.T) {
+		trackedValues := &valueBudget{limit: limit}
+		subject := newQuorumState(powerTable, defaultQuorumFractions, trackedValues)
+		flood(subject, false)
+		for id := ActorID(0); id < honestCount; id++ {
+			subject.Receive(id, honest, []byte("sig"))
+		}
+
+		// Until then, any flooded value could still reach a strong quorum and so
+		// must be tracked beyond the limit.
+		require.Len(t, subject.chainSupport, floodCount+1)
+		require.Equal(t, floodCount+1, trackedValues.tracked)
+		require.True(t, subject.HasStrongQuorumFor(honest.Key()))
+	})
+}
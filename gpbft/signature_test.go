@@ -2,10 +2,12 @@ package gpbft_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"testing"
 
 	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim/signing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	cbg "github.com/whyrusleeping/cbor-gen"
@@ -106,3 +108,34 @@ func TestTipSetMarshalForSigning(t *testing.T) {
 	assert.Equal(t, encoded[40:78], tsCid.Bytes())
 	assert.Equal(t, encoded[78:], ts.PowerTable.Bytes())
 }
+
+func TestPayloadMarshalForSigning_CoversSupplementalData(t *testing.T) {
+	nn := gpbft.NetworkName("filecoin")
+	subject := gpbft.Payload{
+		Instance: 1,
+		Round:    2,
+		Phase:    gpbft.COMMIT_PHASE,
+		SupplementalData: gpbft.SupplementalData{
+			Commitments: [32]byte{0x42},
+			PowerTable:  gpbft.MakeCid([]byte("foo")),
+		},
+	}
+	otherCommitments := subject
+	otherCommitments.SupplementalData.Commitments = [32]byte{0x43}
+	otherPowerTable := subject
+	otherPowerTable.SupplementalData.PowerTable = gpbft.MakeCid([]byte("bar"))
+
+	// Signatures over payloads that differ only in supplemental data are not
+	// interchangeable.
+	encoded := subject.MarshalForSigning(nn)
+	require.NotEqual(t, encoded, otherCommitments.MarshalForSigning(nn))
+	require.NotEqual(t, encoded, otherPowerTable.MarshalForSigning(nn))
+
+	backend := signing.NewFakeBackend()
+	pubKey, _ := backend.GenerateKey()
+	sig, err := backend.Sign(context.Background(), pubKey, encoded)
+	require.NoError(t, err)
+	require.NoError(t, backend.Verify(pubKey, encoded, sig))
+	require.Error(t, backend.Verify(pubKey, otherCommitments.MarshalForSigning(nn), sig))
+	require.Error(t, backend.Verify(pubKey, otherPowerTable.MarshalForSigning(nn), sig))
+}
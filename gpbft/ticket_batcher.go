@@ -0,0 +1,125 @@
+package gpbft
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ticketBatcher verifies CONVERGE tickets in batches. Verifications requested
+// concurrently while a batch is being verified are queued, and verified together
+// as the next batch. No verification is ever delayed waiting for a batch to
+// fill.
+type ticketBatcher struct {
+	verifier     Verifier
+	maxBatchSize int
+
+	mu       sync.Mutex
+	pending  []*ticketVerification
+	flushing bool
+}
+
+type ticketVerification struct {
+	pubKey PubKey
+	msg    []byte
+	sig    []byte
+	result chan error
+}
+
+func newTicketBatcher(verifier Verifier, maxBatchSize int) *ticketBatcher {
+	return &ticketBatcher{verifier: verifier, maxBatchSize: maxBatchSize}
+}
+
+// verifyTicket verifies the given ticket as part of the next batch, blocking
+// until the result is known.
+func (b *ticketBatcher) verifyTicket(nn NetworkName, beacon []byte, instance uint64, round uint64, source PubKey, ticket Ticket) error {
+	request := &ticketVerification{
+		pubKey: source,
+		msg:    vrfSerializeSigInput(beacon, instance, round, nn),
+		sig:    ticket,
+		result: make(chan error, 1),
+	}
+	b.mu.Lock()
+	b.pending = append(b.pending, request)
+	if b.flushing {
+		b.mu.Unlock()
+		return <-request.result
+	}
+	b.flushing = true
+	b.mu.Unlock()
+
+	// Nothing is pending but this request, which is therefore in the first batch.
+	// Verifications queued in the meantime are flushed in the background, so that
+	// the caller is never held up by others.
+	if b.flushNext() {
+		go func() {
+			for b.flushNext() {
+			}
+		}()
+	}
+	return <-request.result
+}
+
+// flushNext verifies the next batch of pending verifications, and reports
+// whether there are more to verify. Otherwise, flushing stops.
+func (b *ticketBatcher) flushNext() bool {
+	b.mu.Lock()
+	size := min(len(b.pending), b.maxBatchSize)
+	batch := b.pending[:size:size]
+	b.pending = b.pending[size:]
+	b.mu.Unlock()
+
+	b.verifyBatch(batch)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.pending) == 0 {
+		b.flushing = false
+		return false
+	}
+	return true
+}
+
+func (b *ticketBatcher) verifyBatch(batch []*ticketVerification) {
+	var errs []error
+	defer func() {
+		// Every request in the batch must get a result, or its caller blocks forever.
+		if r := recover(); r != nil {
+			err := newPanicError(r)
+			errs = make([]error, len(batch))
+			for i := range errs {
+				errs[i] = err
+			}
+		}
+		for i, request := range batch {
+			request.result <- errs[i]
+		}
+	}()
+
+	pubKeys := make([]PubKey, len(batch))
+	msgs := make([][]byte, len(batch))
+	sigs := make([][]byte, len(batch))
+	for i, request := range batch {
+		pubKeys[i], msgs[i], sigs[i] = request.pubKey, request.msg, request.sig
+	}
+	errs = batchVerify(b.verifier, pubKeys, msgs, sigs)
+	if len(errs) != len(batch) {
+		err := fmt.Errorf("batch verification of %d signatures returned %d results", len(batch), len(errs))
+		errs = make([]error, len(batch))
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+}
+
+// batchVerify verifies the given signatures with the verifier as a batch if it
+// implements BatchVerifier, or one at a time otherwise.
+func batchVerify(verifier Verifier, pubKeys []PubKey, msgs, sigs [][]byte) []error {
+	if bv, ok := verifier.(BatchVerifier); ok {
+		return bv.BatchVerify(pubKeys, msgs, sigs)
+	}
+	errs := make([]error, len(pubKeys))
+	for i := range pubKeys {
+		errs[i] = verifier.Verify(pubKeys[i], msgs[i], sigs[i])
+	}
+	return errs
+}
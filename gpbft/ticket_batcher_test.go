@@ -0,0 +1,90 @@
+package gpbft
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ BatchVerifier = (*gatedBatchVerifier)(nil)
+
+// gatedBatchVerifier rejects signatures equal to "bad", and holds up its first
+// batch until released.
+type gatedBatchVerifier struct {
+	Verifier
+	gate chan struct{}
+
+	mu      sync.Mutex
+	batches []int
+}
+
+func (v *gatedBatchVerifier) BatchVerify(_ []PubKey, _, sigs [][]byte) []error {
+	v.mu.Lock()
+	first := len(v.batches) == 0
+	v.batches = append(v.batches, len(sigs))
+	v.mu.Unlock()
+	if first {
+		<-v.gate
+	}
+	errs := make([]error, len(sigs))
+	for i, sig := range sigs {
+		if string(sig) == "bad" {
+			errs[i] = errors.New("bad ticket")
+		}
+	}
+	return errs
+}
+
+func TestTicketBatcher_RejectsOnlyInvalidTickets(t *testing.T) {
+	verifier := &gatedBatchVerifier{gate: make(chan struct{})}
+	subject := newTicketBatcher(verifier, 3)
+	tickets := []Ticket{Ticket("good"), Ticket("good"), Ticket("bad"), Ticket("good"), Ticket("bad"), Ticket("good")}
+	results := make([]error, len(tickets))
+
+	var wg sync.WaitGroup
+	verify := func(i int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = subject.verifyTicket("test", nil, 0, 1, PubKey("pk"), tickets[i])
+		}()
+	}
+	pending := func() int {
+		subject.mu.Lock()
+		defer subject.mu.Unlock()
+		return len(subject.pending)
+	}
+
+	// The first verification is batched on its own, and holds up the rest until
+	// it is done.
+	verify(0)
+	require.Eventually(t, func() bool {
+		verifier.mu.Lock()
+		defer verifier.mu.Unlock()
+		return len(verifier.batches) == 1
+	}, time.Second, time.Millisecond)
+	for i := 1; i < len(tickets); i++ {
+		verify(i)
+	}
+	require.Eventually(t, func() bool { return pending() == len(tickets)-1 }, time.Second, time.Millisecond)
+	close(verifier.gate)
+	wg.Wait()
+
+	require.Equal(t, []int{1, 3, 2}, verifier.batches)
+	for i, ticket := range tickets {
+		if string(ticket) == "bad" {
+			require.Error(t, results[i], "ticket %d", i)
+		} else {
+			require.NoError(t, results[i], "ticket %d", i)
+		}
+	}
+	// Flushing stops once nothing is left pending.
+	require.Eventually(t, func() bool {
+		subject.mu.Lock()
+		defer subject.mu.Unlock()
+		return !subject.flushing && len(subject.pending) == 0
+	}, time.Second, time.Millisecond)
+}
@@ -7,6 +7,8 @@ import (
 	"math"
 
 	"github.com/filecoin-project/go-f3/internal/caching"
+	"github.com/filecoin-project/go-f3/internal/measurements"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
@@ -20,23 +22,38 @@ type cachingValidator struct {
 	// instance identifiers. During validation, if a message or justification is
 	// already present in the cache, it will be skipped to avoid redundant
 	// validations. Otherwise, once validated the cache is updated to include it.
-	cache             *caching.GroupedSet
-	committeeLookback uint64
+	cache *caching.GroupedSet
+	*options
 	committeeProvider CommitteeProvider
 	networkName       NetworkName
+	attrNetwork       attribute.KeyValue
 	signing           Signatures
 	progress          Progress
+	// verifySlots bounds the number of concurrent aggregate signature
+	// verifications, or is nil if unbounded. See WithMaxConcurrentVerifications.
+	verifySlots chan struct{}
+	// tickets verifies CONVERGE tickets in batches, or is nil if tickets are
+	// verified one at a time. See WithTicketBatchVerification.
+	tickets *ticketBatcher
 }
 
-func newValidator(host Host, cp CommitteeProvider, progress Progress, cache *caching.GroupedSet, committeeLookback uint64) *cachingValidator {
-	return &cachingValidator{
+func newValidator(nn NetworkName, signing Signatures, cp CommitteeProvider, progress Progress, cache *caching.GroupedSet, opts *options) *cachingValidator {
+	v := &cachingValidator{
+		options:           opts,
 		cache:             cache,
 		committeeProvider: cp,
-		committeeLookback: committeeLookback,
-		networkName:       host.NetworkName(),
-		signing:           host,
+		networkName:       nn,
+		attrNetwork:       measurements.AttrNetwork.String(string(nn)),
+		signing:           signing,
 		progress:          progress,
 	}
+	if opts.maxConcurrentVerifications > 0 {
+		v.verifySlots = make(chan struct{}, opts.maxConcurrentVerifications)
+	}
+	if opts.maxTicketBatchSize > 0 {
+		v.tickets = newTicketBatcher(signing, opts.maxTicketBatchSize)
+	}
+	return v
 }
 
 // ValidateMessage checks if the given message is valid. If invalid, an error is
@@ -52,17 +69,24 @@ func (v *cachingValidator) ValidateMessage(msg *GMessage) (valid ValidatedMessag
 	case msg.Vote.Instance >= current.ID+v.committeeLookback:
 		// Message is beyond current + committee lookback.
 		return nil, ErrValidationNoCommittee
+	case msg.Vote.Instance > current.ID+v.maxLookaheadInstances:
+		// Message is beyond current + max lookahead instances. Reject it without
+		// resolving its committee, since doing so may be expensive.
+		return nil, ErrValidationNoCommittee
 	case msg.Vote.Instance > current.ID,
-		msg.Vote.Instance+1 == current.ID && msg.Vote.Phase == DECIDE_PHASE:
+		msg.Vote.Instance+1 == current.ID && msg.Vote.Phase == DECIDE_PHASE,
+		msg.Vote.Instance+1 == current.ID && msg.Vote.Phase == COMMIT_PHASE && v.relayLateCommits:
 		// Only proceed to validate the message if it:
 		//  * belongs to an instance within the range of current to current + committee lookback, or
-		//  * is a DECIDE message belonging to previous instance.
+		//  * is a DECIDE message belonging to previous instance, or
+		//  * is a COMMIT message belonging to previous instance, if late COMMITs are relayed.
 	case msg.Vote.Instance == current.ID:
 		// Message belongs to current instance. Only validate messages that are relevant,
 		// i.e.:
 		//   * When current instance is at DECIDE phase only validate DECIDE messages.
 		//   * Otherwise, only validate messages that would be rebroadcasted, i.e. QUALITY,
-		//     DECIDE, messages from previous round, and messages from current round.
+		//     DECIDE, messages from previous round, and messages from current round,
+		//     as well as COMMIT messages within the COMMIT grace window.
 		// Anything else is not relevant.
 		switch {
 		case current.Phase == DECIDE_PHASE && msg.Vote.Phase != DECIDE_PHASE:
@@ -73,7 +97,9 @@ func (v *cachingValidator) ValidateMessage(msg *GMessage) (valid ValidatedMessag
 			msg.Vote.Round >= current.Round,
 			// Check if message round is equal to previous round. Note that we increment the
 			// message round to check this in order to avoid unit64 wrapping.
-			msg.Vote.Round+1 == current.Round:
+			msg.Vote.Round+1 == current.Round,
+			// Check if message is a COMMIT within the grace window behind current round.
+			msg.Vote.Phase == COMMIT_PHASE && msg.Vote.Round+v.commitGraceRounds >= current.Round:
 			// Message is relevant. Progress to further validation.
 		default:
 			return nil, ErrValidationNotRelevant
@@ -90,64 +116,106 @@ func (v *cachingValidator) ValidateMessage(msg *GMessage) (valid ValidatedMessag
 	} else if alreadyValidated, err := v.cache.Contains(msg.Vote.Instance, messageCacheNamespace, buf.Bytes()); err != nil {
 		log.Errorw("failed to check already validated messages", "err", err)
 	} else if alreadyValidated {
-		metrics.validationCache.Add(context.TODO(), 1, metric.WithAttributes(attrCacheHit, attrCacheKindMessage))
+		metrics.validationCache.Add(context.TODO(), 1, metric.WithAttributes(attrCacheHit, attrCacheKindMessage, v.attrNetwork))
 		return &validatedMessage{msg: msg}, nil
 	} else {
 		cacheMessage = true
-		metrics.validationCache.Add(context.TODO(), 1, metric.WithAttributes(attrCacheMiss, attrCacheKindMessage))
+		metrics.validationCache.Add(context.TODO(), 1, metric.WithAttributes(attrCacheMiss, attrCacheKindMessage, v.attrNetwork))
 	}
 
 	comt, err := v.committeeProvider.GetCommittee(msg.Vote.Instance)
 	if err != nil {
 		return nil, ErrValidationNoCommittee
 	}
+	if err := v.validateWithCommittee(msg, comt); err != nil {
+		return nil, err
+	}
+
+	if cacheMessage {
+		if _, err := v.cache.Add(msg.Vote.Instance, messageCacheNamespace, buf.Bytes()); err != nil {
+			log.Warnw("failed to cache to already validated message", "err", err)
+		}
+	}
+	return &validatedMessage{msg: msg}, nil
+}
+
+// ValidateMessage checks if the given message is valid with respect to the given
+// committee power table and beacon, independently of any running Participant.
+// If invalid, an error wrapping ErrValidationInvalid is returned.
+//
+// Unlike Participant.ValidateMessage, the message is validated regardless of its
+// relevance to the progress of any participant, and no validation results are
+// cached. This is intended for offline analysis of captured messages.
+func ValidateMessage(powerTable *PowerTable, beacon []byte, nn NetworkName, signing Signatures, msg *GMessage) error {
+	if msg == nil {
+		return ErrValidationInvalid
+	}
+	aggregateVerifier, err := signing.Aggregate(powerTable.Entries.PublicKeys())
+	if err != nil {
+		return fmt.Errorf("creating aggregate verifier: %w", err)
+	}
+	v := &cachingValidator{networkName: nn, attrNetwork: measurements.AttrNetwork.String(string(nn)), signing: signing, options: &options{quorum: defaultQuorumFractions}}
+	return v.validateWithCommittee(msg, &Committee{
+		PowerTable:        powerTable,
+		Beacon:            beacon,
+		AggregateVerifier: aggregateVerifier,
+	})
+}
+
+// validateWithCommittee checks if the given message is valid with respect to the
+// given committee.
+func (v *cachingValidator) validateWithCommittee(msg *GMessage, comt *Committee) error {
 	// Check sender is eligible.
 	senderPower, senderPubKey := comt.PowerTable.Get(msg.Sender)
 	if senderPower == 0 {
-		return nil, fmt.Errorf("sender %d with zero power or not in power table: %w", msg.Sender, ErrValidationInvalid)
+		return fmt.Errorf("sender %d with zero power or not in power table: %w", msg.Sender, ErrValidationNoPower)
 	}
 
 	// Check that message value is a valid chain.
 	if err := msg.Vote.Value.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid message vote value chain: %w: %w", err, ErrValidationInvalid)
+		return fmt.Errorf("invalid message vote value chain: %w: %w", err, ErrValidationMalformed)
 	}
 
 	// Check phase-specific constraints.
 	switch msg.Vote.Phase {
 	case QUALITY_PHASE:
 		if msg.Vote.Round != 0 {
-			return nil, fmt.Errorf("unexpected round %d for quality phase: %w", msg.Vote.Round, ErrValidationInvalid)
+			return fmt.Errorf("unexpected round %d for quality phase: %w", msg.Vote.Round, ErrValidationMalformed)
 		}
 		if msg.Vote.Value.IsZero() {
-			return nil, fmt.Errorf("unexpected zero value for quality phase: %w", ErrValidationInvalid)
+			return fmt.Errorf("unexpected zero value for quality phase: %w", ErrValidationMalformed)
 		}
 	case CONVERGE_PHASE:
 		if msg.Vote.Round == 0 {
-			return nil, fmt.Errorf("unexpected round 0 for converge phase: %w", ErrValidationInvalid)
+			return fmt.Errorf("unexpected round 0 for converge phase: %w", ErrValidationMalformed)
 		}
 		if msg.Vote.Value.IsZero() {
-			return nil, fmt.Errorf("unexpected zero value for converge phase: %w", ErrValidationInvalid)
+			return fmt.Errorf("unexpected zero value for converge phase: %w", ErrValidationMalformed)
 		}
-		if !VerifyTicket(v.networkName, comt.Beacon, msg.Vote.Instance, msg.Vote.Round, senderPubKey, v.signing, msg.Ticket) {
-			return nil, fmt.Errorf("failed to verify ticket from %v: %w", msg.Sender, ErrValidationInvalid)
+		if v.tickets != nil {
+			if err := v.tickets.verifyTicket(v.networkName, comt.Beacon, msg.Vote.Instance, msg.Vote.Round, senderPubKey, msg.Ticket); err != nil {
+				return fmt.Errorf("failed to verify ticket from %v: %v: %w", msg.Sender, err, ErrValidationBadTicket)
+			}
+		} else if !VerifyTicket(v.networkName, comt.Beacon, msg.Vote.Instance, msg.Vote.Round, senderPubKey, v.signing, msg.Ticket) {
+			return fmt.Errorf("failed to verify ticket from %v: %w", msg.Sender, ErrValidationBadTicket)
 		}
 	case DECIDE_PHASE:
 		if msg.Vote.Round != 0 {
-			return nil, fmt.Errorf("unexpected non-zero round %d for decide phase: %w", msg.Vote.Round, ErrValidationInvalid)
+			return fmt.Errorf("unexpected non-zero round %d for decide phase: %w", msg.Vote.Round, ErrValidationMalformed)
 		}
 		if msg.Vote.Value.IsZero() {
-			return nil, fmt.Errorf("unexpected zero value for decide phase: %w", ErrValidationInvalid)
+			return fmt.Errorf("unexpected zero value for decide phase: %w", ErrValidationMalformed)
 		}
 	case PREPARE_PHASE, COMMIT_PHASE:
 		// No additional checks for PREPARE and COMMIT.
 	default:
-		return nil, fmt.Errorf("invalid vote phase: %d: %w", msg.Vote.Phase, ErrValidationInvalid)
+		return fmt.Errorf("invalid vote phase: %d: %w", msg.Vote.Phase, ErrValidationMalformed)
 	}
 
 	// Check vote signature.
 	sigPayload := v.signing.MarshalPayloadForSigning(v.networkName, &msg.Vote)
 	if err := v.signing.Verify(senderPubKey, sigPayload, msg.Signature); err != nil {
-		return nil, fmt.Errorf("invalid signature on %v, %v: %w", msg, err, ErrValidationInvalid)
+		return fmt.Errorf("invalid signature on %v, %v: %w", msg, err, ErrValidationBadSignature)
 	}
 
 	// Check justification.
@@ -157,23 +225,18 @@ func (v *cachingValidator) ValidateMessage(msg *GMessage) (valid ValidatedMessag
 
 	if needsJustification {
 		if err := v.validateJustification(msg, comt); err != nil {
-			return nil, fmt.Errorf("%v: %w", err, ErrValidationInvalid)
+			return err
 		}
 	} else if msg.Justification != nil {
-		return nil, fmt.Errorf("message %v has unexpected justification: %w", msg, ErrValidationInvalid)
+		return fmt.Errorf("message %v has unexpected justification: %w", msg, ErrValidationBadJustification)
 	}
 
-	if cacheMessage {
-		if _, err := v.cache.Add(msg.Vote.Instance, messageCacheNamespace, buf.Bytes()); err != nil {
-			log.Warnw("failed to cache to already validated message", "err", err)
-		}
-	}
-	return &validatedMessage{msg: msg}, nil
+	return nil
 }
 
 func (v *cachingValidator) validateJustification(msg *GMessage, comt *Committee) error {
 	if msg.Justification == nil {
-		return fmt.Errorf("message for phase %v round %v has no justification", msg.Vote.Phase, msg.Vote.Round)
+		return fmt.Errorf("message for phase %v round %v has no justification: %w", msg.Vote.Phase, msg.Vote.Round, ErrValidationBadJustification)
 	}
 
 	// Only cache the justification if:
@@ -181,28 +244,30 @@ func (v *cachingValidator) validateJustification(msg *GMessage, comt *Committee)
 	//  * it is not already present in the cache.
 	var cacheJustification bool
 	var buf bytes.Buffer
-	if err := msg.Justification.MarshalCBOR(&buf); err != nil {
+	if v.cache == nil {
+		// Validating without a cache.
+	} else if err := msg.Justification.MarshalCBOR(&buf); err != nil {
 		log.Errorw("failed to marshal justification for caching", "err", err)
 	} else if alreadyValidated, err := v.cache.Contains(msg.Vote.Instance, justificationCacheNamespace, buf.Bytes()); err != nil {
 		log.Warnw("failed to check if justification is already cached", "err", err)
 	} else if alreadyValidated {
-		metrics.validationCache.Add(context.TODO(), 1, metric.WithAttributes(attrCacheHit, attrCacheKindJustification))
+		metrics.validationCache.Add(context.TODO(), 1, metric.WithAttributes(attrCacheHit, attrCacheKindJustification, v.attrNetwork))
 		return nil
 	} else {
 		cacheJustification = true
-		metrics.validationCache.Add(context.TODO(), 1, metric.WithAttributes(attrCacheMiss, attrCacheKindJustification))
+		metrics.validationCache.Add(context.TODO(), 1, metric.WithAttributes(attrCacheMiss, attrCacheKindJustification, v.attrNetwork))
 	}
 
 	// Check that the justification is for the same instance.
 	if msg.Vote.Instance != msg.Justification.Vote.Instance {
-		return fmt.Errorf("message with instanceID %v has evidence from instanceID: %v", msg.Vote.Instance, msg.Justification.Vote.Instance)
+		return fmt.Errorf("message with instanceID %v has evidence from instanceID: %v: %w", msg.Vote.Instance, msg.Justification.Vote.Instance, ErrValidationBadJustification)
 	}
 	if !msg.Vote.SupplementalData.Eq(&msg.Justification.Vote.SupplementalData) {
-		return fmt.Errorf("message and justification have inconsistent supplemental data: %v != %v", msg.Vote.SupplementalData, msg.Justification.Vote.SupplementalData)
+		return fmt.Errorf("message and justification have inconsistent supplemental data: %v != %v: %w", msg.Vote.SupplementalData, msg.Justification.Vote.SupplementalData, ErrValidationBadJustification)
 	}
 	// Check that justification vote value is a valid chain.
 	if err := msg.Justification.Vote.Value.Validate(); err != nil {
-		return fmt.Errorf("invalid justification vote value chain: %w", err)
+		return fmt.Errorf("invalid justification vote value chain: %w: %w", err, ErrValidationBadJustification)
 	}
 
 	// Check every remaining field of the justification, according to the phase requirements.
@@ -238,16 +303,16 @@ func (v *cachingValidator) validateJustification(msg *GMessage, comt *Committee)
 	if expectedPhases, ok := expectations[msg.Vote.Phase]; ok {
 		if expected, ok := expectedPhases[msg.Justification.Vote.Phase]; ok {
 			if msg.Justification.Vote.Round != expected.Round && expected.Round != math.MaxUint64 {
-				return fmt.Errorf("message %v has justification from wrong round %d", msg, msg.Justification.Vote.Round)
+				return fmt.Errorf("message %v has justification from wrong round %d: %w", msg, msg.Justification.Vote.Round, ErrValidationBadJustification)
 			}
 			if !msg.Justification.Vote.Value.Eq(expected.Value) {
-				return fmt.Errorf("message %v has justification for a different value: %v", msg, msg.Justification.Vote.Value)
+				return fmt.Errorf("message %v has justification for a different value: %v: %w", msg, msg.Justification.Vote.Value, ErrValidationBadJustification)
 			}
 		} else {
-			return fmt.Errorf("message %v has justification with unexpected phase: %v", msg, msg.Justification.Vote.Phase)
+			return fmt.Errorf("message %v has justification with unexpected phase: %v: %w", msg, msg.Justification.Vote.Phase, ErrValidationBadJustification)
 		}
 	} else {
-		return fmt.Errorf("message %v has unexpected phase for justification", msg)
+		return fmt.Errorf("message %v has unexpected phase for justification: %w", msg, ErrValidationBadJustification)
 	}
 
 	// Check justification power and signature.
@@ -255,26 +320,26 @@ func (v *cachingValidator) validateJustification(msg *GMessage, comt *Committee)
 	signers := make([]int, 0)
 	if err := msg.Justification.Signers.ForEach(func(bit uint64) error {
 		if int(bit) >= len(comt.PowerTable.Entries) {
-			return fmt.Errorf("invalid signer index: %d", bit)
+			return fmt.Errorf("invalid signer index: %d: %w", bit, ErrValidationBadJustification)
 		}
 		power := comt.PowerTable.ScaledPower[bit]
 		if power == 0 {
-			return fmt.Errorf("signer with ID %d has no power", comt.PowerTable.Entries[bit].ID)
+			return fmt.Errorf("signer with ID %d has no power: %w", comt.PowerTable.Entries[bit].ID, ErrValidationBadJustification)
 		}
 		justificationPower += power
 		signers = append(signers, int(bit))
 		return nil
 	}); err != nil {
-		return fmt.Errorf("failed to iterate over signers: %w", err)
+		return fmt.Errorf("failed to iterate over signers: %w: %w", err, ErrValidationBadJustification)
 	}
 
-	if !IsStrongQuorum(justificationPower, comt.PowerTable.ScaledTotal) {
-		return fmt.Errorf("message %v has justification with insufficient power: %v", msg, justificationPower)
+	if err := v.checkJustificationSigners(comt.PowerTable, justificationPower, len(signers)); err != nil {
+		return fmt.Errorf("message %v has %w", msg, err)
 	}
 
 	payload := v.signing.MarshalPayloadForSigning(v.networkName, &msg.Justification.Vote)
-	if err := comt.AggregateVerifier.VerifyAggregate(signers, payload, msg.Justification.Signature); err != nil {
-		return fmt.Errorf("verification of the aggregate failed: %+v: %w", msg.Justification, err)
+	if err := v.verifyAggregate(comt.AggregateVerifier, msg.Justification.Scheme, signers, payload, msg.Justification.Signature); err != nil {
+		return fmt.Errorf("verification of the aggregate failed: %+v: %v: %w", msg.Justification, err, ErrValidationBadSignature)
 	}
 
 	if cacheJustification {
@@ -284,3 +349,66 @@ func (v *cachingValidator) validateJustification(msg *GMessage, comt *Committee)
 	}
 	return nil
 }
+
+// checkJustificationSigners checks that the signers of a justification hold a
+// strong quorum of power between them, and that there are no more of them than
+// bounded by WithMaxJustificationSigners, if set.
+func (o *options) checkJustificationSigners(powerTable *PowerTable, power int64, signers int) error {
+	if !o.quorum.isStrong(power, powerTable.ScaledTotal) {
+		return fmt.Errorf("justification with insufficient power: %v: %w", power, ErrValidationWeakJustification)
+	}
+	if o.maxJustificationSignersFactor > 0 {
+		maxSigners := o.quorum.maxMinimalSigners(powerTable)
+		if float64(signers) > o.maxJustificationSignersFactor*float64(maxSigners) {
+			return fmt.Errorf("justification with too many signers: %d, where honest justifications have at most %d: %w", signers, maxSigners, ErrValidationBadJustification)
+		}
+	}
+	return nil
+}
+
+// ValidationPolicy captures the configured bounds against which messages are
+// validated, such that validators outside this package, e.g. of partial
+// messages, apply the same bounds as the participant.
+type ValidationPolicy struct {
+	*options
+}
+
+// NewValidationPolicy returns the ValidationPolicy configured by the given
+// options, as they would be given to NewParticipant.
+func NewValidationPolicy(o ...Option) (*ValidationPolicy, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	return &ValidationPolicy{options: opts}, nil
+}
+
+// MaxLookaheadInstances returns the number of instances ahead of the current
+// instance beyond which messages are rejected. See WithMaxLookaheadInstances.
+func (p *ValidationPolicy) MaxLookaheadInstances() uint64 {
+	return p.maxLookaheadInstances
+}
+
+// CheckJustificationSigners checks that signers of a justification with the
+// given total power hold a strong quorum of the given power table, and are no
+// more than bounded by WithMaxJustificationSigners.
+func (p *ValidationPolicy) CheckJustificationSigners(powerTable *PowerTable, power int64, signers int) error {
+	return p.checkJustificationSigners(powerTable, power, signers)
+}
+
+// verifyAggregate verifies the given aggregate signature, waiting for a free
+// verification slot first if the maximum number of concurrent verifications has
+// been reached.
+func (v *cachingValidator) verifyAggregate(agg Aggregate, scheme AggregateScheme, signerMask []int, payload, aggSig []byte) error {
+	if v.verifySlots == nil {
+		return VerifyAggregate(agg, scheme, signerMask, payload, aggSig)
+	}
+	select {
+	case v.verifySlots <- struct{}{}:
+	default:
+		metrics.verificationSaturation.Add(context.TODO(), 1, metric.WithAttributes(v.attrNetwork))
+		v.verifySlots <- struct{}{}
+	}
+	defer func() { <-v.verifySlots }()
+	return VerifyAggregate(agg, scheme, signerMask, payload, aggSig)
+}
@@ -0,0 +1,142 @@
+package gpbft
+
+import (
+	"crypto/sha256"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ Aggregate = (*cpuBoundAggregate)(nil)
+
+// cpuBoundAggregate simulates the cost of aggregate signature verification by
+// repeatedly hashing the payload.
+type cpuBoundAggregate struct{}
+
+func (cpuBoundAggregate) Aggregate([]int, [][]byte) ([]byte, error) { return nil, nil }
+
+func (cpuBoundAggregate) VerifyAggregate(_ []int, payload, _ []byte) error {
+	digest := sha256.Sum256(payload)
+	for i := 0; i < 10_000; i++ {
+		digest = sha256.Sum256(digest[:])
+	}
+	return nil
+}
+
+func BenchmarkValidator_VerifyAggregate(b *testing.B) {
+	payload := []byte("fish")
+	for _, test := range []struct {
+		name  string
+		limit int
+	}{
+		{name: "bounded", limit: runtime.GOMAXPROCS(0)},
+		{name: "unbounded", limit: math.MaxInt32},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			v := &cachingValidator{verifySlots: make(chan struct{}, test.limit)}
+			// Oversubscribe the CPUs to exercise contention.
+			b.SetParallelism(16)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if err := v.verifyAggregate(cpuBoundAggregate{}, AggregateSchemeBLSG2, nil, payload, nil); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+// blockingAggregate blocks each verification until released, recording the
+// number of verifications in flight at once.
+type blockingAggregate struct {
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	release     chan struct{}
+}
+
+func (*blockingAggregate) Aggregate([]int, [][]byte) ([]byte, error) { return nil, nil }
+
+func (a *blockingAggregate) VerifyAggregate([]int, []byte, []byte) error {
+	inFlight := a.inFlight.Add(1)
+	defer a.inFlight.Add(-1)
+	for {
+		if peak := a.maxInFlight.Load(); inFlight <= peak || a.maxInFlight.CompareAndSwap(peak, inFlight) {
+			break
+		}
+	}
+	<-a.release
+	return nil
+}
+
+func TestValidator_MaxConcurrentVerifications(t *testing.T) {
+	t.Parallel()
+	const (
+		limit         = 2
+		verifications = 8
+	)
+	opts, err := newOptions(WithMaxConcurrentVerifications(limit))
+	require.NoError(t, err)
+	subject := newValidator("test", nil, nil, nil, nil, opts)
+	agg := &blockingAggregate{release: make(chan struct{})}
+
+	var verified sync.WaitGroup
+	errs := make(chan error, verifications)
+	for range verifications {
+		verified.Add(1)
+		go func() {
+			defer verified.Done()
+			errs <- subject.verifyAggregate(agg, AggregateSchemeBLSG2, nil, nil, nil)
+		}()
+	}
+	require.Eventually(t, func() bool { return agg.inFlight.Load() == limit }, time.Second, time.Millisecond)
+	require.Never(t, func() bool { return agg.inFlight.Load() > limit }, 50*time.Millisecond, time.Millisecond)
+
+	close(agg.release)
+	verified.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, limit, agg.maxInFlight.Load())
+}
+
+func TestValidationPolicy(t *testing.T) {
+	powerTable := NewPowerTable()
+	for id := ActorID(0); id < 4; id++ {
+		require.NoError(t, powerTable.Add(PowerEntry{ID: id, Power: NewStoragePower(1), PubKey: PubKey("pk")}))
+	}
+	power := func(signers int64) int64 { return signers * powerTable.ScaledPower[0] }
+
+	defaults, err := NewValidationPolicy()
+	require.NoError(t, err)
+	require.Equal(t, uint64(defaultMaxLookaheadInstances), defaults.MaxLookaheadInstances())
+	require.NoError(t, defaults.CheckJustificationSigners(powerTable, power(3), 3))
+	require.ErrorIs(t, defaults.CheckJustificationSigners(powerTable, power(2), 2), ErrValidationWeakJustification)
+	require.NoError(t, defaults.CheckJustificationSigners(powerTable, power(4), 5))
+
+	// The configured quorum and bounds apply, rather than the defaults.
+	configured, err := NewValidationPolicy(WithMaxLookaheadInstances(3), WithStrongQuorum(4, 5), WithMaxJustificationSigners(1))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), configured.MaxLookaheadInstances())
+	require.ErrorIs(t, configured.CheckJustificationSigners(powerTable, power(3), 3), ErrValidationWeakJustification)
+	require.NoError(t, configured.CheckJustificationSigners(powerTable, power(4), 4))
+	require.ErrorIs(t, configured.CheckJustificationSigners(powerTable, power(4), 5), ErrValidationBadJustification)
+
+	// The lookahead stays below the configured committee lookback, beyond which it
+	// would have no effect.
+	lookback, err := NewValidationPolicy(WithCommitteeLookback(3))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), lookback.MaxLookaheadInstances())
+	lookback, err = NewValidationPolicy(WithMaxLookaheadInstances(3), WithCommitteeLookback(4))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), lookback.MaxLookaheadInstances())
+	_, err = NewValidationPolicy(WithMaxLookaheadInstances(4), WithCommitteeLookback(4))
+	require.ErrorContains(t, err, "must be less than the committee lookback")
+}
@@ -1,11 +1,14 @@
 package f3
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/filecoin-project/go-f3/certs"
@@ -15,9 +18,11 @@ import (
 	"github.com/filecoin-project/go-f3/internal/caching"
 	"github.com/filecoin-project/go-f3/internal/clock"
 	"github.com/filecoin-project/go-f3/internal/encoding"
+	"github.com/filecoin-project/go-f3/internal/measurements"
 	"github.com/filecoin-project/go-f3/internal/psutil"
 	"github.com/filecoin-project/go-f3/internal/writeaheadlog"
 	"github.com/filecoin-project/go-f3/manifest"
+	"github.com/ipfs/go-datastore"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"go.uber.org/multierr"
@@ -26,6 +31,11 @@ import (
 
 type BroadcastMessage func(*gpbft.MessageBuilder)
 
+// queuedMessagesKey is the datastore key, relative to the datastore prefix of
+// the manifest, at which the messages queued by the participant for future
+// instances are persisted across restarts.
+var queuedMessagesKey = datastore.NewKey("/queuedMessages")
+
 // gpbftRunner is responsible for running gpbft.Participant, taking in all concurrent events and
 // passing them to gpbft in a single thread.
 type gpbftRunner struct {
@@ -36,17 +46,69 @@ type gpbftRunner struct {
 	clock       clock.Clock
 	verifier    gpbft.Verifier
 	wal         *writeaheadlog.WriteAheadLog[walEntry, *walEntry]
+	journal     *receivedJournal
+	ds          datastore.Datastore
 	outMessages chan<- *gpbft.MessageBuilder
 	equivFilter equivocationFilter
 
-	participant *gpbft.Participant
-	topic       *pubsub.Topic
+	participant   *gpbft.Participant
+	topic         *pubsub.Topic
+	announceTopic *pubsub.Topic
 
 	alertTimer *clock.Timer
 
+	// pauseMutex guards access to paused, catchingUp, withheld and loopStarted.
+	pauseMutex sync.Mutex
+	// paused signals whether the run loop suspends the participant, such that it
+	// neither processes messages nor advances instances until resumed.
+	paused bool
+	// catchingUp signals whether the participant is withholding broadcasts until
+	// caughtUp is closed. It is independent of paused, so that neither Pause nor
+	// Resume interferes with catching up.
+	catchingUp bool
+	// caughtUp, if set, signals that certificate exchange has caught up with the
+	// network. It must be set before Start.
+	caughtUp <-chan struct{}
+	// withheld holds the messages of the latest instance that were requested for
+	// broadcast while catching up, to be broadcast once caught up and not paused.
+	// A paused participant is suspended by the run loop, and so requests none.
+	//
+	// The participant counts its own votes only once received back from the
+	// network, so withheld votes count towards no quorum, locally or otherwise,
+	// until broadcast. The exception is its CONVERGE value, which it considers
+	// locally regardless; that only sways its own PREPARE, which is withheld too.
+	withheld []*gpbft.MessageBuilder
+
+	// loopStarted signals whether the run loop has been started. It is guarded by
+	// pauseMutex.
+	loopStarted bool
+	// pauseRequests carries requests from Pause for the run loop to suspend the
+	// participant, each closing the channel it carries once suspended.
+	pauseRequests chan chan struct{}
+	// resumeRequests carries requests from Resume for the run loop to resume
+	// participating, each closing the channel it carries once done.
+	resumeRequests chan chan struct{}
+
+	// followOnly signals whether the runner follows finality solely via the
+	// certificates it receives, without joining any gpbft topic or participating in
+	// instances. It must be set before Start.
+	followOnly bool
+	// participating signals whether the participant has started, having had any
+	// previously persisted queued messages restored. Only then are its queued
+	// messages persisted on Stop, so that a failed Start does not overwrite them.
+	participating atomic.Bool
+	// certAnnounced, if set, is called with the pending instance announced by a
+	// peer upon storing a new certificate. It must be set before Start.
+	certAnnounced func(pendingInstance uint64)
+
 	runningCtx context.Context
 	errgrp     *errgroup.Group
 	ctxCancel  context.CancelFunc
+	// done is closed once the run loop has exited, with runErr set to its terminal
+	// error beforehand.
+	done     chan struct{}
+	doneOnce sync.Once
+	runErr   error
 
 	// msgsMutex guards access to selfMessages
 	msgsMutex    sync.Mutex
@@ -73,26 +135,33 @@ func newRunner(
 	out chan<- *gpbft.MessageBuilder,
 	m *manifest.Manifest,
 	wal *writeaheadlog.WriteAheadLog[walEntry, *walEntry],
+	journal *receivedJournal,
+	ds datastore.Datastore,
 	pID peer.ID,
 ) (*gpbftRunner, error) {
 	runningCtx, ctxCancel := context.WithCancel(context.WithoutCancel(ctx))
 	errgrp, runningCtx := errgroup.WithContext(runningCtx)
 
 	runner := &gpbftRunner{
-		certStore:    cs,
-		manifest:     m,
-		ec:           ec,
-		pubsub:       ps,
-		clock:        clock.GetClock(ctx),
-		verifier:     verifier,
-		wal:          wal,
-		outMessages:  out,
-		runningCtx:   runningCtx,
-		errgrp:       errgrp,
-		ctxCancel:    ctxCancel,
-		equivFilter:  newEquivocationFilter(pID),
-		selfMessages: make(map[uint64]map[roundPhase][]*gpbft.GMessage),
-		inputs:       newInputs(m, cs, ec, verifier, clock.GetClock(ctx)),
+		certStore:      cs,
+		manifest:       m,
+		ec:             ec,
+		pubsub:         ps,
+		clock:          clock.GetClock(ctx),
+		verifier:       verifier,
+		wal:            wal,
+		journal:        journal,
+		ds:             ds,
+		outMessages:    out,
+		runningCtx:     runningCtx,
+		errgrp:         errgrp,
+		ctxCancel:      ctxCancel,
+		done:           make(chan struct{}),
+		pauseRequests:  make(chan chan struct{}),
+		resumeRequests: make(chan chan struct{}),
+		equivFilter:    newEquivocationFilter(pID),
+		selfMessages:   make(map[uint64]map[roundPhase][]*gpbft.GMessage),
+		inputs:         newInputs(m, cs, ec, verifier, clock.GetClock(ctx)),
 	}
 
 	// create a stopped timer to facilitate alerts requested from gpbft
@@ -154,7 +223,11 @@ func newRunner(
 
 	runner.pmCache = caching.NewGroupedSet(int(m.CommitteeLookback), 25_000)
 	obfuscatedHost := (*gpbftHost)(runner)
-	runner.pmv = newCachingPartialValidator(obfuscatedHost, runner.Progress, runner.pmCache, m.CommitteeLookback)
+	policy, err := gpbft.NewValidationPolicy(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating validation policy: %w", err)
+	}
+	runner.pmv = newCachingPartialValidator(obfuscatedHost, runner.Progress, runner.pmCache, m.CommitteeLookback, policy)
 
 	return runner, nil
 }
@@ -166,6 +239,18 @@ func (h *gpbftRunner) Start(ctx context.Context) (_err error) {
 		}
 	}()
 
+	// Certificate announcements are independent of participation, hence started
+	// regardless of whether the runner follows finality only.
+	if err := h.startCertAnnouncements(); err != nil {
+		return err
+	}
+
+	if h.followOnly {
+		log.Infow("starting gpbft runner to follow finality only", "progress", h.Progress())
+		h.startCheckpointing()
+		return nil
+	}
+
 	messageQueue, err := h.startPubsub()
 	if err != nil {
 		return err
@@ -183,22 +268,76 @@ func (h *gpbftRunner) Start(ctx context.Context) (_err error) {
 			log.Errorf("error when receiving certificate: %+v", err)
 		}
 	default:
-		if err := h.startInstanceAt(h.manifest.InitialInstance, h.clock.Now()); err != nil {
+		if err := h.startInstanceAt(h.manifest.InitialInstance, nil, h.clock.Now()); err != nil {
 			log.Errorf("error when starting instance %d: %+v", h.manifest.InitialInstance, err)
 		}
 	}
-
-	h.errgrp.Go(func() (_err error) {
+	if err := h.restoreQueuedMessages(ctx); err != nil {
+		// Restored messages are merely an optimisation; they will be received again
+		// from the network if need be.
+		log.Errorw("failed to restore queued messages", "err", err)
+	}
+	h.participating.Store(true)
+
+	caughtUp := h.caughtUp
+	// suspend stops driving the participant until resumed. Messages received in the
+	// meantime are dropped, whereas certificates and alarms are left pending for
+	// the participant to receive once resumed.
+	suspend := func() error {
+		log.Infow("suspended gpbft participant", "progress", h.Progress())
+		for {
+			select {
+			case _, ok := <-messageQueue:
+				if !ok {
+					return fmt.Errorf("incoming message queue closed")
+				}
+			case _, ok := <-completedMessageQueue:
+				if !ok {
+					return fmt.Errorf("incoming completed message queue closed")
+				}
+			case <-caughtUp:
+				caughtUp = nil
+				if err := h.finishCatchingUp(); err != nil && h.runningCtx.Err() == nil {
+					return err
+				}
+			case paused := <-h.pauseRequests:
+				close(paused)
+			case resumed := <-h.resumeRequests:
+				if h.isSuspended() {
+					// Paused again since the request was made.
+					close(resumed)
+					continue
+				}
+				err := h.resumeParticipating()
+				close(resumed)
+				return err
+			case <-h.runningCtx.Done():
+				return nil
+			}
+		}
+	}
+	h.pauseMutex.Lock()
+	h.loopStarted = true
+	h.pauseMutex.Unlock()
+	h.goRunLoop(func() (_err error) {
 		defer func() {
 			unsubCerts()
 			if _err != nil && h.runningCtx.Err() == nil {
 				log.Errorf("exited GPBFT runner early: %+v", _err)
 			}
 		}()
+		if h.isSuspended() {
+			if err := suspend(); err != nil && h.runningCtx.Err() == nil {
+				return err
+			}
+		}
 		for h.runningCtx.Err() == nil {
 			// prioritise finality certificates and alarm delivery
 			select {
-			case c := <-finalityCertificates:
+			case c, ok := <-finalityCertificates:
+				if !ok {
+					return errors.New("cert store subscription was closed unexpectedly")
+				}
 				if err := h.receiveCertificate(c); err != nil {
 					log.Errorf("error when recieving certificate: %+v", err)
 				}
@@ -215,7 +354,10 @@ func (h *gpbftRunner) Start(ctx context.Context) (_err error) {
 
 			// Handle messages, completed messages, finality certificates, and alarms
 			select {
-			case c := <-finalityCertificates:
+			case c, ok := <-finalityCertificates:
+				if !ok {
+					return errors.New("cert store subscription was closed unexpectedly")
+				}
 				if err := h.receiveCertificate(c); err != nil {
 					log.Errorf("error when recieving certificate: %+v", err)
 				}
@@ -229,7 +371,7 @@ func (h *gpbftRunner) Start(ctx context.Context) (_err error) {
 				if !ok {
 					return fmt.Errorf("incoming message queue closed")
 				}
-				if err := h.participant.ReceiveMessage(msg); err != nil {
+				if err := h.receiveMessage(msg); err != nil {
 					// We silently drop failed messages because GPBFT will
 					// return errors for, e.g., messages from old instances.
 					// Given the async nature of our pubsub message handling, we
@@ -248,10 +390,31 @@ func (h *gpbftRunner) Start(ctx context.Context) (_err error) {
 					log.Debugw("Invalid partially validated message", "err", err)
 				default:
 					recordValidatedMessage(ctx, validatedMessage)
-					if err := h.participant.ReceiveMessage(validatedMessage); err != nil {
+					if err := h.receiveMessage(validatedMessage); err != nil {
 						log.Errorw("error while processing completed message", "err", err)
 					}
 				}
+			case <-caughtUp:
+				// Never select again on the closed channel.
+				caughtUp = nil
+				if err := h.finishCatchingUp(); err != nil && h.runningCtx.Err() == nil {
+					return err
+				}
+			case paused := <-h.pauseRequests:
+				close(paused)
+				if !h.isSuspended() {
+					// Resumed again since the request was made.
+					continue
+				}
+				if err := suspend(); err != nil && h.runningCtx.Err() == nil {
+					return err
+				}
+			case resumed := <-h.resumeRequests:
+				err := h.resumeParticipating()
+				close(resumed)
+				if err != nil && h.runningCtx.Err() == nil {
+					return err
+				}
 			case <-h.runningCtx.Done():
 				return nil
 			}
@@ -259,6 +422,69 @@ func (h *gpbftRunner) Start(ctx context.Context) (_err error) {
 		return nil
 	})
 
+	h.startCheckpointing()
+	return nil
+}
+
+// goRunLoop runs the given run loop in the runner's errgroup, and closes Done
+// once it exits.
+func (h *gpbftRunner) goRunLoop(loop func() error) {
+	h.errgrp.Go(func() error {
+		err := loop()
+		h.exitRunLoop(err)
+		return err
+	})
+}
+
+// exitRunLoop records the terminal error of the run loop and closes Done. Unless
+// the loop itself failed, the terminal error is that of any other goroutine of
+// the runner that failed, causing the loop to exit. Exits caused by Stop are not
+// errors.
+func (h *gpbftRunner) exitRunLoop(err error) {
+	h.doneOnce.Do(func() {
+		if err == nil {
+			if cause := context.Cause(h.runningCtx); !errors.Is(cause, context.Canceled) {
+				err = cause
+			}
+		}
+		h.runErr = err
+		close(h.done)
+	})
+}
+
+// Context returns the context that the runner's goroutines run with. It is
+// cancelled once the runner is stopped, or any of its goroutines fails.
+//
+// This API is safe for concurrent use.
+func (h *gpbftRunner) Context() context.Context {
+	return h.runningCtx
+}
+
+// Done returns a channel that is closed once the run loop has exited, or once
+// the runner is stopped if it never started a run loop, e.g. when following
+// finality only. Embedders may use it to supervise the runner, and restart it
+// if Err reports that it exited abnormally.
+//
+// This API is safe for concurrent use.
+func (h *gpbftRunner) Done() <-chan struct{} {
+	return h.done
+}
+
+// Err returns the error that the run loop exited with once Done is closed, or
+// nil if it has not exited or exited because the runner was stopped.
+//
+// This API is safe for concurrent use.
+func (h *gpbftRunner) Err() error {
+	select {
+	case <-h.done:
+		return h.runErr
+	default:
+		return nil
+	}
+}
+
+// startCheckpointing asynchronously checkpoints the decided tipset keys in EC.
+func (h *gpbftRunner) startCheckpointing() {
 	// Asynchronously checkpoint the decided tipset keys by explicitly making a
 	// separate subscription to the cert store. This may cause a sync in a case where
 	// the finalized tipset is not already stored by the chain store, which is a
@@ -308,6 +534,11 @@ func (h *gpbftRunner) Start(ctx context.Context) (_err error) {
 						log.Errorw("failed to purge messages from WAL", "error", err)
 					}
 				}
+				// Messages received for the decided instance are of no use once its
+				// certificate is stored.
+				if err := h.journal.Purge(cert.GPBFTInstance); err != nil {
+					log.Errorw("failed to purge received messages from journal", "error", err)
+				}
 				h.msgsMutex.Lock()
 				for instance := range h.selfMessages {
 					if instance < cert.GPBFTInstance {
@@ -319,9 +550,36 @@ func (h *gpbftRunner) Start(ctx context.Context) (_err error) {
 		}
 		return nil
 	})
-	return nil
 }
 
+// receiveMessage journals the given message if it is for the current or a future
+// instance, such that it may be replayed after a restart, and then delivers it to
+// the participant. Messages broadcast by this node are not journaled, since they
+// are already replayed from the WAL of self messages.
+func (h *gpbftRunner) receiveMessage(msg gpbft.ValidatedMessage) error {
+	if gmsg := msg.Message(); gmsg.Vote.Instance >= h.participant.Progress().ID && !h.isSelfMessage(gmsg) {
+		h.journal.Append(gmsg)
+	}
+	return h.participant.ReceiveMessage(msg)
+}
+
+// isSelfMessage checks whether the given message was broadcast by this node.
+func (h *gpbftRunner) isSelfMessage(msg *gpbft.GMessage) bool {
+	h.msgsMutex.Lock()
+	defer h.msgsMutex.Unlock()
+	for _, self := range h.selfMessages[msg.Vote.Instance][roundPhase{round: msg.Vote.Round, phase: msg.Vote.Phase}] {
+		if self.Sender == msg.Sender && bytes.Equal(self.Signature, msg.Signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// receiveCertificate skips the participant forward to the instance after the
+// given certificate, if it is behind. Certificates are received from the store
+// in increasing order of instance, but may skip some. There is no need to fill
+// such gaps, since the participant only ever needs to run the instance after
+// the latest one finalized.
 func (h *gpbftRunner) receiveCertificate(c *certs.FinalityCertificate) error {
 	nextInstance := c.GPBFTInstance + 1
 	currentInstance := h.participant.Progress().ID
@@ -332,10 +590,12 @@ func (h *gpbftRunner) receiveCertificate(c *certs.FinalityCertificate) error {
 	log.Debugw("skipping forwards based on cert", "from", currentInstance, "to", nextInstance)
 
 	nextInstanceStart := h.computeNextInstanceStart(c)
-	return h.startInstanceAt(nextInstance, nextInstanceStart)
+	return h.startInstanceAt(nextInstance, c.ECChain.Head(), nextInstanceStart)
 }
 
-func (h *gpbftRunner) startInstanceAt(instance uint64, at time.Time) error {
+// startInstanceAt starts the participant at the given instance, whose proposal
+// must extend the given base unless nil.
+func (h *gpbftRunner) startInstanceAt(instance uint64, base *gpbft.TipSet, at time.Time) error {
 	// Look for any existing messages in WAL for the next instance, and if there is
 	// any replay them to self to aid the participant resume progress when possible.
 	//
@@ -350,6 +610,11 @@ func (h *gpbftRunner) startInstanceAt(instance uint64, at time.Time) error {
 		}
 	}
 	h.msgsMutex.Unlock()
+	// Along with the messages received for the instance before a restart. Since
+	// the main loop has yet to consume any pubsub messages for the instance, these
+	// are received ahead of any from the network. Messages already received are
+	// dropped by the participant as duplicates, so the replay is idempotent.
+	replay = append(replay, h.journal.TakeReplay(instance)...)
 
 	// Order of messages does not matter to GPBFT. But sort them in ascending order
 	// of instance, round, phase, sender for a more optimal resumption.
@@ -378,12 +643,12 @@ func (h *gpbftRunner) startInstanceAt(instance uint64, at time.Time) error {
 
 	for _, message := range replay {
 		if validated, err := h.participant.ValidateMessage(message); err != nil {
-			log.Warnw("invalid self message", "message", message, "err", err)
+			log.Warnw("invalid replayed message", "message", message, "err", err)
 		} else if err := h.participant.ReceiveMessage(validated); err != nil {
 			log.Warnw("failed to send resumption message", "message", message, "err", err)
 		}
 	}
-	return h.participant.StartInstanceAt(instance, at)
+	return h.participant.StartInstanceFrom(instance, base, at)
 }
 
 func (h *gpbftRunner) computeNextInstanceStart(cert *certs.FinalityCertificate) (_nextStart time.Time) {
@@ -423,6 +688,15 @@ func (h *gpbftRunner) computeNextInstanceStart(cert *certs.FinalityCertificate)
 	lookbackDelay := h.manifest.EC.Period * time.Duration(h.manifest.EC.HeadLookback)
 
 	if cert.ECChain.HasSuffix() {
+		if h.manifest.SegmentedCatchUp {
+			// The chain pending finalization is too long to be finalized by a single
+			// instance, so start the next instance right away to finalize its next
+			// segment. In steady state, the pending chain is always shorter than that.
+			proposalLen := int64(min(gpbft.ChainMaxLen, h.manifest.Gpbft.ChainProposedLength) - 1)
+			if pending := head.Epoch() - int64(h.manifest.EC.HeadLookback) - baseTipSet.Epoch; pending > proposalLen {
+				return h.clock.Now()
+			}
+		}
 		// we decided on something new, the tipset that got finalized can at minimum be 30-60s old.
 		return baseTimestamp.Add(ecDelay).Add(lookbackDelay)
 	}
@@ -537,7 +811,7 @@ func (h *gpbftRunner) rebroadcastMessage(msg *gpbft.GMessage) error {
 
 var _ pubsub.ValidatorEx = (*gpbftRunner)(nil).validatePubsubMessage
 
-func (h *gpbftRunner) validatePubsubMessage(ctx context.Context, _ peer.ID, msg *pubsub.Message) (_result pubsub.ValidationResult) {
+func (h *gpbftRunner) validatePubsubMessage(ctx context.Context, pID peer.ID, msg *pubsub.Message) (_result pubsub.ValidationResult) {
 	var partiallyValidated bool
 	defer func(start time.Time) {
 		recordValidationTime(ctx, start, _result, partiallyValidated)
@@ -545,50 +819,110 @@ func (h *gpbftRunner) validatePubsubMessage(ctx context.Context, _ peer.ID, msg
 
 	var pgmsg PartialGMessage
 	if err := h.msgEncoding.Decode(msg.Data, &pgmsg); err != nil {
-		log.Debugw("failed to decode message", "from", msg.GetFrom(), "err", err)
+		recordRejectedMessage(ctx, pubsub.ValidationReject, rejectionReasonDecode)
+		logValidationRejection(pID, msg, nil, pubsub.ValidationReject, rejectionReasonDecode, err)
 		return pubsub.ValidationReject
 	}
 
 	gmsg, completed := h.pmm.CompleteMessage(ctx, &pgmsg)
 	if !completed {
 		partiallyValidatedMessage, err := h.pmv.PartiallyValidateMessage(&pgmsg)
-		result := pubsubValidationResultFromError(err)
+		result, reason := pubsubValidationResultFromError(err)
 		if result == pubsub.ValidationAccept {
 			msg.ValidatorData = partiallyValidatedMessage
+		} else {
+			recordRejectedMessage(ctx, result, reason)
+			logValidationRejection(pID, msg, pgmsg.GMessage, result, reason, err)
 		}
 		partiallyValidated = true
 		return result
 	}
 
 	validatedMessage, err := h.participant.ValidateMessage(gmsg)
-	result := pubsubValidationResultFromError(err)
+	result, reason := pubsubValidationResultFromError(err)
 	if result == pubsub.ValidationAccept {
 		recordValidatedMessage(ctx, validatedMessage)
 		msg.ValidatorData = validatedMessage
+	} else {
+		recordRejectedMessage(ctx, result, reason)
+		logValidationRejection(pID, msg, gmsg, result, reason, err)
 	}
 	return result
 }
 
-func pubsubValidationResultFromError(err error) pubsub.ValidationResult {
+// Categories of reasons for which a pubsub message fails validation, as logged
+// by logValidationRejection and counted by recordRejectedMessage.
+const (
+	rejectionReasonDecode            = "decode"
+	rejectionReasonInvalid           = "invalid"
+	rejectionReasonMalformed         = "invalid_malformed"
+	rejectionReasonNoPower           = "invalid_no_power"
+	rejectionReasonBadTicket         = "invalid_ticket"
+	rejectionReasonBadSignature      = "invalid_signature"
+	rejectionReasonBadJustification  = "invalid_justification"
+	rejectionReasonWeakJustification = "invalid_weak_justification"
+	rejectionReasonTooOld            = "too_old"
+	rejectionReasonNotRelevant       = "not_relevant"
+	rejectionReasonNoCommittee       = "no_committee"
+	rejectionReasonUnknown           = "unknown"
+)
+
+func pubsubValidationResultFromError(err error) (pubsub.ValidationResult, string) {
 	switch {
+	case errors.Is(err, gpbft.ErrValidationMalformed):
+		return pubsub.ValidationReject, rejectionReasonMalformed
+	case errors.Is(err, gpbft.ErrValidationNoPower):
+		return pubsub.ValidationReject, rejectionReasonNoPower
+	case errors.Is(err, gpbft.ErrValidationBadTicket):
+		return pubsub.ValidationReject, rejectionReasonBadTicket
+	case errors.Is(err, gpbft.ErrValidationBadSignature):
+		return pubsub.ValidationReject, rejectionReasonBadSignature
+	case errors.Is(err, gpbft.ErrValidationBadJustification):
+		return pubsub.ValidationReject, rejectionReasonBadJustification
+	case errors.Is(err, gpbft.ErrValidationWeakJustification):
+		return pubsub.ValidationReject, rejectionReasonWeakJustification
 	case errors.Is(err, gpbft.ErrValidationInvalid):
-		log.Debugf("validation error during validation: %+v", err)
-		return pubsub.ValidationReject
+		return pubsub.ValidationReject, rejectionReasonInvalid
 	case errors.Is(err, gpbft.ErrValidationTooOld):
 		// The message has arrived too late to be useful. Ignore it.
-		return pubsub.ValidationIgnore
+		return pubsub.ValidationIgnore, rejectionReasonTooOld
 	case errors.Is(err, gpbft.ErrValidationNotRelevant):
 		// The message is valid but won't effectively aid the progress of GPBFT. Ignore it
 		// to stop its further propagation across the network.
-		return pubsub.ValidationIgnore
+		return pubsub.ValidationIgnore, rejectionReasonNotRelevant
 	case errors.Is(err, gpbft.ErrValidationNoCommittee):
-		log.Debugf("commitee error during validation: %+v", err)
-		return pubsub.ValidationIgnore
+		return pubsub.ValidationIgnore, rejectionReasonNoCommittee
 	case err != nil:
-		log.Infof("unknown error during validation: %+v", err)
-		return pubsub.ValidationIgnore
+		return pubsub.ValidationIgnore, rejectionReasonUnknown
 	default:
-		return pubsub.ValidationAccept
+		return pubsub.ValidationAccept, ""
+	}
+}
+
+// logValidationRejection logs a pubsub message that failed validation along
+// with the peer it was received from and, when decoded, its sender and instant,
+// so that rejections can be correlated across peers. Rejections for an unknown
+// reason are logged at info level, and all others at debug level.
+func logValidationRejection(pID peer.ID, msg *pubsub.Message, gmsg *gpbft.GMessage, result pubsub.ValidationResult, reason string, err error) {
+	fields := []any{
+		"peer", pID,
+		"from", msg.GetFrom(),
+		"result", measurements.AttrFromPubSubValidationResult(result).Value.AsString(),
+		"reason", reason,
+		"err", err,
+	}
+	if gmsg != nil {
+		fields = append(fields,
+			"sender", gmsg.Sender,
+			"instance", gmsg.Vote.Instance,
+			"round", gmsg.Vote.Round,
+			"phase", gmsg.Vote.Phase.String(),
+		)
+	}
+	if reason == rejectionReasonUnknown {
+		log.Infow("message failed validation", fields...)
+	} else {
+		log.Debugw("message failed validation", fields...)
 	}
 }
 
@@ -618,19 +952,137 @@ func (h *gpbftRunner) setupPubsub() error {
 
 func (h *gpbftRunner) teardownPubsub() error {
 	var err error
-	if h.topic != nil {
-		err = multierr.Combine(
-			h.topic.Close(),
-			h.pubsub.UnregisterTopicValidator(h.topic.String()),
+	for _, topic := range []*pubsub.Topic{h.topic, h.announceTopic} {
+		if topic == nil {
+			continue
+		}
+		terr := multierr.Combine(
+			topic.Close(),
+			h.pubsub.UnregisterTopicValidator(topic.String()),
 		)
-
-		if errors.Is(err, context.Canceled) {
-			err = nil
+		if errors.Is(terr, context.Canceled) {
+			terr = nil
 		}
+		err = multierr.Append(err, terr)
 	}
 	return err
 }
 
+// startCertAnnouncements joins the certificate announcement topic, over which
+// the pending instance is announced whenever a new certificate is stored such
+// that peers may poll for it right away rather than waiting for their next
+// scheduled poll. Announcements received from peers are passed on to
+// certAnnounced, if set.
+func (h *gpbftRunner) startCertAnnouncements() error {
+	topicName := h.manifest.CertificateAnnouncementTopic()
+	if err := h.pubsub.RegisterTopicValidator(topicName, h.validateCertAnnouncement); err != nil {
+		return fmt.Errorf("registering certificate announcement topic validator: %w", err)
+	}
+	// Announcements carry nothing but the pending instance, so de-duplicate them by
+	// content: there is no point in propagating the same announcement made by many
+	// nodes.
+	topic, err := h.pubsub.Join(topicName, pubsub.WithTopicMessageIdFn(psutil.CertificateAnnouncementMessageIdFn))
+	if err != nil {
+		return multierr.Append(
+			fmt.Errorf("could not join on pubsub topic: %s: %w", topicName, err),
+			h.pubsub.UnregisterTopicValidator(topicName),
+		)
+	}
+	h.announceTopic = topic
+	sub, err := topic.Subscribe()
+	if err != nil {
+		return fmt.Errorf("could not subscribe to pubsub topic: %s: %w", topicName, err)
+	}
+
+	certs, unsubCerts := h.certStore.Subscribe()
+	h.errgrp.Go(func() error {
+		defer unsubCerts()
+		var announced uint64
+		for {
+			select {
+			case <-h.runningCtx.Done():
+				return nil
+			case cert, ok := <-certs:
+				if !ok {
+					return errors.New("cert store subscription to announce certificates was closed unexpectedly")
+				}
+				// The subscription only ever yields the latest certificate, but guard against
+				// announcing the same pending instance twice regardless.
+				pending := cert.GPBFTInstance + 1
+				if pending <= announced {
+					continue
+				}
+				announced = pending
+				if err := h.announceTopic.Publish(h.runningCtx, binary.BigEndian.AppendUint64(nil, pending)); err != nil {
+					if h.runningCtx.Err() != nil {
+						return nil
+					}
+					log.Warnw("failed to announce new certificate", "pendingInstance", pending, "error", err)
+				}
+			}
+		}
+	})
+	h.errgrp.Go(func() error {
+		defer sub.Cancel()
+		for h.runningCtx.Err() == nil {
+			msg, err := sub.Next(h.runningCtx)
+			if err != nil {
+				if h.runningCtx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("certificate announcement subscription returned an error: %w", err)
+			}
+			if pending, ok := msg.ValidatorData.(uint64); ok && h.certAnnounced != nil {
+				h.certAnnounced(pending)
+			}
+		}
+		return nil
+	})
+	return nil
+}
+
+var _ pubsub.ValidatorEx = (*gpbftRunner)(nil).validateCertAnnouncement
+
+// validateCertAnnouncement accepts announcements that consist of exactly the
+// big-endian encoding of a pending instance, and ignores those that are
+// implausibly far ahead of the local certificate store such that they are not
+// propagated. See maxPlausiblePendingInstance.
+func (h *gpbftRunner) validateCertAnnouncement(ctx context.Context, _ peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+	if len(msg.Data) != 8 {
+		return pubsub.ValidationReject
+	}
+	pending := binary.BigEndian.Uint64(msg.Data)
+	if maxPending, err := h.maxPlausiblePendingInstance(ctx); err != nil {
+		log.Debugw("failed to bound announced pending instance", "error", err)
+		return pubsub.ValidationIgnore
+	} else if pending > maxPending {
+		log.Debugw("ignoring implausible certificate announcement", "pendingInstance", pending, "max", maxPending)
+		return pubsub.ValidationIgnore
+	}
+	msg.ValidatorData = pending
+	return pubsub.ValidationAccept
+}
+
+// maxPlausiblePendingInstance returns the highest pending instance that peers
+// may plausibly announce. Instances rarely finalize no new tipsets, so the
+// network is unlikely to be further ahead of the local certificate store than
+// the epochs from the latest finalized tipset to the EC head, give or take a
+// committee lookback's worth of instances. Ignoring an announcement merely
+// leaves the next poll as scheduled.
+func (h *gpbftRunner) maxPlausiblePendingInstance(ctx context.Context) (uint64, error) {
+	pending := h.manifest.InitialInstance
+	finalized := h.manifest.BootstrapEpoch - h.manifest.EC.Finality
+	if latest := h.certStore.Latest(); latest != nil {
+		pending = latest.GPBFTInstance + 1
+		finalized = latest.ECChain.Head().Epoch
+	}
+	head, err := h.ec.GetHead(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("getting EC head: %w", err)
+	}
+	return pending + uint64(max(head.Epoch()-finalized, 0)) + h.manifest.CommitteeLookback, nil
+}
+
 func (h *gpbftRunner) startPubsub() (<-chan gpbft.ValidatedMessage, error) {
 	if err := h.setupPubsub(); err != nil {
 		return nil, err
@@ -689,6 +1141,9 @@ var (
 type gpbftHost gpbftRunner
 
 func (h *gpbftHost) RequestRebroadcast(instant gpbft.Instant) error {
+	if (*gpbftRunner)(h).isPaused() {
+		return nil
+	}
 	var rebroadcasts []*gpbft.GMessage
 	h.msgsMutex.Lock()
 	if roundPhaseMessages, found := h.selfMessages[instant.ID]; found {
@@ -709,7 +1164,7 @@ func (h *gpbftHost) RequestRebroadcast(instant gpbft.Instant) error {
 
 func (h *gpbftHost) GetProposal(instance uint64) (*gpbft.SupplementalData, *gpbft.ECChain, error) {
 	proposal, chain, err := h.inputs.GetProposal(h.runningCtx, instance)
-	if err == nil {
+	if err == nil && !(*gpbftRunner)(h).isPaused() {
 		if err := h.pmm.BroadcastChain(h.runningCtx, instance, chain); err != nil {
 			log.Warnw("failed to broadcast chain", "instance", instance, "error", err)
 		}
@@ -723,12 +1178,212 @@ func (h *gpbftHost) GetCommittee(instance uint64) (*gpbft.Committee, error) {
 
 func (h *gpbftRunner) Stop(ctx context.Context) error {
 	h.ctxCancel()
-	return multierr.Combine(
+	err := multierr.Combine(
 		h.wal.Close(),
 		h.errgrp.Wait(),
+		// The main loop journals received messages until it exits.
+		h.journal.Close(),
 		h.pmm.Shutdown(ctx),
 		h.teardownPubsub(),
 	)
+	// No run loop remains by now, if one was ever started.
+	h.exitRunLoop(nil)
+	// Persist queued messages only once the participant is no longer in use, and
+	// only if it has been in use at all so as not to discard messages persisted
+	// previously.
+	if h.participating.Load() {
+		err = multierr.Append(err, h.persistQueuedMessages(ctx))
+	}
+	return err
+}
+
+// persistQueuedMessages stores the messages queued by the participant for future
+// instances, so that they need not be received again after a restart.
+func (h *gpbftRunner) persistQueuedMessages(ctx context.Context) error {
+	var buf bytes.Buffer
+	if err := h.participant.SerializeQueuedMessages(&buf); err != nil {
+		return fmt.Errorf("serializing queued messages: %w", err)
+	}
+	if err := h.ds.Put(ctx, queuedMessagesKey, buf.Bytes()); err != nil {
+		return fmt.Errorf("persisting queued messages: %w", err)
+	}
+	return nil
+}
+
+// restoreQueuedMessages restores the messages persisted by persistQueuedMessages,
+// if any. The participant re-validates each message as it is restored.
+func (h *gpbftRunner) restoreQueuedMessages(ctx context.Context) error {
+	data, err := h.ds.Get(ctx, queuedMessagesKey)
+	switch {
+	case errors.Is(err, datastore.ErrNotFound):
+		return nil
+	case err != nil:
+		return fmt.Errorf("loading queued messages: %w", err)
+	}
+	return h.participant.RestoreQueuedMessages(bytes.NewReader(data))
+}
+
+// Pause suspends the participant: the run loop stops processing messages and
+// alarms, such that the participant neither advances instances nor broadcasts,
+// until Resume. Unlike Stop, the pubsub subscription, certificate exchange and
+// certificate store remain active, so that certificates continue to be received
+// and stored. Messages received while paused are dropped. Pause returns once the
+// run loop has suspended the participant, if it has started.
+//
+// This API is safe for concurrent use.
+func (h *gpbftRunner) Pause(ctx context.Context) error {
+	h.pauseMutex.Lock()
+	if h.paused {
+		h.pauseMutex.Unlock()
+		return nil
+	}
+	h.paused = true
+	loopStarted := h.loopStarted
+	h.pauseMutex.Unlock()
+	log.Infow("paused gpbft participation", "progress", h.Progress())
+	if !loopStarted {
+		// The run loop suspends the participant as soon as it starts.
+		return nil
+	}
+	return h.requestRunLoop(ctx, h.pauseRequests)
+}
+
+// Resume restarts the participant after Pause. The participant first skips
+// forward to the instance following the latest certificate in the store, should
+// it have fallen behind while paused, and then broadcasts any messages withheld
+// before it was suspended that are still relevant to its current instance.
+// Resume returns once the run loop has done so, if it has started.
+//
+// This API is safe for concurrent use.
+func (h *gpbftRunner) Resume(ctx context.Context) error {
+	h.pauseMutex.Lock()
+	if !h.paused {
+		h.pauseMutex.Unlock()
+		return nil
+	}
+	h.paused = false
+	catchingUp, loopStarted := h.catchingUp, h.loopStarted
+	h.pauseMutex.Unlock()
+	if catchingUp {
+		log.Infow("resumed gpbft participation once caught up", "progress", h.Progress())
+	}
+	if !loopStarted {
+		return nil
+	}
+	return h.requestRunLoop(ctx, h.resumeRequests)
+}
+
+// requestRunLoop sends a request on the given channel to the run loop, and waits
+// for the run loop to complete it.
+func (h *gpbftRunner) requestRunLoop(ctx context.Context, requests chan<- chan struct{}) error {
+	done := make(chan struct{})
+	select {
+	case requests <- done:
+	case <-h.done:
+		return errors.New("gpbft run loop has exited")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-h.done:
+		return errors.New("gpbft run loop has exited")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resumeParticipating skips forward to the instance following the latest
+// certificate, and then broadcasts the withheld messages, unless paused or
+// catching up. It must only be called from the runner's main loop, so that the
+// participant cannot progress concurrently.
+func (h *gpbftRunner) resumeParticipating() error {
+	// The latest certificates may not yet have been delivered by the subscription
+	// to the certificate store.
+	if latest := h.certStore.Latest(); latest != nil {
+		if err := h.receiveCertificate(latest); err != nil {
+			log.Errorf("error when receiving certificate: %+v", err)
+		}
+	}
+
+	h.pauseMutex.Lock()
+	if h.paused || h.catchingUp {
+		h.pauseMutex.Unlock()
+		return nil
+	}
+	withheld := h.withheld
+	h.withheld = nil
+	h.pauseMutex.Unlock()
+
+	log.Infow("participating in gpbft", "progress", h.Progress(), "withheld", len(withheld))
+	return h.broadcastWithheld(withheld)
+}
+
+// catchUpBeforeParticipating withholds all broadcasts until caughtUp is closed,
+// such that the participant only begins to participate once it has caught up
+// with the network, rather than broadcasting for instances that others have
+// long decided. It must be called before Start.
+func (h *gpbftRunner) catchUpBeforeParticipating(caughtUp <-chan struct{}) {
+	h.pauseMutex.Lock()
+	defer h.pauseMutex.Unlock()
+	h.catchingUp = true
+	h.caughtUp = caughtUp
+}
+
+// finishCatchingUp begins participating once caught up, unless paused. It must
+// only be called from the runner's main loop.
+func (h *gpbftRunner) finishCatchingUp() error {
+	h.pauseMutex.Lock()
+	h.catchingUp = false
+	paused := h.paused
+	h.pauseMutex.Unlock()
+	if paused {
+		log.Infow("caught up with the network while paused", "progress", h.Progress())
+	}
+	return h.resumeParticipating()
+}
+
+// broadcastWithheld broadcasts the withheld messages that are still relevant to
+// the current instance, since the participant considers them sent.
+func (h *gpbftRunner) broadcastWithheld(withheld []*gpbft.MessageBuilder) error {
+	current := h.Progress()
+	for _, mb := range withheld {
+		if mb.Payload.Instance < current.ID {
+			continue
+		}
+		select {
+		case h.outMessages <- mb:
+		case <-h.runningCtx.Done():
+			return h.runningCtx.Err()
+		}
+	}
+	return nil
+}
+
+// isSuspended checks whether the participant is paused, such that the run loop
+// must not drive it.
+func (h *gpbftRunner) isSuspended() bool {
+	h.pauseMutex.Lock()
+	defer h.pauseMutex.Unlock()
+	return h.paused
+}
+
+// isPaused checks whether the participant must not broadcast, either because it
+// is paused or catching up.
+func (h *gpbftRunner) isPaused() bool {
+	h.pauseMutex.Lock()
+	defer h.pauseMutex.Unlock()
+	return h.paused || h.catchingUp
+}
+
+// Finalized returns the justification of the latest decision reached by the
+// local participant, and whether it has reached any decision since the runner
+// started.
+//
+// This API is safe for concurrent use.
+func (h *gpbftRunner) Finalized() (*gpbft.Justification, bool) {
+	return h.participant.Finalized()
 }
 
 // Progress returns the latest progress of GPBFT consensus in terms of instance
@@ -736,6 +1391,13 @@ func (h *gpbftRunner) Stop(ctx context.Context) error {
 //
 // This API is safe for concurrent use.
 func (h *gpbftRunner) Progress() gpbft.Instant {
+	if h.followOnly {
+		// The participant never starts, so progress is only learnt from certificates.
+		if latest := h.certStore.Latest(); latest != nil {
+			return gpbft.Instant{ID: latest.GPBFTInstance + 1}
+		}
+		return gpbft.Instant{ID: h.manifest.InitialInstance}
+	}
 	return h.participant.Progress()
 }
 
@@ -746,6 +1408,26 @@ func (h *gpbftHost) NetworkName() gpbft.NetworkName {
 
 // Sends a message to all other participants.
 func (h *gpbftHost) RequestBroadcast(mb *gpbft.MessageBuilder) error {
+	h.pauseMutex.Lock()
+	if h.paused || h.catchingUp {
+		// Only the latest instance is worth broadcasting upon resume, so late
+		// broadcasts for older instances are dropped.
+		if len(h.withheld) > 0 {
+			switch latest := h.withheld[0].Payload.Instance; {
+			case mb.Payload.Instance < latest:
+				h.pauseMutex.Unlock()
+				log.Debugw("dropping broadcast for an instance older than those withheld", "instance", mb.Payload.Instance, "withheldInstance", latest)
+				return nil
+			case mb.Payload.Instance > latest:
+				h.withheld = nil
+			}
+		}
+		h.withheld = append(h.withheld, mb)
+		h.pauseMutex.Unlock()
+		log.Debugw("withholding broadcast while catching up or paused", "instance", mb.Payload.Instance, "round", mb.Payload.Round, "phase", mb.Payload.Phase)
+		return nil
+	}
+	h.pauseMutex.Unlock()
 	select {
 	case h.outMessages <- mb:
 		return nil
@@ -788,7 +1470,8 @@ func (h *gpbftHost) SetAlarm(at time.Time) {
 // E.g. this might be: finalised tipset timestamp + epoch duration + stabilisation delay.
 func (h *gpbftHost) ReceiveDecision(decision *gpbft.Justification) (time.Time, error) {
 	log.Infow("reached a decision", "instance", decision.Vote.Instance,
-		"ecHeadEpoch", decision.Vote.Value.Head().Epoch)
+		"ecBaseEpoch", decision.Vote.Value.Base().Epoch, "ecHeadEpoch", decision.Vote.Value.Head().Epoch,
+		"ecEpochs", decision.Vote.Value.Epochs())
 	if decision.Vote.Instance > 0 {
 		oldInstance := decision.Vote.Instance - 1
 		h.pmCache.RemoveGroupsLessThan(oldInstance)
@@ -814,6 +1497,9 @@ func (h *gpbftHost) saveDecision(decision *gpbft.Justification) (*certs.Finality
 	if err != nil {
 		return nil, fmt.Errorf("getting commitee for next instance %d: %w", instance+1, err)
 	}
+	if err := checkSupplementalPowerTable(decision, next.PowerTable.Entries); err != nil {
+		return nil, err
+	}
 	powerDiff := certs.MakePowerTableDiff(current.PowerTable.Entries, next.PowerTable.Entries)
 
 	cert, err := certs.NewFinalityCertificate(powerDiff, decision)
@@ -833,6 +1519,22 @@ func (h *gpbftHost) saveDecision(decision *gpbft.Justification) (*certs.Finality
 	return cert, nil
 }
 
+// checkSupplementalPowerTable checks that the power table committed to by the
+// supplemental data of the given decision is that of the next committee, as
+// computed locally. Otherwise, the certificate of the decision would carry a power
+// table diff that diverges from the power table signed by the committee.
+func checkSupplementalPowerTable(decision *gpbft.Justification, nextPowerTable gpbft.PowerEntries) error {
+	want, err := certs.MakePowerTableCID(nextPowerTable)
+	if err != nil {
+		return fmt.Errorf("computing power table CID for instance %d: %w", decision.Vote.Instance+1, err)
+	}
+	if got := decision.Vote.SupplementalData.PowerTable; got != want {
+		return fmt.Errorf("decision at instance %d commits to power table %s for the next instance, but the next committee has power table %s",
+			decision.Vote.Instance, got, want)
+	}
+	return nil
+}
+
 // MarshalPayloadForSigning marshals the given payload into the bytes that should be signed.
 // This should usually call `Payload.MarshalForSigning(NetworkName)` except when testing as
 // that method is slow (computes a merkle tree that's necessary for testing).
@@ -850,6 +1552,19 @@ func (h *gpbftHost) Verify(pubKey gpbft.PubKey, msg []byte, sig []byte) error {
 	return h.verifier.Verify(pubKey, msg, sig)
 }
 
+// BatchVerify verifies the given signatures as a batch if the verifier supports
+// it, or one at a time otherwise.
+func (h *gpbftHost) BatchVerify(pubKeys []gpbft.PubKey, msgs, sigs [][]byte) []error {
+	if bv, ok := h.verifier.(gpbft.BatchVerifier); ok {
+		return bv.BatchVerify(pubKeys, msgs, sigs)
+	}
+	errs := make([]error, len(pubKeys))
+	for i := range pubKeys {
+		errs[i] = h.verifier.Verify(pubKeys[i], msgs[i], sigs[i])
+	}
+	return errs
+}
+
 func (h *gpbftHost) Aggregate(pubKeys []gpbft.PubKey) (gpbft.Aggregate, error) {
 	return h.verifier.Aggregate(pubKeys)
 }
@@ -0,0 +1,557 @@
+package f3
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-f3/certs"
+	"github.com/filecoin-project/go-f3/certstore"
+	"github.com/filecoin-project/go-f3/emulator"
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/internal/clock"
+	"github.com/filecoin-project/go-f3/internal/consensus"
+	"github.com/filecoin-project/go-f3/internal/writeaheadlog"
+	"github.com/filecoin-project/go-f3/manifest"
+	"github.com/filecoin-project/go-f3/sim/signing"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mocknetwork "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestLogValidationRejection(t *testing.T) {
+	previousLevel := log.Level()
+	require.NoError(t, logging.SetLogLevel("f3", "debug"))
+	t.Cleanup(func() { require.NoError(t, logging.SetLogLevel("f3", previousLevel.String())) })
+
+	pipe := logging.NewPipeReader(logging.PipeFormat(logging.JSONOutput))
+	entries := make(chan map[string]any)
+	go func() {
+		defer close(entries)
+		decoder := json.NewDecoder(pipe)
+		for {
+			var entry map[string]any
+			if err := decoder.Decode(&entry); err != nil {
+				return
+			}
+			entries <- entry
+		}
+	}()
+	t.Cleanup(func() {
+		_ = pipe.Close()
+		for range entries {
+		}
+	})
+	nextRejection := func(t *testing.T, pID peer.ID) map[string]any {
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case entry := <-entries:
+				if entry["msg"] == "message failed validation" && entry["peer"] == pID.String() {
+					return entry
+				}
+			case <-timeout:
+				require.FailNow(t, "timed out waiting for rejection log")
+			}
+		}
+	}
+
+	from := peer.ID("0remote")
+	msg := &pubsub.Message{Message: &pb.Message{From: []byte(from)}}
+
+	t.Run("invalid message", func(t *testing.T) {
+		pID := peer.ID("1propagator")
+		gmsg := &gpbft.GMessage{
+			Sender: 42,
+			Vote:   gpbft.Payload{Instance: 7, Round: 3, Phase: gpbft.COMMIT_PHASE},
+		}
+		err := fmt.Errorf("%w: bad signature", gpbft.ErrValidationInvalid)
+		result, reason := pubsubValidationResultFromError(err)
+		require.Equal(t, pubsub.ValidationReject, result)
+		logValidationRejection(pID, msg, gmsg, result, reason, err)
+
+		entry := nextRejection(t, pID)
+		require.Equal(t, from.String(), entry["from"])
+		require.Equal(t, "rejected", entry["result"])
+		require.Equal(t, rejectionReasonInvalid, entry["reason"])
+		require.EqualValues(t, 42, entry["sender"])
+		require.EqualValues(t, 7, entry["instance"])
+		require.EqualValues(t, 3, entry["round"])
+		require.Equal(t, gpbft.COMMIT_PHASE.String(), entry["phase"])
+		require.Contains(t, entry["err"], "bad signature")
+	})
+	t.Run("undecodable message", func(t *testing.T) {
+		pID := peer.ID("2propagator")
+		logValidationRejection(pID, msg, nil, pubsub.ValidationReject, rejectionReasonDecode, errors.New("fish"))
+
+		entry := nextRejection(t, pID)
+		require.Equal(t, rejectionReasonDecode, entry["reason"])
+		require.NotContains(t, entry, "sender")
+		require.NotContains(t, entry, "instance")
+	})
+}
+
+func TestPubsubValidationResultFromError(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantResult pubsub.ValidationResult
+		wantReason string
+	}{
+		{err: nil, wantResult: pubsub.ValidationAccept},
+		{err: gpbft.ErrValidationInvalid, wantResult: pubsub.ValidationReject, wantReason: rejectionReasonInvalid},
+		{err: gpbft.ErrValidationMalformed, wantResult: pubsub.ValidationReject, wantReason: rejectionReasonMalformed},
+		{err: gpbft.ErrValidationNoPower, wantResult: pubsub.ValidationReject, wantReason: rejectionReasonNoPower},
+		{err: gpbft.ErrValidationBadTicket, wantResult: pubsub.ValidationReject, wantReason: rejectionReasonBadTicket},
+		{err: gpbft.ErrValidationBadSignature, wantResult: pubsub.ValidationReject, wantReason: rejectionReasonBadSignature},
+		{err: gpbft.ErrValidationBadJustification, wantResult: pubsub.ValidationReject, wantReason: rejectionReasonBadJustification},
+		{err: gpbft.ErrValidationWeakJustification, wantResult: pubsub.ValidationReject, wantReason: rejectionReasonWeakJustification},
+		{err: gpbft.ErrValidationTooOld, wantResult: pubsub.ValidationIgnore, wantReason: rejectionReasonTooOld},
+		{err: gpbft.ErrValidationNotRelevant, wantResult: pubsub.ValidationIgnore, wantReason: rejectionReasonNotRelevant},
+		{err: gpbft.ErrValidationNoCommittee, wantResult: pubsub.ValidationIgnore, wantReason: rejectionReasonNoCommittee},
+		{err: errors.New("fish"), wantResult: pubsub.ValidationIgnore, wantReason: rejectionReasonUnknown},
+	}
+	for _, test := range tests {
+		name := "nil"
+		if test.err != nil {
+			name = test.err.Error()
+		}
+		t.Run(name, func(t *testing.T) {
+			result, reason := pubsubValidationResultFromError(fmt.Errorf("wrapped: %w", test.err))
+			if test.err == nil {
+				result, reason = pubsubValidationResultFromError(nil)
+			}
+			require.Equal(t, test.wantResult, result)
+			require.Equal(t, test.wantReason, reason)
+		})
+	}
+}
+
+func TestPubsubValidationResultFromError_CorruptSigners(t *testing.T) {
+	const networkName = "fish"
+	signing := emulator.AdhocSigning()
+	powerTable := gpbft.NewPowerTable()
+	for id := gpbft.ActorID(0); id < 4; id++ {
+		require.NoError(t, powerTable.Add(gpbft.PowerEntry{
+			ID:     id,
+			Power:  gpbft.NewStoragePower(1),
+			PubKey: gpbft.PubKey(fmt.Sprintf("🐠%d", id)),
+		}))
+	}
+	chain, err := gpbft.NewChain(&gpbft.TipSet{Epoch: 0, Key: []byte("tsk0"), PowerTable: gpbft.MakeCid([]byte("pt"))})
+	require.NoError(t, err)
+	corrupt, err := bitfield.NewFromBytes([]byte{0, 0, 0})
+	require.NoError(t, err)
+	msg := &gpbft.GMessage{
+		Sender: 1,
+		Vote:   gpbft.Payload{Instance: 7, Phase: gpbft.DECIDE_PHASE, Value: chain},
+		Justification: &gpbft.Justification{
+			Vote:    gpbft.Payload{Instance: 7, Phase: gpbft.COMMIT_PHASE, Value: chain},
+			Signers: corrupt,
+		},
+	}
+	_, pubKey := powerTable.Get(msg.Sender)
+	msg.Signature, err = signing.Sign(context.Background(), pubKey, signing.MarshalPayloadForSigning(networkName, &msg.Vote))
+	require.NoError(t, err)
+
+	validationErr := gpbft.ValidateMessage(powerTable, []byte("lobster"), networkName, signing, msg)
+	result, reason := pubsubValidationResultFromError(validationErr)
+	require.Equal(t, pubsub.ValidationReject, result)
+	require.Equal(t, rejectionReasonBadJustification, reason)
+}
+
+func TestRunner_DoneReportsTerminalError(t *testing.T) {
+	newRunner := func() *gpbftRunner {
+		runningCtx, ctxCancel := context.WithCancel(context.Background())
+		errgrp, runningCtx := errgroup.WithContext(runningCtx)
+		return &gpbftRunner{
+			runningCtx: runningCtx,
+			errgrp:     errgrp,
+			ctxCancel:  ctxCancel,
+			done:       make(chan struct{}),
+		}
+	}
+	requireDone := func(t *testing.T, runner *gpbftRunner) {
+		select {
+		case <-runner.Done():
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for the run loop to exit")
+		}
+	}
+	// runUntilStopped stands in for the run loop, which exits without error once
+	// the runner's context is cancelled.
+	runUntilStopped := func(runner *gpbftRunner) func() error {
+		return func() error {
+			<-runner.Context().Done()
+			return nil
+		}
+	}
+	errBoom := errors.New("boom")
+
+	t.Run("run loop fails", func(t *testing.T) {
+		runner := newRunner()
+		runner.goRunLoop(func() error { return errBoom })
+		requireDone(t, runner)
+		require.ErrorIs(t, runner.Err(), errBoom)
+		require.ErrorIs(t, runner.errgrp.Wait(), errBoom)
+	})
+	t.Run("other goroutine fails", func(t *testing.T) {
+		runner := newRunner()
+		runner.goRunLoop(runUntilStopped(runner))
+		require.NoError(t, runner.Err())
+		runner.errgrp.Go(func() error { return errBoom })
+		requireDone(t, runner)
+		require.ErrorIs(t, runner.Err(), errBoom)
+		require.ErrorIs(t, runner.errgrp.Wait(), errBoom)
+	})
+	t.Run("stopped", func(t *testing.T) {
+		runner := newRunner()
+		runner.goRunLoop(runUntilStopped(runner))
+		runner.ctxCancel()
+		requireDone(t, runner)
+		require.NoError(t, runner.Err())
+		require.NoError(t, runner.errgrp.Wait())
+	})
+}
+
+func TestRunner_SkipsOverDroppedCertificates(t *testing.T) {
+	ctx, clk := clock.WithMockClock(context.Background())
+	m := manifest.LocalDevnetManifest()
+	fakeEC := consensus.NewFakeEC(ctx,
+		consensus.WithBootstrapEpoch(m.BootstrapEpoch),
+		consensus.WithECPeriod(m.EC.Period),
+	)
+	pt := gpbft.PowerEntries{{ID: 1, Power: gpbft.NewStoragePower(1), PubKey: []byte("key")}}
+	ptCid, err := certs.MakePowerTableCID(pt)
+	require.NoError(t, err)
+	cs, err := certstore.NewInMemory(m.InitialInstance, pt)
+	require.NoError(t, err)
+
+	runner := &gpbftRunner{
+		manifest:   m,
+		ec:         fakeEC,
+		clock:      clk,
+		runningCtx: ctx,
+		certStore:  cs,
+		journal:    &receivedJournal{},
+		alertTimer: clk.Timer(0),
+	}
+	runner.participant, err = gpbft.NewParticipant((*gpbftHost)(runner))
+	require.NoError(t, err)
+
+	certAt := func(instance uint64) *certs.FinalityCertificate {
+		tipSetAt := func(epoch int64) *gpbft.TipSet {
+			return &gpbft.TipSet{Epoch: epoch, Key: []byte(fmt.Sprintf("ts%d", epoch)), PowerTable: ptCid}
+		}
+		epoch := m.BootstrapEpoch + int64(instance-m.InitialInstance)
+		chain, err := gpbft.NewChain(tipSetAt(epoch), tipSetAt(epoch+1))
+		require.NoError(t, err)
+		return &certs.FinalityCertificate{
+			GPBFTInstance:    instance,
+			SupplementalData: gpbft.SupplementalData{PowerTable: ptCid},
+			ECChain:          chain,
+		}
+	}
+
+	// Certificates stored faster than the run loop receives them are dropped by the
+	// subscription, but for the latest.
+	finalityCertificates, unsubCerts := cs.Subscribe()
+	defer unsubCerts()
+	const stored = 10
+	for instance := m.InitialInstance; instance < m.InitialInstance+stored; instance++ {
+		require.NoError(t, cs.Put(ctx, certAt(instance)))
+	}
+	latest := <-finalityCertificates
+	require.Equal(t, m.InitialInstance+stored-1, latest.GPBFTInstance)
+
+	// Receiving the latest alone skips the participant past every instance stored.
+	require.NoError(t, runner.receiveCertificate(latest))
+	require.Equal(t, latest.GPBFTInstance+1, runner.participant.Progress().ID)
+
+	// Certificates the participant is already past are ignored.
+	skipped, err := cs.Get(ctx, m.InitialInstance)
+	require.NoError(t, err)
+	require.NoError(t, runner.receiveCertificate(skipped))
+	require.Equal(t, latest.GPBFTInstance+1, runner.participant.Progress().ID)
+}
+
+func TestRunner_KeepsPersistedQueuedMessagesOnFailedStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := manifest.LocalDevnetManifest()
+	fakeEC := consensus.NewFakeEC(ctx,
+		consensus.WithBootstrapEpoch(m.BootstrapEpoch),
+		consensus.WithECPeriod(m.EC.Period),
+	)
+	pt := gpbft.PowerEntries{{ID: 1, Power: gpbft.NewStoragePower(1), PubKey: []byte("key")}}
+	cs, err := certstore.NewInMemory(m.InitialInstance, pt)
+	require.NoError(t, err)
+
+	mocknet := mocknetwork.New()
+	host, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	ps, err := pubsub.NewGossipSub(ctx, host)
+	require.NoError(t, err)
+	// Fail Start by taking the certificate announcement topic validator.
+	require.NoError(t, ps.RegisterTopicValidator(m.CertificateAnnouncementTopic(),
+		func(context.Context, peer.ID, *pubsub.Message) bool { return true }))
+
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	persisted := []byte("queued before restart")
+	require.NoError(t, ds.Put(ctx, queuedMessagesKey, persisted))
+
+	wal, err := writeaheadlog.Open[walEntry](filepath.Join(t.TempDir(), "wal"))
+	require.NoError(t, err)
+	journal, err := openReceivedJournal(filepath.Join(t.TempDir(), "received-wal"))
+	require.NoError(t, err)
+	runner, err := newRunner(ctx, cs, fakeEC, ps, signing.NewFakeBackend(), nil, m, wal, journal, ds, host.ID())
+	require.NoError(t, err)
+
+	// Start stops the runner as it fails, before the participant has restored
+	// anything, and so must leave the messages persisted previously intact.
+	require.Error(t, runner.Start(ctx))
+	got, err := ds.Get(ctx, queuedMessagesKey)
+	require.NoError(t, err)
+	require.Equal(t, persisted, got)
+}
+
+func TestRunner_WithholdsVotesWhileCatchingUp(t *testing.T) {
+	ctx, clk := clock.WithMockClock(context.Background())
+	m := manifest.LocalDevnetManifest()
+	pt := gpbft.PowerEntries{{ID: 1, Power: gpbft.NewStoragePower(1), PubKey: []byte("key")}}
+	cs, err := certstore.NewInMemory(m.InitialInstance, pt)
+	require.NoError(t, err)
+
+	out := make(chan *gpbft.MessageBuilder, 1)
+	runner := &gpbftRunner{
+		manifest:    m,
+		clock:       clk,
+		runningCtx:  ctx,
+		certStore:   cs,
+		outMessages: out,
+	}
+	runner.participant, err = gpbft.NewParticipant((*gpbftHost)(runner))
+	require.NoError(t, err)
+	runner.catchUpBeforeParticipating(make(chan struct{}))
+
+	// A vote requested while catching up never reaches the network, and so is never
+	// received back by the participant to count towards a quorum.
+	vote := &gpbft.MessageBuilder{Payload: gpbft.Payload{Instance: runner.Progress().ID, Phase: gpbft.QUALITY_PHASE}}
+	require.NoError(t, (*gpbftHost)(runner).RequestBroadcast(vote))
+	require.Empty(t, out)
+
+	// Once caught up, the vote is broadcast, since the participant considers it sent.
+	require.NoError(t, runner.finishCatchingUp())
+	require.Equal(t, vote, <-out)
+}
+
+func TestRunner_IgnoresImplausibleCertAnnouncements(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The mock clock holds the EC head, and so the plausible bound, still.
+	ctx, _ = clock.WithMockClock(ctx)
+	m := manifest.LocalDevnetManifest()
+	fakeEC := consensus.NewFakeEC(ctx,
+		consensus.WithBootstrapEpoch(m.BootstrapEpoch),
+		consensus.WithECPeriod(m.EC.Period),
+	)
+	pt := gpbft.PowerEntries{{ID: 1, Power: gpbft.NewStoragePower(1), PubKey: []byte("key")}}
+	cs, err := certstore.NewInMemory(m.InitialInstance, pt)
+	require.NoError(t, err)
+
+	mocknet := mocknetwork.New()
+	receiver, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	announcer, err := mocknet.GenPeer()
+	require.NoError(t, err)
+	require.NoError(t, mocknet.LinkAll())
+	receiverPs, err := pubsub.NewGossipSub(ctx, receiver)
+	require.NoError(t, err)
+	announcerPs, err := pubsub.NewGossipSub(ctx, announcer)
+	require.NoError(t, err)
+
+	errgrp, runningCtx := errgroup.WithContext(ctx)
+	announced := make(chan uint64, 10)
+	runner := &gpbftRunner{
+		manifest:      m,
+		ec:            fakeEC,
+		certStore:     cs,
+		pubsub:        receiverPs,
+		runningCtx:    runningCtx,
+		errgrp:        errgrp,
+		certAnnounced: func(pending uint64) { announced <- pending },
+	}
+	require.NoError(t, runner.startCertAnnouncements())
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, errgrp.Wait())
+	})
+
+	topic, err := announcerPs.Join(m.CertificateAnnouncementTopic())
+	require.NoError(t, err)
+	require.NoError(t, mocknet.ConnectAllButSelf())
+	require.Eventually(t, func() bool {
+		return len(topic.ListPeers()) == 1
+	}, 10*time.Second, 10*time.Millisecond)
+
+	maxPending, err := runner.maxPlausiblePendingInstance(ctx)
+	require.NoError(t, err)
+	require.Greater(t, maxPending, m.InitialInstance)
+	for _, pending := range []uint64{math.MaxUint64, maxPending + 1, maxPending} {
+		require.NoError(t, topic.Publish(ctx, binary.BigEndian.AppendUint64(nil, pending)))
+	}
+
+	// Only the plausible announcement is received.
+	select {
+	case pending := <-announced:
+		require.Equal(t, maxPending, pending)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for announcement")
+	}
+	require.Never(t, func() bool {
+		return len(announced) > 0
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestHost_WithholdsBroadcastsForLatestInstanceOnly(t *testing.T) {
+	host := &gpbftHost{paused: true}
+	broadcast := func(instance, round uint64) *gpbft.MessageBuilder {
+		mb := &gpbft.MessageBuilder{Payload: gpbft.Payload{Instance: instance, Round: round}}
+		require.NoError(t, host.RequestBroadcast(mb))
+		return mb
+	}
+
+	first := broadcast(2, 0)
+	second := broadcast(2, 1)
+	require.Equal(t, []*gpbft.MessageBuilder{first, second}, host.withheld)
+
+	// A late broadcast for an older instance leaves those withheld untouched.
+	broadcast(1, 3)
+	require.Equal(t, []*gpbft.MessageBuilder{first, second}, host.withheld)
+
+	// A broadcast for a newer instance supersedes them.
+	latest := broadcast(3, 0)
+	require.Equal(t, []*gpbft.MessageBuilder{latest}, host.withheld)
+}
+
+func TestHost_AlarmFollowsClock(t *testing.T) {
+	_, clk := clock.WithMockClock(context.Background())
+	clk.Add(time.Hour)
+	host := &gpbftHost{clock: clk, alertTimer: clk.Timer(time.Hour)}
+	requireAlarm := func(t *testing.T, want bool) {
+		t.Helper()
+		select {
+		case <-host.alertTimer.C:
+			require.True(t, want, "unexpected alarm")
+		default:
+			require.False(t, want, "expected alarm")
+		}
+	}
+
+	require.Equal(t, clk.Now(), host.Time())
+
+	host.SetAlarm(host.Time().Add(time.Second))
+	clk.Add(time.Second - 1)
+	requireAlarm(t, false)
+	clk.Add(1)
+	requireAlarm(t, true)
+
+	host.SetAlarm(host.Time().Add(time.Second))
+	host.SetAlarm(time.Time{})
+	clk.Add(time.Minute)
+	requireAlarm(t, false)
+
+	// Alarms in the past fire without the clock advancing.
+	host.SetAlarm(host.Time().Add(-time.Second))
+	requireAlarm(t, true)
+}
+
+func TestComputeNextInstanceStart_SegmentedCatchUp(t *testing.T) {
+	const (
+		behind           = 200
+		instanceDuration = time.Second
+	)
+	// catchUp finalizes the chain pending finalization one proposal at a time,
+	// starting each instance when computeNextInstanceStart says so, until the
+	// pending chain fits in a single proposal. It returns the time taken to do so,
+	// and the time until the instance after that starts.
+	catchUp := func(t *testing.T, segmented bool) (time.Duration, time.Duration) {
+		ctx, clk := clock.WithMockClock(context.Background())
+		m := manifest.LocalDevnetManifest()
+		m.SegmentedCatchUp = segmented
+		m.Gpbft.ChainProposedLength = 20
+		fakeEC := consensus.NewFakeEC(ctx,
+			consensus.WithBootstrapEpoch(m.BootstrapEpoch),
+			consensus.WithECPeriod(m.EC.Period),
+		)
+		runner := &gpbftRunner{manifest: m, ec: fakeEC, clock: clk, runningCtx: ctx}
+		tipSetAt := func(epoch int64) *gpbft.TipSet {
+			return &gpbft.TipSet{Epoch: epoch, Key: []byte(fmt.Sprintf("ts%d", epoch)), PowerTable: gpbft.MakeCid([]byte("pt"))}
+		}
+		finalize := func(instance uint64, base, head int64) time.Duration {
+			chain, err := gpbft.NewChain(tipSetAt(base), tipSetAt(head))
+			require.NoError(t, err)
+			return clk.Until(runner.computeNextInstanceStart(&certs.FinalityCertificate{GPBFTInstance: instance, ECChain: chain}))
+		}
+
+		started := clk.Now()
+		proposalLen := int64(m.Gpbft.ChainProposedLength - 1)
+		base := fakeEC.GetCurrentHead() - behind
+		instance := m.InitialInstance + 1
+		for ; fakeEC.GetCurrentHead()-int64(m.EC.HeadLookback)-base > proposalLen; instance++ {
+			clk.Add(instanceDuration)
+			wait := finalize(instance, base, base+proposalLen)
+			if wait > 0 {
+				clk.Add(wait)
+			}
+			base += proposalLen
+		}
+		caughtUp := clk.Since(started)
+
+		// Once caught up, instances start as they would otherwise.
+		clk.Add(instanceDuration)
+		return caughtUp, finalize(instance, base, base+1)
+	}
+
+	unsegmented, wantSteadyState := catchUp(t, false)
+	segmented, gotSteadyState := catchUp(t, true)
+	require.Less(t, segmented, unsegmented)
+	require.Zero(t, segmented%instanceDuration, "segmented catch up waited between instances")
+	require.Equal(t, wantSteadyState, gotSteadyState)
+	require.Positive(t, gotSteadyState)
+}
+
+func TestCheckSupplementalPowerTable(t *testing.T) {
+	nextPowerTable := gpbft.PowerEntries{
+		{ID: 1, Power: gpbft.NewStoragePower(10), PubKey: gpbft.PubKey("fish")},
+		{ID: 2, Power: gpbft.NewStoragePower(20), PubKey: gpbft.PubKey("lobster")},
+	}
+	nextPowerTableCid, err := certs.MakePowerTableCID(nextPowerTable)
+	require.NoError(t, err)
+	decisionCommittingTo := func(powerTable cid.Cid) *gpbft.Justification {
+		return &gpbft.Justification{Vote: gpbft.Payload{
+			Instance:         7,
+			Phase:            gpbft.DECIDE_PHASE,
+			SupplementalData: gpbft.SupplementalData{PowerTable: powerTable},
+		}}
+	}
+
+	require.NoError(t, checkSupplementalPowerTable(decisionCommittingTo(nextPowerTableCid), nextPowerTable))
+
+	mismatched := gpbft.MakeCid([]byte("not the next power table"))
+	err = checkSupplementalPowerTable(decisionCommittingTo(mismatched), nextPowerTable)
+	require.ErrorContains(t, err, fmt.Sprintf("decision at instance 7 commits to power table %s for the next instance, but the next committee has power table %s", mismatched, nextPowerTableCid))
+}
@@ -1,13 +1,16 @@
 package consensus
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-f3/ec"
+	"github.com/filecoin-project/go-state-types/big"
 	"golang.org/x/crypto/blake2b"
 
 	"github.com/filecoin-project/go-f3/gpbft"
@@ -32,9 +35,25 @@ type FakeEC struct {
 	ecPeriod       time.Duration
 	ecMaxLookback  int64
 	ecStart        time.Time
+	latency        Latency
 
 	lk       sync.RWMutex
 	pausedAt *time.Time
+	// reorgs are the reorgs injected so far and not reverted, in order of
+	// injection. See InjectReorg.
+	reorgs []reorg
+}
+
+// reorg is a fork of the chain after an epoch onto a branch of its own.
+type reorg struct {
+	// atEpoch is the last epoch shared with the chain the branch forks from.
+	atEpoch int64
+	// seed generates the tipsets of the branch.
+	seed []byte
+	// headShift is the number of epochs by which the head is offset from that
+	// implied by the clock, so that the head is at the tip of the branch right
+	// after the reorg.
+	headShift int64
 }
 
 type tipset struct {
@@ -98,6 +117,27 @@ func WithEvolvingPowerTable(fn PowerTableMutator) FakeECOption {
 	}
 }
 
+// Latency specifies the delay injected into each call to the corresponding
+// method of FakeEC. The delay is measured by the clock of FakeEC, and is cut
+// short if the context is cancelled.
+type Latency struct {
+	GetTipsetByEpoch time.Duration
+	GetTipset        time.Duration
+	GetHead          time.Duration
+	GetParent        time.Duration
+	GetPowerTable    time.Duration
+	TipSetWeight     time.Duration
+	Finalize         time.Duration
+}
+
+// WithLatency sets the latency of FakeEC methods, e.g. to measure the effect of
+// a slow EC on the timing of instances. Defaults to no latency.
+func WithLatency(latency Latency) FakeECOption {
+	return func(ec *fakeECConfig) {
+		ec.latency = latency
+	}
+}
+
 func NewFakeEC(ctx context.Context, options ...FakeECOption) *FakeEC {
 	clk := clock.GetClock(ctx)
 	fakeEc := &FakeEC{
@@ -115,8 +155,23 @@ func NewFakeEC(ctx context.Context, options ...FakeECOption) *FakeEC {
 
 var cidPrefixBytes = gpbft.CidPrefix.Bytes()
 
+// genTipset generates the tipset at the given epoch on the current branch of the
+// chain, or nil if the epoch is null.
 func (ec *FakeEC) genTipset(epoch int64) *tipset {
-	h, err := blake2b.New256(ec.seed)
+	ec.lk.RLock()
+	seed := ec.seed
+	for i := len(ec.reorgs) - 1; i >= 0; i-- {
+		if epoch > ec.reorgs[i].atEpoch {
+			seed = ec.reorgs[i].seed
+			break
+		}
+	}
+	ec.lk.RUnlock()
+	return ec.genTipsetFromSeed(seed, epoch)
+}
+
+func (ec *FakeEC) genTipsetFromSeed(seed []byte, epoch int64) *tipset {
+	h, err := blake2b.New256(seed)
 	if err != nil {
 		panic(err)
 	}
@@ -160,7 +215,14 @@ func (ec *FakeEC) genTipset(epoch int64) *tipset {
 
 // GetTipsetByEpoch returns the tipset at a given epoch. If the epoch does not
 // yet exist, it returns an error.
-func (ec *FakeEC) GetTipsetByEpoch(_ context.Context, epoch int64) (ec.TipSet, error) {
+func (ec *FakeEC) GetTipsetByEpoch(ctx context.Context, epoch int64) (ec.TipSet, error) {
+	if err := ec.delay(ctx, ec.latency.GetTipsetByEpoch); err != nil {
+		return nil, err
+	}
+	return ec.getTipsetByEpoch(epoch)
+}
+
+func (ec *FakeEC) getTipsetByEpoch(epoch int64) (ec.TipSet, error) {
 	if ec.GetCurrentHead() < epoch {
 		return nil, fmt.Errorf("does not yet exist")
 	}
@@ -173,8 +235,11 @@ func (ec *FakeEC) GetTipsetByEpoch(_ context.Context, epoch int64) (ec.TipSet, e
 }
 
 func (ec *FakeEC) GetParent(ctx context.Context, ts ec.TipSet) (ec.TipSet, error) {
+	if err := ec.delay(ctx, ec.latency.GetParent); err != nil {
+		return nil, err
+	}
 	for epoch := ts.Epoch() - 1; epoch > 0; epoch-- {
-		ts, err := ec.GetTipsetByEpoch(ctx, epoch)
+		ts, err := ec.getTipsetByEpoch(epoch)
 		if err != nil {
 			return nil, fmt.Errorf("walking back tipsets: %w", err)
 		}
@@ -185,16 +250,89 @@ func (ec *FakeEC) GetParent(ctx context.Context, ts ec.TipSet) (ec.TipSet, error
 	return nil, fmt.Errorf("parent not found")
 }
 
+// delay waits for the given latency to elapse, or the context to be cancelled.
+func (ec *FakeEC) delay(ctx context.Context, latency time.Duration) error {
+	if latency <= 0 {
+		return nil
+	}
+	timer := ec.clock.Timer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (ec *FakeEC) GetCurrentHead() int64 {
 	ec.lk.RLock()
 	defer ec.lk.RUnlock()
+	return ec.getCurrentHead()
+}
+
+func (ec *FakeEC) getCurrentHead() int64 {
+	var headShift int64
+	if len(ec.reorgs) > 0 {
+		headShift = ec.reorgs[len(ec.reorgs)-1].headShift
+	}
+	return ec.getClockHead() + headShift
+}
+
+// getClockHead returns the epoch of the head implied by the clock, not
+// accounting for any reorgs.
+func (ec *FakeEC) getClockHead() int64 {
 	if ec.pausedAt != nil {
 		return int64(ec.pausedAt.Sub(ec.ecStart) / ec.ecPeriod)
 	}
-
 	return int64(ec.clock.Since(ec.ecStart) / ec.ecPeriod)
 }
 
+// InjectReorg forks the chain after the given epoch onto a new branch of the
+// given length, which immediately becomes the head of the chain regardless of
+// whether it is shorter or longer than the chain it replaces. From then on the
+// new branch is extended as time passes, as the chain was before the reorg.
+//
+// Tipsets up to and including atEpoch are unaffected, while those after it are
+// generated deterministically from the seed and the reorgs injected so far, such
+// that each branch has stable tipset keys. Tipsets of a branch forked away from
+// remain resolvable by their key via GetTipset. The timestamp of each tipset
+// remains derived from its epoch, and so is ahead of the clock on a branch longer
+// than the chain it replaces.
+//
+// Reorgs may be injected repeatedly, each forking the then current chain, and
+// reverted in reverse order via RevertReorg.
+//
+// Panics if atEpoch or newBranchLen is negative.
+func (ec *FakeEC) InjectReorg(atEpoch int64, newBranchLen int) {
+	if atEpoch < 0 || newBranchLen < 0 {
+		panic(fmt.Sprintf("invalid reorg at epoch %d with branch length %d", atEpoch, newBranchLen))
+	}
+	ec.lk.Lock()
+	defer ec.lk.Unlock()
+	seed := binary.BigEndian.AppendUint64(slices.Clone(ec.seed), uint64(len(ec.reorgs)+1))
+	seed = binary.BigEndian.AppendUint64(seed, uint64(atEpoch))
+	ec.reorgs = append(ec.reorgs, reorg{
+		atEpoch:   atEpoch,
+		seed:      seed,
+		headShift: atEpoch + int64(newBranchLen) - ec.getClockHead(),
+	})
+}
+
+// RevertReorg reverts the latest reorg injected via InjectReorg, if any,
+// returning the chain to the branch it forked from, which is extended by the
+// time passed since as if the reorg never happened. Returns false if there was
+// no reorg to revert.
+func (ec *FakeEC) RevertReorg() bool {
+	ec.lk.Lock()
+	defer ec.lk.Unlock()
+	if len(ec.reorgs) == 0 {
+		return false
+	}
+	ec.reorgs = ec.reorgs[:len(ec.reorgs)-1]
+	return true
+}
+
 // Pause pauses EC.
 func (ec *FakeEC) Pause() {
 	ec.lk.Lock()
@@ -213,10 +351,16 @@ func (ec *FakeEC) Resume() {
 }
 
 func (ec *FakeEC) GetHead(ctx context.Context) (ec.TipSet, error) {
-	return ec.GetTipsetByEpoch(ctx, ec.GetCurrentHead())
+	if err := ec.delay(ctx, ec.latency.GetHead); err != nil {
+		return nil, err
+	}
+	return ec.getTipsetByEpoch(ec.GetCurrentHead())
 }
 
-func (ec *FakeEC) GetPowerTable(_ context.Context, tsk gpbft.TipSetKey) (gpbft.PowerEntries, error) {
+func (ec *FakeEC) GetPowerTable(ctx context.Context, tsk gpbft.TipSetKey) (gpbft.PowerEntries, error) {
+	if err := ec.delay(ctx, ec.latency.GetPowerTable); err != nil {
+		return nil, err
+	}
 	targetEpoch := ec.epochFromTsk(tsk)
 	headEpoch := ec.GetCurrentHead()
 
@@ -236,12 +380,54 @@ func (ec *FakeEC) GetPowerTable(_ context.Context, tsk gpbft.TipSetKey) (gpbft.P
 	return pt, nil
 }
 
+// TipSetWeight returns a weight derived deterministically from the epoch and key
+// of the given tipset. The weight grows with epoch, as the weight of a chain does
+// with its length, while the key breaks ties between tipsets of the same epoch.
+func (ec *FakeEC) TipSetWeight(ctx context.Context, tsk gpbft.TipSetKey) (big.Int, error) {
+	if err := ec.delay(ctx, ec.latency.TipSetWeight); err != nil {
+		return big.Zero(), err
+	}
+	if len(tsk) < 6+32 {
+		return big.Zero(), fmt.Errorf("invalid tipset key: %x", tsk)
+	}
+	epoch := ec.epochFromTsk(tsk)
+	if epoch < 0 {
+		return big.Zero(), fmt.Errorf("invalid tipset epoch: %d", epoch)
+	}
+	digest := blake2b.Sum256(tsk)
+	weight := big.NewInt(epoch)
+	weight = big.Lsh(weight, 32)
+	return big.Add(weight, big.NewIntUnsigned(uint64(binary.BigEndian.Uint32(digest[:4])))), nil
+}
+
 func (ec *FakeEC) epochFromTsk(tsk gpbft.TipSetKey) int64 {
 	return int64(binary.BigEndian.Uint64(tsk[6+32-8 : 6+32]))
 }
 
-func (ec *FakeEC) GetTipset(_ context.Context, tsk gpbft.TipSetKey) (ec.TipSet, error) {
-	return ec.genTipset(ec.epochFromTsk(tsk)), nil
+func (ec *FakeEC) GetTipset(ctx context.Context, tsk gpbft.TipSetKey) (ec.TipSet, error) {
+	if err := ec.delay(ctx, ec.latency.GetTipset); err != nil {
+		return nil, err
+	}
+	epoch := ec.epochFromTsk(tsk)
+	ts := ec.genTipset(epoch)
+	if ts != nil && bytes.Equal(ts.tsk, tsk) {
+		return ts, nil
+	}
+	// Look for the tipset among the branches forked away from.
+	ec.lk.RLock()
+	seeds := [][]byte{ec.seed}
+	for _, r := range ec.reorgs {
+		seeds = append(seeds, r.seed)
+	}
+	ec.lk.RUnlock()
+	for _, seed := range seeds {
+		if fork := ec.genTipsetFromSeed(seed, epoch); fork != nil && bytes.Equal(fork.tsk, tsk) {
+			return fork, nil
+		}
+	}
+	return ts, nil
 }
 
-func (ec *FakeEC) Finalize(context.Context, gpbft.TipSetKey) error { return nil }
+func (ec *FakeEC) Finalize(ctx context.Context, _ gpbft.TipSetKey) error {
+	return ec.delay(ctx, ec.latency.Finalize)
+}
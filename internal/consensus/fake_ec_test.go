@@ -1,8 +1,15 @@
 package consensus
 
 import (
+	"bytes"
+	"context"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/filecoin-project/go-f3/ec"
+	"github.com/filecoin-project/go-f3/internal/clock"
+	"github.com/filecoin-project/go-state-types/big"
 	"github.com/stretchr/testify/require"
 )
 
@@ -12,3 +19,151 @@ func TestTipsetString(t *testing.T) {
 	require.True(t, len(ts.String()) != 0)
 	t.Log(ts.String())
 }
+
+func TestFakeECLatency(t *testing.T) {
+	ctx, clk := clock.WithMockClock(context.Background())
+	subject := NewFakeEC(ctx,
+		WithBootstrapEpoch(10),
+		WithECPeriod(time.Second),
+		WithLatency(Latency{GetHead: time.Minute}),
+	)
+
+	// Methods without latency return immediately.
+	_, err := subject.GetTipsetByEpoch(ctx, 5)
+	require.NoError(t, err)
+
+	head := make(chan ec.TipSet, 1)
+	go func() {
+		ts, _ := subject.GetHead(ctx)
+		head <- ts
+	}()
+	require.Never(t, func() bool { return len(head) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+	clk.WaitForAllTimers()
+	// The head is determined once the latency has elapsed.
+	require.Equal(t, int64(70), (<-head).Epoch())
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = subject.GetHead(cancelledCtx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFakeECTipSetWeight(t *testing.T) {
+	ctx, clk := clock.WithMockClock(context.Background())
+	subject := NewFakeEC(ctx, WithSeed(1413), WithECPeriod(time.Second))
+	clk.Add(100 * time.Second)
+
+	// Weight grows along the chain, and is the same each time it is asked for.
+	var previous big.Int
+	for epoch := int64(1); epoch <= 100; epoch++ {
+		ts, err := subject.GetTipsetByEpoch(ctx, epoch)
+		require.NoError(t, err)
+		weight, err := subject.TipSetWeight(ctx, ts.Key())
+		require.NoError(t, err)
+		again, err := subject.TipSetWeight(ctx, ts.Key())
+		require.NoError(t, err)
+		require.True(t, weight.Equals(again))
+		if !previous.Nil() {
+			if ts.Epoch() == epoch {
+				require.True(t, weight.GreaterThan(previous), "epoch %d", epoch)
+			} else {
+				// A null epoch resolves to the tipset before it.
+				require.True(t, weight.Equals(previous), "epoch %d", epoch)
+			}
+		}
+		previous = weight
+	}
+
+	// A tipset of another fork at the same epoch weighs differently.
+	ts, err := subject.GetTipsetByEpoch(ctx, 50)
+	require.NoError(t, err)
+	fork := NewFakeEC(ctx, WithSeed(2938), WithECPeriod(time.Second)).genTipset(ts.Epoch())
+	require.NotNil(t, fork)
+	weight, err := subject.TipSetWeight(ctx, ts.Key())
+	require.NoError(t, err)
+	forkWeight, err := subject.TipSetWeight(ctx, fork.Key())
+	require.NoError(t, err)
+	require.False(t, weight.Equals(forkWeight))
+
+	_, err = subject.TipSetWeight(ctx, []byte("fish"))
+	require.Error(t, err)
+}
+
+func TestFakeECInjectReorg(t *testing.T) {
+	ctx, clk := clock.WithMockClock(context.Background())
+	subject := NewFakeEC(ctx, WithSeed(1413), WithECPeriod(time.Second))
+	clk.Add(100 * time.Second)
+
+	// walk returns the tipsets from head back to, and excluding, the given epoch.
+	walk := func(head ec.TipSet, to int64) []ec.TipSet {
+		var tipsets []ec.TipSet
+		for ts := head; ts.Epoch() > to; {
+			tipsets = append(tipsets, ts)
+			var err error
+			ts, err = subject.GetParent(ctx, ts)
+			require.NoError(t, err)
+		}
+		return tipsets
+	}
+	// requireDescends asserts that the given head descends from the chain of
+	// tipsets as walked back from its head.
+	requireDescends := func(chain []ec.TipSet, head ec.TipSet) {
+		walked := walk(head, 50)
+		require.GreaterOrEqual(t, len(walked), len(chain))
+		require.Equal(t, chain, walked[len(walked)-len(chain):])
+	}
+	originalHead, err := subject.GetHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), originalHead.Epoch())
+	original := walk(originalHead, 50)
+	forkPoint, err := subject.GetTipsetByEpoch(ctx, 80)
+	require.NoError(t, err)
+
+	// Reorg onto a branch shorter than the original chain.
+	subject.InjectReorg(80, 10)
+	head, err := subject.GetHead(ctx)
+	require.NoError(t, err)
+	require.LessOrEqual(t, head.Epoch(), int64(90))
+	branch := walk(head, 50)
+	var crossed bool
+	for _, ts := range branch {
+		onOriginal := slices.ContainsFunc(original, func(o ec.TipSet) bool { return bytes.Equal(o.Key(), ts.Key()) })
+		if ts.Epoch() > forkPoint.Epoch() {
+			require.False(t, onOriginal, "epoch %d", ts.Epoch())
+		} else {
+			require.True(t, onOriginal, "epoch %d", ts.Epoch())
+			crossed = true
+		}
+	}
+	require.True(t, crossed)
+
+	// The branch has stable keys, and the tipsets of the original chain remain
+	// resolvable.
+	again, err := subject.GetHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, head.Key(), again.Key())
+	got, err := subject.GetTipset(ctx, originalHead.Key())
+	require.NoError(t, err)
+	require.Equal(t, originalHead.Key(), got.Key())
+
+	// The branch is extended as time passes.
+	clk.Add(5 * time.Second)
+	extendedHead, err := subject.GetHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(95), subject.GetCurrentHead())
+	requireDescends(branch, extendedHead)
+
+	// Reverting the reorg returns to the original chain, extended since.
+	require.True(t, subject.RevertReorg())
+	require.False(t, subject.RevertReorg())
+	revertedHead, err := subject.GetHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(105), subject.GetCurrentHead())
+	requireDescends(original, revertedHead)
+
+	// Injecting the same reorg again is deterministic.
+	subject.InjectReorg(80, 15)
+	head, err = subject.GetHead(ctx)
+	require.NoError(t, err)
+	requireDescends(branch, head)
+}
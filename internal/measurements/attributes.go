@@ -21,6 +21,10 @@ var (
 	AttrStatusNotFound      = attribute.String("status", "error-not-found")
 
 	AttrDialSucceeded = attribute.Key("dial-succeeded")
+	// AttrNetwork attributes measurements to the name of the network they were
+	// recorded for, so that those of a node running several networks, one after
+	// another or concurrently, can be told apart.
+	AttrNetwork = attribute.Key("network")
 )
 
 func Status(ctx context.Context, err error) attribute.KeyValue {
@@ -3,6 +3,7 @@ package powerstore
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"slices"
@@ -42,7 +43,14 @@ type Store struct {
 	lastStoredEpoch int64
 }
 
-const diffPrefix = "/powerdiffs"
+const (
+	diffPrefix = "/powerdiffs"
+	// backfillPrefix holds, for each tipset finalized at the head of a certificate,
+	// the instance whose power table is that of the tipset.
+	backfillPrefix = "/backfill"
+	// backfilledKey holds the next instance from which to resume backfilling.
+	backfilledKey = "/backfilled"
+)
 
 func New(ctx context.Context, ec ec.Backend, ds datastore.Datastore, cs *certstore.Store, manifest *manifest.Manifest) (*Store, error) {
 	runningCtx, ctxCancel := context.WithCancel(context.WithoutCancel(ctx))
@@ -86,6 +94,12 @@ func (ps *Store) GetPowerTable(ctx context.Context, tsk gpbft.TipSetKey) (gpbft.
 		return pt, nil
 	}
 
+	if instance, found, err := ps.backfilledInstance(ctx, tsk); err != nil {
+		log.Errorw("failed to lookup backfilled power table", "tsk", tsk, "error", err)
+	} else if found {
+		return ps.cs.GetPowerTable(ctx, instance)
+	}
+
 	ts, err := ps.GetTipset(ctx, tsk)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load tipset with key %s: %w", tsk, err)
@@ -120,6 +134,76 @@ func (ps *Store) GetPowerTable(ctx context.Context, tsk gpbft.TipSetKey) (gpbft.
 	return certs.ApplyPowerTableDiffs(basePt, diffs...)
 }
 
+// Backfill walks the certificates in the certstore, and records the power table
+// of the tipset finalized at the head of each, so that they can be served by
+// GetPowerTable once EC has forgotten them. This is intended for nodes that
+// enable the power store with an already populated certstore, whose power store
+// would otherwise only cover epochs after it is enabled.
+//
+// The power tables are reconstructed from the certstore on demand, and only an
+// index of the tipsets they belong to is stored. Backfill resumes from where it
+// last left off, and so may be called again to cover newer certificates.
+func (ps *Store) Backfill(ctx context.Context) error {
+	latest := ps.cs.Latest()
+	if latest == nil {
+		return nil
+	}
+	// The power table of the head of a certificate is that of the instance
+	// CommitteeLookback instances later, which the certstore holds up to the
+	// instance after the latest.
+	if latest.GPBFTInstance+1 < ps.manifest.InitialInstance+ps.manifest.CommitteeLookback {
+		return nil
+	}
+	end := latest.GPBFTInstance + 1 - ps.manifest.CommitteeLookback
+
+	start := ps.manifest.InitialInstance
+	switch b, err := ps.ds.Get(ctx, datastore.NewKey(backfilledKey)); {
+	case errors.Is(err, datastore.ErrNotFound):
+	case err != nil:
+		return fmt.Errorf("failed to read backfill progress: %w", err)
+	case len(b) != 8:
+		return fmt.Errorf("unexpected backfill progress len %d != 8", len(b))
+	default:
+		start = binary.BigEndian.Uint64(b)
+	}
+	if start > end {
+		return nil
+	}
+
+	for instance := start; instance <= end; instance++ {
+		cert, err := ps.cs.Get(ctx, instance)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate for instance %d: %w", instance, err)
+		}
+		key := ps.dsKeyForBackfill(cert.ECChain.Head().Key)
+		value := binary.BigEndian.AppendUint64(nil, instance+ps.manifest.CommitteeLookback)
+		if err := ps.ds.Put(ctx, key, value); err != nil {
+			return fmt.Errorf("failed to backfill power table for instance %d: %w", instance, err)
+		}
+	}
+	if err := ps.ds.Put(ctx, datastore.NewKey(backfilledKey), binary.BigEndian.AppendUint64(nil, end+1)); err != nil {
+		return fmt.Errorf("failed to write backfill progress: %w", err)
+	}
+	log.Infow("backfilled power tables from certstore", "from", start, "to", end)
+	return nil
+}
+
+// backfilledInstance returns the instance whose power table is that of the
+// given tipset, if recorded by Backfill.
+func (ps *Store) backfilledInstance(ctx context.Context, tsk gpbft.TipSetKey) (uint64, bool, error) {
+	b, err := ps.ds.Get(ctx, ps.dsKeyForBackfill(tsk))
+	switch {
+	case errors.Is(err, datastore.ErrNotFound):
+		return 0, false, nil
+	case err != nil:
+		return 0, false, err
+	case len(b) != 8:
+		return 0, false, fmt.Errorf("unexpected backfilled instance len %d != 8", len(b))
+	default:
+		return binary.BigEndian.Uint64(b), true, nil
+	}
+}
+
 func (ps *Store) f3PowerBase(ctx context.Context) (int64, uint64, error) {
 	baseEpoch := ps.manifest.BootstrapEpoch - ps.manifest.EC.Finality
 	baseInstance := ps.manifest.InitialInstance
@@ -353,3 +437,7 @@ func (ps *Store) get(ctx context.Context, epoch int64) (certs.PowerTableDiff, er
 func (ps *Store) dsKeyForDiff(epoch int64) datastore.Key {
 	return datastore.NewKey(fmt.Sprintf("%s/%016X", diffPrefix, epoch))
 }
+
+func (ps *Store) dsKeyForBackfill(tsk gpbft.TipSetKey) datastore.Key {
+	return datastore.NewKey(fmt.Sprintf("%s/%X", backfillPrefix, []byte(tsk)))
+}
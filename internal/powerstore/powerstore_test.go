@@ -225,7 +225,80 @@ func advanceF3(t *testing.T, m *manifest.Manifest, ps *powerstore.Store, cs *cer
 		require.NoError(t, cs.Put(ctx, cert))
 
 		basePt = nextPt
-		gpbftChain = &gpbft.ECChain{TipSets: gpbftChain.TipSets[count-1:]}
+		// The next certificate is based on the head finalized by this one.
+		gpbftChain = &gpbft.ECChain{TipSets: gpbftChain.TipSets[newChain.Len()-1:]}
 		instance++
 	}
 }
+
+func TestPowerStore_Backfill(t *testing.T) {
+	ctx, clk := clock.WithMockClock(context.Background())
+
+	m := manifest.LocalDevnetManifest()
+
+	ec := consensus.NewFakeEC(ctx,
+		consensus.WithMaxLookback(2*m.EC.Finality),
+		consensus.WithBootstrapEpoch(m.BootstrapEpoch),
+		consensus.WithECPeriod(m.EC.Period),
+		consensus.WithInitialPowerTable(basePowerTable),
+		consensus.WithEvolvingPowerTable(func(epoch int64, pt gpbft.PowerEntries) gpbft.PowerEntries {
+			pt = slices.Clone(pt)
+			pt[0].Power = big.Add(gpbft.NewStoragePower(epoch), pt[0].Power)
+			return pt
+		}),
+	)
+
+	bsTs, err := ec.GetTipsetByEpoch(ctx, m.BootstrapEpoch-m.EC.Finality)
+	require.NoError(t, err)
+	pt, err := ec.GetPowerTable(ctx, bsTs.Key())
+	require.NoError(t, err)
+
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	cs, err := certstore.CreateStore(ctx, ds, m.InitialInstance, pt)
+	require.NoError(t, err)
+
+	// Populate the certstore while EC still knows the power tables, without
+	// running the power store.
+	ps, err := powerstore.New(ctx, ec, ds, cs, m)
+	require.NoError(t, err)
+	head, err := ec.GetHead(ctx)
+	require.NoError(t, err)
+	advanceF3(t, m, ps, cs, head.Epoch(), 10)
+	latest := cs.Latest()
+	require.NotNil(t, latest)
+	require.Greater(t, latest.GPBFTInstance+1, m.InitialInstance+m.CommitteeLookback)
+
+	// The power tables of the tipsets finalized by each certificate whose power
+	// table the certstore can reconstruct.
+	wantPowerTables := make(map[string]gpbft.PowerEntries)
+	for instance := m.InitialInstance; instance <= latest.GPBFTInstance+1-m.CommitteeLookback; instance++ {
+		cert, err := cs.Get(ctx, instance)
+		require.NoError(t, err)
+		tsk := cert.ECChain.Head().Key
+		wantPowerTables[string(tsk)], err = ec.GetPowerTable(ctx, tsk)
+		require.NoError(t, err)
+	}
+
+	// Until EC forgets them, and so does the power store as they are behind F3.
+	clk.Add(m.EC.Period * time.Duration(3*m.EC.Finality))
+	for tsk := range wantPowerTables {
+		_, err := ec.GetPowerTable(ctx, gpbft.TipSetKey(tsk))
+		require.Error(t, err)
+	}
+	first, err := cs.Get(ctx, m.InitialInstance)
+	require.NoError(t, err)
+	_, err = ps.GetPowerTable(ctx, first.ECChain.Head().Key)
+	require.Error(t, err)
+
+	// Once backfilled, the power store remembers them all, including after a
+	// restart and a repeated backfill.
+	require.NoError(t, ps.Backfill(ctx))
+	ps, err = powerstore.New(ctx, ec, ds, cs, m)
+	require.NoError(t, err)
+	require.NoError(t, ps.Backfill(ctx))
+	for tsk, want := range wantPowerTables {
+		got, err := ps.GetPowerTable(ctx, gpbft.TipSetKey(tsk))
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+}
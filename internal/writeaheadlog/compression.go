@@ -0,0 +1,135 @@
+package writeaheadlog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is the compression of finalized log files.
+type Compression int
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Zstd
+)
+
+const (
+	gzipExtension = ".gz"
+	zstdExtension = ".zst"
+	tmpExtension  = ".tmp"
+)
+
+func (c Compression) extension() string {
+	switch c {
+	case Gzip:
+		return gzipExtension
+	case Zstd:
+		return zstdExtension
+	default:
+		return ""
+	}
+}
+
+// compressionOf infers the compression of the log file with the given name, and
+// whether the name is that of a log file at all.
+func compressionOf(logName string) (Compression, bool) {
+	switch {
+	case strings.HasSuffix(logName, walExtension):
+		return NoCompression, true
+	case strings.HasSuffix(logName, walExtension+gzipExtension):
+		return Gzip, true
+	case strings.HasSuffix(logName, walExtension+zstdExtension):
+		return Zstd, true
+	default:
+		return NoCompression, false
+	}
+}
+
+// decompress wraps the given reader of a log file with the given compression.
+func decompress(c Compression, r io.Reader) (io.ReadCloser, error) {
+	switch c {
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// compressLogFile compresses the log file with the given name in directory, and
+// returns the name of the compressed file. The compressed file is written in
+// full and synced before it replaces the original, so that a crash at any point
+// leaves behind at least one complete copy of the log.
+func compressLogFile(directory, logName string, c Compression) (string, error) {
+	compressedName, err := writeCompressedLogFile(directory, logName, c)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(filepath.Join(directory, compressedName+tmpExtension), filepath.Join(directory, compressedName)); err != nil {
+		_ = os.Remove(filepath.Join(directory, compressedName+tmpExtension))
+		return "", fmt.Errorf("renaming compressed log file: %w", err)
+	}
+	if err := os.Remove(filepath.Join(directory, logName)); err != nil {
+		return "", fmt.Errorf("removing uncompressed log file: %w", err)
+	}
+	return compressedName, nil
+}
+
+// writeCompressedLogFile writes a compressed copy of the log file with the given
+// name in directory under a temporary name, i.e. the returned name of the
+// compressed file followed by tmpExtension. The copy is complete and synced once
+// it returns, and removed if it fails.
+func writeCompressedLogFile(directory, logName string, c Compression) (_ string, _err error) {
+	compressedName := logName + c.extension()
+	tmpPath := filepath.Join(directory, compressedName+tmpExtension)
+	defer func() {
+		if _err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	src, err := os.Open(filepath.Join(directory, logName))
+	if err != nil {
+		return "", fmt.Errorf("opening log file: %w", err)
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return "", fmt.Errorf("creating compressed log file: %w", err)
+	}
+	defer dst.Close()
+
+	var compressor io.WriteCloser
+	switch c {
+	case Gzip:
+		compressor = gzip.NewWriter(dst)
+	case Zstd:
+		if compressor, err = zstd.NewWriter(dst); err != nil {
+			return "", fmt.Errorf("creating zstd writer: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("unknown compression: %d", c)
+	}
+	if _, err := io.Copy(compressor, src); err != nil {
+		return "", fmt.Errorf("compressing log file: %w", err)
+	}
+	if err := compressor.Close(); err != nil {
+		return "", fmt.Errorf("finishing compression: %w", err)
+	}
+	if err := dst.Sync(); err != nil {
+		return "", fmt.Errorf("syncing compressed log file: %w", err)
+	}
+	return compressedName, nil
+}
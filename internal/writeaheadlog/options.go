@@ -0,0 +1,38 @@
+package writeaheadlog
+
+import "fmt"
+
+type Option func(*options) error
+
+type options struct {
+	compression Compression
+}
+
+func newOptions(o ...Option) (*options, error) {
+	opts := &options{}
+	for _, apply := range o {
+		if err := apply(opts); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// WithCompression sets the compression of log files once finalized, i.e. once
+// rotated away from or closed. The active log file is never compressed, so that
+// appending to and recovering it stay cheap. Finalized log files are compressed
+// in the background, so that rotation does not block appends, and Close waits
+// for their compression to complete. Log files are read regardless of their
+// compression, so the compression may change between openings of the same log.
+// Defaults to NoCompression if unset.
+func WithCompression(c Compression) Option {
+	return func(o *options) error {
+		switch c {
+		case NoCompression, Gzip, Zstd:
+			o.compression = c
+			return nil
+		default:
+			return fmt.Errorf("unknown compression: %d", c)
+		}
+	}
+}
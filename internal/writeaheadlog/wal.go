@@ -38,7 +38,11 @@ type WriteAheadLog[T any, PT interface {
 	// pointer type trickery above
 	lk sync.Mutex
 
-	path string
+	path        string
+	compression Compression
+	// compressing tracks the finalized log files being compressed in the
+	// background. See compressInBackground.
+	compressing sync.WaitGroup
 
 	logFiles []logStat
 	active   struct {
@@ -55,12 +59,17 @@ type logStat struct {
 func Open[T any, PT interface {
 	*T
 	Entry
-}](directory string) (*WriteAheadLog[T, PT], error) {
+}](directory string, o ...Option) (*WriteAheadLog[T, PT], error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
 	wal := WriteAheadLog[T, PT]{
-		path: directory,
+		path:        directory,
+		compression: opts.compression,
 	}
 
-	err := wal.hydrate()
+	err = wal.hydrate()
 	if err != nil {
 		return nil, fmt.Errorf("reading the WAL: %w", err)
 	}
@@ -93,6 +102,15 @@ func (wal *WriteAheadLog[T, PT]) All() ([]T, error) {
 }
 
 func (wal *WriteAheadLog[T, PT]) Append(value T) error {
+	return wal.AppendAll(value)
+}
+
+// AppendAll appends the given values to the log, syncing the log file once
+// after all of them are written rather than once per value.
+func (wal *WriteAheadLog[T, PT]) AppendAll(values ...T) error {
+	if len(values) == 0 {
+		return nil
+	}
 	wal.lk.Lock()
 	defer wal.lk.Unlock()
 
@@ -100,12 +118,12 @@ func (wal *WriteAheadLog[T, PT]) Append(value T) error {
 		return fmt.Errorf("attemting to rotate: %w", err)
 	}
 	var buf bytes.Buffer
-
-	err := PT(&value).MarshalCBOR(&buf)
-	if err != nil {
-		return fmt.Errorf("saving value to WAL: %w", err)
+	for i := range values {
+		if err := PT(&values[i]).MarshalCBOR(&buf); err != nil {
+			return fmt.Errorf("saving value to WAL: %w", err)
+		}
 	}
-	_, err = buf.WriteTo(wal.active.file)
+	_, err := buf.WriteTo(wal.active.file)
 	if err != nil {
 		return fmt.Errorf("writing buffer to file: %w", err)
 	}
@@ -115,7 +133,9 @@ func (wal *WriteAheadLog[T, PT]) Append(value T) error {
 		return fmt.Errorf("sycning the file: %w", err)
 	}
 
-	wal.active.maxEpoch = max(wal.active.maxEpoch, PT(&value).WALEpoch())
+	for i := range values {
+		wal.active.maxEpoch = max(wal.active.maxEpoch, PT(&values[i]).WALEpoch())
+	}
 
 	return nil
 }
@@ -126,7 +146,11 @@ func (wal *WriteAheadLog[T, PT]) Append(value T) error {
 func (wal *WriteAheadLog[T, PT]) Purge(keepEpoch uint64) error {
 	wal.lk.Lock()
 	defer wal.lk.Unlock()
+	return wal.purge(keepEpoch)
+}
 
+// purge the finalized log files containing entries only older than keepEpoch
+func (wal *WriteAheadLog[T, PT]) purge(keepEpoch uint64) error {
 	var keptLogFiles []logStat
 	var err error
 	for _, c := range wal.logFiles {
@@ -158,10 +182,13 @@ func (wal *WriteAheadLog[T, PT]) maybeRotate() error {
 
 // Close closes the existing file
 // the WAL is safe to discard or can be used still
+// It waits for any finalized log files to be compressed.
 func (wal *WriteAheadLog[T, PT]) Close() error {
 	wal.lk.Lock()
-	defer wal.lk.Unlock()
-	return wal.flush()
+	err := wal.flush()
+	wal.lk.Unlock()
+	wal.compressing.Wait()
+	return err
 }
 
 // Rotate closes the existing file
@@ -208,6 +235,10 @@ func (wal *WriteAheadLog[T, PT]) flush() error {
 		return fmt.Errorf("closing the file: %w", err)
 	}
 	wal.logFiles = append(wal.logFiles, wal.active.logStat)
+	if wal.compression != NoCompression {
+		wal.compressing.Add(1)
+		go wal.compressInBackground(wal.active.logName)
+	}
 
 	wal.active.file = nil
 	wal.active.logStat = logStat{}
@@ -215,6 +246,44 @@ func (wal *WriteAheadLog[T, PT]) flush() error {
 	return nil
 }
 
+// compressInBackground compresses the finalized log file with the given name
+// without holding the lock, so that appends are not blocked by it, and then
+// swaps the compressed file in unless the log file has been purged meanwhile.
+func (wal *WriteAheadLog[T, PT]) compressInBackground(logName string) {
+	defer wal.compressing.Done()
+
+	// The uncompressed log file stays intact if compression fails, and is
+	// compressed no later than the next time the log is opened.
+	compressedName, err := writeCompressedLogFile(wal.path, logName, wal.compression)
+	if err != nil {
+		log.Errorw("failed to compress WAL file", "file", logName, "err", err)
+		return
+	}
+	tmpPath := filepath.Join(wal.path, compressedName+tmpExtension)
+
+	wal.lk.Lock()
+	defer wal.lk.Unlock()
+	i := slices.IndexFunc(wal.logFiles, func(s logStat) bool { return s.logName == logName })
+	if i < 0 {
+		// Purged while being compressed.
+		if err := os.Remove(tmpPath); err != nil {
+			log.Errorw("failed to remove compressed copy of purged WAL file", "file", logName, "err", err)
+		}
+		return
+	}
+	if err := os.Rename(tmpPath, filepath.Join(wal.path, compressedName)); err != nil {
+		log.Errorw("failed to compress WAL file", "file", logName, "err", err)
+		_ = os.Remove(tmpPath)
+		return
+	}
+	wal.logFiles[i].logName = compressedName
+	// If this fails, the uncompressed log file is removed the next time the log is
+	// opened instead, since its compressed copy is complete.
+	if err := os.Remove(filepath.Join(wal.path, logName)); err != nil {
+		log.Errorw("failed to remove compressed WAL file", "file", logName, "err", err)
+	}
+}
+
 func (wal *WriteAheadLog[T, PT]) hydrate() error {
 	dirEntries, err := os.ReadDir(wal.path)
 
@@ -231,11 +300,43 @@ func (wal *WriteAheadLog[T, PT]) hydrate() error {
 		return strings.Compare(a.Name(), b.Name())
 	})
 
+	names := make(map[string]struct{}, len(dirEntries))
+	for _, entry := range dirEntries {
+		names[entry.Name()] = struct{}{}
+	}
 	for _, entry := range dirEntries {
-		if !strings.HasSuffix(entry.Name(), walExtension) {
+		name := entry.Name()
+		if strings.HasSuffix(name, tmpExtension) {
+			// Left behind by compression interrupted before completion.
+			if err := os.Remove(filepath.Join(wal.path, name)); err != nil {
+				return fmt.Errorf("removing incomplete WAL file %q: %w", name, err)
+			}
 			continue
 		}
-		logS, _, err := wal.readLogFile(entry.Name(), false)
+		compression, ok := compressionOf(name)
+		if !ok {
+			continue
+		}
+		if compression == NoCompression {
+			// A compressed copy is only ever complete, so the uncompressed one is
+			// left over from a crash before its removal.
+			_, gzipped := names[name+gzipExtension]
+			_, zstded := names[name+zstdExtension]
+			if gzipped || zstded {
+				if err := os.Remove(filepath.Join(wal.path, name)); err != nil {
+					return fmt.Errorf("removing already compressed WAL file %q: %w", name, err)
+				}
+				continue
+			}
+			if wal.compression != NoCompression {
+				if compressedName, err := compressLogFile(wal.path, name, wal.compression); err != nil {
+					log.Errorw("failed to compress WAL file", "file", name, "err", err)
+				} else {
+					name = compressedName
+				}
+			}
+		}
+		logS, _, err := wal.readLogFile(name, false)
 		if err != nil {
 			return fmt.Errorf("reading log file: %w", err)
 		}
@@ -258,8 +359,14 @@ func (wal *WriteAheadLog[T, PT]) readLogFile(logname string, keepVaues bool) (lo
 		return res, nil, fmt.Errorf("opening logfile %q: %w", logname, err)
 	}
 	defer logFile.Close()
+	compression, _ := compressionOf(logname)
+	decompressed, err := decompress(compression, logFile)
+	if err != nil {
+		return res, nil, fmt.Errorf("decompressing logfile %q: %w", logname, err)
+	}
+	defer decompressed.Close()
 
-	logFileReader := cbg.NewCborReader(logFile)
+	logFileReader := cbg.NewCborReader(decompressed)
 	var content []T
 	var maxEpoch uint64
 	for err == nil {
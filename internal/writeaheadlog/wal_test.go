@@ -1,8 +1,12 @@
 package writeaheadlog
 
 import (
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	cbgtesting "github.com/whyrusleeping/cbor-gen/testing"
@@ -66,6 +70,30 @@ func TestWALSimple(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, entries, res)
 }
+
+func TestWALAppendAll(t *testing.T) {
+	path := t.TempDir()
+	wal, err := Open[testPayload](path)
+	require.NoError(t, err)
+
+	entries := []testPayload{
+		{Value: 0, Foo: "Foo0"},
+		{Value: 1, Foo: "Foo1"},
+		{Value: 2, Foo: "Foo2"},
+	}
+	require.NoError(t, wal.AppendAll(entries[:2]...))
+	require.NoError(t, wal.AppendAll())
+	require.NoError(t, wal.AppendAll(entries[2]))
+	require.NoError(t, wal.Close())
+
+	wal, err = Open[testPayload](path)
+	require.NoError(t, err)
+	res, err := wal.All()
+	require.NoError(t, err)
+	require.Equal(t, entries, res)
+	require.NoError(t, wal.Close())
+}
+
 func TestWALRecovery(t *testing.T) {
 	path := t.TempDir()
 	t.Logf("tempdir: %v", path)
@@ -175,3 +203,152 @@ func TestWALPurge(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expected, res)
 }
+
+func TestWALCompression(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		compression Compression
+	}{
+		{name: "gzip", compression: Gzip},
+		{name: "zstd", compression: Zstd},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := t.TempDir()
+			wal, err := Open[testPayload](path, WithCompression(test.compression))
+			require.NoError(t, err)
+
+			var entries []testPayload
+			for i := uint64(0); i < 3; i++ {
+				for j := 0; j < 100; j++ {
+					entry := testPayload{Value: i, Foo: fmt.Sprintf("Foo%d.%d", i, j)}
+					require.NoError(t, wal.Append(entry))
+					entries = append(entries, entry)
+				}
+				// Finalize the active log file, which compresses it in the background.
+				require.NoError(t, wal.Rotate())
+			}
+			wal.compressing.Wait()
+			// The active log file is not compressed.
+			active := testPayload{Value: 3, Foo: "Foo3"}
+			require.NoError(t, wal.Append(active))
+			entries = append(entries, active)
+			require.Equal(t, 3, countFiles(t, path, walExtension+test.compression.extension()))
+			require.Equal(t, 1, countFiles(t, path, walExtension))
+
+			res, err := wal.All()
+			require.NoError(t, err)
+			require.Equal(t, entries, res)
+			require.NoError(t, wal.Close())
+			require.Equal(t, 4, countFiles(t, path, walExtension+test.compression.extension()))
+			require.Zero(t, countFiles(t, path, walExtension))
+
+			// Compressed log files are read regardless of the compression the log is
+			// opened with.
+			for _, reopenWith := range []Compression{test.compression, NoCompression} {
+				wal, err = Open[testPayload](path, WithCompression(reopenWith))
+				require.NoError(t, err)
+				res, err = wal.All()
+				require.NoError(t, err)
+				require.Equal(t, entries, res)
+			}
+
+			require.NoError(t, wal.Purge(2))
+			res, err = wal.All()
+			require.NoError(t, err)
+			require.Equal(t, entries[200:], res)
+			require.Equal(t, 2, countFiles(t, path, walExtension+test.compression.extension()))
+		})
+	}
+
+	t.Run("uncompressed log files are compressed on open", func(t *testing.T) {
+		path := t.TempDir()
+		wal, err := Open[testPayload](path)
+		require.NoError(t, err)
+		entries := []testPayload{{Value: 0, Foo: "Foo0"}, {Value: 1, Foo: "Foo1"}}
+		for _, e := range entries {
+			require.NoError(t, wal.Append(e))
+		}
+		require.NoError(t, wal.Close())
+		require.Equal(t, 1, countFiles(t, path, walExtension))
+
+		wal, err = Open[testPayload](path, WithCompression(Zstd))
+		require.NoError(t, err)
+		require.Zero(t, countFiles(t, path, walExtension))
+		require.Equal(t, 1, countFiles(t, path, walExtension+zstdExtension))
+		res, err := wal.All()
+		require.NoError(t, err)
+		require.Equal(t, entries, res)
+	})
+
+	t.Run("log files purged while being compressed are not kept", func(t *testing.T) {
+		path := t.TempDir()
+		wal, err := Open[testPayload](path, WithCompression(Gzip))
+		require.NoError(t, err)
+		require.NoError(t, wal.Append(testPayload{Value: 0, Foo: "Foo0"}))
+		logName := wal.active.logName
+
+		// Hold the lock until the compressed copy is written, so that the log file
+		// is purged before it can be swapped in.
+		wal.lk.Lock()
+		require.NoError(t, wal.flush())
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(filepath.Join(path, logName+gzipExtension+tmpExtension))
+			return err == nil
+		}, time.Second, time.Millisecond)
+		require.NoError(t, wal.purge(1))
+		wal.lk.Unlock()
+		require.NoError(t, wal.Close())
+
+		res, err := wal.All()
+		require.NoError(t, err)
+		require.Empty(t, res)
+		dirEntries, err := os.ReadDir(path)
+		require.NoError(t, err)
+		require.Empty(t, dirEntries)
+	})
+
+	t.Run("leftovers of interrupted compression are removed", func(t *testing.T) {
+		path := t.TempDir()
+		wal, err := Open[testPayload](path)
+		require.NoError(t, err)
+		entries := []testPayload{{Value: 0, Foo: "Foo0"}, {Value: 1, Foo: "Foo1"}}
+		for _, e := range entries {
+			require.NoError(t, wal.Append(e))
+		}
+		logName := wal.active.logName
+		require.NoError(t, wal.Close())
+
+		// Simulate a crash after the compressed copy is complete, but before the
+		// uncompressed original is removed, along with a partial copy of another.
+		uncompressed, err := os.ReadFile(filepath.Join(path, logName))
+		require.NoError(t, err)
+		compressedName, err := compressLogFile(path, logName, Gzip)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(path, logName), uncompressed, 0666))
+		require.NoError(t, os.WriteFile(filepath.Join(path, "other"+walExtension+zstdExtension+tmpExtension), []byte("partial"), 0666))
+
+		wal, err = Open[testPayload](path)
+		require.NoError(t, err)
+		res, err := wal.All()
+		require.NoError(t, err)
+		require.Equal(t, entries, res)
+		dirEntries, err := os.ReadDir(path)
+		require.NoError(t, err)
+		require.Len(t, dirEntries, 1)
+		require.Equal(t, compressedName, dirEntries[0].Name())
+	})
+}
+
+// countFiles counts the files in directory with the given extension, that is
+// the whole of their suffix after the timestamp that names them.
+func countFiles(t *testing.T, directory, extension string) int {
+	dirEntries, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	var count int
+	for _, entry := range dirEntries {
+		if compression, ok := compressionOf(entry.Name()); ok && walExtension+compression.extension() == extension {
+			count++
+		}
+	}
+	return count
+}
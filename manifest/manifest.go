@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"slices"
 	"time"
 
@@ -278,6 +279,25 @@ type Manifest struct {
 	// A good default is `4 * Manifest.Gpbft.Delta` (the expected time for a single-round
 	// instance).
 	CatchUpAlignment time.Duration
+	// SegmentedCatchUp starts instances back-to-back, without waiting for EC delay
+	// or catch-up alignment, while the chain pending finalization is longer than a
+	// single proposal. This allows a network far behind EC to finalize the pending
+	// chain one segment per instance, at the pace at which instances complete.
+	//
+	// It is omitted from the JSON encoding unless set, so that the CID of manifests
+	// that do not set it is unchanged.
+	SegmentedCatchUp bool `json:",omitempty"`
+	// MaxCommitteeSize limits the committee of each instance to the participants
+	// with the most power, up to the given number, with ties broken in favour of
+	// the lower actor ID. The total power of the committee is that of the
+	// participants included only. Zero leaves committees unlimited.
+	//
+	// This changes the power table used for consensus, and so all nodes in the
+	// network must agree on it.
+	//
+	// It is omitted from the JSON encoding unless set, so that the CID of manifests
+	// that do not set it is unchanged.
+	MaxCommitteeSize int `json:",omitempty"`
 	// Config parameters for gpbft
 	Gpbft GpbftConfig
 	// EC-specific parameters
@@ -301,6 +321,7 @@ func (m *Manifest) Equal(o *Manifest) bool {
 		m.BootstrapEpoch == o.BootstrapEpoch &&
 		m.IgnoreECPower == o.IgnoreECPower &&
 		m.CommitteeLookback == o.CommitteeLookback &&
+		m.MaxCommitteeSize == o.MaxCommitteeSize &&
 		// Don't include this in equality checks because it doesn't change the meaning of
 		// the manifest (and we don't want to restart the network when we first publish
 		// this).
@@ -313,6 +334,49 @@ func (m *Manifest) Equal(o *Manifest) bool {
 
 }
 
+// Diff returns a human-readable description of each field that differs between
+// the manifest and the other, e.g. "EC.Period: 30s → 45s", in order of field
+// declaration. Fields of nested configs are named by their path from the
+// manifest, while slices are described as a whole. A nil manifest is treated as
+// a zero-valued one. Returns no differences if the manifests are identical.
+func (m *Manifest) Diff(other *Manifest) []string {
+	var zero Manifest
+	if m == nil {
+		m = &zero
+	}
+	if other == nil {
+		other = &zero
+	}
+	return diffFields("", reflect.ValueOf(*m), reflect.ValueOf(*other), nil)
+}
+
+func diffFields(prefix string, one, other reflect.Value, diffs []string) []string {
+	for i := range one.NumField() {
+		field := one.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := prefix + field.Name
+		oneField, otherField := one.Field(i), other.Field(i)
+		// Recurse into the configs of this package only, leaving structs of other
+		// packages, e.g. cid.Cid, to describe themselves.
+		if field.Type.Kind() == reflect.Struct && field.Type.PkgPath() == manifestPkgPath {
+			diffs = diffFields(name+".", oneField, otherField, diffs)
+			continue
+		}
+		if field.Type.Kind() == reflect.Slice && oneField.Len() == 0 && otherField.Len() == 0 {
+			// Nil and empty slices alike are equivalent.
+			continue
+		}
+		if !reflect.DeepEqual(oneField.Interface(), otherField.Interface()) {
+			diffs = append(diffs, fmt.Sprintf("%s: %v → %v", name, oneField.Interface(), otherField.Interface()))
+		}
+	}
+	return diffs
+}
+
+var manifestPkgPath = reflect.TypeOf(Manifest{}).PkgPath()
+
 func (m *Manifest) Validate() error {
 	switch {
 	case m == nil:
@@ -324,6 +388,8 @@ func (m *Manifest) Validate() error {
 			m.BootstrapEpoch, m.EC.Finality)
 	case m.IgnoreECPower && len(m.ExplicitPower) == 0:
 		return fmt.Errorf("invalid manifest: ignoring ec power with no explicit power")
+	case m.MaxCommitteeSize < 0:
+		return fmt.Errorf("invalid manifest: max committee size %d must not be negative", m.MaxCommitteeSize)
 	}
 
 	if len(m.ExplicitPower) > 0 {
@@ -411,6 +477,10 @@ func (m *Manifest) PubSubTopic() string {
 	return PubSubTopicFromNetworkName(m.NetworkName)
 }
 
+func (m *Manifest) CertificateAnnouncementTopic() string {
+	return CertificateAnnouncementTopicFromNetworkName(m.NetworkName)
+}
+
 var cidPrefix = cid.Prefix{
 	Version:  1,
 	Codec:    cid.DagJSON,
@@ -434,6 +504,10 @@ func ChainExchangeTopicFromNetworkName(nn gpbft.NetworkName) string {
 	return "/f3/chainexchange/0.0.1/" + string(nn)
 }
 
+func CertificateAnnouncementTopicFromNetworkName(nn gpbft.NetworkName) string {
+	return "/f3/certexch/announce/0.0.1/" + string(nn)
+}
+
 func (m *Manifest) GpbftOptions() []gpbft.Option {
 	return m.Gpbft.ToOptions()
 }
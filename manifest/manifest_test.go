@@ -2,6 +2,7 @@ package manifest_test
 
 import (
 	"bytes"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -66,6 +67,40 @@ func TestManifest_Validation(t *testing.T) {
 	cpy = base
 	cpy.CertificateExchange.MinimumPollInterval = time.Nanosecond
 	require.Error(t, cpy.Validate())
+
+	cpy = base
+	cpy.MaxCommitteeSize = 10
+	require.NoError(t, cpy.Validate())
+	require.False(t, cpy.Equal(&base))
+	cpy.MaxCommitteeSize = -1
+	require.Error(t, cpy.Validate())
+}
+
+func TestManifest_Diff(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		cpy := base
+		cpy.EC.BaseDecisionBackoffTable = slices.Clone(base.EC.BaseDecisionBackoffTable)
+		require.Empty(t, base.Diff(&cpy))
+		require.Empty(t, (*manifest.Manifest)(nil).Diff(&manifest.Manifest{ExplicitPower: gpbft.PowerEntries{}}))
+	})
+	t.Run("multiple fields", func(t *testing.T) {
+		cpy := base
+		cpy.CommitteeLookback = 8
+		cpy.EC.Period = 45 * time.Second
+		cpy.EC.BaseDecisionBackoffTable = []float64{1, 2}
+		cpy.Gpbft.Delta = 20
+		cpy.ExplicitPower = nil
+		require.Equal(t, []string{
+			"ExplicitPower: [{2 1 [0]} {3 1 [1]}] → []",
+			"CommitteeLookback: 10 → 8",
+			"Gpbft.Delta: 10ns → 20ns",
+			"EC.Period: 30s → 45s",
+			"EC.BaseDecisionBackoffTable: [1.3 1.69 2.2 2.86 3.71 4.83 6.27 8.16 10.6 13.79 15] → [1 2]",
+		}, base.Diff(&cpy))
+	})
+	t.Run("nil", func(t *testing.T) {
+		require.Contains(t, base.Diff(nil), "NetworkName: test → ")
+	})
 }
 
 func TestManifest_Serialization(t *testing.T) {
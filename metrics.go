@@ -36,6 +36,13 @@ var samples = struct {
 	justifications: measurements.NewSampleSet(25_000),
 }
 
+var (
+	attrCacheHit            = attribute.String("cache", "hit")
+	attrCacheMiss           = attribute.String("cache", "miss")
+	attrCacheKindParent     = attribute.String("kind", "parent")
+	attrCacheKindPowerTable = attribute.String("kind", "power_table")
+)
+
 var metrics = struct {
 	headDiverged             metric.Int64Counter
 	reconfigured             metric.Int64Counter
@@ -44,9 +51,11 @@ var metrics = struct {
 	proposalFetchTime        metric.Float64Histogram
 	committeeFetchTime       metric.Float64Histogram
 	validatedMessages        metric.Int64Counter
+	rejectedMessages         metric.Int64Counter
 	partialMessages          metric.Int64UpDownCounter
 	partialMessageDuplicates metric.Int64Counter
 	partialMessageInstances  metric.Int64UpDownCounter
+	chainCache               metric.Int64Counter
 }{
 	headDiverged:      measurements.Must(meter.Int64Counter("f3_head_diverged", metric.WithDescription("Number of times we encountered the head has diverged from base scenario."))),
 	reconfigured:      measurements.Must(meter.Int64Counter("f3_reconfigured", metric.WithDescription("Number of times we reconfigured due to new manifest being delivered."))),
@@ -68,12 +77,16 @@ var metrics = struct {
 	)),
 	validatedMessages: measurements.Must(meter.Int64Counter("f3_validated_messages",
 		metric.WithDescription("Number of validated GPBFT messages."))),
+	rejectedMessages: measurements.Must(meter.Int64Counter("f3_rejected_messages",
+		metric.WithDescription("Number of GPBFT messages that failed validation, by result and reason."))),
 	partialMessages: measurements.Must(meter.Int64UpDownCounter("f3_partial_messages",
 		metric.WithDescription("Number of partial GPBFT messages pending fulfilment."))),
 	partialMessageDuplicates: measurements.Must(meter.Int64Counter("f3_partial_message_duplicates",
 		metric.WithDescription("Number of partial GPBFT messages recieved that already have an unfulfilled message for the same instance, sender, round and phase."))),
 	partialMessageInstances: measurements.Must(meter.Int64UpDownCounter("f3_partial_message_instances",
 		metric.WithDescription("Number of instances with partial GPBFT messages pending fulfilment."))),
+	chainCache: measurements.Must(meter.Int64Counter("f3_ec_chain_cache",
+		metric.WithDescription("Number of hits and misses of the caches of EC tipset parents and power table CIDs, by kind."))),
 }
 
 func recordValidatedMessage(ctx context.Context, msg gpbft.ValidatedMessage) {
@@ -97,6 +110,12 @@ func recordValidatedMessage(ctx context.Context, msg gpbft.ValidatedMessage) {
 	metrics.validatedMessages.Add(ctx, 1, metric.WithAttributes(attrs...))
 }
 
+func recordRejectedMessage(ctx context.Context, result pubsub.ValidationResult, reason string) {
+	metrics.rejectedMessages.Add(ctx, 1, metric.WithAttributes(
+		measurements.AttrFromPubSubValidationResult(result),
+		attribute.String("reason", reason)))
+}
+
 func recordValidationTime(ctx context.Context, start time.Time, result pubsub.ValidationResult, partiallyValidated bool) {
 	metrics.validationTime.Record(
 		ctx,
@@ -38,17 +38,20 @@ func (v fullyValidatedMessage) Message() *gpbft.GMessage { return v.GMessage }
 type cachingPartialValidator struct {
 	cache             *caching.GroupedSet
 	committeeLookback uint64
+	// policy holds the configured bounds shared with the full validator.
+	policy            *gpbft.ValidationPolicy
 	committeeProvider gpbft.CommitteeProvider
 	networkName       gpbft.NetworkName
 	signing           gpbft.Signatures
 	progress          gpbft.Progress
 }
 
-func newCachingPartialValidator(host gpbft.Host, progress gpbft.Progress, cache *caching.GroupedSet, committeeLookback uint64) *cachingPartialValidator {
+func newCachingPartialValidator(host gpbft.Host, progress gpbft.Progress, cache *caching.GroupedSet, committeeLookback uint64, policy *gpbft.ValidationPolicy) *cachingPartialValidator {
 	return &cachingPartialValidator{
 		cache:             cache,
 		committeeProvider: host,
 		committeeLookback: committeeLookback,
+		policy:            policy,
 		networkName:       host.NetworkName(),
 		signing:           host,
 		progress:          progress,
@@ -65,6 +68,10 @@ func (v *cachingPartialValidator) PartiallyValidateMessage(msg *PartialGMessage)
 	case msg.Vote.Instance >= current.ID+v.committeeLookback:
 		// Message is beyond current + committee lookback.
 		return nil, gpbft.ErrValidationNoCommittee
+	case msg.Vote.Instance > current.ID+v.policy.MaxLookaheadInstances():
+		// Message is beyond current + max lookahead instances. Reject it without
+		// resolving its committee, since doing so may be expensive.
+		return nil, gpbft.ErrValidationNoCommittee
 	case msg.Vote.Instance > current.ID,
 		msg.Vote.Instance+1 == current.ID && msg.Vote.Phase == gpbft.DECIDE_PHASE:
 		// Only proceed to validate the message if it:
@@ -104,7 +111,7 @@ func (v *cachingPartialValidator) PartiallyValidateMessage(msg *PartialGMessage)
 	} else if alreadyValidated, err := v.cache.Contains(msg.Vote.Instance, messageCacheNamespace, buf.Bytes()); err != nil {
 		log.Errorw("failed to check already validated messages", "err", err)
 	} else if alreadyValidated {
-		return nil, nil
+		return &PartiallyValidatedMessage{PartialGMessage: msg}, nil
 	} else {
 		cacheMessage = true
 	}
@@ -116,7 +123,7 @@ func (v *cachingPartialValidator) PartiallyValidateMessage(msg *PartialGMessage)
 	// Check sender is eligible, identical to full validator
 	senderPower, senderPubKey := comt.PowerTable.Get(msg.Sender)
 	if senderPower == 0 {
-		return nil, fmt.Errorf("sender %d with zero power or not in power table: %w", msg.Sender, gpbft.ErrValidationInvalid)
+		return nil, fmt.Errorf("sender %d with zero power or not in power table: %w", msg.Sender, gpbft.ErrValidationNoPower)
 	}
 
 	// Postpone the validity check for the Vote Value ECChain itself, but proceed
@@ -126,38 +133,38 @@ func (v *cachingPartialValidator) PartiallyValidateMessage(msg *PartialGMessage)
 	switch msg.Vote.Phase {
 	case gpbft.QUALITY_PHASE:
 		if msg.Vote.Round != 0 {
-			return nil, fmt.Errorf("unexpected round %d for quality phase: %w", msg.Vote.Round, gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("unexpected round %d for quality phase: %w", msg.Vote.Round, gpbft.ErrValidationMalformed)
 		}
 		if msg.VoteValueKey.IsZero() {
-			return nil, fmt.Errorf("unexpected zero value for quality phase: %w", gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("unexpected zero value for quality phase: %w", gpbft.ErrValidationMalformed)
 		}
 	case gpbft.CONVERGE_PHASE:
 		if msg.Vote.Round == 0 {
-			return nil, fmt.Errorf("unexpected round 0 for converge phase: %w", gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("unexpected round 0 for converge phase: %w", gpbft.ErrValidationMalformed)
 		}
 		if msg.VoteValueKey.IsZero() {
-			return nil, fmt.Errorf("unexpected zero value for converge phase: %w", gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("unexpected zero value for converge phase: %w", gpbft.ErrValidationMalformed)
 		}
 		if !gpbft.VerifyTicket(v.networkName, comt.Beacon, msg.Vote.Instance, msg.Vote.Round, senderPubKey, v.signing, msg.Ticket) {
-			return nil, fmt.Errorf("failed to verify ticket from %v: %w", msg.Sender, gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("failed to verify ticket from %v: %w", msg.Sender, gpbft.ErrValidationBadTicket)
 		}
 	case gpbft.DECIDE_PHASE:
 		if msg.Vote.Round != 0 {
-			return nil, fmt.Errorf("unexpected non-zero round %d for decide phase: %w", msg.Vote.Round, gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("unexpected non-zero round %d for decide phase: %w", msg.Vote.Round, gpbft.ErrValidationMalformed)
 		}
 		if msg.VoteValueKey.IsZero() {
-			return nil, fmt.Errorf("unexpected zero value for decide phase: %w", gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("unexpected zero value for decide phase: %w", gpbft.ErrValidationMalformed)
 		}
 	case gpbft.PREPARE_PHASE, gpbft.COMMIT_PHASE:
 		// No additional checks needed for these phases.
 	default:
-		return nil, fmt.Errorf("invalid vote phase: %d: %w", msg.Vote.Phase, gpbft.ErrValidationInvalid)
+		return nil, fmt.Errorf("invalid vote phase: %d: %w", msg.Vote.Phase, gpbft.ErrValidationMalformed)
 	}
 
 	// Check vote signature by marshaling the payload with the pre-computed vote value key.
 	sigPayload := v.marshalPartialPayloadForSigning(v.networkName, msg.VoteValueKey, &msg.Vote)
 	if err := v.signing.Verify(senderPubKey, sigPayload, msg.Signature); err != nil {
-		return nil, fmt.Errorf("invalid signature on %v, %v: %w", msg, err, gpbft.ErrValidationInvalid)
+		return nil, fmt.Errorf("invalid signature on %v, %v: %w", msg, err, gpbft.ErrValidationBadSignature)
 	}
 
 	// Check if justification is required, similar to full validator but checking if
@@ -168,10 +175,10 @@ func (v *cachingPartialValidator) PartiallyValidateMessage(msg *PartialGMessage)
 
 	if needsJustification {
 		if err := v.validateJustification(msg, comt); err != nil {
-			return nil, fmt.Errorf("%v: %w", err, gpbft.ErrValidationInvalid)
+			return nil, err
 		}
 	} else if msg.Justification != nil {
-		return nil, fmt.Errorf("message %v has unexpected justification: %w", msg, gpbft.ErrValidationInvalid)
+		return nil, fmt.Errorf("message %v has unexpected justification: %w", msg, gpbft.ErrValidationBadJustification)
 	}
 
 	if cacheMessage {
@@ -184,7 +191,7 @@ func (v *cachingPartialValidator) PartiallyValidateMessage(msg *PartialGMessage)
 
 func (v *cachingPartialValidator) validateJustification(msg *PartialGMessage, comt *gpbft.Committee) error {
 	if msg.Justification == nil {
-		return fmt.Errorf("message for phase %v round %v has no justification", msg.Vote.Phase, msg.Vote.Round)
+		return fmt.Errorf("message for phase %v round %v has no justification: %w", msg.Vote.Phase, msg.Vote.Round, gpbft.ErrValidationBadJustification)
 	}
 
 	// Only cache the justification if:
@@ -207,10 +214,10 @@ func (v *cachingPartialValidator) validateJustification(msg *PartialGMessage, co
 	// Check that the justification is for the same instance, identical to the full
 	// validator.
 	if msg.Vote.Instance != msg.Justification.Vote.Instance {
-		return fmt.Errorf("message with instanceID %v has evidence from instanceID: %v", msg.Vote.Instance, msg.Justification.Vote.Instance)
+		return fmt.Errorf("message with instanceID %v has evidence from instanceID: %v: %w", msg.Vote.Instance, msg.Justification.Vote.Instance, gpbft.ErrValidationBadJustification)
 	}
 	if !msg.Vote.SupplementalData.Eq(&msg.Justification.Vote.SupplementalData) {
-		return fmt.Errorf("message and justification have inconsistent supplemental data: %v != %v", msg.Vote.SupplementalData, msg.Justification.Vote.SupplementalData)
+		return fmt.Errorf("message and justification have inconsistent supplemental data: %v != %v: %w", msg.Vote.SupplementalData, msg.Justification.Vote.SupplementalData, gpbft.ErrValidationBadJustification)
 	}
 
 	// Check every remaining field of the justification, according to the phase
@@ -253,14 +260,14 @@ func (v *cachingPartialValidator) validateJustification(msg *PartialGMessage, co
 	if expectedPhases, ok := expectations[msg.Vote.Phase]; ok {
 		if expected, ok := expectedPhases[msg.Justification.Vote.Phase]; ok {
 			if msg.Justification.Vote.Round != expected.Round && expected.Round != math.MaxUint64 {
-				return fmt.Errorf("message %v has justification from wrong round %d", msg, msg.Justification.Vote.Round)
+				return fmt.Errorf("message %v has justification from wrong round %d: %w", msg, msg.Justification.Vote.Round, gpbft.ErrValidationBadJustification)
 			}
 			expectedJustificationVoteValueKey = expected.Value
 		} else {
-			return fmt.Errorf("message %v has justification with unexpected phase: %v", msg, msg.Justification.Vote.Phase)
+			return fmt.Errorf("message %v has justification with unexpected phase: %v: %w", msg, msg.Justification.Vote.Phase, gpbft.ErrValidationBadJustification)
 		}
 	} else {
-		return fmt.Errorf("message %v has unexpected phase for justification", msg)
+		return fmt.Errorf("message %v has unexpected phase for justification: %w", msg, gpbft.ErrValidationBadJustification)
 	}
 
 	// Check justification power and signature, identical to full validator.
@@ -268,27 +275,27 @@ func (v *cachingPartialValidator) validateJustification(msg *PartialGMessage, co
 	signers := make([]int, 0)
 	if err := msg.Justification.Signers.ForEach(func(bit uint64) error {
 		if int(bit) >= len(comt.PowerTable.Entries) {
-			return fmt.Errorf("invalid signer index: %d", bit)
+			return fmt.Errorf("invalid signer index: %d: %w", bit, gpbft.ErrValidationBadJustification)
 		}
 		power := comt.PowerTable.ScaledPower[bit]
 		if power == 0 {
-			return fmt.Errorf("signer with ID %d has no power", comt.PowerTable.Entries[bit].ID)
+			return fmt.Errorf("signer with ID %d has no power: %w", comt.PowerTable.Entries[bit].ID, gpbft.ErrValidationBadJustification)
 		}
 		justificationPower += power
 		signers = append(signers, int(bit))
 		return nil
 	}); err != nil {
-		return fmt.Errorf("failed to iterate over signers: %w", err)
+		return fmt.Errorf("failed to iterate over signers: %w: %w", err, gpbft.ErrValidationBadJustification)
 	}
-	if !gpbft.IsStrongQuorum(justificationPower, comt.PowerTable.ScaledTotal) {
-		return fmt.Errorf("message %v has justification with insufficient power: %v", msg, justificationPower)
+	if err := v.policy.CheckJustificationSigners(comt.PowerTable, justificationPower, len(signers)); err != nil {
+		return fmt.Errorf("message %v has %w", msg, err)
 	}
 
 	// Check justification signature by computing the signing payload using what a
 	// valid justification vote value should be.
 	payload := v.marshalPartialPayloadForSigning(v.networkName, expectedJustificationVoteValueKey, &msg.Justification.Vote)
-	if err := comt.AggregateVerifier.VerifyAggregate(signers, payload, msg.Justification.Signature); err != nil {
-		return fmt.Errorf("verification of the aggregate failed: %+v: %w", msg.Justification, err)
+	if err := gpbft.VerifyAggregate(comt.AggregateVerifier, msg.Justification.Scheme, signers, payload, msg.Justification.Signature); err != nil {
+		return fmt.Errorf("verification of the aggregate failed: %+v: %v: %w", msg.Justification, err, gpbft.ErrValidationBadSignature)
 	}
 
 	if cacheJustification {
@@ -314,12 +321,12 @@ func (v *cachingPartialValidator) ValidateMessage(pmsg *PartiallyValidatedMessag
 		return nil, gpbft.ErrValidationInvalid
 	}
 	if err := pmsg.Vote.Value.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid vote value: %v: %w", err, gpbft.ErrValidationInvalid)
+		return nil, fmt.Errorf("invalid vote value: %v: %w", err, gpbft.ErrValidationMalformed)
 	}
 
 	// Check the consistency chain key with the vote value.
 	if pmsg.VoteValueKey != pmsg.Vote.Value.Key() {
-		return nil, fmt.Errorf("vote value key does not match vote value: %w", gpbft.ErrValidationInvalid)
+		return nil, fmt.Errorf("vote value key does not match vote value: %w", gpbft.ErrValidationMalformed)
 	}
 
 	// If the key is zero, then the vote value must be zero, along with justification
@@ -327,10 +334,10 @@ func (v *cachingPartialValidator) ValidateMessage(pmsg *PartiallyValidatedMessag
 	justified := pmsg.Justification != nil
 	if pmsg.VoteValueKey.IsZero() {
 		if !pmsg.Vote.Value.IsZero() {
-			return nil, fmt.Errorf("unexpected non-zero value for zero vote value key: %w", gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("unexpected non-zero value for zero vote value key: %w", gpbft.ErrValidationMalformed)
 		}
 		if justified && !pmsg.Justification.Vote.Value.IsZero() {
-			return nil, fmt.Errorf("unexpected non-zero justification value for zero vote value key: %w", gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("unexpected non-zero justification value for zero vote value key: %w", gpbft.ErrValidationBadJustification)
 		}
 	}
 	if justified {
@@ -354,13 +361,13 @@ func (v *cachingPartialValidator) ValidateMessage(pmsg *PartiallyValidatedMessag
 		if expectedPhases, ok := expectations[pmsg.Vote.Phase]; ok {
 			if expectedValue, ok := expectedPhases[pmsg.Justification.Vote.Phase]; ok {
 				if !pmsg.Justification.Vote.Value.Eq(expectedValue) {
-					return nil, fmt.Errorf("message %v has justification for a different value: %v: %w", pmsg, pmsg.Justification.Vote.Value, gpbft.ErrValidationInvalid)
+					return nil, fmt.Errorf("message %v has justification for a different value: %v: %w", pmsg, pmsg.Justification.Vote.Value, gpbft.ErrValidationBadJustification)
 				}
 			} else {
-				return nil, fmt.Errorf("message %v has justification with unexpected phase: %v: %w", pmsg, pmsg.Justification.Vote.Phase, gpbft.ErrValidationInvalid)
+				return nil, fmt.Errorf("message %v has justification with unexpected phase: %v: %w", pmsg, pmsg.Justification.Vote.Phase, gpbft.ErrValidationBadJustification)
 			}
 		} else {
-			return nil, fmt.Errorf("message %v has unexpected phase for justification: %w", pmsg, gpbft.ErrValidationInvalid)
+			return nil, fmt.Errorf("message %v has unexpected phase for justification: %w", pmsg, gpbft.ErrValidationBadJustification)
 		}
 	}
 	return &fullyValidatedMessage{GMessage: pmsg.GMessage}, nil
@@ -0,0 +1,55 @@
+package f3
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/internal/caching"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachingPartialValidator_MaxLookaheadInstances(t *testing.T) {
+	policy, err := gpbft.NewValidationPolicy(gpbft.WithMaxLookaheadInstances(3))
+	require.NoError(t, err)
+	// No committee provider is set, since messages beyond the lookahead must be
+	// rejected without resolving their committee.
+	subject := &cachingPartialValidator{
+		cache:             caching.NewGroupedSet(10, 10),
+		committeeLookback: 10,
+		policy:            policy,
+		progress:          func() gpbft.Instant { return gpbft.Instant{ID: 5} },
+	}
+
+	msg := &PartialGMessage{GMessage: &gpbft.GMessage{Vote: gpbft.Payload{Instance: 9, Phase: gpbft.QUALITY_PHASE}}}
+	_, err = subject.PartiallyValidateMessage(msg)
+	require.ErrorIs(t, err, gpbft.ErrValidationNoCommittee)
+}
+
+func TestCachingPartialValidator_ReturnsAlreadyValidatedMessage(t *testing.T) {
+	policy, err := gpbft.NewValidationPolicy()
+	require.NoError(t, err)
+	// No committee provider is set, since already validated messages must be
+	// returned without resolving their committee.
+	subject := &cachingPartialValidator{
+		cache:             caching.NewGroupedSet(10, 10),
+		committeeLookback: 10,
+		policy:            policy,
+		progress:          func() gpbft.Instant { return gpbft.Instant{ID: 5} },
+	}
+
+	msg := &PartialGMessage{GMessage: &gpbft.GMessage{Vote: gpbft.Payload{
+		Instance:         5,
+		Phase:            gpbft.QUALITY_PHASE,
+		SupplementalData: gpbft.SupplementalData{PowerTable: gpbft.MakeCid([]byte("pt"))},
+	}}}
+	var buf bytes.Buffer
+	require.NoError(t, msg.MarshalCBOR(&buf))
+	_, err = subject.cache.Add(msg.Vote.Instance, messageCacheNamespace, buf.Bytes())
+	require.NoError(t, err)
+
+	validated, err := subject.PartiallyValidateMessage(msg)
+	require.NoError(t, err)
+	require.NotNil(t, validated)
+	require.Same(t, msg, validated.PartialGMessage)
+}
@@ -0,0 +1,85 @@
+package f3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/go-f3/certstore"
+	"github.com/filecoin-project/go-f3/ec"
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/manifest"
+)
+
+// PowerTableResolver resolves the power table of the committee for a GPBFT
+// instance. It is the single entry point for power table resolution, and falls
+// back across sources in the following order:
+//  1. the certificate store, which holds the initial power table and the power
+//     tables derived from finality certificates, then
+//  2. the power store, which retains power tables EC may have forgotten, then
+//  3. EC itself, from the tipset at the head of the chain finalized by the
+//     instance at committee lookback.
+//
+// The latter two are typically combined by passing a powerstore.Store wrapping
+// the EC backend as ec.Backend.
+type PowerTableResolver struct {
+	manifest  *manifest.Manifest
+	certStore *certstore.Store
+	ec        ec.Backend
+}
+
+// NewPowerTableResolver instantiates a new PowerTableResolver.
+func NewPowerTableResolver(m *manifest.Manifest, cs *certstore.Store, ec ec.Backend) *PowerTableResolver {
+	return &PowerTableResolver{
+		manifest:  m,
+		certStore: cs,
+		ec:        ec,
+	}
+}
+
+// PowerTableForInstance returns the power table of the committee for the given
+// instance.
+func (r *PowerTableResolver) PowerTableForInstance(ctx context.Context, instance uint64) (gpbft.PowerEntries, error) {
+	powerTable, _, err := r.resolve(ctx, instance)
+	return powerTable, err
+}
+
+// resolve returns the power table of the committee for the given instance along
+// with the key of the tipset from which the committee is derived.
+func (r *PowerTableResolver) resolve(ctx context.Context, instance uint64) (gpbft.PowerEntries, gpbft.TipSetKey, error) {
+	if instance < r.manifest.InitialInstance+r.manifest.CommitteeLookback {
+		//boostrap phase
+		powerEntries, err := r.certStore.GetPowerTable(ctx, r.manifest.InitialInstance)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting power table: %w", err)
+		}
+		if r.certStore.Latest() == nil {
+			ts, err := r.ec.GetTipsetByEpoch(ctx, r.manifest.BootstrapEpoch-r.manifest.EC.Finality)
+			if err != nil {
+				return nil, nil, fmt.Errorf("getting tipset for boostrap epoch with lookback: %w", err)
+			}
+			return powerEntries, ts.Key(), nil
+		}
+		cert, err := r.certStore.Get(ctx, r.manifest.InitialInstance)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting finality certificate: %w", err)
+		}
+		return powerEntries, cert.ECChain.Base().Key, nil
+	}
+
+	cert, err := r.certStore.Get(ctx, instance-r.manifest.CommitteeLookback)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting finality certificate: %w", err)
+	}
+	powerTsk := cert.ECChain.Head().Key
+
+	powerEntries, err := r.certStore.GetPowerTable(ctx, instance)
+	if err != nil {
+		log.Debugf("failed getting power table from certstore: %v, falling back to power store and EC", err)
+
+		powerEntries, err = r.ec.GetPowerTable(ctx, powerTsk)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting power table: %w", err)
+		}
+	}
+	return powerEntries, powerTsk, nil
+}
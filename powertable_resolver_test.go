@@ -0,0 +1,155 @@
+package f3
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/certs"
+	"github.com/filecoin-project/go-f3/certstore"
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/internal/clock"
+	"github.com/filecoin-project/go-f3/internal/consensus"
+	"github.com/filecoin-project/go-f3/manifest"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// divergentPowerEC reports a different power table for a given tipset than the
+// wrapped EC, by dropping its first entry.
+type divergentPowerEC struct {
+	*consensus.FakeEC
+	divergent gpbft.TipSetKey
+}
+
+func (d *divergentPowerEC) GetPowerTable(ctx context.Context, tsk gpbft.TipSetKey) (gpbft.PowerEntries, error) {
+	pt, err := d.FakeEC.GetPowerTable(ctx, tsk)
+	if err != nil || !bytes.Equal(tsk, d.divergent) {
+		return pt, err
+	}
+	return pt[1:], nil
+}
+
+func TestPowerTableResolver_PowerTableForInstance(t *testing.T) {
+	ctx, _ := clock.WithMockClock(context.Background())
+	f := newTestConsensusInputs(t, ctx)
+	m, powerTable, fakeEC, cs := f.m, f.powerTable, f.ec, f.cs
+	m.CommitteeLookback = 2
+
+	// Finalize two instances, where the first changes the power table such that
+	// the tables in the certstore differ from the ones in EC.
+	certifiedPowerTable := slices.Clone(powerTable)
+	certifiedPowerTable[0].Power = gpbft.NewStoragePower(20)
+	certifiedPtCid, err := certs.MakePowerTableCID(certifiedPowerTable)
+	require.NoError(t, err)
+	base := f.bootstrapBase()
+	var decided *gpbft.ECChain
+	for i, deltas := range []certs.PowerTableDiff{certs.MakePowerTableDiff(powerTable, certifiedPowerTable), nil} {
+		decided = nil
+		for epoch := base + int64(i); epoch <= base+int64(i)+1; epoch++ {
+			ts, err := fakeEC.GetTipsetByEpoch(ctx, epoch)
+			require.NoError(t, err)
+			decided = decided.Append(&gpbft.TipSet{Epoch: ts.Epoch(), Key: ts.Key(), PowerTable: certifiedPtCid})
+		}
+		require.NoError(t, cs.Put(ctx, &certs.FinalityCertificate{
+			GPBFTInstance:    m.InitialInstance + uint64(i),
+			ECChain:          decided,
+			SupplementalData: gpbft.SupplementalData{PowerTable: certifiedPtCid},
+			PowerTableDelta:  deltas,
+		}))
+	}
+
+	// EC reports a different power table at the head of the last decided chain
+	// than the one certified.
+	subject := NewPowerTableResolver(m, cs, &divergentPowerEC{FakeEC: fakeEC, divergent: decided.Head().Key})
+
+	t.Run("bootstrap from certstore", func(t *testing.T) {
+		got, err := subject.PowerTableForInstance(ctx, m.InitialInstance+1)
+		require.NoError(t, err)
+		require.Equal(t, powerTable, got)
+	})
+	t.Run("certstore", func(t *testing.T) {
+		got, err := subject.PowerTableForInstance(ctx, m.InitialInstance+2)
+		require.NoError(t, err)
+		require.Equal(t, certifiedPowerTable, got)
+	})
+	t.Run("fallback to EC", func(t *testing.T) {
+		got, err := subject.PowerTableForInstance(ctx, m.InitialInstance+3)
+		require.NoError(t, err)
+		require.Equal(t, powerTable[1:], got)
+	})
+	t.Run("no finality certificate at lookback", func(t *testing.T) {
+		_, err := subject.PowerTableForInstance(ctx, m.InitialInstance+4)
+		require.ErrorContains(t, err, "getting finality certificate")
+	})
+}
+
+func TestPowerTableResolver_FallsBackToPowerStore(t *testing.T) {
+	ctx, clk := clock.WithMockClock(context.Background())
+	// EC forgets power tables beyond its lookback, and its power table changes at
+	// every epoch, such that each tier resolves a distinct power table.
+	f := newTestConsensusInputs(t, ctx,
+		consensus.WithMaxLookback(2*manifest.LocalDevnetManifest().EC.Finality),
+		consensus.WithEvolvingPowerTable(func(epoch int64, pt gpbft.PowerEntries) gpbft.PowerEntries {
+			pt = slices.Clone(pt)
+			pt[0].Power = big.Add(gpbft.NewStoragePower(epoch), pt[0].Power)
+			return pt
+		}),
+	)
+	m, fakeEC := f.m, f.ec
+	m.CommitteeLookback = 2
+	base, err := fakeEC.GetTipsetByEpoch(ctx, f.bootstrapBase())
+	require.NoError(t, err)
+	basePowerTable, err := fakeEC.GetPowerTable(ctx, base.Key())
+	require.NoError(t, err)
+	basePtCid, err := certs.MakePowerTableCID(basePowerTable)
+	require.NoError(t, err)
+
+	// The certstore starts with the power table of EC at the bootstrap base.
+	f.ds = ds_sync.MutexWrap(datastore.NewMapDatastore())
+	f.cs, err = certstore.CreateStore(ctx, f.ds, m.InitialInstance, basePowerTable)
+	require.NoError(t, err)
+	cs := f.cs
+	// Finalize two instances, leaving the certstore unable to resolve the power
+	// table of the instance after the next.
+	var decided *gpbft.ECChain
+	for i := range 2 {
+		decided = nil
+		for epoch := base.Epoch() + int64(i); epoch <= base.Epoch()+int64(i)+1; epoch++ {
+			ts, err := fakeEC.GetTipsetByEpoch(ctx, epoch)
+			require.NoError(t, err)
+			decided = decided.Append(&gpbft.TipSet{Epoch: ts.Epoch(), Key: ts.Key(), PowerTable: basePtCid})
+		}
+		require.NoError(t, cs.Put(ctx, &certs.FinalityCertificate{
+			GPBFTInstance:    m.InitialInstance + uint64(i),
+			ECChain:          decided,
+			SupplementalData: gpbft.SupplementalData{PowerTable: basePtCid},
+		}))
+	}
+	instance := m.InitialInstance + 3
+	_, err = cs.GetPowerTable(ctx, instance)
+	require.Error(t, err)
+	want, err := fakeEC.GetPowerTable(ctx, decided.Head().Key)
+	require.NoError(t, err)
+
+	ps, advance := f.startPowerStore(t, clk)
+	subject := NewPowerTableResolver(m, cs, ps)
+
+	// Once F3 falls behind EC, the power store records the power tables that EC is
+	// about to forget.
+	advance(m.EC.Period * time.Duration(m.EC.Finality*3/2))
+	_, err = fakeEC.GetPowerTable(ctx, decided.Head().Key)
+	require.Error(t, err)
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		got, err := subject.PowerTableForInstance(ctx, instance)
+		assert.NoError(c, err)
+		assert.Equal(c, want, got)
+	}, time.Second, 10*time.Millisecond)
+	require.NotEqual(t, basePowerTable, want)
+}
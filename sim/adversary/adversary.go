@@ -16,6 +16,9 @@ type Host interface {
 	// Note that the adversary can subsequently delay delivery to some participants,
 	// before messages are actually received.
 	RequestSynchronousBroadcast(mb *gpbft.MessageBuilder) error
+	// Re-sends a message received from any participant, unaltered and so signed
+	// by its original sender, to all other participants, immediately.
+	RequestSynchronousRelay(msg *gpbft.GMessage) error
 }
 
 type Generator func(gpbft.ActorID, Host) *Adversary
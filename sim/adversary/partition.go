@@ -0,0 +1,98 @@
+package adversary
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+)
+
+var _ Receiver = (*Partition)(nil)
+
+// Partition adversary splits participants into two disjoint groups, and blocks
+// all messages crossing between them until it observes a message from the
+// configured instance and round or later, at which point the partition heals
+// for the remainder of the simulation. Messages to or from participants in
+// neither group are unaffected, while those broadcast synchronously via
+// Host.RequestSynchronousBroadcast are partitioned like any other.
+//
+// For this adversary to take effect global stabilisation time must be
+// configured, beyond which the adversary no longer controls the network and the
+// partition heals regardless. Honest participants that cannot make progress on
+// either side of the partition never reach the configured instance and round, in
+// which case the partition only heals once global stabilisation time elapses.
+//
+// See sim.WithGlobalStabilizationTime.
+type Partition struct {
+	id         gpbft.ActorID
+	host       Host
+	groupsByID map[gpbft.ActorID]int
+	healAt     gpbft.Instant
+	healed     bool
+}
+
+// NewPartition instantiates a new Partition adversary that partitions the
+// given groups of participants until a message from the given instance and
+// round or later is observed. It panics if the groups are not disjoint.
+func NewPartition(id gpbft.ActorID, host Host, healInstance, healRound uint64, one, other []gpbft.ActorID) *Partition {
+	groupsByID := make(map[gpbft.ActorID]int, len(one)+len(other))
+	for group, members := range [][]gpbft.ActorID{one, other} {
+		for _, member := range members {
+			if _, found := groupsByID[member]; found {
+				panic(fmt.Sprintf("participant %d is in both sides of the partition", member))
+			}
+			groupsByID[member] = group
+		}
+	}
+	return &Partition{
+		id:         id,
+		host:       host,
+		groupsByID: groupsByID,
+		healAt:     gpbft.Instant{ID: healInstance, Round: healRound},
+	}
+}
+
+func NewPartitionGenerator(power gpbft.StoragePower, healInstance, healRound uint64, one, other []gpbft.ActorID) Generator {
+	return func(id gpbft.ActorID, host Host) *Adversary {
+		return &Adversary{
+			Receiver: NewPartition(id, host, healInstance, healRound, one, other),
+			Power:    power,
+		}
+	}
+}
+
+func (p *Partition) ID() gpbft.ActorID {
+	return p.id
+}
+
+func (p *Partition) AllowMessage(from gpbft.ActorID, to gpbft.ActorID, msg gpbft.GMessage) bool {
+	if !p.healed && p.isAtOrAfterHeal(&msg.Vote) {
+		p.healed = true
+	}
+	if p.healed || from == to {
+		return true
+	}
+	fromGroup, fromFound := p.groupsByID[from]
+	toGroup, toFound := p.groupsByID[to]
+	return !fromFound || !toFound || fromGroup == toGroup
+}
+
+func (p *Partition) isAtOrAfterHeal(vote *gpbft.Payload) bool {
+	if vote.Instance != p.healAt.ID {
+		return vote.Instance > p.healAt.ID
+	}
+	return vote.Round >= p.healAt.Round
+}
+
+// Healed returns whether the partition has healed, not accounting for global
+// stabilisation time.
+func (p *Partition) Healed() bool {
+	return p.healed
+}
+
+func (*Partition) StartInstanceAt(uint64, time.Time) error { return nil }
+func (*Partition) ValidateMessage(msg *gpbft.GMessage) (gpbft.ValidatedMessage, error) {
+	return Validated(msg), nil
+}
+func (*Partition) ReceiveMessage(gpbft.ValidatedMessage) error { return nil }
+func (*Partition) ReceiveAlarm() error                         { return nil }
@@ -0,0 +1,156 @@
+package adversary
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+)
+
+var _ Receiver = (*Shuffler)(nil)
+
+// Shuffler adversary re-injects messages it receives from honest participants,
+// unaltered and so still valid, into the network out of order and duplicated.
+// Each message is picked with the configured probability, upon which between
+// one and the maximum duplication factor copies of it are relayed to all
+// participants, each after a uniformly random delay of up to the configured
+// maximum. Since copies are relayed via Host.RequestSynchronousRelay, they may
+// reach participants before the original does.
+//
+// The adversary never sends any messages of its own, and so only tests the
+// handling of duplicate and out of order messages by honest participants.
+type Shuffler struct {
+	id             gpbft.ActorID
+	host           Host
+	probability    float64
+	maxDelay       time.Duration
+	maxDuplication int
+	rng            *rand.Rand
+	// instance is the latest instance started.
+	instance uint64
+	// seen are the messages received so far, by sender and instant, so that the
+	// copies relayed back to the adversary itself are not picked again.
+	seen map[shuffledKey]struct{}
+	// pending are the copies yet to be relayed, ordered by the time at which
+	// they are due.
+	pending []shuffledMessage
+	relayed int
+}
+
+type shuffledKey struct {
+	sender  gpbft.ActorID
+	instant gpbft.Instant
+}
+
+type shuffledMessage struct {
+	at  time.Time
+	msg *gpbft.GMessage
+}
+
+// NewShuffler instantiates a new Shuffler adversary that re-injects each message
+// it receives with the given probability, up to maxDuplication times, after a
+// delay of up to maxDelay. The seed determines which messages are picked, along
+// with their number of copies and delays.
+func NewShuffler(id gpbft.ActorID, host Host, probability float64, maxDelay time.Duration, maxDuplication int, seed int64) *Shuffler {
+	if maxDuplication < 1 {
+		panic(fmt.Sprintf("max duplication must be at least 1, got %d", maxDuplication))
+	}
+	return &Shuffler{
+		id:             id,
+		host:           host,
+		probability:    probability,
+		maxDelay:       maxDelay,
+		maxDuplication: maxDuplication,
+		rng:            rand.New(rand.NewSource(seed)),
+		seen:           make(map[shuffledKey]struct{}),
+	}
+}
+
+func NewShufflerGenerator(power gpbft.StoragePower, probability float64, maxDelay time.Duration, maxDuplication int, seed int64) Generator {
+	return func(id gpbft.ActorID, host Host) *Adversary {
+		return &Adversary{
+			Receiver: NewShuffler(id, host, probability, maxDelay, maxDuplication, seed),
+			Power:    power,
+		}
+	}
+}
+
+func (s *Shuffler) ID() gpbft.ActorID {
+	return s.id
+}
+
+func (s *Shuffler) ReceiveMessage(vmsg gpbft.ValidatedMessage) error {
+	msg := vmsg.Message()
+	key := shuffledKey{
+		sender:  msg.Sender,
+		instant: gpbft.Instant{ID: msg.Vote.Instance, Round: msg.Vote.Round, Phase: msg.Vote.Phase},
+	}
+	if _, found := s.seen[key]; found || msg.Sender == s.id || s.isForgotten(msg.Vote.Instance) {
+		return nil
+	}
+	s.seen[key] = struct{}{}
+	if s.rng.Float64() >= s.probability {
+		return nil
+	}
+	now := s.host.Time()
+	for range 1 + s.rng.Intn(s.maxDuplication) {
+		at := now.Add(time.Duration(s.rng.Int63n(int64(s.maxDelay) + 1)))
+		// Insert after any copies due at the same time, so that copies due together
+		// are relayed in the order they were picked.
+		index, _ := slices.BinarySearchFunc(s.pending, at, func(m shuffledMessage, at time.Time) int {
+			if m.at.After(at) {
+				return 1
+			}
+			return -1
+		})
+		s.pending = slices.Insert(s.pending, index, shuffledMessage{at: at, msg: msg})
+	}
+	s.host.SetAlarm(s.pending[0].at)
+	return nil
+}
+
+func (s *Shuffler) ReceiveAlarm() error {
+	now := s.host.Time()
+	var due int
+	for due < len(s.pending) && !s.pending[due].at.After(now) {
+		if err := s.host.RequestSynchronousRelay(s.pending[due].msg); err != nil {
+			return err
+		}
+		s.relayed++
+		due++
+	}
+	s.pending = slices.Delete(s.pending, 0, due)
+	if len(s.pending) > 0 {
+		s.host.SetAlarm(s.pending[0].at)
+	}
+	return nil
+}
+
+// Relayed returns the number of copies of messages relayed so far.
+func (s *Shuffler) Relayed() int {
+	return s.relayed
+}
+
+func (s *Shuffler) StartInstanceAt(instance uint64, _ time.Time) error {
+	s.instance = instance
+	for key := range s.seen {
+		if s.isForgotten(key.instant.ID) {
+			delete(s.seen, key)
+		}
+	}
+	return nil
+}
+
+// isForgotten returns whether messages from the given instance are ignored, as
+// they are prior to the instance before the latest one started, and so dropped
+// by honest participants as too old regardless.
+func (s *Shuffler) isForgotten(instance uint64) bool {
+	return instance+1 < s.instance
+}
+
+func (*Shuffler) ValidateMessage(msg *gpbft.GMessage) (gpbft.ValidatedMessage, error) {
+	return Validated(msg), nil
+}
+func (*Shuffler) AllowMessage(gpbft.ActorID, gpbft.ActorID, gpbft.GMessage) bool { return true }
@@ -23,6 +23,9 @@ type simEC struct {
 	networkName gpbft.NetworkName
 	verifier    signing.Backend
 	errors      errGroup
+	// beacons holds the explicitly set beacon values by instance, which take
+	// precedence over the beacon derived from the base chain.
+	beacons map[uint64][]byte
 }
 
 type ECInstance struct {
@@ -57,14 +60,18 @@ func newEC(opts *options) *simEC {
 	return &simEC{
 		networkName: opts.networkName,
 		verifier:    opts.signingBacked,
+		beacons:     make(map[uint64][]byte),
 	}
 }
 
 func (ec *simEC) BeginInstance(baseChain *gpbft.ECChain, pt *gpbft.PowerTable) *ECInstance {
-	// Take beacon value from the head of the base chain.
-	// Note a real beacon value will come from a finalised chain with some lookback.
-	beacon := baseChain.Head().Key
 	nextInstanceID := uint64(ec.Len())
+	beacon, found := ec.beacons[nextInstanceID]
+	if !found {
+		// Take beacon value from the head of the base chain.
+		// Note a real beacon value will come from a finalised chain with some lookback.
+		beacon = baseChain.Head().Key
+	}
 
 	agg, err := ec.verifier.Aggregate(pt.Entries.PublicKeys())
 	if err != nil {
@@ -23,9 +23,10 @@ type simHost struct {
 	gpbft.SigningMarshaler
 	gpbft.Clock
 
-	id     gpbft.ActorID
-	sim    *Simulation
-	pubkey gpbft.PubKey
+	id          gpbft.ActorID
+	sim         *Simulation
+	pubkey      gpbft.PubKey
+	isAdversary bool
 
 	ecChain *gpbft.ECChain
 	ecg     ECChainGenerator
@@ -36,11 +37,18 @@ func (v *simHost) RequestSynchronousBroadcast(mb *gpbft.MessageBuilder) error {
 	return v.SimNetwork.RequestSynchronousBroadcast(mb)
 }
 
+func (v *simHost) RequestSynchronousRelay(msg *gpbft.GMessage) error {
+	return v.SimNetwork.RequestSynchronousRelay(msg)
+}
+
 type SimNetwork interface {
 	gpbft.Network
 	gpbft.Tracer
 	// sends a message to all other participants immediately.
 	RequestSynchronousBroadcast(mb *gpbft.MessageBuilder) error
+	// re-sends a message received from any participant to all other participants
+	// immediately, without altering it.
+	RequestSynchronousRelay(msg *gpbft.GMessage) error
 }
 
 func newHost(id gpbft.ActorID, sim *Simulation, ecg ECChainGenerator, spg StoragePowerGenerator, isAdversary bool) *simHost {
@@ -56,6 +64,7 @@ func newHost(id gpbft.ActorID, sim *Simulation, ecg ECChainGenerator, spg Storag
 		spg:              spg,
 		pubkey:           pubKey,
 		ecChain:          sim.baseChain,
+		isAdversary:      isAdversary,
 	}
 }
 
@@ -63,6 +72,9 @@ func (v *simHost) GetProposal(instance uint64) (*gpbft.SupplementalData, *gpbft.
 	// Use the head of latest agreement chain as the base of next.
 	// TODO: use lookback to return the correct next power table commitment and commitments hash.
 	chain := v.ecg.GenerateECChain(instance, v.ecChain.Head(), v.id)
+	if v.sim.stats != nil && !v.isAdversary {
+		v.sim.stats.observeStart(instance, v.Time())
+	}
 	i := v.sim.ec.GetInstance(instance)
 	if i == nil {
 		// It is possible for one node to start the next instance before others have
@@ -106,6 +118,11 @@ func (v *simHost) Time() time.Time {
 
 func (v *simHost) ReceiveDecision(decision *gpbft.Justification) (time.Time, error) {
 	v.sim.ec.NotifyDecision(v.id, decision)
+	if v.sim.stats != nil && !v.isAdversary {
+		// Honest participants are indexed by their ID.
+		round := v.sim.participants[v.id].Progress().Round
+		v.sim.stats.observeDecision(v.id, decision.Vote.Instance, round, v.Time())
+	}
 	v.ecChain = decision.Vote.Value
 	return v.Time().Add(v.sim.ecEpochDuration).Add(v.sim.ecStabilisationDelay), nil
 }
@@ -105,6 +105,15 @@ func (pq *messageQueue) Remove() *messageInFlight {
 	return nil
 }
 
+// Peek returns the earliest messageInFlight without removing it from the queue,
+// or nil if the queue is empty.
+func (pq *messageQueue) Peek() *messageInFlight {
+	if pq.Len() > 0 {
+		return pq.mailbox[0]
+	}
+	return nil
+}
+
 // UpsertFirstWhere finds the first message that matches the given criteria, and
 // if found updates its content to the upsert message. Otherwise, inserts the
 // message to the queue.
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
+	"slices"
 	"time"
 
 	"github.com/filecoin-project/go-f3/emulator"
@@ -20,11 +22,38 @@ const (
 	TraceAll //nolint:unused
 )
 
+// BroadcastOrder determines the order in which a broadcast message is enqueued
+// for delivery to participants.
+type BroadcastOrder int
+
+const (
+	// BroadcastOrderInsertion enqueues messages in the order in which the
+	// participants were added to the network.
+	BroadcastOrderInsertion BroadcastOrder = iota
+	// BroadcastOrderSorted enqueues messages in ascending order of participant
+	// ID.
+	BroadcastOrderSorted
+	// BroadcastOrderShuffled enqueues messages in an order randomly shuffled on
+	// every broadcast, deterministic for a given seed.
+	BroadcastOrderShuffled
+)
+
 type Network struct {
 	// Participants by ID.
 	participants map[gpbft.ActorID]gpbft.Receiver
 	// Participant IDs for deterministic iteration
 	participantIDs []gpbft.ActorID
+	// The order in which broadcast messages are enqueued to participants, and the
+	// source of randomness when shuffled.
+	broadcastOrder BroadcastOrder
+	broadcastRng   *rand.Rand
+	// The fraction of messages delivered twice, the extra latency of each
+	// duplicate, the source of randomness choosing which messages to duplicate,
+	// and the number of duplicates enqueued so far.
+	duplicationFraction float64
+	duplicationLatency  time.Duration
+	duplicationRng      *rand.Rand
+	duplicated          int
 	// Messages received by the network but not yet delivered to all participants.
 	queue   *messageQueue
 	latency latency.Model
@@ -37,16 +66,32 @@ type Network struct {
 	traceLevel  int
 	networkName gpbft.NetworkName
 	gst         time.Time
+	// Optional recorder of the messages enqueued, and replayer of a recording
+	// that overrides the latency model.
+	recorder *Recorder
+	replayer *Replayer
+	// err is the first error encountered while recording or replaying, surfaced
+	// by Tick.
+	err error
 }
 
 func newNetwork(opts *options) *Network {
 	return &Network{
-		participants: make(map[gpbft.ActorID]gpbft.Receiver),
-		latency:      opts.latencyModel,
-		traceLevel:   opts.traceLevel,
-		networkName:  opts.networkName,
-		gst:          time.Time{}.Add(opts.globalStabilizationTime),
-		queue:        newMessagePriorityQueue(),
+		participants:   make(map[gpbft.ActorID]gpbft.Receiver),
+		latency:        opts.latencyModel,
+		traceLevel:     opts.traceLevel,
+		networkName:    opts.networkName,
+		gst:            time.Time{}.Add(opts.globalStabilizationTime),
+		queue:          newMessagePriorityQueue(),
+		broadcastOrder: opts.broadcastOrder,
+		broadcastRng:   rand.New(rand.NewSource(opts.broadcastSeed)),
+
+		duplicationFraction: opts.duplicationFraction,
+		duplicationLatency:  opts.duplicationLatency,
+		duplicationRng:      rand.New(rand.NewSource(opts.duplicationSeed)),
+
+		recorder: opts.recorder,
+		replayer: opts.replayer,
 	}
 }
 
@@ -107,6 +152,14 @@ func (nf *networkFor) RequestSynchronousBroadcast(mb *gpbft.MessageBuilder) erro
 	return nf.requestBroadcast(mb, true)
 }
 
+func (nf *networkFor) RequestSynchronousRelay(msg *gpbft.GMessage) error {
+	if !nf.isAdversary {
+		return fmt.Errorf("participant %d is not permitted to relay messages", nf.ParticipantID)
+	}
+	nf.broadcast(msg, true)
+	return nil
+}
+
 func (nf *networkFor) requestBroadcast(mb *gpbft.MessageBuilder, sync bool) error {
 	msg, err := mb.Build(context.Background(), nf.Signer, nf.ParticipantID)
 	if err != nil {
@@ -130,19 +183,79 @@ func (n *Network) NetworkName() gpbft.NetworkName {
 
 func (n *Network) broadcast(msg *gpbft.GMessage, synchronous bool) {
 	n.log(TraceSent, "P%d ↗ %v", msg.Sender, msg)
-	for _, dest := range n.participantIDs {
+	var digest [32]byte
+	if n.recorder != nil || n.replayer != nil {
+		var err error
+		if digest, err = digestMessage(msg); err != nil {
+			n.fail(fmt.Errorf("digesting message from %d: %w", msg.Sender, err))
+			return
+		}
+	}
+	for _, dest := range n.broadcastDestinations() {
 		var latencySample time.Duration
-		if !synchronous {
+		if !synchronous && n.replayer == nil {
 			latencySample = n.latency.Sample(n.Time(), msg.Sender, dest)
 		}
 
-		n.queue.Insert(
+		n.enqueue(
 			&messageInFlight{
 				source:    msg.Sender,
 				dest:      dest,
 				payload:   *msg,
 				deliverAt: n.clock.Add(latencySample),
-			})
+			}, digest)
+		if n.duplicationFraction > 0 && n.duplicationRng.Float64() < n.duplicationFraction {
+			n.enqueue(
+				&messageInFlight{
+					source:    msg.Sender,
+					dest:      dest,
+					payload:   *msg,
+					deliverAt: n.clock.Add(latencySample + n.duplicationLatency),
+				}, digest)
+			n.duplicated++
+		}
+	}
+}
+
+// enqueue inserts the given message into the queue for delivery, at the time
+// recorded for it if replaying, and records it if recording.
+func (n *Network) enqueue(msg *messageInFlight, digest [32]byte) {
+	if n.replayer != nil {
+		deliverAt, err := n.replayer.deliverAt(msg, digest)
+		if err != nil {
+			n.fail(err)
+			return
+		}
+		msg.deliverAt = deliverAt
+	}
+	if n.recorder != nil {
+		if err := n.recorder.record(msg, digest); err != nil {
+			n.fail(fmt.Errorf("recording message from %d to %d: %w", msg.source, msg.dest, err))
+		}
+	}
+	n.queue.Insert(msg)
+}
+
+// fail retains the given error to be returned by Tick, unless an error has
+// already been encountered.
+func (n *Network) fail(err error) {
+	if n.err == nil {
+		n.err = err
+	}
+}
+
+// broadcastDestinations returns the IDs of all participants in the configured
+// broadcast order.
+func (n *Network) broadcastDestinations() []gpbft.ActorID {
+	switch n.broadcastOrder {
+	case BroadcastOrderSorted:
+		return slices.Sorted(slices.Values(n.participantIDs))
+	case BroadcastOrderShuffled:
+		dests := slices.Clone(n.participantIDs)
+		n.broadcastRng.Shuffle(len(dests), func(i, j int) { dests[i], dests[j] = dests[j], dests[i] })
+		return dests
+	default:
+		return n.participantIDs
 	}
 }
 
@@ -171,9 +284,30 @@ func (n *Network) HasMoreTicks() bool {
 	return n.queue.Len() > 0
 }
 
+// peek returns the message or alarm to be delivered by the next Tick, or nil if
+// there is none.
+func (n *Network) peek() *messageInFlight {
+	return n.queue.Peek()
+}
+
+// latestMessageDelivery returns the latest time at which any message currently in
+// flight is due to be delivered, ignoring alarms.
+func (n *Network) latestMessageDelivery() time.Time {
+	var latest time.Time
+	for _, msg := range n.queue.mailbox {
+		if !msg.isAlarm() && msg.deliverAt.After(latest) {
+			latest = msg.deliverAt
+		}
+	}
+	return latest
+}
+
 // Tick disseminates one message among participants and returns whether there are
 // any more messages to process.
 func (n *Network) Tick(adv *adversary.Adversary) error {
+	if n.err != nil {
+		return n.err
+	}
 	msg := n.queue.Remove()
 	n.clock = msg.deliverAt
 
@@ -217,7 +351,8 @@ func (n *Network) Tick(adv *adversary.Adversary) error {
 	default:
 		return fmt.Errorf("unknown message payload: %v", payload)
 	}
-	return nil
+	// Surface any error recording or replaying the messages broadcast in response.
+	return n.err
 }
 
 func (n *Network) log(level int, format string, args ...interface{}) {
@@ -0,0 +1,76 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim/latency"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_BroadcastOrder(t *testing.T) {
+	insertionOrder := []gpbft.ActorID{3, 1, 4, 0, 5, 9, 2, 6, 8, 7}
+	newTestNetwork := func(t *testing.T, order BroadcastOrder, seed int64) *Network {
+		opts := &options{}
+		require.NoError(t, WithBroadcastOrder(order, seed)(opts))
+		subject := newNetwork(opts)
+		for _, id := range insertionOrder {
+			subject.AddParticipant(id, nil)
+		}
+		return subject
+	}
+
+	t.Run("insertion", func(t *testing.T) {
+		subject := newTestNetwork(t, BroadcastOrderInsertion, 0)
+		require.Equal(t, insertionOrder, subject.broadcastDestinations())
+	})
+	t.Run("sorted", func(t *testing.T) {
+		subject := newTestNetwork(t, BroadcastOrderSorted, 0)
+		require.Equal(t, []gpbft.ActorID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, subject.broadcastDestinations())
+		require.Equal(t, insertionOrder, subject.participantIDs)
+	})
+	t.Run("shuffled is reproducible", func(t *testing.T) {
+		const seed = 1413
+		one := newTestNetwork(t, BroadcastOrderShuffled, seed)
+		other := newTestNetwork(t, BroadcastOrderShuffled, seed)
+		var distinctOrders int
+		var previous []gpbft.ActorID
+		for range 10 {
+			got := one.broadcastDestinations()
+			require.Equal(t, got, other.broadcastDestinations())
+			require.ElementsMatch(t, insertionOrder, got)
+			if !slices.Equal(previous, got) {
+				distinctOrders++
+			}
+			previous = got
+		}
+		// Each broadcast is shuffled independently.
+		require.Greater(t, distinctOrders, 1)
+	})
+	t.Run("unknown order", func(t *testing.T) {
+		require.Error(t, WithBroadcastOrder(BroadcastOrder(42), 0)(&options{}))
+	})
+}
+
+func TestNetwork_Duplication(t *testing.T) {
+	require.Error(t, WithDuplication(-0.1, 0, 0)(&options{}))
+	require.Error(t, WithDuplication(1.1, 0, 0)(&options{}))
+	require.Error(t, WithDuplication(0.5, -time.Second, 0)(&options{}))
+
+	opts := &options{latencyModel: latency.None}
+	require.NoError(t, WithDuplication(1, time.Second, 0)(opts))
+	subject := newNetwork(opts)
+	subject.AddParticipant(1, nil)
+	subject.AddParticipant(2, nil)
+	subject.broadcast(&gpbft.GMessage{Sender: 1}, false)
+
+	// Every message is delivered twice, with the duplicate a second later.
+	require.Equal(t, 2, subject.duplicated)
+	var deliverAt []time.Time
+	for subject.HasMoreTicks() {
+		deliverAt = append(deliverAt, subject.queue.Remove().deliverAt)
+	}
+	require.Equal(t, []time.Time{{}, {}, time.Time{}.Add(time.Second), time.Time{}.Add(time.Second)}, deliverAt)
+}
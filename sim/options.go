@@ -55,6 +55,16 @@ type options struct {
 	adversaryGenerator adversary.Generator
 	adversaryCount     uint64
 	ignoreConsensusFor []gpbft.ActorID
+	broadcastOrder     BroadcastOrder
+	broadcastSeed      int64
+	// duplicationFraction is the fraction of messages delivered twice, with the
+	// duplicate arriving duplicationLatency after the original.
+	duplicationFraction float64
+	duplicationLatency  time.Duration
+	duplicationSeed     int64
+	recorder            *Recorder
+	replayer            *Replayer
+	stats               *StatsCollector
 }
 
 type participantArchetype struct {
@@ -192,3 +202,74 @@ func WithIgnoreConsensusFor(id ...gpbft.ActorID) Option {
 		return nil
 	}
 }
+
+// WithBroadcastOrder sets the order in which messages broadcast over the
+// simulated network are enqueued for delivery to participants. The seed is only
+// used when the order is BroadcastOrderShuffled. Defaults to
+// BroadcastOrderInsertion.
+func WithBroadcastOrder(order BroadcastOrder, seed int64) Option {
+	return func(o *options) error {
+		switch order {
+		case BroadcastOrderInsertion, BroadcastOrderSorted, BroadcastOrderShuffled:
+		default:
+			return fmt.Errorf("unknown broadcast order: %d", order)
+		}
+		o.broadcastOrder = order
+		o.broadcastSeed = seed
+		return nil
+	}
+}
+
+// WithDuplication sets the fraction of messages broadcast over the simulated
+// network that are delivered to a participant twice, with the duplicate
+// delivered the given extra latency after the original. The seed determines
+// which messages are duplicated. Defaults to no duplication.
+func WithDuplication(fraction float64, extraLatency time.Duration, seed int64) Option {
+	return func(o *options) error {
+		if fraction < 0 || fraction > 1 {
+			return fmt.Errorf("duplication fraction must be within [0, 1]; got: %f", fraction)
+		}
+		if extraLatency < 0 {
+			return fmt.Errorf("duplication latency must not be negative; got: %s", extraLatency)
+		}
+		o.duplicationFraction = fraction
+		o.duplicationLatency = extraLatency
+		o.duplicationSeed = seed
+		return nil
+	}
+}
+
+// WithRecorder records the schedule of every message enqueued for delivery over
+// the simulated network to the given recorder, for later replay. The recorder
+// must be flushed once the simulation completes. Defaults to no recording.
+//
+// See WithReplayer.
+func WithRecorder(r *Recorder) Option {
+	return func(o *options) error {
+		o.recorder = r
+		return nil
+	}
+}
+
+// WithReplayer schedules the delivery of messages over the simulated network as
+// recorded by a Recorder, in place of the latency model. All other options must
+// match those of the recorded simulation, otherwise the simulation fails as soon
+// as the messages diverge from the recording. Defaults to no replay.
+//
+// See WithRecorder.
+func WithReplayer(r *Replayer) Option {
+	return func(o *options) error {
+		o.replayer = r
+		return nil
+	}
+}
+
+// WithStatsCollector collects the deciding round and simulated time to decision
+// of every instance completed by Simulation.Run into the given collector.
+// Defaults to no collection.
+func WithStatsCollector(c *StatsCollector) Option {
+	return func(o *options) error {
+		o.stats = c
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+package sim
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+)
+
+// RecordedMessage is a message enqueued for delivery over the simulated network,
+// as captured by a Recorder.
+type RecordedMessage struct {
+	Source gpbft.ActorID
+	Dest   gpbft.ActorID
+	// DeliverAt is the time at which the message is delivered, in nanoseconds
+	// since the start of the simulation.
+	DeliverAt int64
+	// Digest is the SHA-256 digest of the CBOR encoded message.
+	Digest [32]byte `cborgen:"maxlen=32"`
+}
+
+// Recorder captures the schedule of messages enqueued for delivery over the
+// simulated network, in the order in which they are enqueued, so that a
+// simulation can later be replayed with the exact same schedule using a Replayer.
+//
+// The recording is written as a sequence of CBOR encoded RecordedMessage, and so
+// may be checked into testdata for reproducing failures.
+type Recorder struct {
+	w *bufio.Writer
+}
+
+// NewRecorder instantiates a new Recorder that writes to the given writer. The
+// recording is buffered, and must be flushed once the simulation completes.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: bufio.NewWriter(w)}
+}
+
+func (r *Recorder) record(msg *messageInFlight, digest [32]byte) error {
+	rm := RecordedMessage{
+		Source:    msg.source,
+		Dest:      msg.dest,
+		DeliverAt: int64(msg.deliverAt.Sub(time.Time{})),
+		Digest:    digest,
+	}
+	return rm.MarshalCBOR(r.w)
+}
+
+// Flush writes any buffered recording to the underlying writer.
+func (r *Recorder) Flush() error {
+	return r.w.Flush()
+}
+
+// Replayer schedules the delivery of messages over the simulated network exactly
+// as they were scheduled in a recording made by Recorder, regardless of the
+// latency model, so that a simulation with otherwise identical options delivers
+// the same messages in the same order.
+//
+// Replay fails if the messages enqueued diverge from those recorded, which
+// indicates that the simulation options differ from those of the recording.
+type Replayer struct {
+	r *bufio.Reader
+	// next is the index of the next message to replay.
+	next int
+}
+
+// NewReplayer instantiates a new Replayer that reads a recording made by
+// Recorder from the given reader.
+func NewReplayer(r io.Reader) *Replayer {
+	return &Replayer{r: bufio.NewReader(r)}
+}
+
+// deliverAt returns the recorded time at which the given message is delivered,
+// or an error if the message does not match the next recorded one.
+func (r *Replayer) deliverAt(msg *messageInFlight, digest [32]byte) (time.Time, error) {
+	index := r.next
+	r.next++
+	var rm RecordedMessage
+	if err := rm.UnmarshalCBOR(r.r); err != nil {
+		if errors.Is(err, io.EOF) {
+			return time.Time{}, fmt.Errorf("recording exhausted at message %d", index)
+		}
+		return time.Time{}, fmt.Errorf("reading recorded message %d: %w", index, err)
+	}
+	if rm.Source != msg.source || rm.Dest != msg.dest || rm.Digest != digest {
+		return time.Time{}, fmt.Errorf("replay diverged from recording at message %d: recorded %d → %d (%x), got %d → %d (%x)",
+			index, rm.Source, rm.Dest, rm.Digest, msg.source, msg.dest, digest)
+	}
+	return time.Time{}.Add(time.Duration(rm.DeliverAt)), nil
+}
+
+func digestMessage(msg *gpbft.GMessage) ([32]byte, error) {
+	var buf bytes.Buffer
+	if err := msg.MarshalCBOR(&buf); err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(buf.Bytes()), nil
+}
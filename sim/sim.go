@@ -3,7 +3,9 @@ package sim
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/filecoin-project/go-f3/gpbft"
 	"github.com/filecoin-project/go-f3/sim/adversary"
@@ -51,26 +53,23 @@ func NewSimulation(o ...Option) (*Simulation, error) {
 	}, nil
 }
 
+// SetBeacon sets the beacon value used by the committee of the given instance,
+// overriding the beacon that is otherwise derived from the head of the instance
+// base chain. The beacon must be set before the instance begins in order to
+// take effect.
+func (s *Simulation) SetBeacon(instance uint64, beacon []byte) {
+	s.ec.beacons[instance] = beacon
+}
+
 // Run runs simulation, and returns whether all participants decided on the same value.
 func (s *Simulation) Run(instanceCount uint64, maxRounds uint64) error {
-	if err := s.initParticipants(); err != nil {
-		return err
-	}
 	initialInstance := uint64(0)
-	pt, err := s.getPowerTable(initialInstance)
+	currentInstance, err := s.begin(initialInstance)
 	if err != nil {
 		return err
 	}
-	currentInstance := s.ec.BeginInstance(s.baseChain, pt)
-	s.startParticipants(initialInstance)
-
 	finalInstance := initialInstance + instanceCount - 1
 
-	// Exclude adversary ID when checking for decision or instance completion.
-	if s.adversary != nil {
-		s.ignoreConsensusFor = append(s.ignoreConsensusFor, s.adversary.ID())
-	}
-
 	// Run until there are no more messages, meaning termination or deadlock.
 	for s.network.HasMoreTicks() {
 		if err := s.ec.Err(); err != nil {
@@ -85,6 +84,9 @@ func (s *Simulation) Run(instanceCount uint64, maxRounds uint64) error {
 			if !reachedConsensus {
 				return fmt.Errorf("concensus was not reached at instance %d", currentInstance.Instance)
 			}
+			if s.stats != nil {
+				s.stats.observeCompletion(currentInstance.Instance, s.ignoreConsensusFor)
+			}
 
 			pt, err := s.getPowerTable(currentInstance.Instance + 1)
 			if err != nil {
@@ -130,16 +132,169 @@ func (s *Simulation) Run(instanceCount uint64, maxRounds uint64) error {
 			}
 		}
 
-		switch err := s.network.Tick(s.adversary); {
-		case errors.Is(err, gpbft.ErrValidationNotRelevant):
-			// Ignore error signalling valid messages that are no longer useful for the
-			// progress of GPBFT. This can occur in normal operation depending on the order
-			// of delivered messages. In production, deployment this error is used to signal
-			// that the message does not need to be propagated among participants. In
-			// simulation, we simply ignore it.
-		case err != nil:
-			return fmt.Errorf("error performing simulation phase: %w", err)
+		if err := s.tick(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Quiescence describes the state in which RunUntilQuiescent left a simulation.
+type Quiescence struct {
+	// Decided signals whether every honest participant decided on the same value.
+	Decided bool
+	// Stuck lists the progress of the honest participants that had not decided
+	// once the simulation quiesced, in order of participant ID.
+	Stuck []StuckParticipant
+}
+
+// StuckParticipant is the progress of an honest participant that failed to
+// decide.
+type StuckParticipant struct {
+	ID       gpbft.ActorID
+	Progress gpbft.Instant
+}
+
+// Deadlocked returns whether the network settled without every honest
+// participant deciding.
+func (q Quiescence) Deadlocked() bool {
+	return !q.Decided
+}
+
+func (q Quiescence) String() string {
+	if q.Decided {
+		return "decided"
+	}
+	b := strings.Builder{}
+	b.WriteString("deadlocked:")
+	for _, p := range q.Stuck {
+		fmt.Fprintf(&b, " P%d at instance %d round %d phase %s;", p.ID, p.Progress.ID, p.Progress.Round, p.Progress.Phase)
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// RunUntilQuiescent runs the first instance of the simulation until either every
+// honest participant decides, or the network deadlocks, and reports which. An
+// error is returned if any honest participant goes beyond maxRounds, i.e. the
+// network is still making progress but may well need more rounds to decide.
+//
+// The network deadlocks once there is nothing left to deliver. However, honest
+// participants that cannot make progress keep rebroadcasting their messages
+// upon alarms indefinitely. Hence, the network is also considered deadlocked
+// once every undecided honest participant has rebroadcast since the progress of
+// any of them last changed, and every message in flight at that point has been
+// delivered without changing it either.
+func (s *Simulation) RunUntilQuiescent(maxRounds uint64) (Quiescence, error) {
+	instance, err := s.begin(0)
+	if err != nil {
+		return Quiescence{}, err
+	}
+
+	progress := s.honestProgress()
+	var (
+		// rebroadcast records the undecided honest participants that received an
+		// alarm since the last change in progress.
+		rebroadcast = make(map[gpbft.ActorID]struct{})
+		// settledAt, if set, is the time by which every message that may change
+		// progress has been delivered.
+		settledAt time.Time
+	)
+	for s.network.HasMoreTicks() && !instance.HasCompleted(s.ignoreConsensusFor...) {
+		if err := s.ec.Err(); err != nil {
+			return Quiescence{}, fmt.Errorf("error in decision: %w", err)
+		}
+		if s.getMaxRound() > maxRounds {
+			return Quiescence{}, fmt.Errorf("reached maximum number of %d rounds at instance %d", maxRounds, instance.Instance)
+		}
+		next := s.network.peek()
+		if !settledAt.IsZero() && next.deliverAt.After(settledAt) {
+			break
+		}
+		if err := s.tick(); err != nil {
+			return Quiescence{}, err
+		}
+
+		if current := s.honestProgress(); !slices.Equal(current, progress) {
+			progress = current
+			clear(rebroadcast)
+			settledAt = time.Time{}
+			continue
+		}
+		if next.isAlarm() && instance.GetDecision(next.dest) == nil && !slices.Contains(s.ignoreConsensusFor, next.dest) {
+			rebroadcast[next.dest] = struct{}{}
+		}
+		if settledAt.IsZero() && len(rebroadcast) == s.undecidedCount(instance) {
+			settledAt = s.network.latestMessageDelivery()
+		}
+	}
+
+	if instance.HasCompleted(s.ignoreConsensusFor...) {
+		if _, reachedConsensus := instance.HasReachedConsensus(s.ignoreConsensusFor...); !reachedConsensus {
+			return Quiescence{}, fmt.Errorf("concensus was not reached at instance %d", instance.Instance)
 		}
+		return Quiescence{Decided: true}, nil
+	}
+	var q Quiescence
+	for _, p := range s.participants {
+		if instance.GetDecision(p.ID()) == nil {
+			q.Stuck = append(q.Stuck, StuckParticipant{ID: p.ID(), Progress: p.Progress()})
+		}
+	}
+	return q, nil
+}
+
+// honestProgress returns the progress of honest participants in order of ID.
+func (s *Simulation) honestProgress() []gpbft.Instant {
+	progress := make([]gpbft.Instant, len(s.participants))
+	for i, p := range s.participants {
+		progress[i] = p.Progress()
+	}
+	return progress
+}
+
+// undecidedCount returns the number of honest participants that have not decided
+// at the given instance.
+func (s *Simulation) undecidedCount(instance *ECInstance) int {
+	var count int
+	for _, p := range s.participants {
+		if instance.GetDecision(p.ID()) == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// begin initialises the participants and starts them at the given instance,
+// returning the EC instance they run.
+func (s *Simulation) begin(instance uint64) (*ECInstance, error) {
+	if err := s.initParticipants(); err != nil {
+		return nil, err
+	}
+	pt, err := s.getPowerTable(instance)
+	if err != nil {
+		return nil, err
+	}
+	ecInstance := s.ec.BeginInstance(s.baseChain, pt)
+	s.startParticipants(instance)
+
+	// Exclude adversary ID when checking for decision or instance completion.
+	if s.adversary != nil {
+		s.ignoreConsensusFor = append(s.ignoreConsensusFor, s.adversary.ID())
+	}
+	return ecInstance, nil
+}
+
+// tick delivers the next message or alarm in the network.
+func (s *Simulation) tick() error {
+	switch err := s.network.Tick(s.adversary); {
+	case errors.Is(err, gpbft.ErrValidationNotRelevant):
+		// Ignore error signalling valid messages that are no longer useful for the
+		// progress of GPBFT. This can occur in normal operation depending on the order
+		// of delivered messages. In production, deployment this error is used to signal
+		// that the message does not need to be propagated among participants. In
+		// simulation, we simply ignore it.
+	case err != nil:
+		return fmt.Errorf("error performing simulation phase: %w", err)
 	}
 	return nil
 }
@@ -229,6 +384,17 @@ func (s *Simulation) ListParticipantIDs() []gpbft.ActorID {
 	return pids
 }
 
+// Time returns the current time of the simulated network.
+func (s *Simulation) Time() time.Time {
+	return s.network.Time()
+}
+
+// Duplicated returns the number of duplicate messages the simulated network has
+// enqueued for delivery so far. See WithDuplication.
+func (s *Simulation) Duplicated() int {
+	return s.network.duplicated
+}
+
 func (s *Simulation) GetInstance(i uint64) *ECInstance {
 	return s.ec.GetInstance(i)
 }
@@ -0,0 +1,127 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+)
+
+// DecisionStats captures how an instance of a simulation reached its decision.
+type DecisionStats struct {
+	Instance uint64
+	// Round is the highest round at which an honest participant decided.
+	Round uint64
+	// Duration is the simulated time from the first honest participant starting
+	// the instance until the last honest participant decided.
+	Duration time.Duration
+}
+
+// StatsCollector collects the deciding round and simulated time to decision of
+// every instance completed by Simulation.Run. The collector only observes the
+// simulation, and so does not alter the schedule of messages.
+//
+// A collector must not be shared across simulations.
+//
+// See WithStatsCollector.
+type StatsCollector struct {
+	started   map[uint64]time.Time
+	decided   map[uint64]map[gpbft.ActorID]decisionAt
+	decisions []DecisionStats
+}
+
+type decisionAt struct {
+	round uint64
+	at    time.Time
+}
+
+// NewStatsCollector instantiates a new StatsCollector with no decisions.
+func NewStatsCollector() *StatsCollector {
+	return &StatsCollector{
+		started: make(map[uint64]time.Time),
+		decided: make(map[uint64]map[gpbft.ActorID]decisionAt),
+	}
+}
+
+func (c *StatsCollector) observeStart(instance uint64, at time.Time) {
+	if started, found := c.started[instance]; !found || at.Before(started) {
+		c.started[instance] = at
+	}
+}
+
+// observeDecision records the decision of the given participant, which is
+// reached at the round the participant was at upon deciding. Decisions always
+// carry round zero, and so do not tell the round on their own.
+func (c *StatsCollector) observeDecision(participant gpbft.ActorID, instance, round uint64, at time.Time) {
+	if c.decided[instance] == nil {
+		c.decided[instance] = make(map[gpbft.ActorID]decisionAt)
+	}
+	c.decided[instance][participant] = decisionAt{round: round, at: at}
+}
+
+// observeCompletion records the statistics of the given instance, once every
+// participant bar the excluded ones has decided.
+func (c *StatsCollector) observeCompletion(instance uint64, exclude []gpbft.ActorID) {
+	stats := DecisionStats{Instance: instance}
+	started := c.started[instance]
+	for participant, decision := range c.decided[instance] {
+		if slices.Contains(exclude, participant) {
+			continue
+		}
+		stats.Round = max(stats.Round, decision.round)
+		stats.Duration = max(stats.Duration, decision.at.Sub(started))
+	}
+	c.decisions = append(c.decisions, stats)
+	delete(c.started, instance)
+	delete(c.decided, instance)
+}
+
+// Decisions returns the statistics of every completed instance, in order of
+// completion.
+func (c *StatsCollector) Decisions() []DecisionStats {
+	return slices.Clone(c.decisions)
+}
+
+// RoundPercentile returns the p-th percentile, in the range of 0 to 100, of the
+// rounds at which completed instances were decided, or zero if no instance has
+// completed.
+func (c *StatsCollector) RoundPercentile(p float64) uint64 {
+	rounds := make([]uint64, len(c.decisions))
+	for i, d := range c.decisions {
+		rounds[i] = d.Round
+	}
+	return percentile(rounds, p)
+}
+
+// DurationPercentile returns the p-th percentile, in the range of 0 to 100, of
+// the simulated time taken by completed instances to decide, or zero if no
+// instance has completed.
+func (c *StatsCollector) DurationPercentile(p float64) time.Duration {
+	durations := make([]time.Duration, len(c.decisions))
+	for i, d := range c.decisions {
+		durations[i] = d.Duration
+	}
+	return percentile(durations, p)
+}
+
+// String summarises the median, 90th and 99th percentiles of the collected
+// statistics.
+func (c *StatsCollector) String() string {
+	return fmt.Sprintf("%d instances; rounds p50: %d, p90: %d, p99: %d; durations p50: %s, p90: %s, p99: %s",
+		len(c.decisions),
+		c.RoundPercentile(50), c.RoundPercentile(90), c.RoundPercentile(99),
+		c.DurationPercentile(50), c.DurationPercentile(90), c.DurationPercentile(99))
+}
+
+// percentile returns the p-th percentile of values using the nearest-rank
+// method, sorting values in place.
+func percentile[T uint64 | time.Duration](values []T, p float64) T {
+	if len(values) == 0 {
+		return 0
+	}
+	slices.Sort(values)
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	return values[min(max(rank, 1), len(values))-1]
+}
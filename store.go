@@ -16,6 +16,11 @@ import (
 	"github.com/ipfs/go-datastore/namespace"
 )
 
+// ErrBootstrapUnavailable signals that the tipset, or the power table, at the
+// base of the first instance is not available, e.g. because the node started
+// with a truncated chain.
+var ErrBootstrapUnavailable = errors.New("bootstrap base unavailable")
+
 // openCertstore opens the certificate store for the specific manifest (namespaced by the network
 // name).
 func openCertstore(ctx context.Context, ec ec.Backend, ds datastore.Datastore,
@@ -66,3 +71,27 @@ func loadInitialPowerTable(ctx context.Context, ec ec.Backend, m *manifest.Manif
 	}
 	return pt, nil
 }
+
+// validateBootstrap checks that the inputs to the first instance are available,
+// such that a node missing them fails at startup rather than in the middle of
+// the instance. It is a no-op once the first instance has been finalized, as
+// subsequent instances are based on finalized chains.
+func validateBootstrap(ctx context.Context, ec ec.Backend, cs *certstore.Store, m *manifest.Manifest) error {
+	if cs.Latest() != nil {
+		return nil
+	}
+	epoch := m.BootstrapEpoch - m.EC.Finality
+	ts, err := ec.GetTipsetByEpoch(ctx, epoch)
+	if err != nil {
+		return fmt.Errorf("%w: tipset at epoch %d (bootstrap epoch %d minus finality %d) is missing from EC, "+
+			"the chain must extend back to at least epoch %d: %w", ErrBootstrapUnavailable, epoch, m.BootstrapEpoch, m.EC.Finality, epoch, err)
+	}
+	if _, err := ec.GetPowerTable(ctx, ts.Key()); err != nil {
+		// The bootstrap power table may have been fetched from peers instead.
+		if _, csErr := cs.GetPowerTable(ctx, m.InitialInstance); csErr != nil {
+			return fmt.Errorf("%w: power table at epoch %d is missing from both EC and the certificate store: %w",
+				ErrBootstrapUnavailable, epoch, errors.Join(err, csErr))
+		}
+	}
+	return nil
+}
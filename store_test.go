@@ -0,0 +1,43 @@
+package f3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/filecoin-project/go-f3/ec"
+	"github.com/filecoin-project/go-f3/internal/clock"
+	"github.com/filecoin-project/go-f3/internal/consensus"
+	"github.com/stretchr/testify/require"
+)
+
+// truncatedEC simulates a node started with a chain that does not extend back
+// beyond a given epoch.
+type truncatedEC struct {
+	*consensus.FakeEC
+	firstEpoch int64
+}
+
+func (t *truncatedEC) GetTipsetByEpoch(ctx context.Context, epoch int64) (ec.TipSet, error) {
+	if epoch < t.firstEpoch {
+		return nil, fmt.Errorf("epoch %d is before the first available epoch %d", epoch, t.firstEpoch)
+	}
+	return t.FakeEC.GetTipsetByEpoch(ctx, epoch)
+}
+
+func TestValidateBootstrap(t *testing.T) {
+	ctx, _ := clock.WithMockClock(context.Background())
+	f := newTestConsensusInputs(t, ctx)
+	m, fakeEC, cs := f.m, f.ec, f.cs
+	bootstrapBase := f.bootstrapBase()
+	truncated := &truncatedEC{FakeEC: fakeEC, firstEpoch: bootstrapBase + 1}
+
+	require.NoError(t, validateBootstrap(ctx, fakeEC, cs, m))
+	err := validateBootstrap(ctx, truncated, cs, m)
+	require.ErrorIs(t, err, ErrBootstrapUnavailable)
+	require.ErrorContains(t, err, fmt.Sprintf("tipset at epoch %d", bootstrapBase))
+
+	// Once the first instance is finalized, the bootstrap base is no longer needed.
+	f.decideInitialInstance(t)
+	require.NoError(t, validateBootstrap(ctx, truncated, cs, m))
+}
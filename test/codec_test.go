@@ -0,0 +1,63 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/filecoin-project/go-f3/sim/adversary"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is an adversary that never sends anything, and records every message
+// broadcast by honest participants.
+type recorder struct {
+	*adversary.Absent
+	messages []*gpbft.GMessage
+}
+
+func (r *recorder) ReceiveMessage(vmsg gpbft.ValidatedMessage) error {
+	r.messages = append(r.messages, vmsg.Message())
+	return nil
+}
+
+// FuzzGMessageCBOR asserts that arbitrary input either fails to decode as a
+// GMessage, or decodes to a message that survives a round trip through the
+// codec unchanged. The seed corpus comprises the messages of every phase
+// broadcast in a simulation of disagreeing participants.
+func FuzzGMessageCBOR(f *testing.F) {
+	var recorded *recorder
+	sm, err := sim.NewSimulation(asyncOptions(1413,
+		sim.AddHonestParticipants(2, sim.NewUniformECChainGenerator(17, 1, 4), uniformOneStoragePower),
+		sim.AddHonestParticipants(2, sim.NewUniformECChainGenerator(23, 1, 4), uniformOneStoragePower),
+		sim.WithAdversary(func(id gpbft.ActorID, host adversary.Host) *adversary.Adversary {
+			recorded = &recorder{Absent: adversary.NewAbsent(id, host)}
+			return &adversary.Adversary{Receiver: recorded, Power: oneStoragePower}
+		}),
+	)...)
+	require.NoError(f, err)
+	require.NoErrorf(f, sm.Run(5, maxRounds), "%s", sm.Describe())
+	require.NotEmpty(f, recorded.messages)
+	for _, msg := range recorded.messages {
+		var buf bytes.Buffer
+		require.NoError(f, msg.MarshalCBOR(&buf))
+		f.Add(buf.Bytes())
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var decoded gpbft.GMessage
+		if err := decoded.UnmarshalCBOR(bytes.NewReader(data)); err != nil {
+			return
+		}
+		var encoded bytes.Buffer
+		require.NoError(t, decoded.MarshalCBOR(&encoded))
+		var redecoded gpbft.GMessage
+		require.NoError(t, redecoded.UnmarshalCBOR(bytes.NewReader(encoded.Bytes())))
+		require.Equal(t, decoded, redecoded)
+
+		var reencoded bytes.Buffer
+		require.NoError(t, redecoded.MarshalCBOR(&reencoded))
+		require.Equal(t, encoded.Bytes(), reencoded.Bytes())
+	})
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/filecoin-project/go-f3/sim/adversary"
+	"github.com/filecoin-project/go-f3/sim/signing"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConverge_BeaconElectsLeader crafts a scenario in which participants enter
+// CONVERGE with different proposals, and sets the beacon of the instance such
+// that a chosen participant holds the best ticket.
+//
+// Three honest participants propose the same chain, while QUALITY messages to
+// participant 0 are denied. Participant 0 therefore falls back to proposing the
+// base chain, which prevents a strong quorum of PREPARE in round 0. In round 1,
+// participants 1 and 2 hold both the base chain and their proposal as
+// candidates, while participant 0 only holds the base chain. The base chain can
+// then be decided in round 1 only if participant 0 is the CONVERGE leader.
+func TestConverge_BeaconElectsLeader(t *testing.T) {
+	t.Parallel()
+	const (
+		honestCount = 3
+		gst         = 1000 * EcEpochDuration
+	)
+	tsg := sim.NewTipSetGenerator(tipSetGeneratorSeed)
+	baseChain := generateECChain(t, tsg)
+	proposal := baseChain.Extend(tsg.Sample())
+
+	for _, leader := range []gpbft.ActorID{0, 1, 2} {
+		t.Run(fmt.Sprintf("leader %d", leader), func(t *testing.T) {
+			t.Parallel()
+			backend := signing.NewFakeBackend()
+			sm, err := sim.NewSimulation(syncOptions(
+				sim.WithSigningBackend(backend),
+				sim.WithBaseChain(baseChain),
+				sim.AddHonestParticipants(honestCount, sim.NewFixedECChainGenerator(proposal), uniformOneStoragePower),
+				sim.WithAdversary(adversary.NewDenyGenerator(oneStoragePower, gst, adversary.DenyPhase(gpbft.QUALITY_PHASE), adversary.DenyTo, 0)),
+				sim.WithGlobalStabilizationTime(gst),
+			)...)
+			require.NoError(t, err)
+			sm.SetBeacon(0, findBeaconForLeader(t, backend, honestCount, leader))
+
+			err = sm.Run(1, 1)
+			if leader != 0 {
+				// Participant 0 never adopts a chain other than base, and no strong
+				// quorum can be formed without it. Therefore, consensus cannot be
+				// reached in round 1.
+				require.ErrorContains(t, err, "reached maximum number of 1 rounds")
+				return
+			}
+			require.NoErrorf(t, err, "%s", sm.Describe())
+			requireConsensusAtFirstInstance(t, sm, baseChain.Base())
+		})
+	}
+}
+
+// findBeaconForLeader finds a beacon at which the given participant holds the
+// best CONVERGE ticket at round 1 of the first instance, among the given count
+// of participants with equal power.
+func findBeaconForLeader(t *testing.T, backend *signing.FakeBackend, participantCount int, leader gpbft.ActorID) []byte {
+	t.Helper()
+	pt := gpbft.NewPowerTable()
+	for id := 0; id < participantCount; id++ {
+		require.NoError(t, pt.Add(gpbft.PowerEntry{
+			ID:     gpbft.ActorID(id),
+			Power:  oneStoragePower,
+			PubKey: backend.Allow(id),
+		}))
+	}
+	for attempt := 0; attempt < 1000; attempt++ {
+		beacon := []byte(fmt.Sprintf("beacon-%d", attempt))
+		best, bestRank := gpbft.ActorID(math.MaxUint64), math.Inf(1)
+		for _, entry := range pt.Entries {
+			mb := &gpbft.MessageBuilder{
+				// The default simulation network name.
+				NetworkName:      "sim",
+				PowerTable:       pt,
+				SigningMarshaler: backend,
+				Payload: gpbft.Payload{
+					Instance: 0,
+					Round:    1,
+					Phase:    gpbft.CONVERGE_PHASE,
+				},
+				BeaconForTicket: beacon,
+			}
+			st, err := mb.PrepareSigningInputs(entry.ID)
+			require.NoError(t, err)
+			ticket, err := backend.Sign(context.Background(), entry.PubKey, st.VRFToSign)
+			require.NoError(t, err)
+			if rank := gpbft.ComputeTicketRank(ticket, pt.ScaledPower[pt.Lookup[entry.ID]]); rank < bestRank {
+				best, bestRank = entry.ID, rank
+			}
+		}
+		if best == leader {
+			return beacon
+		}
+	}
+	require.FailNow(t, "no beacon found for leader", "%d", leader)
+	return nil
+}
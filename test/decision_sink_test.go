@@ -0,0 +1,44 @@
+package test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/stretchr/testify/require"
+)
+
+var _ gpbft.DecisionSink = (*recordingDecisionSink)(nil)
+
+type recordingDecisionSink struct {
+	instances []uint64
+	chains    []*gpbft.ECChain
+}
+
+func (r *recordingDecisionSink) ReceiveFinalizedChain(_ context.Context, instance uint64, chain *gpbft.ECChain) error {
+	r.instances = append(r.instances, instance)
+	r.chains = append(r.chains, chain)
+	return nil
+}
+
+func TestDecisionSink_ReceivesEveryDecisionInOrder(t *testing.T) {
+	t.Parallel()
+	const instanceCount = 50
+
+	sink := &recordingDecisionSink{}
+	gpbftOptions := append(slices.Clone(testGpbftOptions), gpbft.WithDecisionSink(sink, time.Second))
+	sm, err := sim.NewSimulation(append(syncOptions(
+		sim.AddHonestParticipants(1, sim.NewUniformECChainGenerator(4332, 1, 5), uniformOneStoragePower),
+	), sim.WithGpbftOptions(gpbftOptions...))...)
+	require.NoError(t, err)
+	require.NoErrorf(t, sm.Run(instanceCount, maxRounds), "%s", sm.Describe())
+
+	require.Len(t, sink.instances, instanceCount)
+	for i, instance := range sink.instances {
+		require.Equal(t, uint64(i), instance)
+		require.Equal(t, sm.GetInstance(instance).GetDecision(0), sink.chains[i])
+	}
+}
@@ -0,0 +1,48 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDuplication_OutcomeUnaffected runs the same asynchronous simulation of two
+// disagreeing groups of participants with and without half of the messages
+// delivered twice, and asserts that duplicates are dropped by participants
+// without affecting any decision.
+func TestDuplication_OutcomeUnaffected(t *testing.T) {
+	t.Parallel()
+	const (
+		instanceCount   = 20
+		groupSize       = 3
+		latencySeed     = 2381
+		duplicationSeed = 7
+	)
+	run := func(t *testing.T, o ...sim.Option) ([][]*gpbft.ECChain, *sim.Simulation) {
+		sm, err := sim.NewSimulation(asyncOptions(latencySeed, append(o,
+			sim.AddHonestParticipants(groupSize, sim.NewUniformECChainGenerator(17, 1, 4), uniformOneStoragePower),
+			sim.AddHonestParticipants(groupSize, sim.NewUniformECChainGenerator(23, 1, 4), uniformOneStoragePower),
+		)...)...)
+		require.NoError(t, err)
+		require.NoErrorf(t, sm.Run(instanceCount, maxRounds), "%s", sm.Describe())
+
+		decisions := make([][]*gpbft.ECChain, instanceCount)
+		for instance := range decisions {
+			for _, pid := range sm.ListParticipantIDs() {
+				decision := sm.GetInstance(uint64(instance)).GetDecision(pid)
+				require.NotNil(t, decision, "no decision for participant %d in instance %d", pid, instance)
+				decisions[instance] = append(decisions[instance], decision)
+			}
+		}
+		return decisions, sm
+	}
+
+	want, baseline := run(t)
+	require.Zero(t, baseline.Duplicated())
+	got, duplicating := run(t, sim.WithDuplication(0.5, 100*time.Millisecond, duplicationSeed))
+	require.NotZero(t, duplicating.Duplicated())
+	require.Equal(t, want, got)
+}
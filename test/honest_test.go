@@ -3,6 +3,7 @@ package test
 import (
 	"fmt"
 	"math/rand"
+	"slices"
 	"testing"
 
 	"github.com/filecoin-project/go-f3/gpbft"
@@ -287,3 +288,77 @@ func FuzzHonest_AsyncMajorityCommonPrefix(f *testing.F) {
 		}
 	})
 }
+
+// TestHonest_SkipToCommitOnWeakQuorum asserts that participants which skip ahead
+// to COMMIT upon observing a weak quorum of COMMITs for their proposal
+// consistently reach the same decision across many instances.
+//
+// Skipping to DECIDE on a weak quorum of COMMITs would be unsafe, since only a
+// strong quorum of COMMITs may justify a DECIDE. The early COMMIT, on the other
+// hand, does not weaken safety. A COMMIT for a non-bottom value is only
+// valid if justified by a strong quorum of PREPAREs for that value in the same
+// round, and the participant re-uses such a justification to COMMIT to its own
+// proposal only. That is, the value committed to is identical to the one it would
+// have committed to had it received the PREPAREs itself. Strong quorums of
+// PREPARE in a round intersect in at least one honest participant, hence no
+// other non-bottom value can be committed in that round. Finally, the DECIDE
+// phase is still reached only once a strong quorum of COMMITs is observed, which
+// is the justification carried by every DECIDE message.
+func TestHonest_SkipToCommitOnWeakQuorum(t *testing.T) {
+	SkipInRaceMode(t)
+	t.Parallel()
+	const (
+		instanceCount = 500
+		honestCount   = 7
+	)
+	gpbftOptions := append(slices.Clone(testGpbftOptions), gpbft.WithSkipToCommitOnWeakQuorum(true))
+	for _, seed := range []int{-7, 31, 1413} {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			opts := append(asyncOptions(seed), sim.WithGpbftOptions(gpbftOptions...))
+			multiAgreementTest(t, seed, honestCount, instanceCount, maxRounds*2, opts...)
+		})
+	}
+}
+
+// TestHonest_NoSway compares the convergence of honest participants split across
+// differing chains under asynchrony, with and without swaying from their own
+// proposals. Participants that refuse to sway still reach the same decisions,
+// but take longer to do so.
+func TestHonest_NoSway(t *testing.T) {
+	t.Parallel()
+	const instanceCount = 10
+	for _, seed := range []int{-7, 5465} {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			t.Parallel()
+			run := func(noSway bool) (*sim.Simulation, *sim.StatsCollector) {
+				rng := rand.New(rand.NewSource(int64(seed)))
+				commonPrefixGenerator := sim.NewUniformECChainGenerator(rng.Uint64(), 1, 3)
+				stats := sim.NewStatsCollector()
+				sm, err := sim.NewSimulation(append(asyncOptions(rng.Int()),
+					sim.WithGpbftOptions(append(slices.Clone(testGpbftOptions), gpbft.WithNoSway(noSway))...),
+					sim.WithStatsCollector(stats),
+					sim.AddHonestParticipants(20, sim.NewAppendingECChainGenerator(
+						commonPrefixGenerator,
+						sim.NewRandomECChainGenerator(rng.Uint64(), 1, 4),
+					), uniformOneStoragePower),
+					sim.AddHonestParticipants(6, sim.NewAppendingECChainGenerator(
+						sim.NewUniformECChainGenerator(rng.Uint64(), 1, 4),
+						sim.NewRandomECChainGenerator(rng.Uint64(), 2, 3),
+					), uniformOneStoragePower),
+				)...)
+				require.NoError(t, err)
+				require.NoErrorf(t, sm.Run(instanceCount, maxRounds*2), "%s", sm.Describe())
+				for i := uint64(0); i < instanceCount; i++ {
+					instance := sm.GetInstance(i)
+					commonPrefix := commonPrefixGenerator.GenerateECChain(i, instance.BaseChain.Base(), 0)
+					requireConsensusAtInstance(t, sm, i, commonPrefix.TipSets...)
+				}
+				return sm, stats
+			}
+			swayed, swayedStats := run(false)
+			stubborn, stubbornStats := run(true)
+			require.Greater(t, stubborn.Time(), swayed.Time())
+			require.Greater(t, stubbornStats.DurationPercentile(99), swayedStats.DurationPercentile(99))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/filecoin-project/go-f3/sim/adversary"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartition_ConvergesOnBaseOnceHealed(t *testing.T) {
+	t.Parallel()
+	const (
+		gst       = 10 * EcEpochDuration
+		maxRounds = 30
+	)
+	tests := []struct {
+		name    string
+		options []sim.Option
+	}{
+		{
+			name:    "sync",
+			options: syncOptions(),
+		},
+		{
+			name:    "async",
+			options: asyncOptions(2938),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			// Each side of the partition proposes a different chain, sharing only the
+			// base, and neither holds a strong quorum. The partition never heals by
+			// itself since neither side can progress beyond the first round.
+			partition, generator := newCapturingGenerator(func(id gpbft.ActorID, host adversary.Host) *adversary.Partition {
+				return adversary.NewPartition(id, host, 1, 0, []gpbft.ActorID{0, 1, 2}, []gpbft.ActorID{3, 4, 5})
+			})
+			sm, err := sim.NewSimulation(append(test.options,
+				sim.AddHonestParticipants(3, sim.NewUniformECChainGenerator(4353, 1, 5), uniformOneStoragePower),
+				sim.AddHonestParticipants(3, sim.NewUniformECChainGenerator(9182, 1, 5), uniformOneStoragePower),
+				sim.WithAdversary(generator),
+				sim.WithGlobalStabilizationTime(gst),
+			)...)
+			require.NoError(t, err)
+			require.NoErrorf(t, sm.Run(1, maxRounds), "%s", sm.Describe())
+			require.False(t, (*partition).Healed())
+			require.Greater(t, sm.Time(), time.Time{}.Add(gst))
+			requireConsensusAtFirstInstance(t, sm, sm.GetInstance(0).BaseChain.Head())
+		})
+	}
+}
+
+func TestPartition_HealsAtInstance(t *testing.T) {
+	t.Parallel()
+	const (
+		instanceCount  = 5
+		healAtInstance = 2
+		isolated       = 5
+		gst            = 1000 * EcEpochDuration
+	)
+	// The larger side of the partition holds a strong quorum, and so progresses
+	// regardless, with the isolated participant skipped ahead once each instance
+	// completes. Once the larger side reaches the instance at which the partition
+	// heals, the isolated participant decides along with everyone else at every
+	// instance, long before global stabilisation time.
+	ecChainGenerator := sim.NewUniformECChainGenerator(4353, 1, 5)
+	partition, generator := newCapturingGenerator(func(id gpbft.ActorID, host adversary.Host) *adversary.Partition {
+		return adversary.NewPartition(id, host, healAtInstance, 0, []gpbft.ActorID{0, 1, 2, 3, 4}, []gpbft.ActorID{isolated})
+	})
+	sm, err := sim.NewSimulation(syncOptions(
+		sim.AddHonestParticipants(6, ecChainGenerator, uniformOneStoragePower),
+		sim.WithAdversary(generator),
+		sim.WithIgnoreConsensusFor(isolated),
+		sim.WithGlobalStabilizationTime(gst),
+	)...)
+	require.NoError(t, err)
+	require.NoErrorf(t, sm.Run(instanceCount, maxRounds), "%s", sm.Describe())
+	require.True(t, (*partition).Healed())
+	require.Less(t, sm.Time(), time.Time{}.Add(gst))
+	require.Nil(t, sm.GetInstance(0).GetDecision(isolated))
+	// Consensus is not required of the isolated participant by the simulation,
+	// since it cannot decide while isolated. Once healed, it must decide the same
+	// as everyone else.
+	require.Contains(t, sm.ListParticipantIDs(), gpbft.ActorID(isolated))
+	for instance := uint64(healAtInstance); instance < instanceCount; instance++ {
+		chain := ecChainGenerator.GenerateECChain(instance, &gpbft.TipSet{}, math.MaxUint64)
+		requireConsensusAtInstance(t, sm, instance, chain.Head())
+	}
+}
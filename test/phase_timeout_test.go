@@ -0,0 +1,70 @@
+package test
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/filecoin-project/go-f3/sim/adversary"
+	"github.com/filecoin-project/go-f3/sim/signing"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPhaseDeltaMultiplier reuses the scenario of TestConverge_BeaconElectsLeader
+// with participant 0 as the CONVERGE leader, in which participant 0 waits for
+// the QUALITY timeout in round 0, and consensus on the base chain is reached in
+// round 1 once the CONVERGE timeout elapses. Scaling the timeout of either phase
+// delays the decision by exactly the scaled difference, while the decision
+// itself is unchanged.
+func TestPhaseDeltaMultiplier(t *testing.T) {
+	t.Parallel()
+	const (
+		honestCount = 3
+		gst         = 1000 * EcEpochDuration
+		delta       = 200 * time.Millisecond
+		backOff     = 1.3
+	)
+	tsg := sim.NewTipSetGenerator(tipSetGeneratorSeed)
+	baseChain := generateECChain(t, tsg)
+	proposal := baseChain.Extend(tsg.Sample())
+
+	timeToDecide := func(t *testing.T, o ...gpbft.Option) time.Duration {
+		backend := signing.NewFakeBackend()
+		sm, err := sim.NewSimulation(append(syncOptions(
+			sim.WithSigningBackend(backend),
+			sim.WithBaseChain(baseChain),
+			sim.AddHonestParticipants(honestCount, sim.NewFixedECChainGenerator(proposal), uniformOneStoragePower),
+			sim.WithAdversary(adversary.NewDenyGenerator(oneStoragePower, gst, adversary.DenyPhase(gpbft.QUALITY_PHASE), adversary.DenyTo, 0)),
+			sim.WithGlobalStabilizationTime(gst),
+		), sim.WithGpbftOptions(append(slices.Clone(testGpbftOptions), o...)...))...)
+		require.NoError(t, err)
+		sm.SetBeacon(0, findBeaconForLeader(t, backend, honestCount, 0))
+
+		start := sm.Time()
+		require.NoErrorf(t, sm.Run(1, 1), "%s", sm.Describe())
+		requireConsensusAtFirstInstance(t, sm, baseChain.Base())
+		return sm.Time().Sub(start)
+	}
+	// timeout returns the timeout of a phase at the given round and multiplier.
+	timeout := func(round int, multiplier float64) time.Duration {
+		return 2 * time.Duration(float64(delta)*multiplier*math.Pow(backOff, float64(round)))
+	}
+
+	baseline := timeToDecide(t)
+	for _, test := range []struct {
+		phase      gpbft.Phase
+		round      int
+		multiplier float64
+	}{
+		{phase: gpbft.QUALITY_PHASE, round: 0, multiplier: 3},
+		{phase: gpbft.CONVERGE_PHASE, round: 1, multiplier: 4},
+		{phase: gpbft.CONVERGE_PHASE, round: 1, multiplier: 0.5},
+	} {
+		got := timeToDecide(t, gpbft.WithPhaseDeltaMultiplier(test.phase, test.multiplier))
+		want := baseline + timeout(test.round, test.multiplier) - timeout(test.round, 1)
+		require.InDelta(t, want, got, float64(time.Millisecond), "%s multiplied by %.1f", test.phase, test.multiplier)
+	}
+}
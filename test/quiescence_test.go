@@ -0,0 +1,46 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/filecoin-project/go-f3/sim/adversary"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUntilQuiescent_Decides(t *testing.T) {
+	t.Parallel()
+	sm, err := sim.NewSimulation(syncOptions(
+		sim.AddHonestParticipants(3, sim.NewUniformECChainGenerator(tipSetGeneratorSeed, 1, 5), uniformOneStoragePower),
+	)...)
+	require.NoError(t, err)
+
+	q, err := sm.RunUntilQuiescent(maxRounds)
+	require.NoError(t, err)
+	require.True(t, q.Decided, "%s", q)
+	require.False(t, q.Deadlocked())
+	require.Empty(t, q.Stuck)
+}
+
+func TestRunUntilQuiescent_ReportsDeadlock(t *testing.T) {
+	t.Parallel()
+	// The absent adversary holds half the power, so the honest participants can
+	// never form a strong quorum.
+	sm, err := sim.NewSimulation(syncOptions(
+		sim.AddHonestParticipants(2, sim.NewUniformECChainGenerator(tipSetGeneratorSeed, 1, 5), uniformOneStoragePower),
+		sim.WithAdversary(adversary.NewAbsentGenerator(gpbft.NewStoragePower(2))),
+	)...)
+	require.NoError(t, err)
+
+	q, err := sm.RunUntilQuiescent(maxRounds)
+	require.NoError(t, err)
+	require.True(t, q.Deadlocked(), "%s", q)
+	// Without hearing from a strong quorum, neither participant can complete
+	// PREPARE, and is left rebroadcasting its messages.
+	stuckAt := gpbft.Instant{ID: 0, Round: 0, Phase: gpbft.PREPARE_PHASE}
+	require.Equal(t, []sim.StuckParticipant{
+		{ID: 0, Progress: stuckAt},
+		{ID: 1, Progress: stuckAt},
+	}, q.Stuck, "%s", q)
+}
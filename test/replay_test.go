@@ -0,0 +1,66 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay_ReproducesAsyncSimulation(t *testing.T) {
+	t.Parallel()
+	const instanceCount = 3
+	honest := sim.AddHonestParticipants(5, sim.NewUniformECChainGenerator(tipSetGeneratorSeed, 1, 5), uniformOneStoragePower)
+
+	var recording bytes.Buffer
+	recorder := sim.NewRecorder(&recording)
+	recorded, err := sim.NewSimulation(asyncOptions(1413, honest, sim.WithRecorder(recorder))...)
+	require.NoError(t, err)
+	require.NoError(t, recorded.Run(instanceCount, maxRounds), "%s", recorded.Describe())
+	require.NoError(t, recorder.Flush())
+	require.NotZero(t, recording.Len())
+
+	// Replay the recording without latency, recording the replay in turn.
+	var rerecording bytes.Buffer
+	rerecorder := sim.NewRecorder(&rerecording)
+	replayed, err := sim.NewSimulation(syncOptions(honest,
+		sim.WithReplayer(sim.NewReplayer(bytes.NewReader(recording.Bytes()))),
+		sim.WithRecorder(rerecorder),
+	)...)
+	require.NoError(t, err)
+	require.NoError(t, replayed.Run(instanceCount, maxRounds), "%s", replayed.Describe())
+	require.NoError(t, rerecorder.Flush())
+
+	require.Equal(t, recording.Bytes(), rerecording.Bytes())
+	require.Equal(t, recorded.Time(), replayed.Time())
+	for instance := range uint64(instanceCount) {
+		for _, id := range recorded.ListParticipantIDs() {
+			want := recorded.GetInstance(instance).GetDecision(id)
+			got := replayed.GetInstance(instance).GetDecision(id)
+			require.True(t, want.Eq(got), "participant %d decided %s in instance %d on replay, recorded %s", id, got, instance, want)
+		}
+	}
+}
+
+func TestReplay_FailsOnDivergence(t *testing.T) {
+	t.Parallel()
+	var recording bytes.Buffer
+	recorder := sim.NewRecorder(&recording)
+	recorded, err := sim.NewSimulation(asyncOptions(1413,
+		sim.AddHonestParticipants(4, sim.NewUniformECChainGenerator(tipSetGeneratorSeed, 1, 5), uniformOneStoragePower),
+		sim.WithRecorder(recorder),
+	)...)
+	require.NoError(t, err)
+	require.NoError(t, recorded.Run(1, maxRounds))
+	require.NoError(t, recorder.Flush())
+
+	// Replaying with participants proposing different chains diverges from the
+	// recording as soon as the first message is broadcast.
+	replayed, err := sim.NewSimulation(syncOptions(
+		sim.AddHonestParticipants(4, sim.NewUniformECChainGenerator(tipSetGeneratorSeed+1, 1, 5), uniformOneStoragePower),
+		sim.WithReplayer(sim.NewReplayer(&recording)),
+	)...)
+	require.NoError(t, err)
+	require.ErrorContains(t, replayed.Run(1, maxRounds), "replay diverged from recording at message 0")
+}
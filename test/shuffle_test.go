@@ -0,0 +1,71 @@
+package test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/filecoin-project/go-f3/sim/adversary"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShuffler_DoesNotDoubleCountPower(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		options []sim.Option
+	}{
+		{
+			name:    "sync",
+			options: syncOptions(),
+		},
+		{
+			name:    "async",
+			options: asyncOptions(8123),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			// Each half of the honest participants proposes a different chain, sharing
+			// only the base, and neither holds a strong quorum unless the messages of
+			// its members were counted more than once.
+			shuffler, generator := newCapturingGenerator(func(id gpbft.ActorID, host adversary.Host) *adversary.Shuffler {
+				return adversary.NewShuffler(id, host, 1, time.Second, 4, 7)
+			})
+			sm, err := sim.NewSimulation(append(test.options,
+				sim.AddHonestParticipants(3, sim.NewUniformECChainGenerator(4353, 1, 5), uniformOneStoragePower),
+				sim.AddHonestParticipants(3, sim.NewUniformECChainGenerator(9182, 1, 5), uniformOneStoragePower),
+				sim.WithAdversary(generator),
+			)...)
+			require.NoError(t, err)
+			require.NoErrorf(t, sm.Run(1, maxRounds), "%s", sm.Describe())
+			require.NotZero(t, (*shuffler).Relayed())
+			requireConsensusAtFirstInstance(t, sm, sm.GetInstance(0).BaseChain.Head())
+		})
+	}
+}
+
+func TestShuffler_DecisionsUnaffected(t *testing.T) {
+	t.Parallel()
+	const instanceCount = 5
+	for _, seed := range []int64{1413, 2938, 7171} {
+		ecChainGenerator := sim.NewUniformECChainGenerator(uint64(seed), 1, 5)
+		shuffler, generator := newCapturingGenerator(func(id gpbft.ActorID, host adversary.Host) *adversary.Shuffler {
+			return adversary.NewShuffler(id, host, 0.5, time.Second, 3, seed)
+		})
+		sm, err := sim.NewSimulation(asyncOptions(int(seed),
+			sim.AddHonestParticipants(5, ecChainGenerator, uniformOneStoragePower),
+			sim.WithAdversary(generator),
+		)...)
+		require.NoError(t, err)
+		require.NoErrorf(t, sm.Run(instanceCount, maxRounds), "%s", sm.Describe())
+		require.NotZero(t, (*shuffler).Relayed())
+		for instance := range uint64(instanceCount) {
+			chain := ecChainGenerator.GenerateECChain(instance, &gpbft.TipSet{}, math.MaxUint64)
+			requireConsensusAtInstance(t, sm, instance, chain.Head())
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/filecoin-project/go-f3/sim"
+	"github.com/filecoin-project/go-f3/sim/adversary"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsCollector_Sync(t *testing.T) {
+	t.Parallel()
+	const instanceCount = 10
+	stats := sim.NewStatsCollector()
+	sm, err := sim.NewSimulation(syncOptions(
+		sim.AddHonestParticipants(4, sim.NewUniformECChainGenerator(tipSetGeneratorSeed, 1, 5), uniformOneStoragePower),
+		sim.WithStatsCollector(stats),
+	)...)
+	require.NoError(t, err)
+	require.NoError(t, sm.Run(instanceCount, maxRounds), "%s", sm.Describe())
+
+	// Without latency, every instance decides in the first round.
+	decisions := stats.Decisions()
+	require.Len(t, decisions, instanceCount)
+	for i, decision := range decisions {
+		require.Equal(t, uint64(i), decision.Instance)
+		require.Zero(t, decision.Round)
+	}
+	require.Zero(t, stats.RoundPercentile(100))
+}
+
+func TestStatsCollector_Async(t *testing.T) {
+	t.Parallel()
+	const (
+		instanceCount = 50
+		seed          = 1413
+	)
+	honest := sim.AddHonestParticipants(4, sim.NewUniformECChainGenerator(tipSetGeneratorSeed, 1, 5), uniformOneStoragePower)
+
+	stats := sim.NewStatsCollector()
+	collected, err := sim.NewSimulation(asyncOptions(seed, honest, sim.WithStatsCollector(stats))...)
+	require.NoError(t, err)
+	require.NoError(t, collected.Run(instanceCount, maxRounds*2), "%s", collected.Describe())
+
+	require.Len(t, stats.Decisions(), instanceCount)
+	for _, decision := range stats.Decisions() {
+		require.Positive(t, decision.Duration)
+	}
+	require.LessOrEqual(t, stats.RoundPercentile(50), stats.RoundPercentile(90))
+	require.LessOrEqual(t, stats.RoundPercentile(90), stats.RoundPercentile(100))
+	require.Positive(t, stats.DurationPercentile(50))
+	require.LessOrEqual(t, stats.DurationPercentile(50), stats.DurationPercentile(90))
+	require.LessOrEqual(t, stats.DurationPercentile(90), stats.DurationPercentile(100))
+	// Guard against regressions in the latency of decisions under asynchrony.
+	require.LessOrEqual(t, stats.RoundPercentile(90), uint64(3), "%s", stats)
+
+	// Collecting statistics does not perturb the simulation.
+	uncollected, err := sim.NewSimulation(asyncOptions(seed, honest)...)
+	require.NoError(t, err)
+	require.NoError(t, uncollected.Run(instanceCount, maxRounds*2), "%s", uncollected.Describe())
+	require.Equal(t, collected.Time(), uncollected.Time())
+	for instance := range uint64(instanceCount) {
+		for _, id := range collected.ListParticipantIDs() {
+			require.True(t, collected.GetInstance(instance).GetDecision(id).Eq(uncollected.GetInstance(instance).GetDecision(id)))
+		}
+	}
+}
+
+func TestStatsCollector_LaterRound(t *testing.T) {
+	t.Parallel()
+	const gst = 1000 * EcEpochDuration
+	// Denying QUALITY messages to participant 0 prevents a strong quorum of
+	// PREPARE in round 0, as in TestConverge_BeaconElectsLeader.
+	tsg := sim.NewTipSetGenerator(tipSetGeneratorSeed)
+	baseChain := generateECChain(t, tsg)
+	stats := sim.NewStatsCollector()
+	sm, err := sim.NewSimulation(syncOptions(
+		sim.WithBaseChain(baseChain),
+		sim.AddHonestParticipants(3, sim.NewFixedECChainGenerator(baseChain.Extend(tsg.Sample())), uniformOneStoragePower),
+		sim.WithAdversary(adversary.NewDenyGenerator(oneStoragePower, gst, adversary.DenyPhase(gpbft.QUALITY_PHASE), adversary.DenyTo, 0)),
+		sim.WithGlobalStabilizationTime(gst),
+		sim.WithStatsCollector(stats),
+	)...)
+	require.NoError(t, err)
+	require.NoErrorf(t, sm.Run(1, maxRounds), "%s", sm.Describe())
+
+	decisions := stats.Decisions()
+	require.Len(t, decisions, 1)
+	require.Positive(t, decisions[0].Round)
+	require.Equal(t, decisions[0].Round, stats.RoundPercentile(100))
+}
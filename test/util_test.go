@@ -5,6 +5,7 @@ import (
 
 	"github.com/filecoin-project/go-f3/gpbft"
 	"github.com/filecoin-project/go-f3/sim"
+	"github.com/filecoin-project/go-f3/sim/adversary"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,3 +39,14 @@ func generateECChain(t *testing.T, tsg *sim.TipSetGenerator) *gpbft.ECChain {
 	require.NoError(t, err)
 	return chain
 }
+
+// newCapturingGenerator returns a generator of an adversary with one unit of
+// power that receives messages via the receiver constructed by newReceiver,
+// along with the receiver once generated.
+func newCapturingGenerator[R adversary.Receiver](newReceiver func(gpbft.ActorID, adversary.Host) R) (*R, adversary.Generator) {
+	var receiver R
+	return &receiver, func(id gpbft.ActorID, host adversary.Host) *adversary.Adversary {
+		receiver = newReceiver(id, host)
+		return &adversary.Adversary{Receiver: receiver, Power: oneStoragePower}
+	}
+}
@@ -1,10 +1,13 @@
 package f3
 
 import (
+	"fmt"
 	"io"
+	"sync"
 
 	"github.com/filecoin-project/go-f3/gpbft"
 	"github.com/filecoin-project/go-f3/internal/writeaheadlog"
+	"go.uber.org/multierr"
 )
 
 type walEntry struct {
@@ -25,3 +28,130 @@ func (we *walEntry) UnmarshalCBOR(r io.Reader) error {
 	we.Message = &gpbft.GMessage{}
 	return we.Message.UnmarshalCBOR(r)
 }
+
+// receivedJournal journals the messages received for the current and future
+// instances, so that the progress of an instance survives a restart. Messages
+// journaled before the runner started are replayed to the participant as the
+// instance they belong to starts.
+//
+// Messages are written to disk in batches off the path of message delivery, so
+// the few received just before a crash may not be journaled. Losing them only
+// costs the time to receive them again by rebroadcast.
+type receivedJournal struct {
+	wal *writeaheadlog.WriteAheadLog[walEntry, *walEntry]
+
+	// mu guards access to replay and pending.
+	mu sync.Mutex
+	// replay holds the messages journaled before the journal was opened, by
+	// instance, until taken for replay or purged.
+	replay map[uint64][]*gpbft.GMessage
+	// pending holds the messages appended but not yet written to disk.
+	pending []walEntry
+
+	pendingSignal chan struct{}
+	closing       chan struct{}
+	closed        chan struct{}
+	closeOnce     sync.Once
+	closeErr      error
+}
+
+func openReceivedJournal(path string) (*receivedJournal, error) {
+	wal, err := writeaheadlog.Open[walEntry](path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := wal.All()
+	if err != nil {
+		return nil, multierr.Append(fmt.Errorf("reading journal: %w", err), wal.Close())
+	}
+	replay := make(map[uint64][]*gpbft.GMessage)
+	for _, entry := range entries {
+		instance := entry.Message.Vote.Instance
+		replay[instance] = append(replay[instance], entry.Message)
+	}
+	j := &receivedJournal{
+		wal:           wal,
+		replay:        replay,
+		pendingSignal: make(chan struct{}, 1),
+		closing:       make(chan struct{}),
+		closed:        make(chan struct{}),
+	}
+	go j.writeLoop()
+	return j, nil
+}
+
+// Append queues the given message to be journaled without waiting for it to be
+// written to disk.
+func (j *receivedJournal) Append(msg *gpbft.GMessage) {
+	j.mu.Lock()
+	j.pending = append(j.pending, walEntry{msg})
+	j.mu.Unlock()
+	select {
+	case j.pendingSignal <- struct{}{}:
+	default:
+	}
+}
+
+func (j *receivedJournal) writeLoop() {
+	defer close(j.closed)
+	for {
+		select {
+		case <-j.pendingSignal:
+			j.writePending()
+		case <-j.closing:
+			j.writePending()
+			return
+		}
+	}
+}
+
+// writePending writes every message queued since the last write to disk, with
+// a single sync of the log.
+func (j *receivedJournal) writePending() {
+	j.mu.Lock()
+	pending := j.pending
+	j.pending = nil
+	j.mu.Unlock()
+	if err := j.wal.AppendAll(pending...); err != nil {
+		log.Errorw("appending received messages to journal", "count", len(pending), "error", err)
+	}
+}
+
+// TakeReplay returns the messages journaled for the given instance before the
+// journal was opened, and discards them along with those of prior instances.
+func (j *receivedJournal) TakeReplay(instance uint64) []*gpbft.GMessage {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	messages := j.replay[instance]
+	for journaled := range j.replay {
+		if journaled <= instance {
+			delete(j.replay, journaled)
+		}
+	}
+	return messages
+}
+
+// Purge discards the messages of every instance up to and including the given
+// decided instance. Messages are only removed from disk a whole log file at a
+// time, so some may linger until then, but are never replayed.
+func (j *receivedJournal) Purge(decided uint64) error {
+	j.mu.Lock()
+	for journaled := range j.replay {
+		if journaled <= decided {
+			delete(j.replay, journaled)
+		}
+	}
+	j.mu.Unlock()
+	return j.wal.Purge(decided + 1)
+}
+
+// Close writes any messages pending to disk and closes the journal. Closing an
+// already closed journal returns the error of the first Close.
+func (j *receivedJournal) Close() error {
+	j.closeOnce.Do(func() {
+		close(j.closing)
+		<-j.closed
+		j.closeErr = j.wal.Close()
+	})
+	return j.closeErr
+}
@@ -0,0 +1,56 @@
+package f3
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-f3/gpbft"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceivedJournal(t *testing.T) {
+	dir := t.TempDir()
+	ptCid := gpbft.MakeCid([]byte("pt"))
+	chain, err := gpbft.NewChain(&gpbft.TipSet{Epoch: 0, Key: []byte("fish"), PowerTable: ptCid})
+	require.NoError(t, err)
+	newMessage := func(sender gpbft.ActorID, instance uint64) *gpbft.GMessage {
+		return &gpbft.GMessage{
+			Sender: sender,
+			Vote: gpbft.Payload{
+				Instance:         instance,
+				Phase:            gpbft.PREPARE_PHASE,
+				Value:            chain,
+				SupplementalData: gpbft.SupplementalData{PowerTable: ptCid},
+			},
+			Signature: []byte{byte(sender), byte(instance)},
+		}
+	}
+
+	subject, err := openReceivedJournal(dir)
+	require.NoError(t, err)
+	var want []*gpbft.GMessage
+	for instance := uint64(1); instance <= 3; instance++ {
+		for sender := gpbft.ActorID(0); sender < 2; sender++ {
+			msg := newMessage(sender, instance)
+			subject.Append(msg)
+			want = append(want, msg)
+		}
+	}
+	// Messages journaled since opening are not replayed, having been received.
+	require.Empty(t, subject.TakeReplay(2))
+	require.NoError(t, subject.Close())
+
+	// Upon reopening, the messages of each instance are replayed once, and those of
+	// prior instances discarded.
+	subject, err = openReceivedJournal(dir)
+	require.NoError(t, err)
+	require.Equal(t, want[2:4], subject.TakeReplay(2))
+	require.Empty(t, subject.TakeReplay(2))
+	require.Empty(t, subject.TakeReplay(1))
+
+	// Once decided, an instance is never replayed.
+	require.NoError(t, subject.Purge(3))
+	require.Empty(t, subject.TakeReplay(3))
+	require.NoError(t, subject.Close())
+	// Closing again is a no-op.
+	require.NoError(t, subject.Close())
+}